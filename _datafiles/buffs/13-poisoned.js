@@ -7,7 +7,8 @@ function onStart(actor, triggersLeft) {
 
 // Invoked every time the buff is triggered (see roundinterval)
 function onTrigger(actor, triggersLeft) {
-    dmgAmt = Math.abs(Math.abs(actor.AddHealth(UtilDiceRoll(1, 8)*-1)))
+    // Every stack of poison adds another die of damage
+    dmgAmt = actor.TakeDamage(UtilDiceRoll(Math.max(1, actor.GetBuffStacks(13)), 8), 'poison')
 
     SendUserMessage(actor.UserId(),     'The poison hurts you for <ansi fg="damage">'+String(dmgAmt)+' damage</ansi>!')
     SendRoomMessage(actor.GetRoomId(),  actor.GetCharacterName(true)+' convulses under the effects of a poison.', actor.UserId())
@@ -1,12 +1,14 @@
 
 // Invoked when the buff is first applied to the player.
 function onStart(actor, triggersLeft) {
-    
+    if ( (room = GetRoom(actor.GetRoomId())) != null ) {
+        room.PropagateSound('an explosion', 4);
+    }
 }
 
 // Invoked every time the buff is triggered (see roundinterval)
 function onTrigger(actor, triggersLeft) {
-    dmgAmt = Math.abs(actor.AddHealth(-1*(UtilDiceRoll(2, 9)+2)))
+    dmgAmt = actor.TakeDamage(UtilDiceRoll(2, 9)+2, 'fire')
 
     SendUserMessage(actor.UserId(),     'Fiery shrapnel hits you for <ansi fg="damage">'+String(dmgAmt)+' damage</ansi>!')
     SendRoomMessage(actor.GetRoomId(),  'Fiery shrapnel hits '+actor.GetCharacterName(true)+'', actor.UserId())
@@ -7,7 +7,7 @@ function onStart(actor, triggersLeft) {
 
 // Invoked every time the buff is triggered (see roundinterval)
 function onTrigger(actor, triggersLeft) {
-    dmgAmt = Math.abs(actor.AddHealth(-1*UtilDiceRoll(2, 6)))
+    dmgAmt = actor.TakeDamage(UtilDiceRoll(2, 6), 'fire')
 
     SendUserMessage(actor.UserId(),     'Flames envelop you, causing <ansi fg="damage">'+String(dmgAmt)+' damage</ansi> while you writh in pain!')
     SendRoomMessage(actor.GetRoomId(),  actor.GetCharacterName(true)+' is enveloped in <ansi fg="red">flames</ansi>.', actor.UserId())
@@ -6,10 +6,7 @@ function onStart(actor, triggersLeft) {
         actor.RemoveBuff(31)
         return
     }
-    harmAmt = actor.AddHealth(-1 * UtilDiceRoll(1, 2));
-    if (harmAmt < 1 ) {
-        harmAmt *= -1;
-    }
+    harmAmt = actor.TakeDamage(UtilDiceRoll(1, 2), 'ice');
     SendUserMessage(actor.UserId(),     '<ansi fg="51">The cold bites for <ansi fg="damage">'+String(harmAmt)+' damage</ansi>!</ansi>\n');
     SendRoomMessage(actor.GetRoomId(),  actor.GetCharacterName(true)+' is freezing.', actor.UserId());
 
@@ -0,0 +1,15 @@
+// Invoked when the buff is first applied to the player.
+function onStart(actor, triggersLeft) {
+
+    // The rain puts out any fires
+    if ( actor.CancelBuffWithFlag("cancel-on-water") ) {
+        SendUserMessage(actor.UserId(),     'The rain puts out the flames!');
+    }
+
+    SendUserMessage(actor.UserId(),     '<ansi fg="117">You are soaked through by the rain.</ansi>');
+}
+
+// Invoked when the buff has run its course.
+function onEnd(actor, triggersLeft) {
+    SendUserMessage(actor.UserId(),     'You finally dry off.');
+}
@@ -0,0 +1,29 @@
+// Invoked when the buff is first applied to the player.
+function onStart(actor, triggersLeft) {
+
+    if ( actor.HasBuffFlag("warmed")  ) {
+        actor.RemoveBuff(41)
+        return
+    }
+
+    SendUserMessage(actor.UserId(),     '<ansi fg="51">The falling snow chills you.</ansi>');
+}
+
+// Invoked every time the buff is triggered (see roundinterval)
+function onTrigger(actor, triggersLeft) {
+
+    if ( actor.HasBuffFlag("warmed")  ) {
+        actor.RemoveBuff(41)
+        return
+    }
+
+    harmAmt = actor.TakeDamage(1, 'ice');
+
+    SendUserMessage(actor.UserId(),     '<ansi fg="51">You shiver from the cold, taking <ansi fg="damage">'+String(harmAmt)+' damage</ansi>.</ansi>');
+    SendRoomMessage(actor.GetRoomId(),  actor.GetCharacterName(true)+' shivers from the cold.', actor.UserId());
+}
+
+// Invoked when the buff has run its course.
+function onEnd(actor, triggersLeft) {
+    SendUserMessage(actor.UserId(),     'You are no longer chilled.');
+}
@@ -0,0 +1,15 @@
+// Invoked when the buff is first applied to the player.
+function onStart(actor, triggersLeft) {
+
+    if ( actor.HasBuffFlag("hydrated")  ) {
+        actor.RemoveBuff(42)
+        return
+    }
+
+    SendUserMessage(actor.UserId(),     '<ansi fg="208">Sweat pours from you in the stifling heat.</ansi>');
+}
+
+// Invoked when the buff has run its course.
+function onEnd(actor, triggersLeft) {
+    SendUserMessage(actor.UserId(),     'You no longer feel overwhelmed by the heat.');
+}
@@ -0,0 +1,20 @@
+
+// Invoked when the buff is first applied to the player.
+function onStart(actor, triggersLeft) {
+    SendUserMessage(actor.UserId(),     'You choke on a lungful of water!')
+    SendRoomMessage(actor.GetRoomId(),  actor.GetCharacterName(true)+' is choking on water.', actor.UserId())
+}
+
+// Invoked every time the buff is triggered (see roundinterval)
+function onTrigger(actor, triggersLeft) {
+    dmgAmt = Math.abs(actor.AddHealth(-1*UtilDiceRoll(1, 6)))
+
+    SendUserMessage(actor.UserId(),     'You cough up water, taking <ansi fg="damage">'+String(dmgAmt)+' damage</ansi>!')
+    SendRoomMessage(actor.GetRoomId(),  actor.GetCharacterName(true)+' coughs and sputters.', actor.UserId())
+}
+
+// Invoked when the buff has run its course.
+function onEnd(actor, triggersLeft) {
+    SendUserMessage(actor.UserId(),     'You finally catch your breath.')
+    SendRoomMessage(actor.GetRoomId(),  actor.GetCharacterName(true)+' catches their breath.', actor.UserId())
+}
@@ -0,0 +1,14 @@
+
+// Invoked when the event starts.
+function onStart(event) {
+    SendBroadcast('The full moon rises over the land.');
+
+    if ( (room = GetRoom(526)) != null ) {
+        room.PropagateSound('wolves howling', 5);
+    }
+}
+
+// Invoked when the event ends.
+function onEnd(event) {
+
+}
@@ -132,3 +132,33 @@ function onConverse(message, mob, sourceMob, room) {
         return true;
     }
 }
+
+// Every third round of a fight he slips into the shadows instead of attacking,
+// and his next strike comes out of nowhere for double damage.
+function onCombatRound(mob, room, eventDetails) {
+
+    if ( eventDetails.round % 3 != 0 ) {
+        return false;
+    }
+
+    mob.SetTempData("shadowStrike", true);
+    room.SendText( mob.GetCharacterName(true) + " slips into the shadows..." );
+
+    return true;
+}
+
+function onAttack(mob, room, eventDetails) {
+
+    if ( mob.GetTempData("shadowStrike") !== true ) {
+        return false;
+    }
+
+    mob.SetTempData("shadowStrike", false);
+
+    if ( eventDetails.attack.IsHit() ) {
+        eventDetails.attack.SetDamage(eventDetails.attack.GetDamage() * 2);
+        eventDetails.attack.SendToTarget(mob.GetCharacterName(true) + ' strikes at you from the shadows!');
+    }
+
+    return false;
+}
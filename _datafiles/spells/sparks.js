@@ -27,7 +27,8 @@ function onMagic(sourceActor, targetActors) {
 
     for (var i = 0; i < targetActors.length; i++) {
         
-        dmgAmt = UtilDiceRoll(DMG_DICE_QTY, DMG_DICE_SIDES) + 1;
+        // Sparks are electricity, so some targets shrug them off
+        dmgAmt = targetActors[i].TakeDamage(UtilDiceRoll(DMG_DICE_QTY, DMG_DICE_SIDES) + 1, 'electricity');
         dmgAmtStr = String(dmgAmt);
 
         targetUserId = targetActors[i].UserId();
@@ -53,9 +54,6 @@ function onMagic(sourceActor, targetActors) {
             SendRoomMessage(roomId, sourceName+' stops chanting and fires a shower of sparks at themselves, hurting themselves.', sourceUserId, targetUserId);
 
         }
-
-        // Apply the dmg to the target
-        targetActors[i].AddHealth(dmgAmt * -1);
     }
     
 }
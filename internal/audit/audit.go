@@ -0,0 +1,381 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/volte6/gomud/internal/util"
+	"gopkg.in/yaml.v2"
+)
+
+//
+// A journal of changes builders make to the world.
+// Every entry keeps a snapshot of the thing before and after the change,
+// so it can be reviewed later and undone if needed.
+//
+
+type Kind string
+
+const (
+	KindRoom  Kind = `room`
+	KindZone  Kind = `zone`
+	KindMob   Kind = `mob`
+	KindItem  Kind = `item`
+	KindBuff  Kind = `buff`
+	KindQuest Kind = `quest`
+
+	journalFilePath = `_datafiles/audit/journal.jsonl`
+)
+
+var (
+	lock    = sync.Mutex{}
+	entries = []Entry{}
+	undone  = map[int]int{} // key = entryId that was undone, value = entryId of the undo
+	nextId  = 1
+	session *Session // The builder command currently running, if any
+)
+
+// Changes are only journaled while a session is open.
+// A session is opened around every admin command, so any save it makes is credited to that builder.
+type Session struct {
+	UserId   int
+	Username string
+	Action   string
+	// Set by undo, so the change it makes is recorded as reverting these entries
+	undoKind     Kind
+	undoEntityId string
+	undoIds      []int
+}
+
+type Entry struct {
+	EntryId   int
+	Time      time.Time
+	UserId    int
+	Username  string
+	Kind      Kind
+	EntityId  string // roomId, zone name, mobId etc.
+	Action    string // What was typed to make the change
+	Before    string `json:",omitempty"` // Empty if the change created it
+	After     string
+	UndoneIds []int `json:",omitempty"` // If this entry is an undo, which entries it reverted
+}
+
+func (e Entry) IsUndo() bool {
+	return len(e.UndoneIds) > 0
+}
+
+// Whether a later undo reverted this entry
+func (e Entry) IsUndone() bool {
+	lock.Lock()
+	defer lock.Unlock()
+
+	_, ok := undone[e.EntryId]
+	return ok
+}
+
+func AllKinds() []Kind {
+	return []Kind{KindRoom, KindZone, KindMob, KindItem, KindBuff, KindQuest}
+}
+
+func Begin(userId int, username string, action string) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	session = &Session{
+		UserId:   userId,
+		Username: username,
+		Action:   action,
+	}
+}
+
+func End() {
+	lock.Lock()
+	defer lock.Unlock()
+
+	session = nil
+}
+
+func Active() bool {
+	lock.Lock()
+	defer lock.Unlock()
+
+	return session != nil
+}
+
+// Flags the next change to a thing in this session as an undo of the given entries
+func Undoing(kind Kind, entityId string, entryIds []int) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if session == nil {
+		return
+	}
+
+	session.undoKind = kind
+	session.undoEntityId = entityId
+	session.undoIds = entryIds
+}
+
+// Journals a change made during the current session.
+// Called by whatever saves the thing, with yaml snapshots of it before and after.
+func Capture(kind Kind, entityId string, before string, after string) {
+
+	lock.Lock()
+	if session == nil {
+		lock.Unlock()
+		return
+	}
+
+	e := Entry{
+		UserId:   session.UserId,
+		Username: session.Username,
+		Kind:     kind,
+		EntityId: entityId,
+		Action:   session.Action,
+		Before:   before,
+		After:    after,
+	}
+
+	if session.undoKind == kind && session.undoEntityId == entityId {
+		e.UndoneIds = session.undoIds
+		session.undoKind = ``
+		session.undoEntityId = ``
+		session.undoIds = nil
+	}
+	lock.Unlock()
+
+	Record(e)
+}
+
+// Returns the yaml used as a snapshot of something.
+func Snapshot(v any) string {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		slog.Error("audit.Snapshot()", "error", err)
+		return ``
+	}
+	return string(data)
+}
+
+// Adds a change to the journal.
+// Nothing is recorded if the snapshots are identical, unless it's an undo.
+func Record(e Entry) (Entry, bool) {
+
+	if e.Before == e.After && !e.IsUndo() {
+		return e, false
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	e.EntryId = nextId
+	nextId++
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	entries = append(entries, e)
+
+	for _, entryId := range e.UndoneIds {
+		undone[entryId] = e.EntryId
+	}
+
+	if err := appendToFile(e); err != nil {
+		slog.Error("audit.Record()", "entryId", e.EntryId, "error", err)
+	}
+
+	return e, true
+}
+
+// Returns the journal for one thing, newest first.
+// A limit of zero returns everything.
+func GetHistory(kind Kind, entityId string, limit int) []Entry {
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	ret := []Entry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Kind != kind || entries[i].EntityId != entityId {
+			continue
+		}
+		ret = append(ret, entries[i])
+		if limit > 0 && len(ret) >= limit {
+			break
+		}
+	}
+
+	return ret
+}
+
+// Returns the latest changes to anything, newest first.
+func GetRecent(limit int) []Entry {
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	ret := []Entry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		ret = append(ret, entries[i])
+		if limit > 0 && len(ret) >= limit {
+			break
+		}
+	}
+
+	return ret
+}
+
+// Returns the most recent changes to a thing that can still be undone, newest first.
+// Stops at the first undo, since anything before it has already been stepped back through.
+func GetUndoable(kind Kind, entityId string, count int) []Entry {
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	ret := []Entry{}
+	for i := len(entries) - 1; i >= 0 && len(ret) < count; i-- {
+		e := entries[i]
+		if e.Kind != kind || e.EntityId != entityId {
+			continue
+		}
+		if _, ok := undone[e.EntryId]; ok {
+			continue
+		}
+		if e.IsUndo() {
+			continue
+		}
+		ret = append(ret, e)
+	}
+
+	return ret
+}
+
+// Returns a simple line by line diff of two snapshots.
+// Removed lines start with "-", added lines start with "+".
+func Diff(before string, after string) []string {
+
+	beforeLines := strings.Split(strings.TrimSpace(before), "\n")
+	afterLines := strings.Split(strings.TrimSpace(after), "\n")
+
+	if before == `` {
+		beforeLines = []string{}
+	}
+	if after == `` {
+		afterLines = []string{}
+	}
+
+	// Longest common subsequence, so that moved/unchanged lines line up
+	lcs := make([][]int, len(beforeLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(afterLines)+1)
+	}
+	for i := len(beforeLines) - 1; i >= 0; i-- {
+		for j := len(afterLines) - 1; j >= 0; j-- {
+			if beforeLines[i] == afterLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := []string{}
+	i, j := 0, 0
+	for i < len(beforeLines) && j < len(afterLines) {
+		if beforeLines[i] == afterLines[j] {
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			diff = append(diff, `- `+beforeLines[i])
+			i++
+		} else {
+			diff = append(diff, `+ `+afterLines[j])
+			j++
+		}
+	}
+	for ; i < len(beforeLines); i++ {
+		diff = append(diff, `- `+beforeLines[i])
+	}
+	for ; j < len(afterLines); j++ {
+		diff = append(diff, `+ `+afterLines[j])
+	}
+
+	return diff
+}
+
+func appendToFile(e Entry) error {
+
+	filePath := util.FilePath(journalFilePath)
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Loads the journal from disk
+func LoadDataFiles() {
+
+	start := time.Now()
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	entries = []Entry{}
+	undone = map[int]int{}
+	nextId = 1
+
+	f, err := os.Open(util.FilePath(journalFilePath))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Error("audit.LoadDataFiles()", "error", err)
+		}
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+
+		e := Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			slog.Error("audit.LoadDataFiles()", "error", fmt.Sprintf(`line %d: %s`, lineNum, err))
+			continue
+		}
+
+		entries = append(entries, e)
+		for _, entryId := range e.UndoneIds {
+			undone[entryId] = e.EntryId
+		}
+		if e.EntryId >= nextId {
+			nextId = e.EntryId + 1
+		}
+	}
+
+	slog.Info("audit.LoadDataFiles()", "loadedCount", len(entries), "Time Taken", time.Since(start))
+}
@@ -1,8 +1,25 @@
 package buffs
 
+import (
+	"errors"
+	"strconv"
+
+	"github.com/volte6/gomud/internal/util"
+)
+
 const (
 	TriggersLeftExpired   = 0 // When it hits this number it will be pruned ASAP
 	TriggersLeftUnlimited = 1000000000
+
+	DiminishResetRounds = 20 // Diminishing returns wear off after this many rounds without the buff being reapplied
+	DiminishImmuneAfter = 3  // Once applied this many times in a row, the target is immune until diminishing returns wear off
+)
+
+var (
+	ErrUnknownBuff = errors.New(`buff does not exist`)
+	ErrBuffIgnored = errors.New(`buff is already applied and does not stack`)
+	ErrBuffImmune  = errors.New(`immune to buff due to diminishing returns`)
+	ErrBuffBlocked = errors.New(`a permanent buff in the same group is applied`)
 )
 
 type Buff struct {
@@ -10,36 +27,63 @@ type Buff struct {
 	OnStartEvent bool // Has the onStart event been triggered?
 	PermaBuff    bool `yaml:"permabuff,omitempty"` // Is this buff from a worn item or race?
 	// Need to instance track the following:
-	RoundCounter int `yaml:"roundcounter,omitempty"` // How many rounds have passed. Triggers on (RoundCounter%RoundInterval == 0)
-	TriggersLeft int `yaml:"triggersleft,omitempty"` // How many times it triggers
+	RoundCounter        int `yaml:"roundcounter,omitempty"`        // How many rounds have passed. Triggers on (RoundCounter%RoundInterval == 0)
+	TriggersLeft        int `yaml:"triggersleft,omitempty"`        // How many times it triggers
+	Stacks              int `yaml:"stacks,omitempty"`              // How many times it has stacked up, for buffs that stack
+	SourceUserId        int `yaml:"sourceuserid,omitempty"`        // Who applied it, if a player
+	SourceMobInstanceId int `yaml:"sourcemobinstanceid,omitempty"` // Who applied it, if a mob
 }
 
+// Who applied a buff. Zero values mean it came from nowhere in particular (items, rooms, etc.)
+type Source struct {
+	UserId        int
+	MobInstanceId int
+}
+
+// How many stacks this buff has. Always at least one.
+func (b *Buff) StackCount() int {
+	return max(b.Stacks, 1)
+}
+
+// Statmods are multiplied by the number of stacks
 func (b *Buff) StatMod(statName string) int {
 	if b.Expired() {
 		return 0
 	}
 	if buffInfo := GetBuffSpec(b.BuffId); buffInfo != nil {
-		return buffInfo.StatMods.Get(statName)
+		return buffInfo.StatMods.Get(statName) * b.StackCount()
 	}
 	return 0
 }
 
+func (b *Buff) fromSource(src Source) bool {
+	return b.SourceUserId == src.UserId && b.SourceMobInstanceId == src.MobInstanceId
+}
+
 func (b *Buff) Expired() bool {
 	return b.TriggersLeft <= TriggersLeftExpired
 }
 
+// Tracks how often a crowd control buff has been applied recently
+type diminishInfo struct {
+	Count     int    // Applications since diminishing returns last wore off
+	LastRound uint64 // When it was last applied
+}
+
 // A list of applied buffs
 type Buffs struct {
-	List      []*Buff
-	buffFlags map[Flag][]int // a map of buff flags to the index of the buff
-	buffIds   map[int]int    // a map of a buffId to it position in buffList
+	List       []*Buff
+	buffFlags  map[Flag][]int           // a map of buff flags to the index of the buff
+	buffIds    map[int][]int            // a map of a buffId to its positions in buffList. Independent buffs may have several.
+	diminished map[string]*diminishInfo // diminishing returns, by buff group or buffId
 }
 
 func New() Buffs {
 	return Buffs{
-		List:      []*Buff{},
-		buffFlags: make(map[Flag][]int),
-		buffIds:   make(map[int]int),
+		List:       []*Buff{},
+		buffFlags:  make(map[Flag][]int),
+		buffIds:    make(map[int][]int),
+		diminished: make(map[string]*diminishInfo),
 	}
 }
 
@@ -48,16 +92,24 @@ func (bs *Buffs) Validate(forceRebuild ...bool) {
 		bs.buffFlags = make(map[Flag][]int)
 	}
 	if bs.buffIds == nil {
-		bs.buffIds = make(map[int]int)
+		bs.buffIds = make(map[int][]int)
+	}
+	if bs.diminished == nil {
+		bs.diminished = make(map[string]*diminishInfo)
+	}
+
+	indexCount := 0
+	for _, indexes := range bs.buffIds {
+		indexCount += len(indexes)
 	}
 
-	if (len(bs.List) != len(bs.buffIds)) || (len(forceRebuild) > 0 && forceRebuild[0]) {
+	if (len(bs.List) != indexCount) || (len(forceRebuild) > 0 && forceRebuild[0]) {
 		// Rebuild
-		bs.buffIds = make(map[int]int)
+		bs.buffIds = make(map[int][]int)
 		bs.buffFlags = make(map[Flag][]int)
 
 		for idx, b := range bs.List {
-			bs.buffIds[b.BuffId] = idx
+			bs.buffIds[b.BuffId] = append(bs.buffIds[b.BuffId], idx)
 			bSpec := GetBuffSpec(b.BuffId)
 			for _, flag := range bSpec.Flags {
 				if _, ok := bs.buffFlags[flag]; !ok {
@@ -84,19 +136,35 @@ func (bs *Buff) Name() string {
 	return ""
 }
 
+// Removes every instance of a buff
 func (bs *Buffs) RemoveBuff(buffId int) bool {
-	if index, ok := bs.buffIds[buffId]; ok {
-		bs.List[index].TriggersLeft = TriggersLeftExpired
+	if indexes, ok := bs.buffIds[buffId]; ok {
+		for _, index := range indexes {
+			bs.List[index].TriggersLeft = TriggersLeftExpired
+		}
 		return true
 	}
 	return false
 }
 
+// Returns the most triggers left of any instance of a buff
 func (bs *Buffs) TriggersLeft(buffId int) int {
-	if idx, ok := bs.buffIds[buffId]; ok {
-		return bs.List[idx].TriggersLeft
+	triggersLeft := 0
+	for _, idx := range bs.buffIds[buffId] {
+		triggersLeft = max(triggersLeft, bs.List[idx].TriggersLeft)
 	}
-	return 0
+	return triggersLeft
+}
+
+// Returns the total stacks of a buff, counting independent instances as a stack each
+func (bs *Buffs) StackCount(buffId int) int {
+	stacks := 0
+	for _, idx := range bs.buffIds[buffId] {
+		if !bs.List[idx].Expired() {
+			stacks += bs.List[idx].StackCount()
+		}
+	}
+	return stacks
 }
 
 func (bs *Buffs) GetBuffIdsWithFlag(action Flag) []int {
@@ -150,55 +218,208 @@ func (bs *Buffs) HasFlag(action Flag, expire bool) bool {
 }
 
 func (bs *Buffs) HasBuff(buffId int) bool {
-	if _, ok := bs.buffIds[buffId]; ok {
+	if len(bs.buffIds[buffId]) > 0 {
 		return true
 	}
 	return false
 }
 
 func (bs *Buffs) Started(buffId int) {
-	if idx, ok := bs.buffIds[buffId]; ok {
+	for _, idx := range bs.buffIds[buffId] {
 		bs.List[idx].OnStartEvent = true
 	}
 }
 
-func (bs *Buffs) AddBuff(buffId int, isPermanent bool) bool {
-	if buffInfo := GetBuffSpec(buffId); buffInfo != nil {
+// Applies a buff, following its stacking policy, group and diminishing returns.
+// Permanent buffs (from gear, race, etc.) always just refresh.
+func (bs *Buffs) AddBuff(buffId int, isPermanent bool, source ...Source) error {
+
+	buffInfo := GetBuffSpec(buffId)
+	if buffInfo == nil {
+		return ErrUnknownBuff
+	}
+
+	src := Source{}
+	if len(source) > 0 {
+		src = source[0]
+	}
 
-		newBuff := Buff{
-			BuffId:       buffInfo.BuffId,
-			RoundCounter: 0,
-			PermaBuff:    false,
-			TriggersLeft: buffInfo.TriggerCount,
+	newBuff := Buff{
+		BuffId:              buffInfo.BuffId,
+		RoundCounter:        0,
+		PermaBuff:           false,
+		TriggersLeft:        buffInfo.TriggerCount,
+		SourceUserId:        src.UserId,
+		SourceMobInstanceId: src.MobInstanceId,
+	}
+
+	if isPermanent {
+		newBuff.TriggersLeft = TriggersLeftUnlimited
+		newBuff.PermaBuff = true
+	}
+
+	existing := []*Buff{}
+	for _, idx := range bs.buffIds[buffId] {
+		if !bs.List[idx].Expired() {
+			existing = append(existing, bs.List[idx])
 		}
+	}
 
-		if isPermanent {
-			newBuff.TriggersLeft = TriggersLeftUnlimited
-			newBuff.PermaBuff = true
+	if isPermanent {
+		if len(existing) > 0 {
+			existing[0].TriggersLeft = newBuff.TriggersLeft
+			existing[0].PermaBuff = newBuff.PermaBuff
+			return nil
 		}
+		bs.removeGroup(buffInfo)
+		bs.appendBuff(&newBuff, buffInfo)
+		return nil
+	}
 
-		if idx, ok := bs.buffIds[buffId]; ok {
-			bs.List[idx].TriggersLeft = newBuff.TriggersLeft
-			bs.List[idx].PermaBuff = newBuff.PermaBuff
-			return true
+	if len(existing) > 0 && buffInfo.GetStacking() == StackIgnore {
+		return ErrBuffIgnored
+	}
+
+	// Only one buff from a group can be applied at a time, and gear always wins
+	if buffInfo.Group != `` && bs.hasPermanentInGroup(buffInfo) {
+		return ErrBuffBlocked
+	}
+
+	if buffInfo.Diminishing {
+		triggers, err := bs.diminish(buffInfo)
+		if err != nil {
+			return err
 		}
+		newBuff.TriggersLeft = triggers
+	}
+
+	if len(existing) > 0 {
+
+		switch buffInfo.GetStacking() {
 
-		bs.List = append(bs.List, &newBuff)
-		listIndex := len(bs.List) - 1
-		bs.buffIds[buffId] = listIndex
-		for _, flag := range buffInfo.Flags {
-			if _, ok := bs.buffFlags[flag]; !ok {
-				bs.buffFlags[flag] = []int{}
+		case StackStack:
+			b := existing[0]
+			b.Stacks = min(b.StackCount()+1, buffInfo.MaxStacks)
+			b.TriggersLeft = max(b.TriggersLeft, newBuff.TriggersLeft)
+			b.SourceUserId, b.SourceMobInstanceId = src.UserId, src.MobInstanceId
+			return nil
+
+		case StackIndependent:
+			// The same source just refreshes their own copy
+			for _, b := range existing {
+				if b.fromSource(src) {
+					b.TriggersLeft = max(b.TriggersLeft, newBuff.TriggersLeft)
+					return nil
+				}
 			}
-			bs.buffFlags[flag] = append(bs.buffFlags[flag], listIndex)
+			// Too many copies? Refresh whichever is closest to wearing off
+			if buffInfo.MaxStacks > 0 && len(existing) >= buffInfo.MaxStacks {
+				oldest := existing[0]
+				for _, b := range existing[1:] {
+					if b.TriggersLeft < oldest.TriggersLeft {
+						oldest = b
+					}
+				}
+				oldest.TriggersLeft = newBuff.TriggersLeft
+				oldest.RoundCounter = 0
+				oldest.SourceUserId, oldest.SourceMobInstanceId = src.UserId, src.MobInstanceId
+				return nil
+			}
+			bs.appendBuff(&newBuff, buffInfo)
+			return nil
+
+		default: // StackRefresh
+			b := existing[0]
+			if !b.PermaBuff {
+				b.TriggersLeft = newBuff.TriggersLeft
+			}
+			b.SourceUserId, b.SourceMobInstanceId = src.UserId, src.MobInstanceId
+			return nil
 		}
+	}
 
-		return true
+	bs.removeGroup(buffInfo)
+	bs.appendBuff(&newBuff, buffInfo)
+
+	return nil
+}
+
+func (bs *Buffs) appendBuff(b *Buff, buffInfo *BuffSpec) {
+
+	bs.List = append(bs.List, b)
+	listIndex := len(bs.List) - 1
+	bs.buffIds[b.BuffId] = append(bs.buffIds[b.BuffId], listIndex)
+	for _, flag := range buffInfo.Flags {
+		if _, ok := bs.buffFlags[flag]; !ok {
+			bs.buffFlags[flag] = []int{}
+		}
+		bs.buffFlags[flag] = append(bs.buffFlags[flag], listIndex)
+	}
+}
+
+// Expires any other buffs in the same group
+func (bs *Buffs) removeGroup(buffInfo *BuffSpec) {
+
+	if buffInfo.Group == `` {
+		return
+	}
+
+	for _, b := range bs.List {
+		if b.BuffId == buffInfo.BuffId || b.Expired() {
+			continue
+		}
+		if spec := GetBuffSpec(b.BuffId); spec != nil && spec.Group == buffInfo.Group {
+			b.TriggersLeft = TriggersLeftExpired
+		}
 	}
+}
+
+func (bs *Buffs) hasPermanentInGroup(buffInfo *BuffSpec) bool {
 
+	for _, b := range bs.List {
+		if b.BuffId == buffInfo.BuffId || b.Expired() || !b.PermaBuff {
+			continue
+		}
+		if spec := GetBuffSpec(b.BuffId); spec != nil && spec.Group == buffInfo.Group {
+			return true
+		}
+	}
 	return false
 }
 
+// Each application in quick succession lasts half as long as the last, until the target becomes immune.
+// Returns how many triggers the buff should get.
+func (bs *Buffs) diminish(buffInfo *BuffSpec) (int, error) {
+
+	if bs.diminished == nil {
+		bs.diminished = make(map[string]*diminishInfo)
+	}
+
+	key := buffInfo.Group
+	if key == `` {
+		key = strconv.Itoa(buffInfo.BuffId)
+	}
+
+	roundNow := util.GetRoundCount()
+
+	info, ok := bs.diminished[key]
+	if !ok || roundNow-info.LastRound > DiminishResetRounds {
+		info = &diminishInfo{}
+		bs.diminished[key] = info
+	}
+
+	if info.Count >= DiminishImmuneAfter {
+		return 0, ErrBuffImmune
+	}
+
+	triggers := max(buffInfo.TriggerCount>>info.Count, 1)
+
+	info.Count++
+	info.LastRound = roundNow
+
+	return triggers, nil
+}
+
 // Returns what buffs were triggered
 func (bs *Buffs) Trigger(buffId ...int) (triggeredBuffs []*Buff) {
 
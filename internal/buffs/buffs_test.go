@@ -0,0 +1,108 @@
+package buffs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/volte6/gomud/internal/statmods"
+)
+
+func setTestSpecs(t *testing.T, specs ...*BuffSpec) {
+	t.Helper()
+
+	oldBuffs := buffs
+	t.Cleanup(func() { buffs = oldBuffs })
+
+	buffs = map[int]*BuffSpec{}
+	for _, spec := range specs {
+		if spec.TriggerRate == `` {
+			spec.TriggerRate = `1 round`
+		}
+		if err := spec.Validate(); err != nil {
+			t.Fatalf("Validate() failed: %v", err)
+		}
+		buffs[spec.BuffId] = spec
+	}
+}
+
+func TestAddBuffStacking(t *testing.T) {
+
+	setTestSpecs(t,
+		&BuffSpec{BuffId: 1, Name: `refresh`, TriggerCount: 5},
+		&BuffSpec{BuffId: 2, Name: `stack`, TriggerCount: 5, Stacking: StackStack, MaxStacks: 3, StatMods: statmods.StatMods{`strength`: 2}},
+		&BuffSpec{BuffId: 3, Name: `independent`, TriggerCount: 5, Stacking: StackIndependent, MaxStacks: 2},
+		&BuffSpec{BuffId: 4, Name: `ignore`, TriggerCount: 5, Stacking: StackIgnore},
+	)
+
+	bs := New()
+
+	// Refresh starts the duration over
+	bs.AddBuff(1, false)
+	bs.List[0].TriggersLeft = 1
+	if err := bs.AddBuff(1, false); err != nil || bs.TriggersLeft(1) != 5 || len(bs.List) != 1 {
+		t.Errorf("refresh: err=%v triggersLeft=%d buffs=%d", err, bs.TriggersLeft(1), len(bs.List))
+	}
+
+	// Stacks cap out and multiply statmods
+	for i := 0; i < 5; i++ {
+		bs.AddBuff(2, false)
+	}
+	if stacks := bs.StackCount(2); stacks != 3 {
+		t.Errorf("stack: got %d stacks, want 3", stacks)
+	}
+	if mod := bs.StatMod(`strength`); mod != 6 {
+		t.Errorf("stack: got strength %d, want 6", mod)
+	}
+
+	// A copy per source, up to the max
+	bs.AddBuff(3, false, Source{UserId: 1})
+	bs.AddBuff(3, false, Source{UserId: 1})
+	bs.AddBuff(3, false, Source{MobInstanceId: 7})
+	bs.AddBuff(3, false, Source{UserId: 2})
+	if stacks := bs.StackCount(3); stacks != 2 {
+		t.Errorf("independent: got %d copies, want 2", stacks)
+	}
+
+	// Ignored while already applied
+	bs.AddBuff(4, false)
+	if err := bs.AddBuff(4, false); !errors.Is(err, ErrBuffIgnored) {
+		t.Errorf("ignore: got err=%v, want ErrBuffIgnored", err)
+	}
+}
+
+func TestAddBuffGroupsAndDiminishing(t *testing.T) {
+
+	setTestSpecs(t,
+		&BuffSpec{BuffId: 1, Name: `tackled`, TriggerCount: 8, Group: `immobilized`, Diminishing: true},
+		&BuffSpec{BuffId: 2, Name: `hamstrung`, TriggerCount: 8, Group: `immobilized`, Diminishing: true},
+		&BuffSpec{BuffId: 3, Name: `gear`, TriggerCount: 1, Group: `fed`},
+		&BuffSpec{BuffId: 4, Name: `food`, TriggerCount: 1, Group: `fed`},
+	)
+
+	bs := New()
+
+	// The newest buff in a group replaces the others, and they share diminishing returns
+	wantTriggers := []int{8, 4, 2}
+	for i, want := range wantTriggers {
+		buffId := 1 + i%2
+		if err := bs.AddBuff(buffId, false); err != nil {
+			t.Fatalf("application %d: unexpected err=%v", i+1, err)
+		}
+		if got := bs.TriggersLeft(buffId); got != want {
+			t.Errorf("application %d: got %d triggers, want %d", i+1, got, want)
+		}
+		if other := 2 - i%2; bs.TriggersLeft(other) != 0 {
+			t.Errorf("application %d: buff %d should have been replaced", i+1, other)
+		}
+	}
+
+	if err := bs.AddBuff(2, false); !errors.Is(err, ErrBuffImmune) {
+		t.Errorf("got err=%v, want ErrBuffImmune", err)
+	}
+
+	// Permanent buffs in a group can't be pushed out
+	bs.AddBuff(3, true)
+	if err := bs.AddBuff(4, false); !errors.Is(err, ErrBuffBlocked) {
+		t.Errorf("got err=%v, want ErrBuffBlocked", err)
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"log/slog"
 	"math"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/volte6/gomud/internal/audit"
 	"github.com/volte6/gomud/internal/fileloader"
 	"github.com/volte6/gomud/internal/gametime"
 	"github.com/volte6/gomud/internal/statmods"
@@ -61,6 +63,8 @@ const (
 	Warmed       Flag = `warmed`
 	Hydrated     Flag = `hydrated`
 	Thirsty      Flag = `thirsty`
+	Flying       Flag = `flying`
+	WaterBreath  Flag = `water-breathing`
 
 	// Flags that reveal things
 	SeeHidden Flag = `see-hidden`
@@ -70,12 +74,39 @@ const (
 	validationRound = 1000000
 )
 
+// What happens when a buff is applied to someone who already has it
+type StackPolicy string
+
+const (
+	StackRefresh     StackPolicy = `refresh`     // The duration starts over (default)
+	StackStack       StackPolicy = `stack`       // Adds a stack, up to maxstacks, and the duration starts over. Statmods are per stack.
+	StackIndependent StackPolicy = `independent` // Each source gets their own copy, which triggers separately (up to maxstacks, if set)
+	StackIgnore      StackPolicy = `ignore`      // Nothing happens until the current one wears off
+)
+
 var (
 	buffs map[int]*BuffSpec = make(map[int]*BuffSpec)
 
 	validationCalculator = gametime.GetDate(validationRound)
 )
 
+// Returns every stacking policy a buff can have
+func GetAllStackPolicies() []StackPolicy {
+	return []StackPolicy{StackRefresh, StackStack, StackIndependent, StackIgnore}
+}
+
+// Returns every flag a buff can be given
+func GetAllFlags() []Flag {
+	return []Flag{
+		NoCombat, NoMovement, NoFlee, CancelIfCombat, CancelOnAction, CancelOnWater,
+		ReviveOnDeath,
+		PermaGear, RemoveCurse,
+		Poison, Drunk,
+		Hidden, Accuracy, Blink, EmitsLight, SuperHearing, NightVision, Warmed, Hydrated, Thirsty, Flying, WaterBreath,
+		SeeHidden, SeeNouns,
+	}
+}
+
 type BuffSpec struct {
 	BuffId        int               // Unique identifier for this buff spec
 	Name          string            // The name of the buff
@@ -87,6 +118,17 @@ type BuffSpec struct {
 	TriggerCount  int               `yaml:"triggercount,omitempty"`  // How many times it triggers before it is removed
 	StatMods      statmods.StatMods `yaml:"statmods,omitempty"`      // stat mods for the duration of the buff
 	Flags         []Flag            `yaml:"flags,omitempty"`         // A list of actions and such that this buff prevents or enables
+	Stacking      StackPolicy       `yaml:"stacking,omitempty"`      // What happens when it is reapplied (refresh/stack/independent/ignore)
+	MaxStacks     int               `yaml:"maxstacks,omitempty"`     // The most stacks (or independent copies) it can have
+	Group         string            `yaml:"group,omitempty"`         // Only one buff from a group can be applied at a time. The newest replaces the rest.
+	Diminishing   bool              `yaml:"diminishing,omitempty"`   // Crowd control: each reapplication in quick succession lasts half as long, until immune
+}
+
+func (b *BuffSpec) GetStacking() StackPolicy {
+	if b.Stacking == `` {
+		return StackRefresh
+	}
+	return b.Stacking
 }
 
 // Calculates the value of this buff
@@ -178,6 +220,24 @@ func (b *BuffSpec) Validate() error {
 	if b.RoundInterval < 1 {
 		return fmt.Errorf("buffId %d (%s) has a RoundInterval of < 1, must be at least 1. Is %s a valid time string?", b.BuffId, b.Name, b.TriggerRate)
 	}
+
+	b.Stacking = StackPolicy(strings.ToLower(string(b.Stacking)))
+	b.Group = strings.ToLower(b.Group)
+
+	switch b.GetStacking() {
+	case StackRefresh, StackIgnore:
+	case StackStack:
+		if b.MaxStacks < 2 {
+			return fmt.Errorf("buffId %d (%s) stacks, but has a MaxStacks of < 2", b.BuffId, b.Name)
+		}
+	case StackIndependent:
+		if b.MaxStacks < 0 {
+			return fmt.Errorf("buffId %d (%s) has a MaxStacks of < 0", b.BuffId, b.Name)
+		}
+	default:
+		return fmt.Errorf("buffId %d (%s) has an invalid Stacking: %s", b.BuffId, b.Name, b.Stacking)
+	}
+
 	return nil
 }
 
@@ -217,6 +277,72 @@ func (b *BuffSpec) GetScriptPath() string {
 	return util.FilePath(fullScriptPath)
 }
 
+// Returns the next unused BuffId
+func GetNextBuffId() int {
+	nextBuffId := 1
+	for buffId := range buffs {
+		if buffId >= nextBuffId {
+			nextBuffId = buffId + 1
+		}
+	}
+	return nextBuffId
+}
+
+// Returns a copy of a buff spec as it was authored in its datafile, without any defaults filled in.
+// Used for editing a spec and saving it back.
+func LoadBuffSpecFile(buffId int) (*BuffSpec, error) {
+
+	spec, ok := buffs[buffId]
+	if !ok {
+		return nil, fmt.Errorf("buffId %d does not exist", buffId)
+	}
+
+	return fileloader.LoadFlatFileRaw[*BuffSpec](util.FilePath(buffDataFilesFolderPath, `/`, spec.Filepath()))
+}
+
+// Writes a buff spec to disk and makes it live.
+// Active buffs look up their spec by id, so they pick up the changes right away.
+// If the name changed, the old file and its script are moved.
+func SaveBuffSpec(b *BuffSpec) error {
+
+	// Catch a bad trigger rate etc. before anything is written
+	checkSpec := *b
+	if err := checkSpec.Validate(); err != nil {
+		return err
+	}
+
+	oldFilepath := ``
+	before := ``
+	if oldSpec, ok := buffs[b.BuffId]; ok {
+		oldFilepath = oldSpec.Filepath()
+		if diskSpec, err := LoadBuffSpecFile(b.BuffId); err == nil {
+			before = audit.Snapshot(diskSpec)
+		}
+	}
+
+	if err := fileloader.SaveFlatFile(buffDataFilesFolderPath, b); err != nil {
+		return err
+	}
+
+	if oldFilepath != `` {
+		if err := fileloader.RelocateFlatFile(buffDataFilesFolderPath, oldFilepath, b.Filepath()); err != nil {
+			return err
+		}
+	}
+
+	// Load it back the same way a reload would
+	liveSpec, err := fileloader.LoadFlatFile[*BuffSpec](util.FilePath(buffDataFilesFolderPath, `/`, b.Filepath()))
+	if err != nil {
+		return err
+	}
+
+	buffs[liveSpec.BuffId] = liveSpec
+
+	audit.Capture(audit.KindBuff, strconv.Itoa(b.BuffId), before, audit.Snapshot(b))
+
+	return nil
+}
+
 // file self loads due to init()
 func LoadDataFiles() {
 
@@ -0,0 +1,523 @@
+package calendar
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/volte6/gomud/internal/characters"
+	"github.com/volte6/gomud/internal/events"
+	"github.com/volte6/gomud/internal/fileloader"
+	"github.com/volte6/gomud/internal/gametime"
+	"github.com/volte6/gomud/internal/mobs"
+	"github.com/volte6/gomud/internal/mutators"
+	"github.com/volte6/gomud/internal/rooms"
+)
+
+const (
+	maxSearchDays = 365 * 4 // How far ahead to look for the next time an event happens
+)
+
+var (
+	allEvents                   = map[string]*EventSpec{}
+	calendarDataFilesFolderPath = "_datafiles/calendar"
+
+	// Runtime state, keyed by EventId
+	eventStates = map[string]*eventState{}
+)
+
+// When an event starts. Anything left empty matches every time.
+// For example, month 3 day 3 is the 3rd of the third month every year,
+// moon "full" is every full moon, and just hour 22 is every night at 22:00.
+type Schedule struct {
+	Month int    `yaml:"month,omitempty"` // 1-12
+	Day   int    `yaml:"day,omitempty"`   // Day of the month
+	Moon  string `yaml:"moon,omitempty"`  // A moon phase such as "full" or "new". It starts on the first day of the phase.
+	Hour  int    `yaml:"hour,omitempty"`  // Hour of the day it starts (0-23)
+}
+
+// A mutator that is added while the event runs
+type EventMutator struct {
+	MutatorId string `yaml:"mutatorid"`
+	Zone      string `yaml:"zone,omitempty"`    // (optional) add it to a whole zone
+	RoomIds   []int  `yaml:"roomids,omitempty"` // (optional) add it to specific rooms
+}
+
+// Mobs that show up while the event runs, and leave when it ends
+type EventSpawn struct {
+	MobId   int    `yaml:"mobid"`
+	RoomId  int    `yaml:"roomid"`
+	Count   int    `yaml:"count,omitempty"`   // Defaults to 1
+	Message string `yaml:"message,omitempty"` // (optional) sent to the room when they arrive
+}
+
+// Extra stock for a shopkeeper while the event runs
+type EventShop struct {
+	RoomId int             `yaml:"roomid"` // The room the shopkeeper is in
+	MobId  int             `yaml:"mobid"`  // Which mob the shopkeeper is
+	Items  characters.Shop `yaml:"items"`  // Stocked when it starts, removed when it ends
+}
+
+type EventSpec struct {
+	EventId      string         `yaml:"eventid"`                // Unique id such as "harvest-festival"
+	Name         string         `yaml:"name"`                   // What it's called, such as "the Harvest Festival"
+	Description  string         `yaml:"description,omitempty"`  // Shown by the calendar command
+	Schedule     Schedule       `yaml:"schedule"`               // When it starts
+	Duration     string         `yaml:"duration,omitempty"`     // How long it lasts, such as "6 hours". Defaults to "1 day"
+	Hidden       bool           `yaml:"hidden,omitempty"`       // Not listed by the calendar command
+	StartMessage string         `yaml:"startmessage,omitempty"` // (optional) sent to everyone when it starts
+	EndMessage   string         `yaml:"endmessage,omitempty"`   // (optional) sent to everyone when it ends
+	Mutators     []EventMutator `yaml:"mutators,omitempty"`
+	Spawns       []EventSpawn   `yaml:"spawns,omitempty"`
+	Shops        []EventShop    `yaml:"shops,omitempty"`
+}
+
+type eventState struct {
+	initialized    bool
+	active         bool
+	mobInstanceIds []int
+	mutators       []addedMutator  // Only what the event added, so anything authored is left alone when it ends
+	shopItems      []addedShopItem // Likewise for shop stock
+}
+
+type addedMutator struct {
+	zone      string // Set if it was added to a whole zone
+	roomId    int    // Otherwise the room it was added to
+	mutatorId string
+}
+
+type addedShopItem struct {
+	mobInstanceId int
+	item          characters.ShopItem
+}
+
+// A single time an event happens
+type Occurrence struct {
+	Start uint64 // The round it starts
+	End   uint64 // The round it ends
+}
+
+// Sent back from RoundTick whenever an event starts or ends
+type Change struct {
+	EventId string
+	Started bool
+}
+
+func (e *EventSpec) Filepath() string {
+	return fmt.Sprintf("%s.yaml", strings.ToLower(e.EventId))
+}
+
+func (e *EventSpec) Id() string {
+	return e.EventId
+}
+
+func (e *EventSpec) Validate() error {
+
+	if e.EventId == `` {
+		return fmt.Errorf(`eventid cannot be empty`)
+	}
+
+	if e.Name == `` {
+		e.Name = e.EventId
+	}
+
+	if e.Duration == `` {
+		e.Duration = `1 day`
+	}
+
+	if e.Schedule.Month < 0 || e.Schedule.Month > 12 {
+		return fmt.Errorf(`event %s has an invalid month: %d`, e.EventId, e.Schedule.Month)
+	}
+
+	if e.Schedule.Day < 0 || e.Schedule.Day > 31 {
+		return fmt.Errorf(`event %s has an invalid day: %d`, e.EventId, e.Schedule.Day)
+	}
+
+	if e.Schedule.Hour < 0 || e.Schedule.Hour > 23 {
+		return fmt.Errorf(`event %s has an invalid hour: %d`, e.EventId, e.Schedule.Hour)
+	}
+
+	if e.Schedule.Moon != `` && !gametime.IsMoonPhase(e.Schedule.Moon) {
+		return fmt.Errorf(`event %s has an invalid moon phase: %s`, e.EventId, e.Schedule.Moon)
+	}
+
+	for i := range e.Spawns {
+		if e.Spawns[i].Count < 1 {
+			e.Spawns[i].Count = 1
+		}
+	}
+
+	return nil
+}
+
+func (e *EventSpec) GetScript() string {
+
+	scriptPath := e.GetScriptPath()
+
+	// Load the script into a string
+	if _, err := os.Stat(scriptPath); err == nil {
+		if bytes, err := os.ReadFile(scriptPath); err == nil {
+			return string(bytes)
+		}
+	}
+
+	return ``
+}
+
+func (e *EventSpec) GetScriptPath() string {
+	return strings.Replace(calendarDataFilesFolderPath+`/`+e.Filepath(), `.yaml`, `.js`, 1)
+}
+
+// Describes the schedule, such as "every full moon at 22:00"
+func (s Schedule) String() string {
+
+	when := ``
+
+	switch {
+	case s.Moon != ``:
+		when = fmt.Sprintf(`every %s moon`, strings.ToLower(s.Moon))
+		if s.Month > 0 {
+			when += ` in ` + gametime.MonthName(s.Month)
+		}
+		if s.Day > 0 {
+			when += fmt.Sprintf(` that falls on day %d of the month`, s.Day)
+		}
+	case s.Month > 0 && s.Day > 0:
+		when = fmt.Sprintf(`day %d of %s, every year`, s.Day, gametime.MonthName(s.Month))
+	case s.Month > 0:
+		when = fmt.Sprintf(`every day of %s`, gametime.MonthName(s.Month))
+	case s.Day > 0:
+		when = fmt.Sprintf(`day %d of every month`, s.Day)
+	default:
+		when = `every day`
+	}
+
+	return fmt.Sprintf(`%s at %02d:00`, when, s.Hour)
+}
+
+// Whether the event starts at some point on the day of a date
+func (s Schedule) startsOn(gd gametime.GameDate) bool {
+
+	if s.Month > 0 && gd.Month != s.Month {
+		return false
+	}
+
+	if s.Day > 0 && gd.MonthDay != s.Day {
+		return false
+	}
+
+	if s.Moon != `` && !gd.MoonPhaseStarts(s.Moon) {
+		return false
+	}
+
+	return true
+}
+
+func (e *EventSpec) durationRounds() uint64 {
+	rounds := gametime.GetDate(0).AddPeriod(e.Duration)
+	if rounds < 1 {
+		return 1
+	}
+	return rounds
+}
+
+// Returns the occurrence of the event going on at a given round, if there is one.
+// Like transports, this is worked out from the clock alone, so it's the same every time the server starts.
+func (e *EventSpec) GetCurrent(roundNumber uint64) (Occurrence, bool) {
+
+	gd := gametime.GetDate(roundNumber)
+	durationRounds := e.durationRounds()
+
+	// Look back far enough to find one that started earlier and is still going
+	lookBackDays := int(durationRounds/uint64(gd.RoundsPerDay)) + 1
+
+	for i := 0; i <= lookBackDays; i++ {
+
+		if uint64(i*gd.RoundsPerDay) > gd.RoundNumber {
+			break
+		}
+
+		day := gd.Add(0, -i, 0)
+		if !e.Schedule.startsOn(day) {
+			continue
+		}
+
+		start := day.HourRound(e.Schedule.Hour)
+		if start <= roundNumber && roundNumber < start+durationRounds {
+			return Occurrence{Start: start, End: start + durationRounds}, true
+		}
+	}
+
+	return Occurrence{}, false
+}
+
+// Returns the next time the event starts after a given round.
+// Returns false if it doesn't happen within the next few years.
+func (e *EventSpec) GetNext(roundNumber uint64) (Occurrence, bool) {
+
+	gd := gametime.GetDate(roundNumber)
+	durationRounds := e.durationRounds()
+
+	for i := 0; i <= maxSearchDays; i++ {
+
+		day := gd.Add(0, i, 0)
+		if !e.Schedule.startsOn(day) {
+			continue
+		}
+
+		if start := day.HourRound(e.Schedule.Hour); start > roundNumber {
+			return Occurrence{Start: start, End: start + durationRounds}, true
+		}
+	}
+
+	return Occurrence{}, false
+}
+
+func (e *EventSpec) IsActive() bool {
+	if state, ok := eventStates[e.EventId]; ok {
+		return state.active
+	}
+	return false
+}
+
+func GetEvent(eventId string) *EventSpec {
+	return allEvents[eventId]
+}
+
+// All events, sorted by id
+func GetAllEvents() []*EventSpec {
+
+	ret := make([]*EventSpec, 0, len(allEvents))
+	for _, e := range allEvents {
+		ret = append(ret, e)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].EventId < ret[j].EventId
+	})
+
+	return ret
+}
+
+// Ids of the events going on right now
+func GetActiveEventIds() []string {
+
+	ret := []string{}
+	for _, e := range GetAllEvents() {
+		if e.IsActive() {
+			ret = append(ret, e.EventId)
+		}
+	}
+
+	return ret
+}
+
+// Starts and ends events as the clock reaches them.
+// Returns what changed so that scripts can be run.
+func RoundTick(roundNumber uint64) []Change {
+
+	changes := []Change{}
+
+	for _, e := range GetAllEvents() {
+
+		state, ok := eventStates[e.EventId]
+		if !ok {
+			state = &eventState{}
+			eventStates[e.EventId] = state
+		}
+
+		_, active := e.GetCurrent(roundNumber)
+
+		if !state.initialized {
+			state.initialized = true
+
+			// Pick up anything that was going on when the server started, without announcing it
+			if active {
+				e.start(state, false)
+				changes = append(changes, Change{EventId: e.EventId, Started: true})
+			}
+			continue
+		}
+
+		if active == state.active {
+			continue
+		}
+
+		if active {
+			e.start(state, true)
+		} else {
+			e.end(state)
+		}
+
+		changes = append(changes, Change{EventId: e.EventId, Started: active})
+	}
+
+	return changes
+}
+
+func (e *EventSpec) start(state *eventState, announce bool) {
+
+	state.active = true
+
+	slog.Info("Calendar", "started", e.EventId)
+
+	if announce && e.StartMessage != `` {
+		events.AddToQueue(events.Broadcast{
+			Text: e.StartMessage + "\n",
+		})
+	}
+
+	for _, m := range e.Mutators {
+
+		if !mutators.IsMutator(m.MutatorId) {
+			slog.Error("Calendar", "eventId", e.EventId, "error", "invalid mutator", "mutatorId", m.MutatorId)
+			continue
+		}
+
+		if m.Zone != `` {
+			if zoneConfig := rooms.GetZoneConfig(m.Zone); zoneConfig != nil && zoneConfig.Mutators.AddTemporary(m.MutatorId) {
+				state.mutators = append(state.mutators, addedMutator{zone: m.Zone, mutatorId: m.MutatorId})
+			}
+		}
+
+		for _, roomId := range m.RoomIds {
+			if room := rooms.LoadRoom(roomId); room != nil && room.Mutators.AddTemporary(m.MutatorId) {
+				state.mutators = append(state.mutators, addedMutator{roomId: roomId, mutatorId: m.MutatorId})
+			}
+		}
+	}
+
+	state.mobInstanceIds = state.mobInstanceIds[:0]
+	for _, s := range e.Spawns {
+
+		room := rooms.LoadRoom(s.RoomId)
+		if room == nil {
+			continue
+		}
+
+		for i := 0; i < s.Count; i++ {
+			if mob := mobs.NewMobById(mobs.MobId(s.MobId), s.RoomId); mob != nil {
+				room.AddMob(mob.InstanceId)
+				state.mobInstanceIds = append(state.mobInstanceIds, mob.InstanceId)
+			}
+		}
+
+		if s.Message != `` {
+			room.SendText(s.Message)
+		}
+	}
+
+	state.shopItems = state.shopItems[:0]
+	for _, s := range e.Shops {
+		for _, shopkeeper := range findShopkeepers(s) {
+			for _, shopItem := range stockShop(&shopkeeper.Character.Shop, s.Items) {
+				state.shopItems = append(state.shopItems, addedShopItem{mobInstanceId: shopkeeper.InstanceId, item: shopItem})
+			}
+		}
+	}
+}
+
+func (e *EventSpec) end(state *eventState) {
+
+	state.active = false
+
+	slog.Info("Calendar", "ended", e.EventId)
+
+	if e.EndMessage != `` {
+		events.AddToQueue(events.Broadcast{
+			Text: e.EndMessage + "\n",
+		})
+	}
+
+	state.clearMutators()
+
+	// Anything that showed up for it leaves, wherever it wandered off to
+	for _, mobInstanceId := range state.mobInstanceIds {
+		if mob := mobs.GetInstance(mobInstanceId); mob != nil {
+			mob.Command(`despawn calendar event ended`)
+		}
+	}
+	state.mobInstanceIds = state.mobInstanceIds[:0]
+
+	for _, added := range state.shopItems {
+		if shopkeeper := mobs.GetInstance(added.mobInstanceId); shopkeeper != nil {
+			unstockShop(&shopkeeper.Character.Shop, []characters.ShopItem{added.item})
+		}
+	}
+	state.shopItems = state.shopItems[:0]
+}
+
+// Deletes the mutators the event added. They are temporary, so were never saved with the room.
+func (state *eventState) clearMutators() {
+	for _, m := range state.mutators {
+
+		if m.zone != `` {
+			if zoneConfig := rooms.GetZoneConfig(m.zone); zoneConfig != nil {
+				zoneConfig.Mutators.Delete(m.mutatorId)
+			}
+			continue
+		}
+
+		if room := rooms.LoadRoom(m.roomId); room != nil {
+			room.Mutators.Delete(m.mutatorId)
+		}
+	}
+	state.mutators = state.mutators[:0]
+}
+
+// Adds anything the shop doesn't already sell, and returns what was added
+func stockShop(shop *characters.Shop, stock characters.Shop) []characters.ShopItem {
+
+	added := []characters.ShopItem{}
+	for _, shopItem := range stock {
+		if !shop.Has(shopItem) {
+			*shop = append(*shop, shopItem)
+			added = append(added, shopItem)
+		}
+	}
+
+	return added
+}
+
+// Takes back out what stockShop() added
+func unstockShop(shop *characters.Shop, added []characters.ShopItem) {
+	for _, shopItem := range added {
+		shop.Remove(shopItem)
+	}
+}
+
+func findShopkeepers(s EventShop) []*mobs.Mob {
+
+	ret := []*mobs.Mob{}
+
+	room := rooms.LoadRoom(s.RoomId)
+	if room == nil {
+		return ret
+	}
+
+	for _, mobInstanceId := range room.GetMobs() {
+		if mob := mobs.GetInstance(mobInstanceId); mob != nil && int(mob.MobId) == s.MobId {
+			ret = append(ret, mob)
+		}
+	}
+
+	return ret
+}
+
+// file self loads due to init()
+func LoadDataFiles() {
+
+	start := time.Now()
+
+	tmpEvents, err := fileloader.LoadAllFlatFiles[string, *EventSpec](calendarDataFilesFolderPath)
+	if err != nil {
+		panic(err)
+	}
+
+	allEvents = tmpEvents
+
+	slog.Info("calendar.LoadDataFiles()", "loadedCount", len(allEvents), "Time Taken", time.Since(start))
+}
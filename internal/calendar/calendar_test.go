@@ -0,0 +1,50 @@
+package calendar
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/volte6/gomud/internal/characters"
+)
+
+func TestEventShopRestoresStock(t *testing.T) {
+
+	shop := characters.Shop{
+		{ItemId: 1, Quantity: 2, QuantityMax: 5},
+		{MobId: 7, Quantity: 1, QuantityMax: 1},
+	}
+
+	original := make(characters.Shop, len(shop))
+	copy(original, shop)
+
+	stock := characters.Shop{
+		{ItemId: 1, QuantityMax: characters.StockUnlimited}, // Already sold here, so the shopkeeper keeps their own
+		{ItemId: 2, QuantityMax: characters.StockUnlimited},
+		{BuffId: 3, QuantityMax: characters.StockUnlimited},
+	}
+
+	added := stockShop(&shop, stock)
+
+	if len(added) != 2 {
+		t.Fatalf("stockShop() added %d items; want 2", len(added))
+	}
+
+	if !shop.Has(stock[1]) || !shop.Has(stock[2]) {
+		t.Errorf("stockShop() didn't stock the event items: %+v", shop)
+	}
+
+	// Ending the event puts the shop back how it was
+	unstockShop(&shop, added)
+
+	if !reflect.DeepEqual(shop, original) {
+		t.Errorf("unstockShop() left %+v; want %+v", shop, original)
+	}
+
+	// A second start and end doesn't touch what was there before either
+	added = stockShop(&shop, stock)
+	unstockShop(&shop, added)
+
+	if !reflect.DeepEqual(shop, original) {
+		t.Errorf("Second event left %+v; want %+v", shop, original)
+	}
+}
@@ -0,0 +1,82 @@
+package characters
+
+import "math"
+
+const (
+	ArenaStartingRating = 1500
+)
+
+// Ranked arena results for a single season
+type ArenaSeasonStats struct {
+	Rating int `yaml:"rating,omitempty"`
+	Wins   int `yaml:"wins,omitempty"`
+	Losses int `yaml:"losses,omitempty"`
+	Draws  int `yaml:"draws,omitempty"`
+}
+
+func (s ArenaSeasonStats) Played() int {
+	return s.Wins + s.Losses + s.Draws
+}
+
+type ArenaStats struct {
+	Season           int                      `yaml:"season,omitempty"` // Which season the current stats belong to
+	ArenaSeasonStats `yaml:",inline"`         // Current season stats
+	PastSeasons      map[int]ArenaSeasonStats `yaml:"pastseasons,omitempty"` // Final results of earlier seasons
+}
+
+// Starts a fresh ladder if the season has changed, archiving the old results
+func (a *ArenaStats) SetSeason(season int) {
+
+	if a.Season == season {
+		return
+	}
+
+	if a.Season > 0 && a.Played() > 0 {
+		if a.PastSeasons == nil {
+			a.PastSeasons = map[int]ArenaSeasonStats{}
+		}
+		a.PastSeasons[a.Season] = a.ArenaSeasonStats
+	}
+
+	a.Season = season
+	a.ArenaSeasonStats = ArenaSeasonStats{Rating: ArenaStartingRating}
+}
+
+func (a *ArenaStats) GetRating() int {
+	if a.Rating == 0 {
+		return ArenaStartingRating
+	}
+	return a.Rating
+}
+
+// Records a match result and returns how much the rating changed.
+// score is 1 for a win, 0.5 for a draw and 0 for a loss.
+func (a *ArenaStats) AddResult(opponentRating int, score float64, kFactor int) int {
+
+	change := EloChange(a.GetRating(), opponentRating, score, kFactor)
+
+	a.Rating = a.GetRating() + change
+	if a.Rating < 1 {
+		a.Rating = 1
+	}
+
+	if score > 0.5 {
+		a.Wins++
+	} else if score < 0.5 {
+		a.Losses++
+	} else {
+		a.Draws++
+	}
+
+	return change
+}
+
+// The chance (0-1) that a rating is expected to win against another
+func EloExpected(rating int, opponentRating int) float64 {
+	return 1 / (1 + math.Pow(10, float64(opponentRating-rating)/400))
+}
+
+// How much a rating should change after a match against an opponent
+func EloChange(rating int, opponentRating int, score float64, kFactor int) int {
+	return int(math.Round(float64(kFactor) * (score - EloExpected(rating, opponentRating))))
+}
@@ -76,7 +76,9 @@ type Character struct {
 	Settings         map[string]string `yaml:"settings,omitempty"`      // custom setting tracking, used for anything.
 	QuestProgress    map[int]string    `yaml:"questprogress,omitempty"` // quest progress tracking
 	KeyRing          map[string]string `yaml:"keyring,omitempty"`       // key is the lock id, value is the sequence
+	Explored         map[int]bool      `yaml:"explored,omitempty"`      // Rooms the character has been to, by RoomId
 	KD               KDStats           `yaml:"kd,omitempty"`            // Kill/Death stats
+	Arena            ArenaStats        `yaml:"arena,omitempty"`         // Ranked arena ratings
 	MiscData         map[string]any    `yaml:"miscdata,omitempty"`      // Any random other data that needs to be stored
 	ExtraLives       int               `yaml:"extralives,omitempty"`    // How many lives remain. If enabled, players can perma-die if they die at zero
 	MobMastery       MobMasteries      `yaml:"mobmastery,omitempty"`    // Tracks particular masteries around a given mob
@@ -85,6 +87,11 @@ type Character struct {
 	roomHistory      []int             // A stack FILO of the last X rooms the character has been in
 	PlayerDamage     map[int]int       `yaml:"-"` // key = who, value = how much
 	LastPlayerDamage uint64            `yaml:"-"` // last round a player damaged this character
+	AttackEnergy     float64           `yaml:"-"` // energy built up towards the next swing in combat. See energy.go
+	CombatRounds     int               `yaml:"-"` // how many rounds of the current fight this character has taken part in
+	Threat           ThreatTable       `yaml:"-"` // how much each player is hated. See threat.go
+	LastThreat       uint64            `yaml:"-"` // last round threat was added
+	Taunt            *TauntInfo        `yaml:"-"` // who has taunted this character, if anyone
 	followers        []int             // everyone following this user
 	permaBuffIds     []int             // Buff Id's that are always present for this character
 }
@@ -156,6 +163,9 @@ func (c *Character) TrackPlayerDamage(userId int, damageAmt int) {
 	c.PlayerDamage[userId] = c.PlayerDamage[userId] + damageAmt
 	c.LastPlayerDamage = roundNow
 
+	// Hurting someone makes them hate you
+	c.AddThreat(userId, damageAmt)
+
 }
 
 /*
@@ -256,6 +266,21 @@ func (c *Character) GetMiscData(key string) any {
 	return nil
 }
 
+// Remembers that the character has been to a room. Returns true the first time.
+func (c *Character) MarkExplored(roomId int) bool {
+
+	if c.Explored == nil {
+		c.Explored = make(map[int]bool)
+	}
+
+	if c.Explored[roomId] {
+		return false
+	}
+
+	c.Explored[roomId] = true
+	return true
+}
+
 func (c *Character) GetMiscDataKeys(prefixMatch ...string) []string {
 
 	if c.MiscData == nil {
@@ -466,7 +491,7 @@ func (c *Character) Charm(userId int, rounds int, expireCommand string) {
 	c.SetAdjective(`charmed`, true)
 	c.Charmed = NewCharm(userId, rounds, expireCommand)
 	if c.Aggro != nil && c.Aggro.UserId == userId {
-		c.Aggro = nil
+		c.EndAggro()
 	}
 }
 
@@ -821,7 +846,8 @@ func (c *Character) UpdateItem(originalItm items.Item, replacement items.Item) b
 			return true
 		}
 	}
-	return false
+	// Scripts on equipped items can change them too
+	return c.Equipment.UpdateItem(originalItm, replacement)
 }
 
 func (c *Character) UseItem(i items.Item) int {
@@ -1078,14 +1104,10 @@ func (c *Character) SetAggroRemote(exitName string, userId int, mobInstanceId in
 
 func (c *Character) SetAggro(userId int, mobInstanceId int, aggroType AggroType, roundsWaitTime ...int) {
 
+	// Weapon speed is handled by attack energy, so only an explicit wait applies here
 	var combatAddlWaitRounds int = 0
-
-	if len(roundsWaitTime) > 0 {
-		for _, waitAmt := range roundsWaitTime {
-			combatAddlWaitRounds += waitAmt
-		}
-	} else {
-		combatAddlWaitRounds = c.Equipment.Weapon.GetSpec().WaitRounds + c.Equipment.Offhand.GetSpec().WaitRounds
+	for _, waitAmt := range roundsWaitTime {
+		combatAddlWaitRounds += waitAmt
 	}
 
 	if aggroType == DefaultAttack {
@@ -1113,8 +1135,12 @@ func (c *Character) SetCast(roundsWaitTime int, sInfo SpellAggroInfo) {
 
 }
 
+// Clears aggro, along with anything built up over the fight.
+// Always use this rather than setting Aggro to nil.
 func (c *Character) EndAggro() {
 	c.Aggro = nil
+	c.AttackEnergy = 0
+	c.CombatRounds = 0
 }
 
 func (c *Character) IsAggro(targetUserId int, targetMobInstanceId int) bool {
@@ -1171,15 +1197,20 @@ func (c *Character) HasBuff(buffId int) bool {
 	return c.Buffs.HasBuff(buffId)
 }
 
-func (c *Character) AddBuff(buffId int, isPermanent bool) error {
+func (c *Character) AddBuff(buffId int, isPermanent bool, source ...buffs.Source) error {
 	buffId = int(math.Abs(float64(buffId)))
-	if !c.Buffs.AddBuff(buffId, isPermanent) {
-		return fmt.Errorf(`failed to add buff. target: "%s" buffId: %d`, c.Name, buffId)
+	if err := c.Buffs.AddBuff(buffId, isPermanent, source...); err != nil {
+		return fmt.Errorf(`failed to add buff. target: "%s" buffId: %d: %w`, c.Name, buffId, err)
 	}
 	c.Validate()
 	return nil
 }
 
+// How many stacks of a buff the character has. Zero if they don't have it.
+func (c *Character) GetBuffStacks(buffId int) int {
+	return c.Buffs.StackCount(int(math.Abs(float64(buffId))))
+}
+
 func (c *Character) TrackBuffStarted(buffId int) {
 	c.Buffs.Started(buffId)
 }
@@ -1326,7 +1357,52 @@ func (c *Character) MovementCost() int {
 }
 
 func (c *Character) StatMod(statName string) int {
-	return c.Equipment.StatMod(statName) + c.Buffs.StatMod(statName) + c.Pet.StatMod(statName)
+	raceMod := 0
+	if raceInfo := races.GetRace(c.RaceId); raceInfo != nil {
+		raceMod = raceInfo.StatMods.Get(statName)
+	}
+	return c.Equipment.StatMod(statName) + c.Buffs.StatMod(statName) + c.Pet.StatMod(statName) + raceMod
+}
+
+// Percent of an element's damage the character shrugs off. Negative means they are weak to it.
+func (c *Character) GetResistance(element items.Element) int {
+	if element == `` {
+		return 0
+	}
+	return c.StatMod(element.ResistStatMod())
+}
+
+// How much of a hit of an element's damage actually gets through
+func (c *Character) ResistDamage(damage int, element items.Element) int {
+	return items.ResistDamage(damage, c.GetResistance(element))
+}
+
+// Total armor against a type of weapon, from everything worn plus any natural armor
+func (c *Character) GetArmor(damageType items.ItemSubType) int {
+
+	armor := c.Equipment.Weapon.GetArmor(damageType) +
+		c.Equipment.Offhand.GetArmor(damageType) +
+		c.Equipment.Head.GetArmor(damageType) +
+		c.Equipment.Neck.GetArmor(damageType) +
+		c.Equipment.Body.GetArmor(damageType) +
+		c.Equipment.Belt.GetArmor(damageType) +
+		c.Equipment.Gloves.GetArmor(damageType) +
+		c.Equipment.Ring.GetArmor(damageType) +
+		c.Equipment.Legs.GetArmor(damageType) +
+		c.Equipment.Feet.GetArmor(damageType)
+
+	armor += c.StatMod(string(statmods.Armor))
+
+	if armor < 0 {
+		armor = 0
+	}
+
+	return armor
+}
+
+// Returns the % of a hit that armor stops, after the attacker's armor penetration
+func (c *Character) GetArmorMitigation(damageType items.ItemSubType, penetration int) int {
+	return items.ArmorMitigation(items.PenetrateArmor(c.GetArmor(damageType), penetration))
 }
 
 func (c *Character) RecalculateStats() {
@@ -1602,6 +1678,22 @@ func (c *Character) Race() string {
 	return `Ghostly Spirit`
 }
 
+// Whether the character can fly, either by race or from a buff
+func (c *Character) CanFly() bool {
+	if r := races.GetRace(c.RaceId); r != nil && r.Flying {
+		return true
+	}
+	return c.HasBuffFlag(buffs.Flying)
+}
+
+// Whether the character can breathe underwater, either by race or from a buff
+func (c *Character) CanBreatheWater() bool {
+	if r := races.GetRace(c.RaceId); r != nil && r.WaterBreathing {
+		return true
+	}
+	return c.HasBuffFlag(buffs.WaterBreath)
+}
+
 func (c *Character) UpdateAlignment(amt int) {
 	newAlignment := int(c.Alignment) + amt
 	if newAlignment < int(AlignmentMinimum) {
@@ -0,0 +1,85 @@
+package characters
+
+import (
+	"math"
+
+	"github.com/volte6/gomud/internal/items"
+	"github.com/volte6/gomud/internal/statmods"
+)
+
+//
+// Attack energy.
+// Every round a character in combat builds up energy, faster the quicker they are than their target.
+// Each swing of a weapon costs energy, so a fast weapon can swing several times a round and a slow
+// one might only swing every other round. Leftover energy carries into the next round.
+//
+
+const (
+	EnergyPerRound      = 1.0 // Energy gained each round when as fast as the target
+	EnergySpeedScale    = 50  // How much faster than the target it takes to gain another round's worth of energy
+	EnergyGainMin       = 0.5 // Even a very slow character gets a swing in every other round
+	EnergyGainMax       = 4.0
+	EnergyDefaultWeapon = 1.0 // Speed of fists, claws and weapons without a speed
+)
+
+// How much energy the character builds up in a round against a target of a given speed
+func (c *Character) AttackEnergyGain(targetSpeed int) float64 {
+
+	gain := EnergyPerRound + float64(c.Stats.Speed.ValueAdj-targetSpeed)/EnergySpeedScale
+
+	return math.Max(EnergyGainMin, math.Min(EnergyGainMax, gain))
+}
+
+// The speed of whatever the character is attacking with. Dual wielded weapons are averaged.
+func (c *Character) AttackSpeed() float64 {
+
+	speeds := []float64{}
+
+	if c.Equipment.Weapon.ItemId > 0 {
+		speeds = append(speeds, c.Equipment.Weapon.GetSpec().GetSpeed())
+	}
+
+	if c.Equipment.Offhand.ItemId > 0 && c.Equipment.Offhand.GetSpec().Type == items.Weapon {
+		speeds = append(speeds, c.Equipment.Offhand.GetSpec().GetSpeed())
+	}
+
+	if len(speeds) == 0 {
+		return EnergyDefaultWeapon
+	}
+
+	total := 0.0
+	for _, s := range speeds {
+		total += s
+	}
+
+	return total / float64(len(speeds))
+}
+
+// The energy a single swing costs
+func (c *Character) AttackCost() float64 {
+	return 1.0 / c.AttackSpeed()
+}
+
+// Builds up a round of energy and spends as much of it as possible on swings.
+// Returns how many swings the character gets this round, which may be zero.
+func (c *Character) TakeAttackEnergy(targetSpeed int) int {
+
+	cost := c.AttackCost()
+
+	c.AttackEnergy += c.AttackEnergyGain(targetSpeed)
+
+	swings := int(math.Floor(c.AttackEnergy/cost + 0.0001)) // Allow for float rounding
+	c.AttackEnergy -= float64(swings) * cost
+
+	// Don't let energy bank up beyond the next swing
+	if c.AttackEnergy > cost {
+		c.AttackEnergy = cost
+	}
+
+	if swings > 0 {
+		// Some things just grant extra attacks
+		swings += c.StatMod(string(statmods.Attacks))
+	}
+
+	return swings
+}
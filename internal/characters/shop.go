@@ -176,6 +176,31 @@ func (s *Shop) Destock(si ShopItem) bool {
 	return false
 }
 
+// Whether the shop stocks the same thing as a ShopItem (ignoring quantities)
+func (s *Shop) Has(si ShopItem) bool {
+	for _, fsItem := range *s {
+		if fsItem.sameAs(si) {
+			return true
+		}
+	}
+	return false
+}
+
+// Removes something from the shop entirely, no matter how many are in stock
+func (s *Shop) Remove(si ShopItem) bool {
+	for i, fsItem := range *s {
+		if fsItem.sameAs(si) {
+			(*s) = append((*s)[:i], (*s)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (si ShopItem) sameAs(other ShopItem) bool {
+	return si.ItemId == other.ItemId && si.MobId == other.MobId && si.BuffId == other.BuffId && si.PetType == other.PetType
+}
+
 func (s *Shop) GetInstock() Shop {
 	ret := Shop{}
 	for _, fsItem := range *s {
@@ -0,0 +1,161 @@
+package characters
+
+import (
+	"sort"
+
+	"github.com/volte6/gomud/internal/util"
+)
+
+//
+// Threat.
+// Mobs keep track of how much they hate each player fighting them, and attack whoever tops the list.
+// Damage builds threat one for one, and healing and skills add their own.
+// A taunt forces the mob onto the taunter for a few rounds no matter what the list says.
+//
+
+const (
+	ThreatForgetRounds = 30 // Threat is forgotten after this many rounds without any being added
+	TauntThreatBonus   = 10 // % above the top threat a taunt puts the taunter
+)
+
+type ThreatTable map[int]int // userId => threat
+
+type TauntInfo struct {
+	UserId     int    // Who taunted
+	UntilRound uint64 // The taunt wears off after this round
+}
+
+// Adds threat for a user. Negative amounts lower it, but never below zero.
+func (c *Character) AddThreat(userId int, amount int) {
+
+	if userId < 1 {
+		return
+	}
+
+	roundNow := util.GetRoundCount()
+
+	if len(c.Threat) == 0 || roundNow-c.LastThreat > ThreatForgetRounds {
+		c.Threat = ThreatTable{}
+	}
+
+	c.Threat[userId] += amount
+	if c.Threat[userId] < 0 {
+		c.Threat[userId] = 0
+	}
+
+	c.LastThreat = roundNow
+}
+
+func (c *Character) GetThreat(userId int) int {
+	if util.GetRoundCount()-c.LastThreat > ThreatForgetRounds {
+		return 0
+	}
+	return c.Threat[userId]
+}
+
+// Forgets a user, such as when they die or leave
+func (c *Character) ClearThreat(userId int) {
+	delete(c.Threat, userId)
+	if c.Taunt != nil && c.Taunt.UserId == userId {
+		c.Taunt = nil
+	}
+}
+
+// Returns the user with the most threat, and how much they have
+func (c *Character) TopThreat(canTarget func(userId int) bool) (int, int) {
+
+	topUserId, topThreat := 0, 0
+
+	if util.GetRoundCount()-c.LastThreat > ThreatForgetRounds {
+		return topUserId, topThreat
+	}
+
+	for userId, threat := range c.Threat {
+		if canTarget != nil && !canTarget(userId) {
+			continue
+		}
+		// Ties go to the lowest userId, so the choice doesn't flip around
+		if threat > topThreat || (threat == topThreat && threat > 0 && userId < topUserId) {
+			topUserId, topThreat = userId, threat
+		}
+	}
+
+	return topUserId, topThreat
+}
+
+// Everyone with any threat, most hated first
+func (c *Character) ThreatList() []int {
+
+	if util.GetRoundCount()-c.LastThreat > ThreatForgetRounds {
+		return []int{}
+	}
+
+	ret := []int{}
+	for userId, threat := range c.Threat {
+		if threat > 0 {
+			ret = append(ret, userId)
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if c.Threat[ret[i]] == c.Threat[ret[j]] {
+			return ret[i] < ret[j]
+		}
+		return c.Threat[ret[i]] > c.Threat[ret[j]]
+	})
+
+	return ret
+}
+
+// Taunts the character into attacking a user for some rounds.
+// The taunter is also put on top of the threat list, so the mob doesn't switch straight back afterwards.
+func (c *Character) SetTaunt(userId int, rounds int) {
+
+	_, topThreat := c.TopThreat(nil)
+	newThreat := topThreat + topThreat*TauntThreatBonus/100 + 1
+
+	if current := c.GetThreat(userId); current < newThreat {
+		c.AddThreat(userId, newThreat-current)
+	}
+
+	c.Taunt = &TauntInfo{
+		UserId:     userId,
+		UntilRound: util.GetRoundCount() + uint64(rounds),
+	}
+}
+
+// Returns who taunted the character, if the taunt is still going
+func (c *Character) GetTauntedBy() int {
+	if c.Taunt == nil {
+		return 0
+	}
+	if util.GetRoundCount() > c.Taunt.UntilRound {
+		c.Taunt = nil
+		return 0
+	}
+	return c.Taunt.UserId
+}
+
+// Decides who to attack, given who is being attacked now.
+// Someone else has to have stickiness% more threat than the current target to pull aggro.
+// Returns zero if there is nobody on the threat list to attack.
+func (c *Character) ThreatTarget(currentUserId int, stickiness int, canTarget func(userId int) bool) int {
+
+	if tauntUserId := c.GetTauntedBy(); tauntUserId > 0 && (canTarget == nil || canTarget(tauntUserId)) {
+		return tauntUserId
+	}
+
+	topUserId, topThreat := c.TopThreat(canTarget)
+	if topUserId == 0 || topUserId == currentUserId {
+		return topUserId
+	}
+
+	if currentUserId > 0 && (canTarget == nil || canTarget(currentUserId)) {
+		currentThreat := c.GetThreat(currentUserId)
+		if topThreat*100 <= currentThreat*(100+stickiness) {
+			return currentUserId
+		}
+	}
+
+	return topUserId
+}
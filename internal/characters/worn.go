@@ -97,6 +97,17 @@ func (w *Worn) GetAllItems() []items.Item {
 	return iList
 }
 
+// Replaces a worn item with an updated copy of it
+func (w *Worn) UpdateItem(originalItm items.Item, replacement items.Item) bool {
+	for _, slot := range []*items.Item{&w.Weapon, &w.Offhand, &w.Head, &w.Neck, &w.Body, &w.Belt, &w.Gloves, &w.Ring, &w.Legs, &w.Feet} {
+		if slot.ItemId > 0 && slot.Equals(originalItm) {
+			*slot = replacement
+			return true
+		}
+	}
+	return false
+}
+
 func GetAllSlotTypes() []string {
 	return []string{
 		string(items.Weapon),
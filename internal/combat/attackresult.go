@@ -14,6 +14,66 @@ type AttackResult struct {
 	MessagesToSourceRoom    []string
 	MessagesToTargetRoom    []string
 	MessagesToRoomOld       []string
+	Cancelled               bool // defaults false
+	swings                  []attackSwing
+}
+
+// A single hit whose messages haven't been written yet
+type attackSwing struct {
+	damage       int
+	writeMessage func(a *AttackResult, damage int)
+}
+
+// Called with the result of an attack before any damage is done, so it can be changed or cancelled
+type AttackModifier func(attackResult *AttackResult)
+
+// Cancels the attack, throwing away its damage, buffs and messages
+func (a *AttackResult) Cancel() {
+	*a = AttackResult{Cancelled: true}
+}
+
+func (a *AttackResult) addSwing(damage int, writeMessage func(a *AttackResult, damage int)) {
+	a.swings = append(a.swings, attackSwing{damage: damage, writeMessage: writeMessage})
+}
+
+// Writes the messages for every swing.
+// If the total damage was changed after the swings were rolled, it is shared out between them so the messages add up.
+func (a *AttackResult) writeSwingMessages() {
+
+	swings := a.swings
+	a.swings = nil
+
+	if len(swings) == 0 {
+		return
+	}
+
+	rolledDamage := 0
+	for _, swing := range swings {
+		rolledDamage += swing.damage
+	}
+
+	if rolledDamage != a.DamageToTarget {
+
+		remaining := a.DamageToTarget
+		for i := range swings {
+
+			if i == len(swings)-1 {
+				swings[i].damage = remaining
+				break
+			}
+
+			if rolledDamage > 0 {
+				swings[i].damage = int(float64(swings[i].damage) / float64(rolledDamage) * float64(a.DamageToTarget))
+			} else {
+				swings[i].damage = 0
+			}
+			remaining -= swings[i].damage
+		}
+	}
+
+	for _, swing := range swings {
+		swing.writeMessage(a, swing.damage)
+	}
 }
 
 func (a *AttackResult) SendToSource(msg string) {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -28,9 +29,16 @@ const (
 )
 
 // Performs a combat round from a player to a mob
-func AttackPlayerVsMob(user *users.UserRecord, mob *mobs.Mob) AttackResult {
+// Any modifiers are run before damage is applied or attack messages are written, in the order given
+func AttackPlayerVsMob(user *users.UserRecord, mob *mobs.Mob, modifiers ...AttackModifier) AttackResult {
 
-	attackResult := calculateCombat(*user.Character, mob.Character, User, Mob)
+	attackCount := user.Character.TakeAttackEnergy(mob.Character.Stats.Speed.ValueAdj)
+	attackResult := calculateCombat(*user.Character, mob.Character, User, Mob, attackCount)
+
+	for _, modify := range modifiers {
+		modify(&attackResult)
+	}
+	attackResult.writeSwingMessages()
 
 	user.Character.ApplyHealthChange(attackResult.DamageToSource * -1)
 	mob.Character.ApplyHealthChange(attackResult.DamageToTarget * -1)
@@ -42,9 +50,15 @@ func AttackPlayerVsMob(user *users.UserRecord, mob *mobs.Mob) AttackResult {
 }
 
 // Performs a combat round from a player to a player
-func AttackPlayerVsPlayer(userAtk *users.UserRecord, userDef *users.UserRecord) AttackResult {
+func AttackPlayerVsPlayer(userAtk *users.UserRecord, userDef *users.UserRecord, modifiers ...AttackModifier) AttackResult {
 
-	attackResult := calculateCombat(*userAtk.Character, *userDef.Character, User, User)
+	attackCount := userAtk.Character.TakeAttackEnergy(userDef.Character.Stats.Speed.ValueAdj)
+	attackResult := calculateCombat(*userAtk.Character, *userDef.Character, User, User, attackCount)
+
+	for _, modify := range modifiers {
+		modify(&attackResult)
+	}
+	attackResult.writeSwingMessages()
 
 	userAtk.Character.ApplyHealthChange(attackResult.DamageToSource * -1)
 	userDef.Character.ApplyHealthChange(attackResult.DamageToTarget * -1)
@@ -53,9 +67,15 @@ func AttackPlayerVsPlayer(userAtk *users.UserRecord, userDef *users.UserRecord)
 }
 
 // Performs a combat round from a mob to a player
-func AttackMobVsPlayer(mob *mobs.Mob, user *users.UserRecord) AttackResult {
+func AttackMobVsPlayer(mob *mobs.Mob, user *users.UserRecord, modifiers ...AttackModifier) AttackResult {
 
-	attackResult := calculateCombat(mob.Character, *user.Character, Mob, User)
+	attackCount := mob.Character.TakeAttackEnergy(user.Character.Stats.Speed.ValueAdj)
+	attackResult := calculateCombat(mob.Character, *user.Character, Mob, User, attackCount)
+
+	for _, modify := range modifiers {
+		modify(&attackResult)
+	}
+	attackResult.writeSwingMessages()
 
 	mob.Character.ApplyHealthChange(attackResult.DamageToSource * -1)
 	user.Character.ApplyHealthChange(attackResult.DamageToTarget * -1)
@@ -64,9 +84,15 @@ func AttackMobVsPlayer(mob *mobs.Mob, user *users.UserRecord) AttackResult {
 }
 
 // Performs a combat round from a mob to a mob
-func AttackMobVsMob(mobAtk *mobs.Mob, mobDef *mobs.Mob) AttackResult {
+func AttackMobVsMob(mobAtk *mobs.Mob, mobDef *mobs.Mob, modifiers ...AttackModifier) AttackResult {
+
+	attackCount := mobAtk.Character.TakeAttackEnergy(mobDef.Character.Stats.Speed.ValueAdj)
+	attackResult := calculateCombat(mobAtk.Character, mobDef.Character, Mob, User, attackCount)
 
-	attackResult := calculateCombat(mobAtk.Character, mobDef.Character, Mob, User)
+	for _, modify := range modifiers {
+		modify(&attackResult)
+	}
+	attackResult.writeSwingMessages()
 
 	mobAtk.Character.ApplyHealthChange(attackResult.DamageToSource * -1)
 	mobDef.Character.ApplyHealthChange(attackResult.DamageToTarget * -1)
@@ -90,7 +116,7 @@ func GetWaitMessages(stepType items.Intensity, sourceChar *characters.Character,
 
 	// zero means randomly selected, otherwise use the ItemId to consistently choose a message
 	msgSeed := 0
-	if configs.GetConfig().ConsistentAttackMessages {
+	if configs.GetConfig().ConsistentAttackMessages && sourceChar.Equipment.Weapon.ItemId > 0 { // Disabled slots have negative ids
 		msgSeed = sourceChar.Equipment.Weapon.ItemId
 	}
 
@@ -187,19 +213,20 @@ func GetWaitMessages(stepType items.Intensity, sourceChar *characters.Character,
 	return attackResult
 }
 
-func calculateCombat(sourceChar characters.Character, targetChar characters.Character, sourceType SourceTarget, targetType SourceTarget) AttackResult {
+// attackCount is how many swings the attacker's energy pays for this round. See characters/energy.go
+func calculateCombat(sourceChar characters.Character, targetChar characters.Character, sourceType SourceTarget, targetType SourceTarget, attackCount int) AttackResult {
 
-	attackResult := AttackResult{}
-
-	attackCount := int(math.Ceil(float64(sourceChar.Stats.Speed.ValueAdj-targetChar.Stats.Speed.ValueAdj) / 25))
+	// Not enough energy built up for a swing yet
 	if attackCount < 1 {
-		attackCount = 1
+		return GetWaitMessages(items.Wait, &sourceChar, &targetChar, sourceType, targetType)
 	}
 
+	attackResult := AttackResult{}
+
 	// Statmods can add a damage bonus...
 	statModDBonus := sourceChar.StatMod(`damage`)
-	// Add any additional attacks
-	attackCount += sourceChar.StatMod(`attacks`)
+	// ...or help get through armor
+	statModPenetration := sourceChar.StatMod(string(statmods.Penetration))
 
 	for i := 0; i < attackCount; i++ {
 
@@ -283,6 +310,8 @@ func calculateCombat(sourceChar characters.Character, targetChar characters.Char
 			raceInfo := races.GetRace(sourceChar.RaceId)
 			weaponName := raceInfo.UnarmedName
 			weaponSubType := items.Generic
+			weaponElement := items.Element(``)
+			weaponPenetration := statModPenetration
 
 			// Get default racial dice rolls
 			attacks, dCount, dSides, dBonus, critBuffs := sourceChar.GetDefaultDiceRoll()
@@ -297,6 +326,8 @@ func calculateCombat(sourceChar characters.Character, targetChar characters.Char
 				weaponName = weapon.DisplayName()
 
 				weaponSubType = itemSpec.Subtype
+				weaponElement = itemSpec.Element
+				weaponPenetration += itemSpec.Damage.Penetration
 				attacks, dCount, dSides, dBonus, critBuffs = weapon.GetDiceRoll()
 
 				// If there is a bonus vs. a specific race, apply it
@@ -328,6 +359,11 @@ func calculateCombat(sourceChar characters.Character, targetChar characters.Char
 						attackResult.Crit = true
 						attackResult.BuffTarget = critBuffs
 						attackTargetDamage += dCount*dSides + dBonus
+
+						// Elements add their own effects, unless the target is immune to them
+						if weaponElement != `` && targetChar.GetResistance(weaponElement) < 100 {
+							attackResult.BuffTarget = mergeBuffIds(critBuffs, weaponElement.CritBuffIds())
+						}
 					}
 				}
 
@@ -337,140 +373,174 @@ func calculateCombat(sourceChar characters.Character, targetChar characters.Char
 					attackTargetDamage -= attackTargetReduction
 				}
 
+				// Armor soaks up a share of whatever wasn't blocked
+				attackTargetSoaked := 0
+				if attackTargetDamage > 0 {
+					if mitigation := targetChar.GetArmorMitigation(weaponSubType, weaponPenetration); mitigation > 0 {
+						attackTargetSoaked = int(math.Round((float64(mitigation) / 100) * float64(attackTargetDamage)))
+						attackTargetDamage -= attackTargetSoaked
+					}
+				}
+
+				// Elemental damage is resisted (or made worse) after armor
+				elementResult := ``
+				if weaponElement != `` && attackTargetDamage > 0 {
+					resistedDamage := targetChar.ResistDamage(attackTargetDamage, weaponElement)
+					elementResult = elementResultText(attackTargetDamage, resistedDamage, weaponElement)
+					attackTargetDamage = resistedDamage
+				}
+
 				defenseAmt = util.Rand(sourceChar.GetDefense())
 				if defenseAmt > 0 {
 					attackSourceReduction = int(math.Round((float64(defenseAmt) / 100) * float64(attackSourceDamage)))
 					attackSourceDamage -= attackSourceReduction
 				}
 
-				// Calculate actual damage vs. possible damage pct
-				pctDamage := math.Ceil(float64(attackTargetDamage) / float64(dCount*dSides+dBonus) * 100)
+				// The messages are written once the damage is final, since scripts can still change it
+				crit := attackResult.Crit
+				attackResult.addSwing(attackTargetDamage, func(a *AttackResult, attackTargetDamage int) {
 
-				msgs := items.GetAttackMessage(weaponSubType, int(pctDamage))
+					// Calculate actual damage vs. possible damage pct
+					pctDamage := math.Ceil(float64(attackTargetDamage) / float64(dCount*dSides+dBonus) * 100)
 
-				var toAttackerMsg, toDefenderMsg, toAttackerRoomMsg, toDefenderRoomMsg items.ItemMessage
+					msgs := items.GetAttackMessage(weaponSubType, int(pctDamage))
 
-				tokenReplacements := map[items.TokenName]string{
-					items.TokenItemName:     weaponName,
-					items.TokenSource:       sourceChar.Name,
-					items.TokenSourceType:   string(sourceType) + `name`,
-					items.TokenTarget:       targetChar.Name,
-					items.TokenTargetType:   string(targetType) + `name`,
-					items.TokenUsesLeft:     `[Invalid]`,
-					items.TokenDamage:       strconv.Itoa(attackTargetDamage),
-					items.TokenEntranceName: `unknown`,
-					items.TokenExitName:     `unknown`,
-				}
+					var toAttackerMsg, toDefenderMsg, toAttackerRoomMsg, toDefenderRoomMsg items.ItemMessage
 
-				if sourceChar.RoomId == targetChar.RoomId {
+					tokenReplacements := map[items.TokenName]string{
+						items.TokenItemName:     weaponName,
+						items.TokenSource:       sourceChar.Name,
+						items.TokenSourceType:   string(sourceType) + `name`,
+						items.TokenTarget:       targetChar.Name,
+						items.TokenTargetType:   string(targetType) + `name`,
+						items.TokenUsesLeft:     `[Invalid]`,
+						items.TokenDamage:       strconv.Itoa(attackTargetDamage),
+						items.TokenEntranceName: `unknown`,
+						items.TokenExitName:     `unknown`,
+					}
 
-					toAttackerMsg = msgs.Together.ToAttacker.Get(msgSeed)
-					toDefenderMsg = msgs.Together.ToDefender.Get(msgSeed)
-					toAttackerRoomMsg = msgs.Together.ToRoom.Get(msgSeed)
-					toDefenderRoomMsg = items.ItemMessage("")
+					if sourceChar.RoomId == targetChar.RoomId {
 
-				} else {
+						toAttackerMsg = msgs.Together.ToAttacker.Get(msgSeed)
+						toDefenderMsg = msgs.Together.ToDefender.Get(msgSeed)
+						toAttackerRoomMsg = msgs.Together.ToRoom.Get(msgSeed)
+						toDefenderRoomMsg = items.ItemMessage("")
+
+					} else {
 
-					toAttackerMsg = msgs.Separate.ToAttacker.Get(msgSeed)
-					toDefenderMsg = msgs.Separate.ToDefender.Get(msgSeed)
-					toAttackerRoomMsg = msgs.Separate.ToAttackerRoom.Get(msgSeed)
-					toDefenderRoomMsg = msgs.Separate.ToDefenderRoom.Get(msgSeed)
-
-					slog.Error("toDefenderRoomMsg", "msg", toDefenderRoomMsg)
-					// Find the exit that leads to the target from the source (if any)
-					if atkRoom := rooms.LoadRoom(sourceChar.RoomId); atkRoom != nil {
-						for exitName, exit := range atkRoom.Exits {
-							if exit.RoomId == targetChar.RoomId {
-								tokenReplacements[items.TokenExitName] = exitName
-								break
+						toAttackerMsg = msgs.Separate.ToAttacker.Get(msgSeed)
+						toDefenderMsg = msgs.Separate.ToDefender.Get(msgSeed)
+						toAttackerRoomMsg = msgs.Separate.ToAttackerRoom.Get(msgSeed)
+						toDefenderRoomMsg = msgs.Separate.ToDefenderRoom.Get(msgSeed)
+
+						slog.Error("toDefenderRoomMsg", "msg", toDefenderRoomMsg)
+						// Find the exit that leads to the target from the source (if any)
+						if atkRoom := rooms.LoadRoom(sourceChar.RoomId); atkRoom != nil {
+							for exitName, exit := range atkRoom.Exits {
+								if exit.RoomId == targetChar.RoomId {
+									tokenReplacements[items.TokenExitName] = exitName
+									break
+								}
 							}
 						}
-					}
-					// find the exit that leads to the source from the target (if any)
-					if defRoom := rooms.LoadRoom(targetChar.RoomId); defRoom != nil {
-						for exitName, exit := range defRoom.Exits {
-							if exit.RoomId == sourceChar.RoomId {
-								tokenReplacements[items.TokenEntranceName] = exitName
-								break
+						// find the exit that leads to the source from the target (if any)
+						if defRoom := rooms.LoadRoom(targetChar.RoomId); defRoom != nil {
+							for exitName, exit := range defRoom.Exits {
+								if exit.RoomId == sourceChar.RoomId {
+									tokenReplacements[items.TokenEntranceName] = exitName
+									break
+								}
 							}
 						}
 					}
-				}
 
-				if sourceChar.Equipment.Weapon.ItemId > 0 {
-					tokenReplacements[items.TokenItemName] = sourceChar.Equipment.Weapon.DisplayName()
-				}
+					if sourceChar.Equipment.Weapon.ItemId > 0 {
+						tokenReplacements[items.TokenItemName] = sourceChar.Equipment.Weapon.DisplayName()
+					}
 
-				if sourceType == Mob {
-					tokenReplacements[items.TokenSource] = sourceChar.GetMobName(0).String()
-				}
+					if sourceType == Mob {
+						tokenReplacements[items.TokenSource] = sourceChar.GetMobName(0).String()
+					}
 
-				if targetType == Mob {
-					tokenReplacements[items.TokenTarget] = targetChar.GetMobName(0).String()
-				}
+					if targetType == Mob {
+						tokenReplacements[items.TokenTarget] = targetChar.GetMobName(0).String()
+					}
 
-				for tokenName, tokenValue := range tokenReplacements {
-					toAttackerMsg = toAttackerMsg.SetTokenValue(tokenName, tokenValue)
-					toDefenderMsg = toDefenderMsg.SetTokenValue(tokenName, tokenValue)
-					toAttackerRoomMsg = toAttackerRoomMsg.SetTokenValue(tokenName, tokenValue)
-					if len(string(toDefenderRoomMsg)) > 0 {
-						toDefenderRoomMsg = toDefenderRoomMsg.SetTokenValue(tokenName, tokenValue)
+					for tokenName, tokenValue := range tokenReplacements {
+						toAttackerMsg = toAttackerMsg.SetTokenValue(tokenName, tokenValue)
+						toDefenderMsg = toDefenderMsg.SetTokenValue(tokenName, tokenValue)
+						toAttackerRoomMsg = toAttackerRoomMsg.SetTokenValue(tokenName, tokenValue)
+						if len(string(toDefenderRoomMsg)) > 0 {
+							toDefenderRoomMsg = toDefenderRoomMsg.SetTokenValue(tokenName, tokenValue)
+						}
 					}
-				}
 
-				if attackResult.Crit {
-					toAttackerMsg = items.ItemMessage(`<ansi fg="yellow-bold">***</ansi> ` + string(toAttackerMsg) + ` <ansi fg="yellow-bold">***</ansi>`)
-					toDefenderMsg = items.ItemMessage(`<ansi fg="yellow-bold">***</ansi> ` + string(toDefenderMsg) + ` <ansi fg="yellow-bold">***</ansi>`)
-					toAttackerRoomMsg = items.ItemMessage(`<ansi fg="yellow-bold">***</ansi> ` + string(toAttackerRoomMsg) + ` <ansi fg="yellow-bold">***</ansi>`)
-					if len(string(toDefenderRoomMsg)) > 0 {
-						toDefenderRoomMsg = items.ItemMessage(`<ansi fg="yellow-bold">***</ansi> ` + string(toDefenderRoomMsg) + ` <ansi fg="yellow-bold">***</ansi>`)
+					if crit {
+						toAttackerMsg = items.ItemMessage(`<ansi fg="yellow-bold">***</ansi> ` + string(toAttackerMsg) + ` <ansi fg="yellow-bold">***</ansi>`)
+						toDefenderMsg = items.ItemMessage(`<ansi fg="yellow-bold">***</ansi> ` + string(toDefenderMsg) + ` <ansi fg="yellow-bold">***</ansi>`)
+						toAttackerRoomMsg = items.ItemMessage(`<ansi fg="yellow-bold">***</ansi> ` + string(toAttackerRoomMsg) + ` <ansi fg="yellow-bold">***</ansi>`)
+						if len(string(toDefenderRoomMsg)) > 0 {
+							toDefenderRoomMsg = items.ItemMessage(`<ansi fg="yellow-bold">***</ansi> ` + string(toDefenderRoomMsg) + ` <ansi fg="yellow-bold">***</ansi>`)
+						}
 					}
-				}
 
-				if len(attackMessagePrefix) > 0 {
-					toAttackerMsg = items.ItemMessage(attackMessagePrefix + string(toAttackerMsg))
-					toDefenderMsg = items.ItemMessage(attackMessagePrefix + string(toDefenderMsg))
-					toAttackerRoomMsg = items.ItemMessage(attackMessagePrefix + string(toAttackerRoomMsg))
-					if len(string(toDefenderRoomMsg)) > 0 {
-						toDefenderRoomMsg = items.ItemMessage(attackMessagePrefix + string(toDefenderRoomMsg))
+					if len(attackMessagePrefix) > 0 {
+						toAttackerMsg = items.ItemMessage(attackMessagePrefix + string(toAttackerMsg))
+						toDefenderMsg = items.ItemMessage(attackMessagePrefix + string(toDefenderMsg))
+						toAttackerRoomMsg = items.ItemMessage(attackMessagePrefix + string(toAttackerRoomMsg))
+						if len(string(toDefenderRoomMsg)) > 0 {
+							toDefenderRoomMsg = items.ItemMessage(attackMessagePrefix + string(toDefenderRoomMsg))
+						}
 					}
-				}
 
-				// Send to attacker
-				attackerMsg := string(toAttackerMsg)
-				if attackSourceDamage > 0 && attackSourceReduction > 0 {
-					attackerMsg += fmt.Sprintf(` <ansi fg="white">[%d was blocked]</ansi>`, attackSourceReduction)
-				}
+					if elementResult != `` {
+						toAttackerMsg = items.ItemMessage(string(toAttackerMsg) + elementResult)
+						toDefenderMsg = items.ItemMessage(string(toDefenderMsg) + elementResult)
+						toAttackerRoomMsg = items.ItemMessage(string(toAttackerRoomMsg) + elementResult)
+						if len(string(toDefenderRoomMsg)) > 0 {
+							toDefenderRoomMsg = items.ItemMessage(string(toDefenderRoomMsg) + elementResult)
+						}
+					}
 
-				attackResult.SendToSource(
-					string(attackerMsg),
-				)
+					// Send to attacker
+					attackerMsg := string(toAttackerMsg)
+					if attackSourceDamage > 0 && attackSourceReduction > 0 {
+						attackerMsg += fmt.Sprintf(` <ansi fg="white">[%d was blocked]</ansi>`, attackSourceReduction)
+					}
 
-				// Send to victim
-				defenderMsg := string(toDefenderMsg)
-				if attackTargetDamage > 0 && attackTargetReduction > 0 {
-					defenderMsg += fmt.Sprintf(` <ansi fg="red">[you blocked %d]</ansi>`, attackTargetReduction)
-				}
+					a.SendToSource(
+						string(attackerMsg),
+					)
 
-				attackResult.SendToTarget(
-					string(defenderMsg),
-				)
+					// Send to victim
+					defenderMsg := string(toDefenderMsg)
+					if attackTargetDamage > 0 && attackTargetReduction > 0 {
+						defenderMsg += fmt.Sprintf(` <ansi fg="red">[you blocked %d]</ansi>`, attackTargetReduction)
+					}
+					if attackTargetSoaked > 0 {
+						defenderMsg += fmt.Sprintf(` <ansi fg="red">[armor soaked %d]</ansi>`, attackTargetSoaked)
+					}
 
-				// Send to room
-				attackResult.SendToSourceRoom(
-					string(toAttackerRoomMsg.SetTokenValue(items.TokenTarget, targetChar.Name).
-						SetTokenValue(items.TokenTargetType, string(targetType))),
-				)
+					a.SendToTarget(
+						string(defenderMsg),
+					)
 
-				// Send to defender room if separate
-				if len(string(toDefenderRoomMsg)) > 0 {
-					attackResult.SendToTargetRoom(
-						string(toDefenderRoomMsg.SetTokenValue(items.TokenTarget, targetChar.Name).SetTokenValue(items.TokenTargetType, string(targetType))),
+					// Send to room
+					a.SendToSourceRoom(
+						string(toAttackerRoomMsg.SetTokenValue(items.TokenTarget, targetChar.Name).
+							SetTokenValue(items.TokenTargetType, string(targetType))),
 					)
-				}
+
+					// Send to defender room if separate
+					if len(string(toDefenderRoomMsg)) > 0 {
+						a.SendToTargetRoom(
+							string(toDefenderRoomMsg.SetTokenValue(items.TokenTarget, targetChar.Name).SetTokenValue(items.TokenTargetType, string(targetType))),
+						)
+					}
+				})
 
 				attackResult.DamageToTarget += attackTargetDamage
-				attackResult.DamageToTargetReduction += attackTargetReduction
+				attackResult.DamageToTargetReduction += attackTargetReduction + attackTargetSoaked
 
 				attackResult.DamageToSource += attackSourceDamage
 				attackResult.DamageToSourceReduction += attackSourceReduction
@@ -488,14 +558,17 @@ func calculateCombat(sourceChar characters.Character, targetChar characters.Char
 
 							attackResult.DamageToTarget += attackTargetDamage
 
-							toAttackerMsg := fmt.Sprintf(`%s jumps into the fray and deals <ansi fg="damage">%d damage</ansi> to <ansi fg="%sname">%s</ansi>!`, sourceChar.Pet.DisplayName(), attackTargetDamage, string(targetType), targetChar.Name)
-							attackResult.SendToSource(toAttackerMsg)
+							attackResult.addSwing(attackTargetDamage, func(a *AttackResult, attackTargetDamage int) {
+
+								toAttackerMsg := fmt.Sprintf(`%s jumps into the fray and deals <ansi fg="damage">%d damage</ansi> to <ansi fg="%sname">%s</ansi>!`, sourceChar.Pet.DisplayName(), attackTargetDamage, string(targetType), targetChar.Name)
+								a.SendToSource(toAttackerMsg)
 
-							toDefenderMsg := fmt.Sprintf(`%s jumps into the fray and deals <ansi fg="damage">%d damage</ansi> to you!`, sourceChar.Pet.DisplayName(), attackTargetDamage)
-							attackResult.SendToTarget(toDefenderMsg)
+								toDefenderMsg := fmt.Sprintf(`%s jumps into the fray and deals <ansi fg="damage">%d damage</ansi> to you!`, sourceChar.Pet.DisplayName(), attackTargetDamage)
+								a.SendToTarget(toDefenderMsg)
 
-							toAttackerRoomMsg := fmt.Sprintf(`%s jumps into the fray and deals <ansi fg="damage">%d damage</ansi> to <ansi fg="%sname">%s</ansi>!`, sourceChar.Pet.DisplayName(), attackTargetDamage, string(targetType), targetChar.Name)
-							attackResult.SendToTargetRoom(toAttackerRoomMsg)
+								toAttackerRoomMsg := fmt.Sprintf(`%s jumps into the fray and deals <ansi fg="damage">%d damage</ansi> to <ansi fg="%sname">%s</ansi>!`, sourceChar.Pet.DisplayName(), attackTargetDamage, string(targetType), targetChar.Name)
+								a.SendToTargetRoom(toAttackerRoomMsg)
+							})
 
 						}
 
@@ -509,6 +582,36 @@ func calculateCombat(sourceChar characters.Character, targetChar characters.Char
 
 }
 
+// Tags an attack message with its element, and how well the target stood up to it
+func elementResultText(damage int, resistedDamage int, element items.Element) string {
+
+	switch {
+	case resistedDamage == 0:
+		return fmt.Sprintf(` <ansi fg="white">[%s - immune]</ansi>`, element.ColorName())
+	case resistedDamage < damage:
+		return fmt.Sprintf(` <ansi fg="white">[%s - %d resisted]</ansi>`, element.ColorName(), damage-resistedDamage)
+	case resistedDamage > damage:
+		return fmt.Sprintf(` <ansi fg="white">[%s - <ansi fg="red-bold">weak!</ansi> +%d]</ansi>`, element.ColorName(), resistedDamage-damage)
+	}
+
+	return fmt.Sprintf(` <ansi fg="white">[%s]</ansi>`, element.ColorName())
+}
+
+// Combines lists of buff ids, leaving out repeats
+func mergeBuffIds(buffIdLists ...[]int) []int {
+
+	ret := []int{}
+	for _, buffIds := range buffIdLists {
+		for _, buffId := range buffIds {
+			if !slices.Contains(ret, buffId) {
+				ret = append(ret, buffId)
+			}
+		}
+	}
+
+	return ret
+}
+
 // hit chance will be between 30 and 100
 func hitChance(attackSpd, defendSpd int) int {
 	atkPlusDef := float64(attackSpd + defendSpd)
@@ -0,0 +1,345 @@
+package combat
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/volte6/gomud/internal/buffs"
+	"github.com/volte6/gomud/internal/characters"
+	"github.com/volte6/gomud/internal/items"
+	"github.com/volte6/gomud/internal/mobs"
+	"github.com/volte6/gomud/internal/races"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+//
+// Combat simulator.
+// Runs fights between two combatants through the same attack code the game uses, with a seeded
+// random number generator so the same setup always gives the same results.
+// Used by the "simulate" command line subcommand, and by tests that want to catch balance changes.
+// Buffs that fire are counted, but not applied, since their effects play out through scripts.
+//
+
+const (
+	SimDefaultFights    = 100
+	SimDefaultMaxRounds = 100 // A fight that goes on this long is called a draw
+	SimDefaultSeed      = 1
+)
+
+var (
+	ErrSimNoCombatant = errors.New(`a mob id or race id is required`)
+	ErrSimInvalidMob  = errors.New(`invalid mob id`)
+	ErrSimInvalidRace = errors.New(`invalid race id`)
+	ErrSimInvalidItem = errors.New(`invalid item id`)
+	ErrSimCannotEquip = errors.New(`item cannot be equipped`)
+)
+
+// One side of a simulated fight.
+// Either MobId or RaceId must be set. Mobs start with whatever they normally spawn with.
+type SimCombatant struct {
+	MobId   int   // Spawn this mob...
+	RaceId  int   // ...or build a character of this race
+	Level   int   // Overrides the mob level. Defaults to 1 for races.
+	ItemIds []int // Equipment to wear, replacing anything already in that slot
+}
+
+type SimOptions struct {
+	Fights    int        // How many fights to run
+	MaxRounds int        // How many rounds before a fight is a draw
+	Seed      int64      // Same seed, same results
+	Rand      *rand.Rand // (optional) where the random numbers come from. Made from Seed if not set.
+}
+
+// How one side did over all of the fights
+type SimSideStats struct {
+	Name       string
+	Wins       int
+	Rounds     int         // Rounds this side got to attack in
+	HitRounds  int         // Rounds with at least one hit
+	CritRounds int         // Rounds with at least one crit
+	Damage     int         // Total damage dealt
+	BuffsFired map[int]int // buffId => how many times this side's attacks applied it
+}
+
+type SimReport struct {
+	Fights      int
+	Draws       int
+	TotalRounds int
+	Seed        int64
+	A           SimSideStats
+	B           SimSideStats
+}
+
+// One side of a fight while it runs. Mobs fight as mobs, and races fight as players.
+type simFighter struct {
+	mob  *mobs.Mob
+	user *users.UserRecord
+}
+
+func (f simFighter) character() *characters.Character {
+	if f.user != nil {
+		return f.user.Character
+	}
+	return &f.mob.Character
+}
+
+// Goes after the other side, the same way the game would
+func (f simFighter) setAggro(target simFighter) {
+	if target.user != nil {
+		f.character().SetAggro(target.user.UserId, 0, characters.DefaultAttack)
+		return
+	}
+	f.character().SetAggro(0, target.mob.InstanceId, characters.DefaultAttack)
+}
+
+// Attacks the other side with whichever attack the game uses for the two of them
+func (f simFighter) attack(target simFighter) AttackResult {
+	switch {
+	case f.user != nil && target.user != nil:
+		return AttackPlayerVsPlayer(f.user, target.user)
+	case f.user != nil:
+		return AttackPlayerVsMob(f.user, target.mob)
+	case target.user != nil:
+		return AttackMobVsPlayer(f.mob, target.user)
+	}
+	return AttackMobVsMob(f.mob, target.mob)
+}
+
+// Builds a fresh fighter. simId is a stand-in UserId or mob InstanceId for hand built combatants.
+// Mobs spawned from a MobId must be destroyed afterwards.
+func (sc SimCombatant) build(simId int) (simFighter, error) {
+
+	var f simFighter
+
+	if sc.MobId > 0 {
+
+		if f.mob = mobs.NewMobById(mobs.MobId(sc.MobId), 0, sc.Level); f.mob == nil {
+			return f, fmt.Errorf(`%w: %d`, ErrSimInvalidMob, sc.MobId)
+		}
+
+	} else if sc.RaceId > 0 {
+
+		raceInfo := races.GetRace(sc.RaceId)
+		if raceInfo == nil {
+			return f, fmt.Errorf(`%w: %d`, ErrSimInvalidRace, sc.RaceId)
+		}
+
+		f.user = users.NewUserRecord(simId, 0)
+		f.user.Character.Name = raceInfo.Name
+		f.user.Character.RaceId = sc.RaceId
+		f.user.Character.RoomId = 0
+
+		// Levelled and trained the same way a spawned mob is
+		c := f.user.Character
+		c.Level = max(sc.Level, 1)
+		c.StatPoints = c.Level
+		c.Level--
+		c.Experience = c.XPTNL()
+		c.Level++
+		c.AutoTrain()
+
+	} else {
+		return f, ErrSimNoCombatant
+	}
+
+	for _, itemId := range sc.ItemIds {
+
+		itm := items.New(itemId)
+		if itm.ItemId == 0 {
+			sc.destroy(f)
+			return f, fmt.Errorf(`%w: %d`, ErrSimInvalidItem, itemId)
+		}
+
+		if _, worn, reason := f.character().Wear(itm); !worn {
+			sc.destroy(f)
+			return f, fmt.Errorf(`%w: %d (%s)`, ErrSimCannotEquip, itemId, reason)
+		}
+	}
+
+	c := f.character()
+	c.Validate(true)
+	c.Health = c.HealthMax.Value
+	c.Mana = c.ManaMax.Value
+
+	return f, nil
+}
+
+// Cleans up anything build() left behind
+func (sc SimCombatant) destroy(f simFighter) {
+	if f.mob != nil && sc.MobId > 0 {
+		mobs.DestroyInstance(f.mob.InstanceId)
+	}
+}
+
+// Runs a number of fights between a and b, with a attacking first each round.
+// This swaps out the game's random numbers while it runs, so it must not be used on a live server.
+func Simulate(a SimCombatant, b SimCombatant, opts SimOptions) (SimReport, error) {
+
+	if opts.Fights < 1 {
+		opts.Fights = SimDefaultFights
+	}
+
+	if opts.MaxRounds < 1 {
+		opts.MaxRounds = SimDefaultMaxRounds
+	}
+
+	if opts.Seed == 0 {
+		opts.Seed = SimDefaultSeed
+	}
+
+	if opts.Rand == nil {
+		opts.Rand = rand.New(rand.NewSource(opts.Seed))
+	}
+
+	defer util.SetRandSource(opts.Rand)()
+
+	report := SimReport{
+		Seed: opts.Seed,
+		A:    SimSideStats{BuffsFired: map[int]int{}},
+		B:    SimSideStats{BuffsFired: map[int]int{}},
+	}
+
+	for i := 0; i < opts.Fights; i++ {
+
+		fighterA, err := a.build(-1)
+		if err != nil {
+			return report, err
+		}
+
+		fighterB, err := b.build(-2)
+		if err != nil {
+			a.destroy(fighterA)
+			return report, err
+		}
+
+		report.A.Name = fighterA.character().Name
+		report.B.Name = fighterB.character().Name
+
+		switch report.fight(fighterA, fighterB, opts.MaxRounds) {
+		case 1:
+			report.A.Wins++
+		case 2:
+			report.B.Wins++
+		default:
+			report.Draws++
+		}
+
+		report.Fights++
+
+		a.destroy(fighterA)
+		b.destroy(fighterB)
+	}
+
+	return report, nil
+}
+
+// Fights until one side drops. Returns 1 or 2 for the winner, or 0 for a draw.
+func (r *SimReport) fight(a simFighter, b simFighter, maxRounds int) int {
+
+	a.setAggro(b)
+	b.setAggro(a)
+
+	for round := 1; round <= maxRounds; round++ {
+
+		r.TotalRounds++
+
+		r.A.record(a.attack(b))
+		if b.character().Health <= 0 {
+			return 1
+		}
+
+		r.B.record(b.attack(a))
+		if a.character().Health <= 0 {
+			return 2
+		}
+	}
+
+	return 0
+}
+
+func (s *SimSideStats) record(result AttackResult) {
+
+	s.Rounds++
+	s.Damage += result.DamageToTarget
+
+	if result.Hit {
+		s.HitRounds++
+	}
+
+	if result.Crit {
+		s.CritRounds++
+	}
+
+	for _, buffId := range result.BuffSource {
+		s.BuffsFired[buffId]++
+	}
+
+	for _, buffId := range result.BuffTarget {
+		s.BuffsFired[buffId]++
+	}
+}
+
+// Percentage of fights this side won
+func (r SimReport) WinRate(s SimSideStats) float64 {
+	if r.Fights == 0 {
+		return 0
+	}
+	return float64(s.Wins) / float64(r.Fights) * 100
+}
+
+func (r SimReport) AverageRounds() float64 {
+	if r.Fights == 0 {
+		return 0
+	}
+	return float64(r.TotalRounds) / float64(r.Fights)
+}
+
+func (s SimSideStats) DamagePerRound() float64 {
+	if s.Rounds == 0 {
+		return 0
+	}
+	return float64(s.Damage) / float64(s.Rounds)
+}
+
+// Percentage of rounds with a hit that also crit
+func (s SimSideStats) CritRate() float64 {
+	if s.HitRounds == 0 {
+		return 0
+	}
+	return float64(s.CritRounds) / float64(s.HitRounds) * 100
+}
+
+func (r SimReport) String() string {
+
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Fights: %d  Seed: %d  Avg Rounds: %.2f  Draws: %d\n", r.Fights, r.Seed, r.AverageRounds(), r.Draws))
+
+	for _, s := range []SimSideStats{r.A, r.B} {
+
+		sb.WriteString(fmt.Sprintf("%s\n", s.Name))
+		sb.WriteString(fmt.Sprintf("  Win Rate:   %.1f%%\n", r.WinRate(s)))
+		sb.WriteString(fmt.Sprintf("  Dmg/Round:  %.2f\n", s.DamagePerRound()))
+		sb.WriteString(fmt.Sprintf("  Crit Rate:  %.1f%%\n", s.CritRate()))
+
+		buffIds := make([]int, 0, len(s.BuffsFired))
+		for buffId := range s.BuffsFired {
+			buffIds = append(buffIds, buffId)
+		}
+		sort.Ints(buffIds)
+
+		for _, buffId := range buffIds {
+			buffName := fmt.Sprintf(`#%d`, buffId)
+			if spec := buffs.GetBuffSpec(buffId); spec != nil {
+				buffName = fmt.Sprintf(`%s (#%d)`, spec.Name, buffId)
+			}
+			sb.WriteString(fmt.Sprintf("  Buff:       %s fired %d times (%.2f per fight)\n", buffName, s.BuffsFired[buffId], float64(s.BuffsFired[buffId])/float64(max(r.Fights, 1))))
+		}
+	}
+
+	return sb.String()
+}
@@ -56,6 +56,7 @@ type Config struct {
 	TelnetPort                   ConfigSliceString `yaml:"TelnetPort"`                   // One or more Ports used to accept telnet connections
 	LocalPort                    ConfigInt         `yaml:"LocalPort"`                    // Port used for admin connections, localhost only
 	WebPort                      ConfigInt         `yaml:"WebPort"`                      // Port used for web requests
+	PublicMaps                   ConfigBool        `yaml:"PublicMaps"`                   // Whether zone maps (explored rooms only, without secrets) can be viewed on the web by anyone
 	NextRoomId                   ConfigInt         `yaml:"NextRoomId"`                   // The next room id to use when creating a new room
 	LootGoblinRoundCount         ConfigInt         `yaml:"LootGoblinRoundCount"`         // How often to spawn a loot goblin
 	LootGoblinMinimumItems       ConfigInt         `yaml:"LootGoblinMinimumItems"`       // How many items on the ground to attract the loot goblin
@@ -98,6 +99,31 @@ type Config struct {
 
 	LeaderboardSize ConfigInt `yaml:"LeaderboardSize"` // Maximum size of leaderboard
 
+	// Player housing
+	HousePrice          ConfigInt    `yaml:"HousePrice"`          // Price in gold to buy a house from a realtor
+	HouseRent           ConfigInt    `yaml:"HouseRent"`           // Rent taken from the owner's bank each period. 0 for no rent.
+	HouseRentPeriod     ConfigString `yaml:"HouseRentPeriod"`     // How often rent is taken
+	HouseMaxDecorations ConfigInt    `yaml:"HouseMaxDecorations"` // How many items can be put on display in a house
+
+	// Mob threat
+	ThreatStickiness ConfigInt `yaml:"ThreatStickiness"` // % more threat than the current target it takes to pull a mob away
+	ThreatHealing    ConfigInt `yaml:"ThreatHealing"`    // % of healing done that becomes threat with mobs fighting whoever was healed
+
+	// Ranked arena
+	ArenaRoomId      ConfigInt    `yaml:"ArenaRoomId"`      // Room that is copied to make a private arena for each match. 0 to disable the arena.
+	ArenaSeason      ConfigInt    `yaml:"ArenaSeason"`      // The current arena season. Raising it starts a fresh ladder.
+	ArenaKFactor     ConfigInt    `yaml:"ArenaKFactor"`     // The most rating that can be won or lost in a single match
+	ArenaMatchRange  ConfigInt    `yaml:"ArenaMatchRange"`  // How far apart ratings can be when matching players. Grows the longer they wait.
+	ArenaMatchLength ConfigString `yaml:"ArenaMatchLength"` // How long a match can go before it is called a draw
+
+	// Duels
+	DuelYieldPercent ConfigInt `yaml:"DuelYieldPercent"` // % of max health at which a duelist yields and loses the duel
+	DuelMaxWager     ConfigInt `yaml:"DuelMaxWager"`     // The most gold that can be wagered on a duel. 0 to disallow wagers.
+
+	// Corpses
+	CorpseDecayTime       ConfigString `yaml:"CorpseDecayTime"`       // How long a mob corpse lasts before rotting to bones. The bones last as long again.
+	PlayerCorpseDecayTime ConfigString `yaml:"PlayerCorpseDecayTime"` // How long a player corpse lasts before rotting to bones. The bones last as long again.
+
 	SeedInt int64 `yaml:"-"`
 
 	RoundCount ConfigUInt64 `yaml:"RoundCount,omitempty"` // Last saved round count
@@ -524,6 +550,66 @@ func (c *Config) Validate() {
 
 	// nothing to do with LootGoblinIncludeRecentRooms
 
+	if c.HousePrice < 0 {
+		c.HousePrice = 0
+	}
+
+	if c.HouseRent < 0 {
+		c.HouseRent = 0
+	}
+
+	if c.HouseRentPeriod == `` {
+		c.HouseRentPeriod = `1 week` // default
+	}
+
+	if c.HouseMaxDecorations < 0 {
+		c.HouseMaxDecorations = 0
+	}
+
+	if c.ThreatStickiness < 0 {
+		c.ThreatStickiness = 0
+	}
+
+	if c.ThreatHealing < 0 {
+		c.ThreatHealing = 0
+	}
+
+	if c.ArenaRoomId < 0 {
+		c.ArenaRoomId = 0
+	}
+
+	if c.ArenaSeason < 1 {
+		c.ArenaSeason = 1 // default
+	}
+
+	if c.ArenaKFactor < 1 {
+		c.ArenaKFactor = 32 // default
+	}
+
+	if c.ArenaMatchRange < 0 {
+		c.ArenaMatchRange = 100 // default
+	}
+
+	if c.ArenaMatchLength == `` {
+		c.ArenaMatchLength = `5 real minutes` // default
+	}
+
+	if c.DuelYieldPercent < 1 || c.DuelYieldPercent > 99 {
+		c.DuelYieldPercent = 20 // default
+	}
+
+	if c.DuelMaxWager < 0 {
+		c.DuelMaxWager = 0
+	}
+
+	if c.CorpseDecayTime == `` {
+		c.CorpseDecayTime = `3 real minutes` // default
+	}
+
+	if c.PlayerCorpseDecayTime == `` {
+		c.PlayerCorpseDecayTime = `15 real minutes` // default
+	}
+
 	if c.LogIntervalRoundCount < 0 {
 		c.LogIntervalRoundCount = 0
 	}
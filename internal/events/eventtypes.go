@@ -2,9 +2,11 @@ package events
 
 // Used to apply or remove buffs
 type Buff struct {
-	UserId        int
-	MobInstanceId int
-	BuffId        int
+	UserId              int
+	MobInstanceId       int
+	BuffId              int
+	SourceUserId        int // Who applied it, if anyone
+	SourceMobInstanceId int
 }
 
 func (b Buff) Type() string { return `Buff` }
@@ -28,6 +30,14 @@ type RoomAction struct {
 
 func (r RoomAction) Type() string { return `RoomAction` }
 
+// Fired after a zone has been restored to its authored state
+type ZoneReset struct {
+	Zone    string
+	RoomIds []int
+}
+
+func (z ZoneReset) Type() string { return `ZoneReset` }
+
 // Used for Input from players/mobs
 type Input struct {
 	UserId        int
@@ -1,6 +1,10 @@
 package exit
 
-import "github.com/volte6/gomud/internal/gamelock"
+import (
+	"github.com/volte6/gomud/internal/gamelock"
+	"github.com/volte6/gomud/internal/gametime"
+	"github.com/volte6/gomud/internal/util"
+)
 
 // There is a magic portal of Chuckles, magic portal of Henry here!
 // There is a magical hole in the east wall here!
@@ -17,8 +21,59 @@ type RoomExit struct {
 	Secret       bool          `yaml:"secret,omitempty"`
 	MapDirection string        `yaml:"mapdirection,omitempty"` // Optionaly indicate the direction of this exit for mapping purposes
 	Lock         gamelock.Lock `yaml:"lock,omitempty"`         // 0 - no lock. greater than zero = difficulty to unlock.
+	Door         *Door         `yaml:"door,omitempty"`         // nil - no door. Doors can be opened and closed, and block sight/sound when closed.
+	Movement     string        `yaml:"movement,omitempty"`     // (optional) how it is traveled: swim, climb, fly or squeeze. Defaults to the biome of the room it leads to.
 }
 
 func (re RoomExit) HasLock() bool {
 	return re.Lock.Difficulty > 0
 }
+
+func (re RoomExit) HasDoor() bool {
+	return re.Door != nil
+}
+
+// Returns true if there is a door and it is shut
+func (re RoomExit) IsClosed() bool {
+	return re.Door != nil && !re.Door.Open
+}
+
+// A door sitting in an exit.
+// The other side of the exit should have its own door, and the state is kept in sync.
+type Door struct {
+	Name        string `yaml:"name,omitempty"`       // (optional) what it's called. Defaults to "door" (gate, hatch, portcullis etc.)
+	StartsOpen  bool   `yaml:"startsopen,omitempty"` // Whether it is open when first loaded or reset
+	AutoClose   string `yaml:"autoclose,omitempty"`  // (optional) how long after being opened until it shuts itself, such as "5 rounds"
+	Open        bool   `yaml:"-"`                    // Whether it is currently open
+	OpenedRound uint64 `yaml:"-"`                    // What round it was opened (zero if it started open)
+}
+
+func (d *Door) GetName() string {
+	if d.Name == `` {
+		return `door`
+	}
+	return d.Name
+}
+
+func (d *Door) SetOpen(open bool) {
+	d.Open = open
+	if open {
+		d.OpenedRound = util.GetRoundCount()
+	} else {
+		d.OpenedRound = 0
+	}
+}
+
+// Puts the door back into its starting state
+func (d *Door) Reset() {
+	d.Open = d.StartsOpen
+	d.OpenedRound = 0
+}
+
+// Returns true if the door was opened by someone and has been open long enough to close on its own
+func (d *Door) ShouldAutoClose(roundNow uint64) bool {
+	if !d.Open || d.AutoClose == `` || d.OpenedRound == 0 {
+		return false
+	}
+	return roundNow >= gametime.GetDate(d.OpenedRound).AddPeriod(d.AutoClose)
+}
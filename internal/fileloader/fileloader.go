@@ -38,6 +38,16 @@ const (
 )
 
 func LoadFlatFile[T LoadableSimple](path string) (T, error) {
+	return loadFlatFile[T](path, true)
+}
+
+// Loads a flat file without validating it.
+// Useful for editing a file and saving it back the way it was authored, without any defaults filled in.
+func LoadFlatFileRaw[T LoadableSimple](path string) (T, error) {
+	return loadFlatFile[T](path, false)
+}
+
+func loadFlatFile[T LoadableSimple](path string, validate bool) (T, error) {
 
 	var loaded T
 
@@ -88,6 +98,10 @@ func LoadFlatFile[T LoadableSimple](path string) (T, error) {
 		return loaded, errors.New(fmt.Sprintf(`filesystem path "%s" did not end in Filepath() "%s" for type %T`, path, loaded.Filepath(), loaded))
 	}
 
+	if !validate {
+		return loaded, nil
+	}
+
 	// validate the structure
 	if err := loaded.Validate(); err != nil {
 		return loaded, errors.Wrap(err, `filepath: `+path)
@@ -386,3 +400,49 @@ func SaveAllFlatFiles[K comparable, T Loadable[K]](basePath string, data map[K]T
 
 	return int(saveCt), nil
 }
+
+// Removes the old copy of a flat file once it has been saved under a new path (renamed or moved),
+// and carries any scripts that were named after it along to the new name.
+// Scripts may be named {name}.js or {name}-{tag}.js, optionally inside a subfolder of the flat file's folder.
+func RelocateFlatFile(basePath string, oldFilepath string, newFilepath string, scriptFolder ...string) error {
+
+	// Normalize slashes
+	basePath = filepath.FromSlash(basePath)
+
+	oldPath := filepath.Join(basePath, oldFilepath)
+	newPath := filepath.Join(basePath, newFilepath)
+
+	if oldPath == newPath {
+		return nil
+	}
+
+	if err := os.Remove(oldPath); err != nil && !os.IsNotExist(err) {
+		return errors.New(fmt.Sprint(`RelocateFlatFile`, `basePath`, basePath, `path`, oldPath, `err`, err))
+	}
+
+	subFolder := ``
+	if len(scriptFolder) > 0 {
+		subFolder = scriptFolder[0]
+	}
+
+	oldScriptBase := filepath.Join(filepath.Dir(oldPath), subFolder, strings.TrimSuffix(filepath.Base(oldPath), filepath.Ext(oldPath)))
+	newScriptBase := filepath.Join(filepath.Dir(newPath), subFolder, strings.TrimSuffix(filepath.Base(newPath), filepath.Ext(newPath)))
+
+	scriptPaths, _ := filepath.Glob(oldScriptBase + `-*.js`)
+	if _, err := os.Stat(oldScriptBase + `.js`); err == nil {
+		scriptPaths = append(scriptPaths, oldScriptBase+`.js`)
+	}
+
+	for _, scriptPath := range scriptPaths {
+
+		if err := os.MkdirAll(filepath.Dir(newScriptBase), 0755); err != nil {
+			return errors.New(fmt.Sprint(`RelocateFlatFile`, `basePath`, basePath, `path`, scriptPath, `err`, err))
+		}
+
+		if err := os.Rename(scriptPath, newScriptBase+strings.TrimPrefix(scriptPath, oldScriptBase)); err != nil {
+			return errors.New(fmt.Sprint(`RelocateFlatFile`, `basePath`, basePath, `path`, scriptPath, `err`, err))
+		}
+	}
+
+	return nil
+}
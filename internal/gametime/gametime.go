@@ -27,6 +27,7 @@ type GameDate struct {
 	Month       int
 	Week        int
 	Day         int
+	MonthDay    int
 	Hour        int
 	Hour24      int
 	Minute      int
@@ -202,10 +203,15 @@ func (g *GameDate) ReCalculate() {
 	week := math.Floor(float64(day) / 7)
 
 	month := 1 + math.Floor((day*24)/730) // 730 hours in a "month" (24 hours * 365 days / 12 months)
+	// The last day of the year would otherwise be a 13th month
+	if month > 12 {
+		month = 12
+	}
 
 	g.Day = int(day)
 	g.Year = int(year)
 	g.Month = int(month)
+	g.MonthDay = int(day) - monthStartDay(int(month)) + 1
 	g.Week = int(week)
 	g.Hour = hour
 	g.Hour24 = hour24
@@ -218,6 +224,17 @@ func (g *GameDate) ReCalculate() {
 	g.DayStart = nightEnd
 }
 
+// The round this date's day started on (midnight)
+func (g GameDate) DayStartRound() uint64 {
+	roundOfDay := (g.RoundNumber + uint64(dayResetOffset)) % uint64(g.RoundsPerDay)
+	return g.RoundNumber - roundOfDay
+}
+
+// The round a given hour (0-23) of this date's day starts on
+func (g GameDate) HourRound(hour24 int) uint64 {
+	return g.DayStartRound() + uint64(math.Floor(float64(hour24%24)*float64(g.RoundsPerDay)/24))
+}
+
 func (g GameDate) Add(adjustHours int, adjustDays int, adjustYears int) GameDate {
 
 	rStart := g.RoundNumber
@@ -1,5 +1,7 @@
 package gametime
 
+import "math"
+
 var (
 	monthNames = []string{
 		`Arvalon`,
@@ -17,6 +19,11 @@ var (
 	}
 )
 
+// The day of the year a month starts on
+func monthStartDay(month int) int {
+	return int(math.Floor(float64((month-1)*730)/24)) + 1
+}
+
 func MonthName(month int) string {
 	month--
 	return monthNames[month%len(monthNames)]
@@ -0,0 +1,54 @@
+package gametime
+
+import "strings"
+
+const (
+	MoonCycleDays = 28 // How many days from one new moon to the next
+)
+
+var (
+	moonPhases = []string{
+		`new`,
+		`waxing crescent`,
+		`first quarter`,
+		`waxing gibbous`,
+		`full`,
+		`waning gibbous`,
+		`last quarter`,
+		`waning crescent`,
+	}
+)
+
+func MoonPhaseNames() []string {
+	return append([]string{}, moonPhases...)
+}
+
+// How many days into the moon's cycle this date is. Zero is the first day of the new moon.
+func (g GameDate) MoonDay() int {
+	return int(((g.RoundNumber + uint64(dayResetOffset)) / uint64(g.RoundsPerDay)) % MoonCycleDays)
+}
+
+// The phase of the moon, such as "full" or "waxing crescent"
+func (g GameDate) MoonPhase() string {
+	return moonPhases[g.MoonDay()*len(moonPhases)/MoonCycleDays]
+}
+
+// Whether today is the first day of a moon phase
+func (g GameDate) MoonPhaseStarts(phase string) bool {
+	phase = strings.ToLower(phase)
+	if g.MoonPhase() != phase {
+		return false
+	}
+	yesterday := (g.MoonDay() + MoonCycleDays - 1) % MoonCycleDays
+	return moonPhases[yesterday*len(moonPhases)/MoonCycleDays] != phase
+}
+
+func IsMoonPhase(phase string) bool {
+	phase = strings.ToLower(phase)
+	for _, p := range moonPhases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
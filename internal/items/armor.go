@@ -0,0 +1,164 @@
+package items
+
+import (
+	"sort"
+	"strings"
+)
+
+//
+// Armor.
+// Every piece of worn equipment can have an armor value, which soaks up a share of each hit.
+// How well it holds up depends on what it's made of and what kind of weapon is hitting it,
+// so plate shrugs off a sword but a hammer still rings the bell.
+//
+
+type ArmorType string
+
+const (
+	Cloth   ArmorType = "cloth"
+	Leather ArmorType = "leather"
+	Hide    ArmorType = "hide"
+	Chain   ArmorType = "chain"
+	Plate   ArmorType = "plate"
+
+	// The amount of armor that stops half of a hit.
+	// Armor has diminishing returns, so each point is worth a little less than the last.
+	ArmorHalfMitigation = 50
+	// No amount of armor stops more than this % of a hit
+	ArmorMaxMitigation = 85
+)
+
+type ArmorTypeInfo struct {
+	Description   string
+	Effectiveness map[ItemSubType]int // % of the armor value that counts against a weapon type. Missing is 100.
+}
+
+var (
+	ArmorTypes = map[ArmorType]ArmorTypeInfo{
+		Cloth: {
+			Description: `Padding that does little against edges and points.`,
+			Effectiveness: map[ItemSubType]int{
+				Slashing: 75,
+				Cleaving: 75,
+				Stabbing: 75,
+				Claws:    75,
+			},
+		},
+		Leather: {
+			Description: `Supple and good at taking a beating, but easily punctured.`,
+			Effectiveness: map[ItemSubType]int{
+				Bludgeoning: 125,
+				Whipping:    125,
+				Stabbing:    75,
+				Shooting:    75,
+			},
+		},
+		Hide: {
+			Description: `Thick fur and skin that turns claws and teeth.`,
+			Effectiveness: map[ItemSubType]int{
+				Claws:    125,
+				Whipping: 125,
+				Cleaving: 75,
+			},
+		},
+		Chain: {
+			Description: `Rings of metal that stop edges, but not points or blunt force.`,
+			Effectiveness: map[ItemSubType]int{
+				Slashing:    125,
+				Cleaving:    125,
+				Claws:       125,
+				Stabbing:    75,
+				Bludgeoning: 75,
+			},
+		},
+		Plate: {
+			Description: `Solid metal that turns most blows, but rings under a heavy one.`,
+			Effectiveness: map[ItemSubType]int{
+				Slashing:    125,
+				Shooting:    125,
+				Whipping:    150,
+				Bludgeoning: 75,
+			},
+		},
+	}
+)
+
+func ArmorTypeNames() []string {
+	ret := []string{}
+	for a := range ArmorTypes {
+		ret = append(ret, string(a))
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (a ArmorType) IsValid() bool {
+	_, ok := ArmorTypes[a]
+	return ok
+}
+
+func (a ArmorType) String() string {
+	return string(a)
+}
+
+// How much of an armor value counts against a type of weapon.
+// Armor without a type counts fully against everything.
+func (a ArmorType) EffectiveArmor(armor int, damageType ItemSubType) int {
+	if info, ok := ArmorTypes[a]; ok {
+		if pct, ok := info.Effectiveness[damageType]; ok {
+			return armor * pct / 100
+		}
+	}
+	return armor
+}
+
+// Sums up which weapons an armor type holds up well or poorly against
+func (a ArmorType) Summary() string {
+
+	strong := []string{}
+	weak := []string{}
+
+	for damageType, pct := range ArmorTypes[a].Effectiveness {
+		if pct > 100 {
+			strong = append(strong, string(damageType))
+		} else if pct < 100 {
+			weak = append(weak, string(damageType))
+		}
+	}
+
+	sort.Strings(strong)
+	sort.Strings(weak)
+
+	parts := []string{}
+	if len(strong) > 0 {
+		parts = append(parts, `strong vs `+strings.Join(strong, `, `))
+	}
+	if len(weak) > 0 {
+		parts = append(parts, `weak vs `+strings.Join(weak, `, `))
+	}
+
+	return strings.Join(parts, `; `)
+}
+
+// Armor penetration ignores a % of the armor before it counts
+func PenetrateArmor(armor int, penetration int) int {
+	if armor < 1 || penetration < 1 {
+		return armor
+	}
+	if penetration >= 100 {
+		return 0
+	}
+	return armor * (100 - penetration) / 100
+}
+
+// The % of a hit a given amount of armor stops
+func ArmorMitigation(armor int) int {
+	if armor < 1 {
+		return 0
+	}
+	pct := armor * 100 / (armor + ArmorHalfMitigation)
+	if pct > ArmorMaxMitigation {
+		pct = ArmorMaxMitigation
+	}
+	return pct
+}
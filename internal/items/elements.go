@@ -0,0 +1,100 @@
+package items
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/volte6/gomud/internal/statmods"
+)
+
+//
+// Elemental damage.
+// Weapons and spells with an element deal that kind of damage. How much of it gets through
+// comes down to the target's `resist-{element}` statmod, which is the percent of it stopped.
+// 100 or more is immunity, and a negative value is a weakness that adds damage instead.
+//
+
+const (
+	PoisonedBuffId = 13
+	OnFireBuffId   = 22
+	SoakedBuffId   = 40
+	ChilledBuffId  = 41
+)
+
+type ElementInfo struct {
+	CritBuffIds []int // Applied on a critical hit, along with any the weapon has
+}
+
+var (
+	Elements = map[Element]ElementInfo{
+		Fire:        {CritBuffIds: []int{OnFireBuffId}},
+		Water:       {CritBuffIds: []int{SoakedBuffId}},
+		Ice:         {CritBuffIds: []int{ChilledBuffId}},
+		Electricity: {},
+		Acid:        {},
+		Life:        {},
+		Death:       {},
+		Poison:      {CritBuffIds: []int{PoisonedBuffId}},
+		Holy:        {},
+		Shadow:      {},
+	}
+)
+
+func ElementNames() []string {
+	ret := []string{}
+	for e := range Elements {
+		ret = append(ret, string(e))
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (e Element) IsValid() bool {
+	_, ok := Elements[e]
+	return ok
+}
+
+func (e Element) CritBuffIds() []int {
+	return Elements[e].CritBuffIds
+}
+
+// The statmod that resists this element, such as "resist-fire"
+func (e Element) ResistStatMod() string {
+	return string(statmods.ResistPrefix) + string(e)
+}
+
+// The element name in its own color, for messages
+func (e Element) ColorName() string {
+	return fmt.Sprintf(`<ansi fg="element-%s">%s</ansi>`, e, e)
+}
+
+// Works out how much of a hit gets through a resistance. Returns the new damage amount.
+func ResistDamage(damage int, resistance int) int {
+
+	if damage < 1 || resistance == 0 {
+		return damage
+	}
+
+	if resistance >= 100 {
+		return 0
+	}
+
+	return damage * (100 - resistance) / 100
+}
+
+// Describes a resistance in a few words, such as "immune" or "weak"
+func ResistanceDescription(resistance int) string {
+	switch {
+	case resistance >= 100:
+		return `immune`
+	case resistance >= 50:
+		return `very resistant`
+	case resistance > 0:
+		return `resistant`
+	case resistance <= -50:
+		return `very weak`
+	case resistance < 0:
+		return `weak`
+	}
+	return ``
+}
@@ -231,10 +231,15 @@ func (i *Item) GetLongDescription() string {
 
 		}
 
-		if iSpec.WaitRounds > 0 {
+		if speed := iSpec.GetSpeed(); speed < 1 {
 
 			longDesc.WriteString("\n")
-			longDesc.WriteString(fmt.Sprintf(`- It requires an extra %d round(s) between attacks.`, iSpec.WaitRounds))
+			longDesc.WriteString(fmt.Sprintf(`- It is slow, and swings about once every %.1f rounds.`, 1/speed))
+
+		} else if speed > 1 {
+
+			longDesc.WriteString("\n")
+			longDesc.WriteString(fmt.Sprintf(`- It is quick, and swings about %.1f times a round.`, speed))
 
 		}
 
@@ -382,6 +387,15 @@ func (i *Item) GetDefense() int {
 	return itemInfo.DamageReduction
 }
 
+// Returns how much armor this item gives against a type of weapon
+func (i *Item) GetArmor(damageType ItemSubType) int {
+	if i.ItemId < 1 {
+		return 0
+	}
+	itemInfo := i.GetSpec()
+	return itemInfo.ArmorType.EffectiveArmor(itemInfo.Armor, damageType)
+}
+
 func (i *Item) Equals(b Item) bool {
 
 	if i.UniqueId() == b.UniqueId() {
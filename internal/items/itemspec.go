@@ -5,9 +5,11 @@ import (
 	"log/slog"
 	"math"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/volte6/gomud/internal/audit"
 	"github.com/volte6/gomud/internal/buffs"
 	"github.com/volte6/gomud/internal/configs"
 	"github.com/volte6/gomud/internal/fileloader"
@@ -149,6 +151,9 @@ const (
 	Acid        Element = "acid"
 	Life        Element = "life"
 	Death       Element = "death"
+	Poison      Element = "poison"
+	Holy        Element = "holy"
+	Shadow      Element = "shadow"
 
 	// Intensity of the attack
 	Prepare  Intensity = "prepare"
@@ -183,6 +188,7 @@ type Damage struct {
 	DiceCount   int    // how many dice to roll for this weapons damage
 	SideCount   int    // how many sides per dice roll
 	BonusDamage int    `yaml:"bonusdamage,omitempty"` // flat damage bonus, so for example 1d6+1
+	Penetration int    `yaml:"penetration,omitempty"` // % of the target's armor this damage ignores
 }
 
 type ItemMessage string
@@ -200,7 +206,10 @@ type ItemSpec struct {
 	BuffIds         []int       `yaml:"buffids,omitempty"`         // What buffs it can apply (if used)
 	WornBuffIds     []int       `yaml:"wornbuffids,omitempty"`     // BuffId's that are applied while worn, and expired when removed.
 	DamageReduction int         `yaml:"damagereduction,omitempty"` // % of damage it reduces when it blocks attacks
-	WaitRounds      int         `yaml:"waitrounds,omitempty"`      // How many extra rounds each combat requires
+	Armor           int         `yaml:"armor,omitempty"`           // Soaks up part of every hit while worn. See armor.go
+	ArmorType       ArmorType   `yaml:"armortype,omitempty"`       // What the armor is made of, which decides which weapons it holds up against
+	WaitRounds      int         `yaml:"waitrounds,omitempty"`      // Deprecated: converted to Speed when loaded
+	Speed           float64     `yaml:"speed,omitempty"`           // Swings per round for a weapon, when as fast as the target. 0.5 is every other round, 2.0 is twice a round.
 	Hands           WeaponHands `yaml:"hands"`                     // How many hands it takes to wield
 	Name            string
 	DisplayName     string `yaml:"displayname,omitempty"` // Name that is typically displayed to the user
@@ -221,6 +230,14 @@ func (i Element) String() string {
 	return string(i)
 }
 
+// Weapon speed, defaulting to one swing a round
+func (i ItemSpec) GetSpeed() float64 {
+	if i.Speed <= 0 {
+		return 1.0
+	}
+	return i.Speed
+}
+
 func (i ItemType) String() string {
 	return string(i)
 }
@@ -315,6 +332,12 @@ func (i *ItemSpec) AutoCalculateValue() {
 	val += i.Damage.BonusDamage * 25
 	// Armor based damage valuation
 	val += (i.DamageReduction * i.DamageReduction) * 17
+	val += (i.Armor * i.Armor) * 2
+	val += i.Damage.Penetration * 15
+	// Faster weapons swing more often
+	if i.Type == Weapon && i.Speed > 0 {
+		val = int(math.Ceil(float64(val) * i.Speed))
+	}
 
 	// Get the value of any buff it applies
 	for _, buffId := range i.BuffIds {
@@ -381,6 +404,14 @@ func (i *ItemSpec) Validate() error {
 		}
 	}
 
+	// Waiting extra rounds between attacks is the same as swinging at a fraction of the speed
+	if i.WaitRounds > 0 {
+		if i.Speed == 0 {
+			i.Speed = math.Round(100/float64(1+i.WaitRounds)) / 100
+		}
+		i.WaitRounds = 0
+	}
+
 	if i.NameSimple == `` {
 		i.NameSimple = i.Name
 	}
@@ -396,6 +427,24 @@ func (i *ItemSpec) Validate() error {
 		i.AutoCalculateValue()
 	}
 
+	if i.ArmorType != `` {
+		i.ArmorType = ArmorType(strings.ToLower(string(i.ArmorType)))
+		if !i.ArmorType.IsValid() {
+			return fmt.Errorf(`item %d has an unknown armor type: %s`, i.ItemId, i.ArmorType)
+		}
+	}
+
+	if i.Damage.Penetration > 100 {
+		i.Damage.Penetration = 100
+	}
+
+	if i.Element != `` {
+		i.Element = Element(strings.ToLower(string(i.Element)))
+		if !i.Element.IsValid() {
+			return fmt.Errorf(`item %d has an unknown element: %s`, i.ItemId, i.Element)
+		}
+	}
+
 	return nil
 }
 
@@ -442,6 +491,96 @@ func GetItemSpec(itemId int) *ItemSpec {
 	return nil
 }
 
+// Returns the range of ItemIds an item type is allocated from.
+// This also decides which folder the item is saved in.
+func ItemIdRange(iType ItemType) (minId int, maxId int) {
+	switch iType {
+	case Weapon:
+		return 10000, 19999
+	case Offhand, Head, Neck, Body, Belt, Gloves, Ring, Legs, Feet:
+		return 20000, 29999
+	case Potion, Food, Drink, Scroll:
+		return 30000, 39999
+	}
+	return 1, 9999
+}
+
+// Returns the next unused ItemId for a given item type
+func GetNextItemId(iType ItemType) int {
+
+	minId, maxId := ItemIdRange(iType)
+
+	nextItemId := minId
+	for itemId := range items {
+		if itemId >= nextItemId && itemId <= maxId {
+			nextItemId = itemId + 1
+		}
+	}
+
+	return nextItemId
+}
+
+// Returns a copy of an item spec as it was authored in its datafile, without any defaults filled in.
+// Used for editing a spec and saving it back.
+func LoadItemSpecFile(itemId int) (*ItemSpec, error) {
+
+	spec, ok := items[itemId]
+	if !ok {
+		return nil, fmt.Errorf("itemId %d does not exist", itemId)
+	}
+
+	return fileloader.LoadFlatFileRaw[*ItemSpec](util.FilePath(string(configs.GetConfig().FolderItemData), `/`, spec.Filepath()))
+}
+
+// Writes an item spec to disk and makes it live.
+// Items already in the world look up their spec by id, so they pick up the changes right away.
+// If the name or type changed, the old file and its script are moved.
+func SaveItemSpec(i *ItemSpec) error {
+
+	// Catch bad dice rolls etc. before anything is written
+	checkSpec := *i
+	if err := checkSpec.Validate(); err != nil {
+		return err
+	}
+
+	oldFilepath := ``
+	before := ``
+	if oldSpec, ok := items[i.ItemId]; ok {
+		oldFilepath = oldSpec.Filepath()
+		if diskSpec, err := LoadItemSpecFile(i.ItemId); err == nil {
+			before = audit.Snapshot(diskSpec)
+		}
+	}
+
+	itemFolder := string(configs.GetConfig().FolderItemData)
+
+	if err := os.MkdirAll(util.FilePath(itemFolder, `/`, i.ItemFolder()), 0755); err != nil {
+		return err
+	}
+
+	if err := fileloader.SaveFlatFile(itemFolder, i); err != nil {
+		return err
+	}
+
+	if oldFilepath != `` {
+		if err := fileloader.RelocateFlatFile(itemFolder, oldFilepath, i.Filepath()); err != nil {
+			return err
+		}
+	}
+
+	// Load it back the same way a reload would
+	liveSpec, err := fileloader.LoadFlatFile[*ItemSpec](util.FilePath(itemFolder, `/`, i.Filepath()))
+	if err != nil {
+		return err
+	}
+
+	items[liveSpec.ItemId] = liveSpec
+
+	audit.Capture(audit.KindItem, strconv.Itoa(i.ItemId), before, audit.Snapshot(i))
+
+	return nil
+}
+
 // file self loads due to init()
 func LoadDataFiles() {
 
@@ -28,6 +28,7 @@ type LeaderboardEntry struct {
 	Level          int
 	Gold           int
 	Kills          int
+	ArenaRating    int
 }
 
 type Leaderboard []LeaderboardEntry
@@ -98,7 +99,8 @@ func Get() map[string]Leaderboard {
 
 func considerUser(u *users.UserRecord) bool {
 
-	lSize := int(configs.GetConfig().LeaderboardSize)
+	c := configs.GetConfig()
+	lSize := int(c.LeaderboardSize)
 
 	allChars := []characters.Character{}
 	allChars = append(allChars, *u.Character)
@@ -112,6 +114,11 @@ func considerUser(u *users.UserRecord) bool {
 			"kills":      char.KD.TotalKills,
 		}
 
+		// Only ranked arena fighters from the current season make the ladder
+		if char.Arena.Season == int(c.ArenaSeason) && char.Arena.Played() > 0 {
+			lbTypes["arena"] = char.Arena.GetRating()
+		}
+
 		for lbName, lbValue := range lbTypes {
 
 			if leaderboardCache[lbName] == nil {
@@ -127,6 +134,8 @@ func considerUser(u *users.UserRecord) bool {
 					lbLowest = leaderboardCache[lbName][len(leaderboardCache[lbName])-1].Gold
 				} else if lbName == `kills` {
 					lbLowest = leaderboardCache[lbName][len(leaderboardCache[lbName])-1].Kills
+				} else if lbName == `arena` {
+					lbLowest = leaderboardCache[lbName][len(leaderboardCache[lbName])-1].ArenaRating
 				}
 
 			}
@@ -151,6 +160,10 @@ func considerUser(u *users.UserRecord) bool {
 					if entry.Kills >= lbValue {
 						continue
 					}
+				} else if lbName == `arena` {
+					if entry.ArenaRating >= lbValue {
+						continue
+					}
 				}
 
 				addAt = i
@@ -165,6 +178,7 @@ func considerUser(u *users.UserRecord) bool {
 				Level:          char.Level,
 				Gold:           char.Gold + char.Bank,
 				Kills:          char.KD.TotalKills,
+				ArenaRating:    lbTypes["arena"],
 			}
 
 			if addAt == -1 {
@@ -0,0 +1,40 @@
+package mobcommands
+
+import (
+	"fmt"
+
+	"github.com/volte6/gomud/internal/mobs"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/scripting"
+)
+
+func Close(rest string, mob *mobs.Mob, room *rooms.Room) (bool, error) {
+
+	exitName, _ := room.FindExitByName(rest)
+
+	exitInfo, ok := room.Exits[exitName]
+	if !ok || !exitInfo.HasDoor() {
+		return true, nil
+	}
+
+	if exitInfo.IsClosed() {
+		return true, nil
+	}
+
+	if handled, err := scripting.TryRoomDoorEvent(`onDoorClose`, exitName, room.RoomId, 0, mob.InstanceId); err == nil && handled {
+		return true, nil
+	}
+
+	doorName := exitInfo.Door.GetName()
+	otherRoom, otherExitName := room.GetDoorReturnExit(exitName)
+
+	room.SetExitOpen(exitName, false)
+
+	room.SendText(fmt.Sprintf(`<ansi fg="mobname">%s</ansi> closes the %s to the <ansi fg="exit">%s</ansi>.`, mob.Character.Name, doorName, exitName))
+
+	if otherRoom != nil && otherExitName != `` {
+		otherRoom.SendText(fmt.Sprintf(`The %s to the <ansi fg="exit">%s</ansi> closes.`, doorName, otherExitName))
+	}
+
+	return true, nil
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/volte6/gomud/internal/configs"
 	"github.com/volte6/gomud/internal/mobs"
 	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/scripting"
+	"github.com/volte6/gomud/internal/users"
 	"github.com/volte6/gomud/internal/util"
 )
 
@@ -71,16 +73,48 @@ func Go(rest string, mob *mobs.Mob, room *rooms.Room) (bool, error) {
 			return true, nil
 		}
 
+		if exitInfo.IsClosed() {
+
+			mob.Command(fmt.Sprintf(`emote tries to go the <ansi fg="exit">%s</ansi> exit, but the %s is closed.`, exitName, exitInfo.Door.GetName()))
+
+			return true, nil
+		}
+
 	}
 
 	if exitName != `` {
 
+		// Charmed mobs follow their master into any zone instance
+		if charmedUserId := mob.Character.GetCharmedUserId(); charmedUserId > 0 {
+			goRoomId = rooms.GetInstanceRoomId(charmedUserId, goRoomId)
+		}
+
+		// Mobs don't risk swimming or climbing, but they can't fly without wings or fit where they're too big.
+		// The "home" trip may not have an exit to check.
+		if _, ok := room.GetExitInfo(exitName); ok {
+			movement := room.GetExitMovement(exitName)
+			if stopped, err := scripting.TryRoomMoveEvent(exitName, string(movement), room.RoomId, 0, mob.InstanceId); err == nil && stopped {
+				return true, nil
+			}
+			if moveInfo, ok := rooms.GetMovement(movement); ok && moveInfo.IsBlocked(&mob.Character) {
+				return true, nil
+			}
+		}
+
 		// Load current room details
 		destRoom := rooms.LoadRoom(goRoomId)
 		if destRoom == nil {
 			return false, fmt.Errorf(`room %d not found`, goRoomId)
 		}
 
+		// Only mobs brought along by someone allowed in can enter a house
+		if destRoom.House != nil && destRoom.House.OwnerUserId > 0 {
+			charmedUser := users.GetByUserId(mob.Character.GetCharmedUserId())
+			if charmedUser == nil || !destRoom.House.CanEnter(charmedUser) {
+				return true, nil
+			}
+		}
+
 		// Grab the exit in the target room that leads to this room (if any)
 		enterFromExit := destRoom.FindExitTo(room.RoomId)
 
@@ -136,7 +170,11 @@ func Go(rest string, mob *mobs.Mob, room *rooms.Room) (bool, error) {
 				fmt.Sprintf(`<ansi fg="mobname">%s</ansi> enters from %s.`, mob.Character.Name, enterFromExit),
 			))
 
-		destRoom.SendTextToExits(`You hear someone moving around.`, true, room.GetPlayers(rooms.FindAll)...)
+		moveSound := rooms.Sound{Description: `someone moving around`, Volume: 1, IsQuiet: true}
+		if mob.Character.HasBuffFlag(buffs.Hidden) {
+			moveSound = moveSound.Sneaking()
+		}
+		destRoom.PropagateSound(moveSound, room.GetPlayers(rooms.FindAll)...)
 
 		return true, nil
 	}
@@ -32,6 +32,7 @@ var (
 		"cast":           {Cast, false},
 		"converse":       {Converse, false},
 		"callforhelp":    {CallForHelp, false},
+		"close":          {Close, false},
 		"despawn":        {Despawn, false},
 		"drink":          {Drink, false},
 		"drop":           {Drop, false},
@@ -47,6 +48,7 @@ var (
 		"lookforaid":     {LookForAid, false},
 		"lookfortrouble": {LookForTrouble, false},
 		"noop":           {Noop, true},
+		"open":           {Open, false},
 		"portal":         {Portal, false},
 		"put":            {Put, false},
 		"remove":         {Remove, false},
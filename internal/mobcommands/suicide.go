@@ -7,6 +7,7 @@ import (
 
 	"github.com/volte6/gomud/internal/buffs"
 	"github.com/volte6/gomud/internal/combat"
+	"github.com/volte6/gomud/internal/items"
 	"github.com/volte6/gomud/internal/mobs"
 	"github.com/volte6/gomud/internal/parties"
 	"github.com/volte6/gomud/internal/rooms"
@@ -265,14 +266,14 @@ func Suicide(rest string, mob *mobs.Mob, room *rooms.Room) (bool, error) {
 
 	}
 
+	// Whatever the mob drops stays with its corpse
+	corpseItems := []items.Item{}
+	corpseGold := 0
+
 	if !mob.Character.HasBuffFlag(buffs.PermaGear) {
 
 		// Check for any dropped loot...
-		for _, item := range mob.Character.Items {
-			msg := fmt.Sprintf(`<ansi fg="item">%s</ansi> drops to the ground.`, item.DisplayName())
-			room.SendText(msg)
-			room.AddItem(item, false)
-		}
+		corpseItems = append(corpseItems, mob.Character.Items...)
 
 		allWornItems := mob.Character.Equipment.GetAllItems()
 
@@ -286,17 +287,19 @@ func Suicide(rest string, mob *mobs.Mob, room *rooms.Room) (bool, error) {
 				continue
 			}
 
-			msg := fmt.Sprintf(`<ansi fg="item">%s</ansi> drops to the ground.`, item.DisplayName())
-			room.SendText(msg)
-			room.AddItem(item, false)
+			corpseItems = append(corpseItems, item)
 		}
 
-		if mob.Character.Gold > 0 {
-			msg := fmt.Sprintf(`<ansi fg="yellow-bold">%d gold</ansi> drops to the ground.`, mob.Character.Gold)
-			room.SendText(msg)
-			room.Gold += mob.Character.Gold
-		}
+		corpseGold = mob.Character.Gold
+	}
 
+	// Nothing worth looting, nothing left behind
+	if len(corpseItems) > 0 || corpseGold > 0 {
+		room.SpawnCorpse(rooms.Corpse{
+			MobId: int(mob.MobId),
+			Name:  mob.Character.Name,
+			Level: mob.Character.Level,
+		}, corpseGold, corpseItems)
 	}
 
 	// Destroy any record of this mob.
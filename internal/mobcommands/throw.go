@@ -47,7 +47,7 @@ func Throw(rest string, mob *mobs.Mob, room *rooms.Room) (bool, error) {
 	if exitName != `` {
 
 		exitInfo, _ := room.GetExitInfo(exitName)
-		if exitInfo.Lock.IsLocked() {
+		if exitInfo.Lock.IsLocked() || exitInfo.IsClosed() {
 			return true, nil
 		}
 
@@ -5,9 +5,12 @@ import (
 	"log/slog"
 	"math"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/volte6/gomud/internal/audit"
 	"github.com/volte6/gomud/internal/buffs"
 	"github.com/volte6/gomud/internal/characters"
 	"github.com/volte6/gomud/internal/configs"
@@ -76,6 +79,7 @@ type Mob struct {
 	ScriptTag       string   `yaml:"scripttag"`                 // Script for this mob: mobs/frostfang/scripts/{mobId}-{mobname}-{ScriptTag}.js
 	QuestFlags      []string `yaml:"questflags,omitempty,flow"` // What quest flags are set on this mob?
 	BuffIds         []int    `yaml:"buffids,omitempty"`         // Buff Id's this mob always has upon spawn
+	Realtor         bool     `yaml:"realtor,omitempty"`         // Whether players can buy and sell houses through this mob
 	tempDataStore   map[string]any
 }
 
@@ -541,6 +545,100 @@ func (r *Mob) Save() error {
 	return nil
 }
 
+// Returns the next unused MobId
+func GetNextMobId() MobId {
+	nextMobId := 1
+	for mobId := range mobs {
+		if mobId >= nextMobId {
+			nextMobId = mobId + 1
+		}
+	}
+	return MobId(nextMobId)
+}
+
+// Returns a copy of a mob spec as it was authored in its datafile, without any defaults filled in.
+// Used for editing a spec and saving it back.
+func LoadMobSpecFile(mobId MobId) (*Mob, error) {
+
+	spec, ok := mobs[int(mobId)]
+	if !ok {
+		return nil, fmt.Errorf("mobId %d does not exist", mobId)
+	}
+
+	return fileloader.LoadFlatFileRaw[*Mob](util.FilePath(mobDataFilesFolderPath, `/`, spec.Filepath()))
+}
+
+// Writes a mob spec to disk and makes it live for any future spawns.
+// Mobs already in the world keep the spec they spawned with.
+// If the name or zone changed, the old file and its scripts are moved.
+func SaveMobSpec(m *Mob) (retErr error) {
+
+	// Catch problems before anything is written
+	checkMob := *m
+	if err := checkMob.Validate(); err != nil {
+		return err
+	}
+
+	oldFilepath := ``
+	before := ``
+	if oldMob, ok := mobs[m.Id()]; ok {
+		oldFilepath = oldMob.Filepath()
+		if diskMob, err := LoadMobSpecFile(m.MobId); err == nil {
+			before = audit.Snapshot(diskMob)
+		}
+	}
+
+	// The file is named after whatever is in the name cache, so it has to be updated first.
+	// Put it back if the save doesn't go through.
+	oldCacheName, hadCacheName := mobNameCache[m.MobId]
+	mobNameCache[m.MobId] = m.Character.Name
+	defer func() {
+		if retErr == nil {
+			return
+		}
+		if hadCacheName {
+			mobNameCache[m.MobId] = oldCacheName
+		} else {
+			delete(mobNameCache, m.MobId)
+		}
+	}()
+
+	if err := os.MkdirAll(util.FilePath(mobDataFilesFolderPath, `/`, ZoneNameSanitize(m.Zone), `/scripts`), 0755); err != nil {
+		return err
+	}
+
+	if err := fileloader.SaveFlatFile(mobDataFilesFolderPath, m); err != nil {
+		return err
+	}
+
+	if oldFilepath != `` {
+		if err := fileloader.RelocateFlatFile(mobDataFilesFolderPath, oldFilepath, m.Filepath(), `scripts`); err != nil {
+			return err
+		}
+	}
+
+	// Load it back the same way a reload would
+	liveMob, err := fileloader.LoadFlatFile[*Mob](util.FilePath(mobDataFilesFolderPath, `/`, m.Filepath()))
+	if err != nil {
+		return err
+	}
+
+	liveMob.Character.CacheDescription()
+	mobs[liveMob.Id()] = liveMob
+
+	// Rebuild the names so a renamed mob doesn't leave its old name behind
+	allMobNames = allMobNames[:0]
+	for _, mob := range mobs {
+		if !slices.Contains(allMobNames, mob.Character.Name) {
+			allMobNames = append(allMobNames, mob.Character.Name)
+		}
+	}
+
+	audit.Capture(audit.KindMob, strconv.Itoa(int(m.MobId)), before, audit.Snapshot(m))
+
+	return nil
+}
+
 func (m *Mob) GetScript() string {
 
 	scriptPath := m.GetScriptPath()
@@ -0,0 +1,32 @@
+package mobs
+
+import (
+	"github.com/volte6/gomud/internal/configs"
+)
+
+// Healing someone draws the attention of every mob in the room that is fighting them.
+func AddHealingThreat(roomId int, healerUserId int, healedUserId int, amountHealed int) {
+
+	threat := amountHealed * int(configs.GetConfig().ThreatHealing) / 100
+	if threat < 1 {
+		return
+	}
+
+	for _, mob := range mobInstances {
+
+		if mob.Character.RoomId != roomId || mob.Character.Aggro == nil {
+			continue
+		}
+
+		if mob.Character.Aggro.UserId == healedUserId || mob.Character.GetThreat(healedUserId) > 0 {
+			mob.Character.AddThreat(healerUserId, threat)
+		}
+	}
+}
+
+// Every mob forgets a user, such as when they die.
+func ClearThreat(userId int) {
+	for _, mob := range mobInstances {
+		mob.Character.ClearThreat(userId)
+	}
+}
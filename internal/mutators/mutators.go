@@ -41,6 +41,7 @@ type Mutator struct {
 	MutatorId      string // Short text that will uniquely identify this modifier ("dusty")
 	SpawnedRound   uint64 `yaml:"-"` // Tracks when this mutator was created (useful for decay)
 	DespawnedRound uint64 `yaml:"-"` // Track when it decayed to nothing.
+	Temporary      bool   `yaml:"-"` // Added by something like a calendar event, and never saved
 }
 
 type TextModifier struct {
@@ -70,6 +71,7 @@ type MutatorSpec struct {
 	LightMod      int                      `yaml:"lightmod,omitempty"`      //  -2 to 2 (change). If result is 0 = none. 1 = can see this room. 2 = can see this room and all exits
 	Exits         map[string]exit.RoomExit `yaml:"exits,omitempty"`         // name/roomId pairs of exits only available while mutator is live.
 	Pvp           PvpOverride              `yaml:"pvp,omitempty"`           // optionally force room pvp attributes.
+	Movement      string                   `yaml:"movement,omitempty"`      // optionally change how the room is entered (walk, swim, climb, fly, squeeze). A frozen lake can be walked on.
 }
 
 func GetAllMutatorSpecs() []MutatorSpec {
@@ -149,6 +151,46 @@ func (ml *MutatorList) Remove(mutName string) bool {
 	return false
 }
 
+// Adds a mutator that won't be saved, unless the list already has one by that name.
+// Returns false if it wasn't added, so the caller knows not to delete it later.
+func (ml *MutatorList) AddTemporary(mutName string) bool {
+
+	if _, ok := allMutators[mutName]; !ok {
+		return false
+	}
+
+	for _, mut := range *ml {
+		if mut.MutatorId == mutName {
+			return false
+		}
+	}
+
+	*ml = append(*ml, Mutator{MutatorId: mutName, Temporary: true})
+	return true
+}
+
+// A copy of the list without any temporary mutators, for saving
+func (ml MutatorList) Permanent() MutatorList {
+	ret := MutatorList{}
+	for _, mut := range ml {
+		if !mut.Temporary {
+			ret = append(ret, mut)
+		}
+	}
+	return ret
+}
+
+// Takes the mutator out of the list entirely, rather than letting it decay/respawn
+func (ml *MutatorList) Delete(mutName string) bool {
+	for i, mut := range *ml {
+		if mut.MutatorId == mutName {
+			*ml = append((*ml)[:i], (*ml)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 func (ml *MutatorList) Update(roundNow uint64) {
 
 	if ml == nil {
@@ -0,0 +1,59 @@
+package mutators
+
+import (
+	"testing"
+)
+
+func setTestSpecs(t *testing.T, specs ...*MutatorSpec) {
+	t.Helper()
+
+	oldMutators := allMutators
+	t.Cleanup(func() { allMutators = oldMutators })
+
+	allMutators = map[string]*MutatorSpec{}
+	for _, spec := range specs {
+		allMutators[spec.MutatorId] = spec
+	}
+}
+
+func TestAddTemporary(t *testing.T) {
+
+	setTestSpecs(t,
+		&MutatorSpec{MutatorId: `dusty`},
+		&MutatorSpec{MutatorId: `festive`},
+	)
+
+	ml := MutatorList{{MutatorId: `dusty`}}
+
+	// Something already there isn't taken over, so it won't be removed later
+	if ml.AddTemporary(`dusty`) {
+		t.Errorf("AddTemporary(dusty) = true; want false when it's already in the list")
+	}
+
+	if ml.AddTemporary(`nonexistent`) {
+		t.Errorf("AddTemporary(nonexistent) = true; want false for an unknown mutator")
+	}
+
+	if !ml.AddTemporary(`festive`) {
+		t.Fatalf("AddTemporary(festive) = false; want true")
+	}
+
+	if !ml.Has(`festive`) {
+		t.Errorf("Has(festive) = false after AddTemporary()")
+	}
+
+	saved := ml.Permanent()
+	if len(saved) != 1 || saved[0].MutatorId != `dusty` {
+		t.Errorf("Permanent() = %+v; want only dusty", saved)
+	}
+
+	// Permanent() is a copy, so the live list is unchanged
+	if len(ml) != 2 {
+		t.Errorf("Permanent() changed the list: %+v", ml)
+	}
+
+	ml.Delete(`festive`)
+	if len(ml) != 1 || ml[0].MutatorId != `dusty` {
+		t.Errorf("Delete(festive) left %+v; want only dusty", ml)
+	}
+}
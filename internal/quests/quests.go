@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/volte6/gomud/internal/audit"
 	"github.com/volte6/gomud/internal/fileloader"
 	"github.com/volte6/gomud/internal/util"
 )
@@ -179,6 +180,65 @@ func GetAllQuests() []Quest {
 	return ret
 }
 
+// Returns the next unused QuestId
+func GetNextQuestId() int {
+	nextQuestId := 1
+	for questId := range quests {
+		if questId >= nextQuestId {
+			nextQuestId = questId + 1
+		}
+	}
+	return nextQuestId
+}
+
+// Returns a copy of a quest as it was authored in its datafile.
+// Used for editing a quest and saving it back.
+func LoadQuestFile(questId int) (*Quest, error) {
+
+	questInfo, ok := quests[questId]
+	if !ok {
+		return nil, fmt.Errorf("questId %d does not exist", questId)
+	}
+
+	return fileloader.LoadFlatFileRaw[*Quest](util.FilePath(questDataFilesFolderPath, `/`, questInfo.Filepath()))
+}
+
+// Writes a quest to disk and makes it live.
+// If the name changed, the old file is removed.
+func SaveQuest(q *Quest) error {
+
+	oldFilepath := ``
+	before := ``
+	if oldQuest, ok := quests[q.QuestId]; ok {
+		oldFilepath = oldQuest.Filepath()
+		if diskQuest, err := LoadQuestFile(q.QuestId); err == nil {
+			before = audit.Snapshot(diskQuest)
+		}
+	}
+
+	if err := fileloader.SaveFlatFile(questDataFilesFolderPath, q); err != nil {
+		return err
+	}
+
+	if oldFilepath != `` {
+		if err := fileloader.RelocateFlatFile(questDataFilesFolderPath, oldFilepath, q.Filepath()); err != nil {
+			return err
+		}
+	}
+
+	// Load it back the same way a reload would
+	liveQuest, err := fileloader.LoadFlatFile[*Quest](util.FilePath(questDataFilesFolderPath, `/`, q.Filepath()))
+	if err != nil {
+		return err
+	}
+
+	quests[liveQuest.QuestId] = liveQuest
+
+	audit.Capture(audit.KindQuest, strconv.Itoa(q.QuestId), before, audit.Snapshot(q))
+
+	return nil
+}
+
 // file self loads due to init()
 func LoadDataFiles() {
 
@@ -10,6 +10,7 @@ import (
 
 	"github.com/volte6/gomud/internal/fileloader"
 	"github.com/volte6/gomud/internal/items"
+	"github.com/volte6/gomud/internal/statmods"
 	"github.com/volte6/gomud/internal/stats"
 	"github.com/volte6/gomud/internal/util"
 	"gopkg.in/yaml.v2"
@@ -41,10 +42,13 @@ type Race struct {
 	Tameable         bool
 	Damage           items.Damage
 	Selectable       bool
-	AngryCommands    []string         // randomly chosen to queue when they are angry/entering combat.
-	KnowsFirstAid    bool             // Whether they can apply aid to other players.
-	Stats            stats.Statistics // Base stats for this race.
-	DisabledSlots    []string         `yaml:"disabledslots,omitempty"`
+	AngryCommands    []string          // randomly chosen to queue when they are angry/entering combat.
+	KnowsFirstAid    bool              // Whether they can apply aid to other players.
+	Stats            stats.Statistics  // Base stats for this race.
+	DisabledSlots    []string          `yaml:"disabledslots,omitempty"`
+	Flying           bool              `yaml:"flying,omitempty"`         // Can fly, so doesn't need to swim or climb
+	WaterBreathing   bool              `yaml:"waterbreathing,omitempty"` // Can't drown
+	StatMods         statmods.StatMods `yaml:"statmods,omitempty"`       // Always applied to members of this race, such as resistances
 }
 
 func GetRaces() []Race {
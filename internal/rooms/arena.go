@@ -0,0 +1,785 @@
+package rooms
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/volte6/gomud/internal/configs"
+	"github.com/volte6/gomud/internal/events"
+	"github.com/volte6/gomud/internal/exit"
+	"github.com/volte6/gomud/internal/gametime"
+	"github.com/volte6/gomud/internal/parties"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+//
+// Ranked arena.
+// Players queue alone or as a party, and are matched against others with a similar rating.
+// Each match is fought in a private copy of the arena room, where nobody can die.
+//
+
+const (
+	ArenaSolo = `1v1`
+	ArenaTeam = `team`
+
+	arenaCountdownRounds    = 3  // Rounds between entering the arena and the fight starting
+	arenaRangeGrowthSeconds = 30 // How long someone waits in the queue before their match range grows
+	arenaRangeGrowth        = 50 // How much the match range grows each time
+)
+
+var (
+	ErrArenaClosed       = errors.New(`the arena is closed`)
+	ErrArenaPvpDisabled  = errors.New(`the arena is closed while PVP is disabled`)
+	ErrArenaBadMode      = errors.New(`unknown arena mode`)
+	ErrArenaQueued       = errors.New(`already in the arena queue`)
+	ErrArenaInMatch      = errors.New(`already in an arena match`)
+	ErrArenaBusy         = errors.New(`can't join the arena while fighting or hurt`)
+	ErrArenaLevel        = errors.New(`not a high enough level for the arena`)
+	ErrArenaNotLeader    = errors.New(`only a party leader can queue their party`)
+	ErrArenaPartyTooFew  = errors.New(`need at least 2 party members for a team match`)
+	ErrArenaNoMatch      = errors.New(`arena match not found`)
+	ErrArenaNotSpectator = errors.New(`fighters can't watch their own match`)
+
+	arenaQueue       = []*arenaQueueEntry{}
+	arenaMatches     = map[int]*ArenaMatch{}
+	nextArenaMatchId = 1
+)
+
+// A solo player or a party waiting for a match
+type arenaQueueEntry struct {
+	UserIds     []int
+	Mode        string
+	Rating      int
+	QueuedRound uint64
+}
+
+// How far apart ratings can be to match with this entry. Grows the longer they wait.
+func (e *arenaQueueEntry) matchRange(roundNow uint64) int {
+	c := configs.GetConfig()
+	waitedSeconds := c.RoundsToSeconds(int(roundNow - e.QueuedRound))
+	return int(c.ArenaMatchRange) + (waitedSeconds/arenaRangeGrowthSeconds)*arenaRangeGrowth
+}
+
+type ArenaMatch struct {
+	MatchId       int
+	Mode          string
+	RoomId        int
+	Teams         [2][]int
+	Ratings       [2]int           // Average rating of each team when the match started
+	ReturnRoomIds map[int]int      // userId => where they go back to afterwards
+	Defeated      map[int]struct{} // Fighters who are out of the match
+	Spectators    map[int]struct{} // Users watching from elsewhere
+	StartRound    uint64           // When fighting is allowed
+	EndRound      uint64           // When the match is called a draw
+}
+
+// Returns which team (0 or 1) a user fights for, or -1
+func (m *ArenaMatch) GetTeam(userId int) int {
+	for team, userIds := range m.Teams {
+		for _, uId := range userIds {
+			if uId == userId {
+				return team
+			}
+		}
+	}
+	return -1
+}
+
+func (m *ArenaMatch) IsDefeated(userId int) bool {
+	_, ok := m.Defeated[userId]
+	return ok
+}
+
+func (m *ArenaMatch) IsStarted() bool {
+	return util.GetRoundCount() >= m.StartRound
+}
+
+// Returns the character names of a team, such as "Bob and Alice"
+func (m *ArenaMatch) GetTeamNames(team int) string {
+	names := []string{}
+	for _, userId := range m.Teams[team] {
+		if u := users.GetByUserId(userId); u != nil {
+			names = append(names, fmt.Sprintf(`<ansi fg="username">%s</ansi>`, u.Character.Name))
+		}
+	}
+	if len(names) == 0 {
+		return `nobody`
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], `, `) + ` and ` + names[len(names)-1]
+}
+
+func (m *ArenaMatch) GetSpectators() []int {
+	userIds := []int{}
+	for userId := range m.Spectators {
+		userIds = append(userIds, userId)
+	}
+	sort.Ints(userIds)
+	return userIds
+}
+
+// Returns why the arena can't be used right now, or nil
+func ArenaAvailable() error {
+
+	c := configs.GetConfig()
+
+	if c.PVP == configs.PVPDisabled {
+		return ErrArenaPvpDisabled
+	}
+
+	if c.ArenaRoomId == 0 || LoadRoom(int(c.ArenaRoomId)) == nil {
+		return ErrArenaClosed
+	}
+
+	return nil
+}
+
+// Adds a user (or their whole party, for team matches) to the arena queue
+func QueueForArena(user *users.UserRecord, mode string) error {
+
+	if err := ArenaAvailable(); err != nil {
+		return err
+	}
+
+	if mode != ArenaSolo && mode != ArenaTeam {
+		return ErrArenaBadMode
+	}
+
+	userIds := []int{user.UserId}
+
+	if mode == ArenaTeam {
+		party := parties.Get(user.UserId)
+		if party == nil || !party.IsMember(user.UserId) || !party.IsLeader(user.UserId) {
+			return ErrArenaNotLeader
+		}
+		userIds = party.GetMembers()
+		if len(userIds) < 2 {
+			return ErrArenaPartyTooFew
+		}
+	}
+
+	season := int(configs.GetConfig().ArenaSeason)
+	ratingTotal := 0
+
+	for _, userId := range userIds {
+
+		u := users.GetByUserId(userId)
+		if u == nil {
+			return fmt.Errorf(`%w: a party member is offline`, ErrArenaBusy)
+		}
+
+		if err := checkArenaEligible(u); err != nil {
+			if userId != user.UserId {
+				return fmt.Errorf(`%s: %w`, u.Character.Name, err)
+			}
+			return err
+		}
+
+		u.Character.Arena.SetSeason(season)
+		ratingTotal += u.Character.Arena.GetRating()
+	}
+
+	arenaQueue = append(arenaQueue, &arenaQueueEntry{
+		UserIds:     userIds,
+		Mode:        mode,
+		Rating:      ratingTotal / len(userIds),
+		QueuedRound: util.GetRoundCount(),
+	})
+
+	return nil
+}
+
+func checkArenaEligible(u *users.UserRecord) error {
+
+	if _, _, ok := GetArenaQueueStatus(u.UserId); ok {
+		return ErrArenaQueued
+	}
+
+	if GetArenaMatchForUser(u.UserId) != nil {
+		return ErrArenaInMatch
+	}
+
+	if u.Character.Level < int(configs.GetConfig().PVPMinimumLevel) {
+		return ErrArenaLevel
+	}
+
+	if u.Character.Aggro != nil || u.Character.Health < 1 {
+		return ErrArenaBusy
+	}
+
+	return nil
+}
+
+// Removes a user from the queue, along with anyone they queued with
+func LeaveArenaQueue(userId int) bool {
+	for i, entry := range arenaQueue {
+		for _, uId := range entry.UserIds {
+			if uId == userId {
+				arenaQueue = append(arenaQueue[:i], arenaQueue[i+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns the mode and how many rounds a user has been waiting, if they are queued
+func GetArenaQueueStatus(userId int) (string, uint64, bool) {
+	roundNow := util.GetRoundCount()
+	for _, entry := range arenaQueue {
+		for _, uId := range entry.UserIds {
+			if uId == userId {
+				return entry.Mode, roundNow - entry.QueuedRound, true
+			}
+		}
+	}
+	return ``, 0, false
+}
+
+func GetArenaMatch(matchId int) *ArenaMatch {
+	return arenaMatches[matchId]
+}
+
+// Returns the match a user is fighting in, if any
+func GetArenaMatchForUser(userId int) *ArenaMatch {
+	for _, m := range arenaMatches {
+		if m.GetTeam(userId) >= 0 {
+			return m
+		}
+	}
+	return nil
+}
+
+// Puts a users health and mana back the way they were before their arena match.
+// Anyone offline when their match ended gets this the next time they enter the world.
+func RestoreArenaVitals(u *users.UserRecord) {
+
+	health, ok := u.Character.GetMiscData(`ArenaHealth`).(int)
+	if !ok {
+		return
+	}
+	mana, _ := u.Character.GetMiscData(`ArenaMana`).(int)
+
+	u.Character.Health = min(max(health, 1), u.Character.HealthMax.Value)
+	u.Character.Mana = min(mana, u.Character.ManaMax.Value)
+
+	u.Character.SetMiscData(`ArenaHealth`, nil)
+	u.Character.SetMiscData(`ArenaMana`, nil)
+}
+
+func GetAllArenaMatches() []*ArenaMatch {
+	ret := []*ArenaMatch{}
+	for _, m := range arenaMatches {
+		ret = append(ret, m)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].MatchId < ret[j].MatchId })
+	return ret
+}
+
+// Lets a user follow everything said and done in an arena from wherever they are
+func WatchArenaMatch(userId int, matchId int) error {
+
+	m := GetArenaMatch(matchId)
+	if m == nil {
+		return ErrArenaNoMatch
+	}
+
+	if m.GetTeam(userId) >= 0 {
+		return ErrArenaNotSpectator
+	}
+
+	StopWatchingArena(userId)
+	m.Spectators[userId] = struct{}{}
+
+	return nil
+}
+
+func StopWatchingArena(userId int) bool {
+	for _, m := range arenaMatches {
+		if _, ok := m.Spectators[userId]; ok {
+			delete(m.Spectators, userId)
+			return true
+		}
+	}
+	return false
+}
+
+// Returns who is watching an arena room from outside of it
+func GetArenaSpectators(roomId int) []int {
+	if r, ok := roomManager.rooms[roomId]; ok && r.arenaMatchId > 0 {
+		if m := GetArenaMatch(r.arenaMatchId); m != nil {
+			return m.GetSpectators()
+		}
+	}
+	return nil
+}
+
+// Gives up an arena match
+func ForfeitArenaMatch(userId int) bool {
+	m := GetArenaMatchForUser(userId)
+	if m == nil || m.IsDefeated(userId) {
+		return false
+	}
+	m.defeat(userId, `has forfeited`)
+	return true
+}
+
+// Called when a user would die. If they were fighting in the arena they are just
+// knocked out of the match instead, and true is returned.
+func ArenaDefeat(userId int) bool {
+	m := GetArenaMatchForUser(userId)
+	if m == nil {
+		return false
+	}
+
+	if u := users.GetByUserId(userId); u == nil || u.Character.RoomId != m.RoomId {
+		return false
+	}
+
+	if !m.IsDefeated(userId) {
+		m.defeat(userId, `has been defeated`)
+	}
+
+	return true
+}
+
+// Arena fights are only between opponents, once the match has started
+func canArenaPvp(matchId int, attUserId int, defUserId int) error {
+
+	m := GetArenaMatch(matchId)
+	if m == nil {
+		return ErrArenaNoMatch
+	}
+
+	attTeam, defTeam := m.GetTeam(attUserId), m.GetTeam(defUserId)
+
+	if attTeam < 0 || defTeam < 0 {
+		return errors.New(`Only arena fighters can fight here.`)
+	}
+
+	if attTeam == defTeam {
+		return errors.New(`They are on your team!`)
+	}
+
+	if !m.IsStarted() {
+		return errors.New(`The match hasn't started yet.`)
+	}
+
+	if m.IsDefeated(attUserId) || m.IsDefeated(defUserId) {
+		return errors.New(`They are already out of the match.`)
+	}
+
+	return nil
+}
+
+// Handles matchmaking, and the progress of any matches going on
+func arenaMaintenance(roundNow uint64) {
+
+	if len(arenaQueue) > 0 {
+		if err := ArenaAvailable(); err != nil {
+			for _, entry := range arenaQueue {
+				for _, userId := range entry.UserIds {
+					if u := users.GetByUserId(userId); u != nil {
+						u.SendText(fmt.Sprintf(`<ansi fg="yellow">You have been removed from the arena queue: %s.</ansi>`, err))
+					}
+				}
+			}
+			arenaQueue = arenaQueue[:0]
+		} else {
+			arenaMatchmake(roundNow)
+		}
+	}
+
+	for _, m := range arenaMatches {
+		m.update(roundNow)
+	}
+}
+
+func arenaMatchmake(roundNow uint64) {
+
+	// Anyone who has gone offline drops out of the queue, along with whoever they queued with
+	keep := arenaQueue[:0]
+	for _, entry := range arenaQueue {
+		online := true
+		for _, userId := range entry.UserIds {
+			if users.GetByUserId(userId) == nil {
+				online = false
+				break
+			}
+		}
+		if online {
+			keep = append(keep, entry)
+		}
+	}
+	arenaQueue = keep
+
+	// Longest waiting first
+	sort.SliceStable(arenaQueue, func(i, j int) bool { return arenaQueue[i].QueuedRound < arenaQueue[j].QueuedRound })
+
+	matched := map[*arenaQueueEntry]struct{}{}
+
+	for i, a := range arenaQueue {
+
+		if _, ok := matched[a]; ok {
+			continue
+		}
+
+		if !arenaEntryReady(a) {
+			continue
+		}
+
+		var best *arenaQueueEntry
+		bestDiff := 0
+
+		for _, b := range arenaQueue[i+1:] {
+
+			if _, ok := matched[b]; ok {
+				continue
+			}
+
+			if b.Mode != a.Mode || len(b.UserIds) != len(a.UserIds) || !arenaEntryReady(b) {
+				continue
+			}
+
+			diff := a.Rating - b.Rating
+			if diff < 0 {
+				diff = -diff
+			}
+
+			if diff > max(a.matchRange(roundNow), b.matchRange(roundNow)) {
+				continue
+			}
+
+			if best == nil || diff < bestDiff {
+				best = b
+				bestDiff = diff
+			}
+		}
+
+		if best == nil {
+			continue
+		}
+
+		if _, err := startArenaMatch(a.Mode, a.UserIds, best.UserIds); err != nil {
+			slog.Error("arenaMatchmake()", "error", err)
+			continue
+		}
+
+		matched[a] = struct{}{}
+		matched[best] = struct{}{}
+	}
+
+	keep = arenaQueue[:0]
+	for _, entry := range arenaQueue {
+		if _, ok := matched[entry]; !ok {
+			keep = append(keep, entry)
+		}
+	}
+	arenaQueue = keep
+}
+
+// Whether everyone in a queue entry is free to be pulled into a match right now
+func arenaEntryReady(entry *arenaQueueEntry) bool {
+	for _, userId := range entry.UserIds {
+		u := users.GetByUserId(userId)
+		if u == nil || u.Character.Aggro != nil || u.Character.Health < 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// Builds a private arena and moves both teams into it
+func startArenaMatch(mode string, teamA []int, teamB []int) (*ArenaMatch, error) {
+
+	c := configs.GetConfig()
+
+	srcRoom := LoadRoom(int(c.ArenaRoomId))
+	if srcRoom == nil {
+		return nil, ErrArenaClosed
+	}
+
+	arenaRoom, err := copyRoom(srcRoom, nextInstanceRoomId)
+	if err != nil {
+		return nil, err
+	}
+	nextInstanceRoomId++
+
+	// Nothing but the fighters. The only way out is to win, lose or forfeit.
+	arenaRoom.Exits = map[string]exit.RoomExit{}
+	arenaRoom.SpawnInfo = nil
+	arenaRoom.Items = nil
+	arenaRoom.Stash = nil
+	arenaRoom.Gold = 0
+	arenaRoom.Containers = nil
+	arenaRoom.Signs = nil
+	arenaRoom.House = nil
+	arenaRoom.Mutators = nil
+	arenaRoom.Pvp = true
+	arenaRoom.arenaMatchId = nextArenaMatchId
+
+	if err = arenaRoom.Validate(); err != nil {
+		return nil, err
+	}
+
+	roundNow := util.GetRoundCount()
+
+	m := &ArenaMatch{
+		MatchId:       nextArenaMatchId,
+		Mode:          mode,
+		RoomId:        arenaRoom.RoomId,
+		Teams:         [2][]int{teamA, teamB},
+		ReturnRoomIds: map[int]int{},
+		Defeated:      map[int]struct{}{},
+		Spectators:    map[int]struct{}{},
+		StartRound:    roundNow + arenaCountdownRounds,
+	}
+	m.EndRound = gametime.GetDate(m.StartRound).AddPeriod(string(c.ArenaMatchLength))
+	nextArenaMatchId++
+
+	addInstanceRoomToMemory(arenaRoom)
+	arenaMatches[m.MatchId] = m
+
+	season := int(c.ArenaSeason)
+
+	for team, userIds := range m.Teams {
+
+		ratingTotal := 0
+
+		for _, userId := range userIds {
+
+			u := users.GetByUserId(userId)
+			if u == nil {
+				continue
+			}
+
+			u.Character.Arena.SetSeason(season)
+			ratingTotal += u.Character.Arena.GetRating()
+
+			m.ReturnRoomIds[userId] = u.Character.RoomId
+			// Kept on the character so it survives them logging out before the match ends
+			u.Character.SetMiscData(`ArenaHealth`, u.Character.Health)
+			u.Character.SetMiscData(`ArenaMana`, u.Character.Mana)
+
+			StopWatchingArena(userId)
+
+			// Everyone starts fresh
+			u.Character.EndAggro()
+			u.Character.Health = u.Character.HealthMax.Value
+			u.Character.Mana = u.Character.ManaMax.Value
+
+			if oldRoom := LoadRoom(u.Character.RoomId); oldRoom != nil {
+				oldRoom.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> is summoned away to the arena.`, u.Character.Name), userId)
+			}
+
+			if err := MoveToRoom(userId, m.RoomId); err != nil {
+				slog.Error("startArenaMatch()", "userId", userId, "error", err)
+				continue
+			}
+
+			u.SendText(`<ansi fg="yellow">Your arena match has been found! You are summoned to the arena.</ansi>`)
+			u.Command(`look`)
+		}
+
+		if len(userIds) > 0 {
+			m.Ratings[team] = ratingTotal / len(userIds)
+		}
+	}
+
+	arenaRoom.SendText(fmt.Sprintf(`<ansi fg="yellow">The crowd roars as %s face off against %s! The fight begins in %d rounds...</ansi>`, m.GetTeamNames(0), m.GetTeamNames(1), arenaCountdownRounds))
+
+	slog.Info("startArenaMatch()", "matchId", m.MatchId, "mode", mode, "roomId", m.RoomId, "teamA", teamA, "teamB", teamB)
+
+	return m, nil
+}
+
+func (m *ArenaMatch) update(roundNow uint64) {
+
+	arenaRoom := LoadRoom(m.RoomId)
+	if arenaRoom == nil {
+		m.finish(-1)
+		return
+	}
+
+	if roundNow < m.StartRound {
+		arenaRoom.SendText(fmt.Sprintf(`<ansi fg="yellow">%d...</ansi>`, m.StartRound-roundNow))
+	} else if roundNow == m.StartRound {
+		arenaRoom.SendText(`<ansi fg="red-bold">FIGHT!</ansi>`)
+	}
+
+	for _, userIds := range m.Teams {
+		for _, userId := range userIds {
+
+			if m.IsDefeated(userId) {
+				continue
+			}
+
+			u := users.GetByUserId(userId)
+			if u == nil {
+				m.defeat(userId, `has left the arena`)
+			} else if u.Character.RoomId != m.RoomId {
+				m.defeat(userId, `has fled the arena`)
+			} else if u.Character.Health < 1 {
+				m.defeat(userId, `has been defeated`)
+			}
+		}
+	}
+
+	standing := [2]int{}
+	for team, userIds := range m.Teams {
+		for _, userId := range userIds {
+			if !m.IsDefeated(userId) {
+				standing[team]++
+			}
+		}
+	}
+
+	if standing[0] == 0 && standing[1] == 0 {
+		m.finish(-1)
+	} else if standing[0] == 0 {
+		m.finish(1)
+	} else if standing[1] == 0 {
+		m.finish(0)
+	} else if roundNow >= m.EndRound {
+		arenaRoom.SendText(`<ansi fg="yellow">Time is up!</ansi>`)
+		m.finish(-1)
+	}
+}
+
+// Knocks a fighter out of the match. Nobody dies in the arena.
+func (m *ArenaMatch) defeat(userId int, reason string) {
+
+	m.Defeated[userId] = struct{}{}
+
+	name := `Someone`
+	if u := users.GetByUserId(userId); u != nil {
+		name = u.Character.Name
+		u.Character.EndAggro()
+		clear(u.Character.PlayerDamage)
+		if u.Character.Health < 1 {
+			u.Character.Health = 1
+		}
+	}
+
+	// Anyone fighting them stops
+	for _, userIds := range m.Teams {
+		for _, uId := range userIds {
+			if u := users.GetByUserId(uId); u != nil && u.Character.Aggro != nil && u.Character.Aggro.UserId == userId {
+				u.Character.EndAggro()
+			}
+		}
+	}
+
+	if arenaRoom := LoadRoom(m.RoomId); arenaRoom != nil {
+		arenaRoom.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> <ansi fg="red">%s!</ansi>`, name, reason))
+	}
+}
+
+// Ends a match, updating everyone's rating. winner is the winning team, or -1 for a draw.
+func (m *ArenaMatch) finish(winner int) {
+
+	c := configs.GetConfig()
+	season := int(c.ArenaSeason)
+
+	var resultMsg string
+	if winner < 0 {
+		resultMsg = fmt.Sprintf(`The arena match between %s and %s ended in a draw.`, m.GetTeamNames(0), m.GetTeamNames(1))
+	} else {
+		resultMsg = fmt.Sprintf(`%s defeated %s in the arena!`, m.GetTeamNames(winner), m.GetTeamNames(1-winner))
+	}
+
+	for team, userIds := range m.Teams {
+
+		score := 0.5
+		if winner == team {
+			score = 1
+		} else if winner >= 0 {
+			score = 0
+		}
+
+		opponentRating := m.Ratings[1-team]
+
+		for _, userId := range userIds {
+			withUser(userId, func(u *users.UserRecord, online bool) {
+
+				u.Character.Arena.SetSeason(season)
+				change := u.Character.Arena.AddResult(opponentRating, score, int(c.ArenaKFactor))
+
+				if !online {
+					return
+				}
+
+				changeStr := fmt.Sprintf(`<ansi fg="green">+%d</ansi>`, change)
+				if change < 0 {
+					changeStr = fmt.Sprintf(`<ansi fg="red">%d</ansi>`, change)
+				}
+				u.SendText(fmt.Sprintf(`Your arena rating is now <ansi fg="yellow-bold">%d</ansi> (%s).`, u.Character.Arena.GetRating(), changeStr))
+			})
+		}
+	}
+
+	events.AddToQueue(events.Broadcast{
+		Text: fmt.Sprintf(`<ansi fg="yellow"><ansi fg="red-bold">[Arena]</ansi> %s</ansi>%s`, resultMsg, "\n"),
+	})
+
+	m.close()
+
+	slog.Info("ArenaMatch.finish()", "matchId", m.MatchId, "winner", winner)
+}
+
+// Sends everyone home and removes the arena
+func (m *ArenaMatch) close() {
+
+	arenaRoom, ok := roomManager.rooms[m.RoomId]
+
+	for _, userIds := range m.Teams {
+		for _, userId := range userIds {
+
+			u := users.GetByUserId(userId)
+			if u == nil {
+				continue
+			}
+
+			u.Character.EndAggro()
+			clear(u.Character.PlayerDamage)
+
+			RestoreArenaVitals(u)
+
+			if ok && u.Character.RoomId == m.RoomId {
+
+				returnRoomId := m.ReturnRoomIds[userId]
+				if LoadRoom(returnRoomId) == nil || returnRoomId == m.RoomId {
+					returnRoomId = StartRoomIdAlias
+				}
+
+				MoveToRoom(userId, returnRoomId)
+				u.SendText(`<ansi fg="yellow">The arena fades away, and you find yourself back where you started.</ansi>`)
+				u.Command(`look`)
+			}
+		}
+	}
+
+	for userId := range m.Spectators {
+		if u := users.GetByUserId(userId); u != nil {
+			u.SendText(`<ansi fg="yellow">The arena match you were watching is over.</ansi>`)
+		}
+	}
+
+	if ok {
+		for _, userId := range arenaRoom.GetPlayers() {
+			MoveToRoom(userId, StartRoomIdAlias)
+		}
+	}
+
+	delete(roomManager.roomsWithUsers, m.RoomId)
+	delete(roomManager.roomsWithMobs, m.RoomId)
+	delete(roomManager.rooms, m.RoomId)
+
+	delete(arenaMatches, m.MatchId)
+}
@@ -0,0 +1,112 @@
+package rooms
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/volte6/gomud/internal/characters"
+	"github.com/volte6/gomud/internal/connections"
+	"github.com/volte6/gomud/internal/users"
+)
+
+// Logs in a user for a test, standing in a room that doesn't need to exist
+func newTestUser(t *testing.T, userId int, roomId int) *users.UserRecord {
+	t.Helper()
+
+	u := users.NewUserRecord(userId, uint64(userId))
+	u.Username = fmt.Sprintf(`testuser%d`, userId)
+	u.Character.Name = fmt.Sprintf(`Tester%d`, userId)
+	u.Character.RoomId = roomId
+	u.Character.HealthMax.Value = 50
+	u.Character.Health = 50
+	u.Character.Gold = 0
+
+	if _, _, err := users.LoginUser(u, connections.ConnectionId(userId)); err != nil {
+		t.Fatalf("LoginUser() failed: %v", err)
+	}
+
+	return u
+}
+
+func addTestArenaMatch(t *testing.T, m *ArenaMatch) {
+	t.Helper()
+
+	arenaMatches[m.MatchId] = m
+	t.Cleanup(func() { delete(arenaMatches, m.MatchId) })
+}
+
+func TestArenaDefeat(t *testing.T) {
+
+	fighter := newTestUser(t, 9201, 9200)
+	opponent := newTestUser(t, 9202, 9200)
+
+	addTestArenaMatch(t, &ArenaMatch{
+		MatchId:       9200,
+		Mode:          ArenaSolo,
+		RoomId:        9200,
+		Teams:         [2][]int{{fighter.UserId}, {opponent.UserId}},
+		ReturnRoomIds: map[int]int{},
+		Defeated:      map[int]struct{}{},
+		Spectators:    map[int]struct{}{},
+	})
+
+	opponent.Character.SetAggro(fighter.UserId, 0, characters.DefaultAttack)
+	fighter.Character.Health = -12
+
+	// Nobody dies in the arena, they are just out of the match
+	if !ArenaDefeat(fighter.UserId) {
+		t.Fatalf("ArenaDefeat() = false; want true in the arena")
+	}
+
+	if fighter.Character.Health != 1 {
+		t.Errorf("Defeated fighter has %d health; want 1", fighter.Character.Health)
+	}
+
+	m := GetArenaMatchForUser(fighter.UserId)
+	if m == nil || !m.IsDefeated(fighter.UserId) {
+		t.Fatalf("Fighter wasn't knocked out of the match")
+	}
+
+	if opponent.Character.Aggro != nil {
+		t.Errorf("Opponent is still attacking a defeated fighter")
+	}
+
+	// Outside of the arena room, dying works the normal way
+	opponent.Character.RoomId = 1
+	if ArenaDefeat(opponent.UserId) {
+		t.Errorf("ArenaDefeat() = true for a fighter who left the arena; want false")
+	}
+}
+
+func TestRestoreArenaVitals(t *testing.T) {
+
+	u := newTestUser(t, 9211, 9210)
+	u.Character.ManaMax.Value = 20
+
+	u.Character.SetMiscData(`ArenaHealth`, 30)
+	u.Character.SetMiscData(`ArenaMana`, 25)
+	u.Character.Health = 1
+	u.Character.Mana = 0
+
+	RestoreArenaVitals(u)
+
+	if u.Character.Health != 30 {
+		t.Errorf("Health is %d after the match; want 30", u.Character.Health)
+	}
+
+	// Never more than the maximum
+	if u.Character.Mana != 20 {
+		t.Errorf("Mana is %d after the match; want 20", u.Character.Mana)
+	}
+
+	if u.Character.GetMiscData(`ArenaHealth`) != nil || u.Character.GetMiscData(`ArenaMana`) != nil {
+		t.Errorf("Saved vitals weren't cleared")
+	}
+
+	// Only restored once
+	u.Character.Health = 5
+	RestoreArenaVitals(u)
+	if u.Character.Health != 5 {
+		t.Errorf("RestoreArenaVitals() ran a second time")
+	}
+}
@@ -1,16 +1,22 @@
 package rooms
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/volte6/gomud/internal/weather"
+)
 
 type BiomeInfo struct {
 	name           string
 	symbol         rune
 	description    string
-	darkArea       bool // Whether is always dark
-	litArea        bool // Whether is always lit
-	requiredItemId int  // item id required to move into any room with this biome
-	usesItem       bool // Whether it "uses" the item (i.e. consumes it or decreases its uses left) when moving into a room with this biome
-	burns          bool // Does this area catch fire? (brush etc.)
+	darkArea       bool            // Whether is always dark
+	litArea        bool            // Whether is always lit
+	requiredItemId int             // item id required to move into any room with this biome
+	usesItem       bool            // Whether it "uses" the item (i.e. consumes it or decreases its uses left) when moving into a room with this biome
+	burns          bool            // Does this area catch fire? (brush etc.)
+	climate        weather.Climate // What kind of weather the area gets. Empty means sheltered from weather.
+	movement       MovementMode    // How you get into the area, if not by walking
 }
 
 func (bi BiomeInfo) Name() string {
@@ -37,6 +43,13 @@ func (bi BiomeInfo) UsesItem() bool {
 	return bi.usesItem
 }
 
+func (bi BiomeInfo) Movement() MovementMode {
+	if bi.movement == `` {
+		return MovementWalk
+	}
+	return bi.movement
+}
+
 func (bi BiomeInfo) IsLit() bool {
 	return bi.litArea && !bi.darkArea
 }
@@ -49,6 +62,14 @@ func (bi BiomeInfo) Burns() bool {
 	return bi.burns
 }
 
+func (bi BiomeInfo) Climate() weather.Climate {
+	return bi.climate
+}
+
+func (bi BiomeInfo) IsSheltered() bool {
+	return bi.climate == ``
+}
+
 var (
 	AllBiomes = map[string]BiomeInfo{
 		`city`: {
@@ -56,17 +77,20 @@ var (
 			symbol:      '•',
 			litArea:     true,
 			description: `Cities are generally well protected, with well built roads. Usually they will have shops, inns, and law enforcement. Fighting and Killing in cities can lead to a lasting bad reputation.`,
+			climate:     weather.Temperate,
 		},
 		`fort`: {
 			name:        `Fort`,
 			symbol:      '•',
 			litArea:     true,
 			description: `Forts are structures built to house soldiers or people.`,
+			climate:     weather.Temperate,
 		},
 		`road`: {
 			name:        `Road`,
 			symbol:      '•',
 			description: `Roads are well traveled paths, often extending out into the countryside.`,
+			climate:     weather.Temperate,
 		},
 		`house`: {
 			name:        `House`,
@@ -79,39 +103,48 @@ var (
 			name:        `Shore`,
 			symbol:      '~',
 			description: `Shores are the transition between land and water. You can usually fish from them.`,
+			climate:     weather.Coastal,
 		},
 		`water`: {
 			name:           `Deep Water`,
 			symbol:         '≈',
 			description:    `Deep water is dangerous and usually requires some sort of assistance to cross.`,
 			requiredItemId: 20030,
+			climate:        weather.Coastal,
+			movement:       MovementSwim,
 		},
 		`forest`: {
 			name:        `Forest`,
 			symbol:      '♣',
 			description: `Forests are wild areas full of trees. Animals and monsters often live here.`,
 			burns:       true,
+			climate:     weather.Temperate,
 		},
 		`mountains`: {
 			name:        `Mountains`,
 			symbol:      '⩕', //'▲',
 			description: `Mountains are difficult to traverse, with roads that don't often follow a straight line.`,
+			climate:     weather.Alpine,
 		},
 		`cliffs`: {
 			name:        `Cliffs`,
 			symbol:      '▼',
 			description: `Cliffs are steep, rocky areas that are difficult to traverse. They can be climbed up or down with the right skills and equipment.`,
+			climate:     weather.Alpine,
+			movement:    MovementClimb,
 		},
 		`swamp`: {
 			name:        `Swamp`,
 			symbol:      '♨',
 			darkArea:    true,
 			description: `Swamps are wet, muddy areas that are difficult to traverse.`,
+			climate:     weather.Marsh,
 		},
 		`snow`: {
 			name:        `Snow`,
 			symbol:      '❄',
 			description: `Snow is cold and wet. It can be difficult to traverse, but is usually safe.`,
+			climate:     weather.Alpine,
 		},
 		`spiderweb`: {
 			name:        `Spiderweb`,
@@ -129,12 +162,14 @@ var (
 			name:        `Desert`,
 			symbol:      '*',
 			description: `The harsh desert is unforgiving and dry.`,
+			climate:     weather.Arid,
 		},
 		`farmland`: {
 			name:        `Farmland`,
 			symbol:      ',',
 			description: `Wheat or other food is grown here.`,
 			burns:       true,
+			climate:     weather.Temperate,
 		},
 	}
 )
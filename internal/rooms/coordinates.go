@@ -0,0 +1,281 @@
+package rooms
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/volte6/gomud/internal/exit"
+)
+
+//
+// Optional fixed positions for rooms.
+// Without them, maps work out where rooms are from exit directions every time they are drawn.
+// With them, rooms are placed exactly, and up/down exits put rooms on other z-levels.
+//
+
+var (
+	// How far up/down exits move on the z axis
+	VerticalDeltas = map[string]int{
+		`up`:   1,
+		`down`: -1,
+	}
+)
+
+type Coordinates struct {
+	X int `yaml:"x"`
+	Y int `yaml:"y"`
+	Z int `yaml:"z"`
+}
+
+func (c Coordinates) String() string {
+	return fmt.Sprintf(`%d,%d,%d`, c.X, c.Y, c.Z)
+}
+
+// Parses coordinates in the form "x,y,z"
+func ParseCoordinates(str string) (Coordinates, error) {
+	parts := strings.Split(strings.ReplaceAll(str, ` `, ``), `,`)
+	if len(parts) != 3 {
+		return Coordinates{}, fmt.Errorf(`"%s" should look like x,y,z`, str)
+	}
+
+	values := [3]int{}
+	for i, part := range parts {
+		num, err := strconv.Atoi(part)
+		if err != nil {
+			return Coordinates{}, fmt.Errorf(`"%s" is not a number`, part)
+		}
+		values[i] = num
+	}
+
+	return Coordinates{X: values[0], Y: values[1], Z: values[2]}, nil
+}
+
+// Returns how far an exit moves, going by its MapDirection if it has one.
+// Exits like "enter" or "portal" have no direction and return false.
+func ExitOffset(exitName string, exitInfo exit.RoomExit) (Coordinates, bool) {
+
+	direction := exitName
+	if exitInfo.MapDirection != `` {
+		direction = exitInfo.MapDirection
+	}
+
+	if delta, ok := DirectionDeltas[direction]; ok {
+		// Gaps are only for drawing, the rooms are still next to each other
+		if delta.Arrow == ' ' && strings.Contains(direction, `-gap`) {
+			delta = DirectionDeltas[strings.Split(direction, `-`)[0]]
+		}
+		return Coordinates{X: delta.Dx, Y: delta.Dy, Z: delta.Dz}, true
+	}
+
+	if dz, ok := VerticalDeltas[direction]; ok {
+		return Coordinates{Z: dz}, true
+	}
+
+	return Coordinates{}, false
+}
+
+type CoordinateConflict struct {
+	RoomId      int
+	OtherRoomId int // Zero if the conflict is only about RoomId
+	Reason      string
+}
+
+// Works out coordinates for every room in a zone by walking its exits out from the root room.
+// The root room keeps its coordinates if it has them, otherwise it starts at 0,0,0.
+// The first placement wins; anything that doesn't agree with it is returned as a conflict.
+func LayoutZone(zone string) (map[int]Coordinates, []CoordinateConflict, error) {
+
+	rootRoomId, err := GetZoneRoot(zone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	zoneRoomIds := GetZoneRoomIds(zone)
+	if rootRoomId == 0 {
+		if len(zoneRoomIds) == 0 {
+			return nil, nil, fmt.Errorf(`zone %s has no rooms`, zone)
+		}
+		rootRoomId = zoneRoomIds[0]
+	}
+
+	rootRoom := LoadRoom(rootRoomId)
+	if rootRoom == nil {
+		return nil, nil, fmt.Errorf(`could not load room %d`, rootRoomId)
+	}
+
+	layout := map[int]Coordinates{}
+	if rootRoom.Coordinates != nil {
+		layout[rootRoomId] = *rootRoom.Coordinates
+	} else {
+		layout[rootRoomId] = Coordinates{}
+	}
+
+	queue := []int{rootRoomId}
+	for len(queue) > 0 {
+
+		roomId := queue[0]
+		queue = queue[1:]
+
+		room := LoadRoom(roomId)
+		if room == nil {
+			continue
+		}
+
+		from := layout[roomId]
+
+		for _, exitName := range sortedExitNames(room) {
+
+			exitInfo := room.Exits[exitName]
+
+			if _, placed := layout[exitInfo.RoomId]; placed {
+				continue
+			}
+
+			offset, ok := ExitOffset(exitName, exitInfo)
+			if !ok {
+				continue
+			}
+
+			toRoom := LoadRoom(exitInfo.RoomId)
+			if toRoom == nil || toRoom.Zone != zone {
+				continue
+			}
+
+			layout[exitInfo.RoomId] = Coordinates{X: from.X + offset.X, Y: from.Y + offset.Y, Z: from.Z + offset.Z}
+			queue = append(queue, exitInfo.RoomId)
+		}
+	}
+
+	return layout, findCoordinateConflicts(zone, layout), nil
+}
+
+// Reports problems with the coordinates rooms in a zone have saved
+func CheckZoneCoordinates(zone string) []CoordinateConflict {
+
+	layout := map[int]Coordinates{}
+	for _, roomId := range GetZoneRoomIds(zone) {
+		if room := LoadRoom(roomId); room != nil && room.Coordinates != nil {
+			layout[roomId] = *room.Coordinates
+		}
+	}
+
+	return findCoordinateConflicts(zone, layout)
+}
+
+// Saves a layout to the rooms in a zone
+func ApplyZoneLayout(layout map[int]Coordinates) int {
+
+	savedCt := 0
+	for roomId, coords := range layout {
+		room := LoadRoom(roomId)
+		if room == nil {
+			continue
+		}
+		if room.Coordinates != nil && *room.Coordinates == coords {
+			continue
+		}
+		c := coords
+		room.Coordinates = &c
+		SaveRoom(*room)
+		savedCt++
+	}
+
+	return savedCt
+}
+
+// Removes saved coordinates from every room in a zone
+func ClearZoneCoordinates(zone string) int {
+
+	clearedCt := 0
+	for _, roomId := range GetZoneRoomIds(zone) {
+		room := LoadRoom(roomId)
+		if room == nil || room.Coordinates == nil {
+			continue
+		}
+		room.Coordinates = nil
+		SaveRoom(*room)
+		clearedCt++
+	}
+
+	return clearedCt
+}
+
+func findCoordinateConflicts(zone string, layout map[int]Coordinates) []CoordinateConflict {
+
+	conflicts := []CoordinateConflict{}
+
+	zoneRoomIds := GetZoneRoomIds(zone)
+
+	// Two rooms in the same spot
+	byPosition := map[Coordinates]int{}
+	for _, roomId := range zoneRoomIds {
+		coords, ok := layout[roomId]
+		if !ok {
+			conflicts = append(conflicts, CoordinateConflict{
+				RoomId: roomId,
+				Reason: `has no position`,
+			})
+			continue
+		}
+		if otherRoomId, ok := byPosition[coords]; ok {
+			conflicts = append(conflicts, CoordinateConflict{
+				RoomId:      roomId,
+				OtherRoomId: otherRoomId,
+				Reason:      fmt.Sprintf(`both rooms are at %s`, coords),
+			})
+			continue
+		}
+		byPosition[coords] = roomId
+	}
+
+	// Exits that don't lead where their direction says they should
+	for _, roomId := range zoneRoomIds {
+
+		from, ok := layout[roomId]
+		if !ok {
+			continue
+		}
+
+		room := LoadRoom(roomId)
+		if room == nil {
+			continue
+		}
+
+		for _, exitName := range sortedExitNames(room) {
+
+			exitInfo := room.Exits[exitName]
+
+			to, ok := layout[exitInfo.RoomId]
+			if !ok {
+				continue
+			}
+
+			offset, ok := ExitOffset(exitName, exitInfo)
+			if !ok {
+				continue
+			}
+
+			expected := Coordinates{X: from.X + offset.X, Y: from.Y + offset.Y, Z: from.Z + offset.Z}
+			if expected != to {
+				conflicts = append(conflicts, CoordinateConflict{
+					RoomId:      roomId,
+					OtherRoomId: exitInfo.RoomId,
+					Reason:      fmt.Sprintf(`exit "%s" points to %s but the room is at %s`, exitName, expected, to),
+				})
+			}
+		}
+	}
+
+	return conflicts
+}
+
+func sortedExitNames(room *Room) []string {
+	exitNames := make([]string, 0, len(room.Exits))
+	for exitName := range room.Exits {
+		exitNames = append(exitNames, exitName)
+	}
+	sort.Strings(exitNames)
+	return exitNames
+}
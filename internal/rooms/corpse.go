@@ -0,0 +1,231 @@
+package rooms
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/volte6/gomud/internal/configs"
+	"github.com/volte6/gomud/internal/gametime"
+	"github.com/volte6/gomud/internal/items"
+	"github.com/volte6/gomud/internal/parties"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+//
+// Corpses.
+// When something dies it leaves a corpse holding whatever it dropped.
+// Corpses are temporary containers that rot down to bones, and then disappear.
+//
+
+var (
+	// userId => roomIds that might have one of their corpses in them
+	playerCorpseRooms = map[int]map[int]struct{}{}
+)
+
+type Corpse struct {
+	UserId     int    `yaml:"userid,omitempty"`     // Who it was, if it was a player
+	MobId      int    `yaml:"mobid,omitempty"`      // What it was, if it was a mob
+	Name       string `yaml:"name,omitempty"`       // The name of the deceased
+	Level      int    `yaml:"level,omitempty"`      // The level of the deceased
+	Pvp        bool   `yaml:"pvp,omitempty"`        // Killed by another player. Anyone can loot it.
+	BonesRound uint64 `yaml:"bonesround,omitempty"` // When it rots down to bones
+	Bones      bool   `yaml:"bones,omitempty"`      // Whether it has rotted down to bones
+}
+
+// A corpse somewhere in the world
+type CorpseLocation struct {
+	RoomId        int
+	ContainerName string
+}
+
+// Leaves a corpse in the room holding gold and items. Returns the name of the corpse.
+func (r *Room) SpawnCorpse(corpse Corpse, gold int, corpseItems []items.Item) string {
+
+	c := configs.GetConfig()
+
+	decayTime := string(c.CorpseDecayTime)
+	if corpse.UserId > 0 {
+		decayTime = string(c.PlayerCorpseDecayTime)
+	}
+
+	roundNow := util.GetRoundCount()
+
+	corpse.BonesRound = gametime.GetDate(roundNow).AddPeriod(decayTime)
+	corpse.Bones = false
+
+	containerName := r.SpawnTempContainer(`corpse of `+corpse.Name, decayTime, 0)
+
+	container := r.Containers[containerName]
+	container.Corpse = &corpse
+	container.Gold = gold
+	container.Items = append([]items.Item{}, corpseItems...)
+	// The bones last as long as the corpse did
+	container.DespawnRound = corpse.BonesRound + (corpse.BonesRound - roundNow)
+	r.Containers[containerName] = container
+
+	r.indexPlayerCorpses()
+
+	return containerName
+}
+
+// Remembers where any player corpses in the room are.
+// Corpses are saved with the room, so this is done whenever rooms are loaded as well as when a corpse is left.
+func (r *Room) indexPlayerCorpses() {
+	for _, c := range r.Containers {
+		if c.Corpse == nil || c.Corpse.UserId == 0 {
+			continue
+		}
+		if playerCorpseRooms[c.Corpse.UserId] == nil {
+			playerCorpseRooms[c.Corpse.UserId] = map[int]struct{}{}
+		}
+		playerCorpseRooms[c.Corpse.UserId][r.RoomId] = struct{}{}
+	}
+}
+
+// Whether a user is allowed to take things out of a container.
+// Player corpses can only be looted by the owner, their party, or admins, unless they died in PVP.
+func (c Container) CanLoot(user *users.UserRecord) bool {
+
+	if c.Corpse == nil || c.Corpse.UserId == 0 || c.Corpse.Pvp {
+		return true
+	}
+
+	if c.Corpse.UserId == user.UserId || user.Permission == users.PermissionAdmin {
+		return true
+	}
+
+	if party := parties.Get(c.Corpse.UserId); party != nil && party.IsMember(user.UserId) {
+		return true
+	}
+
+	return false
+}
+
+// Rots any corpses whose time has come
+func (r *Room) decayCorpses(roundNow uint64) {
+	for name, c := range r.Containers {
+
+		if c.Corpse == nil || c.Corpse.Bones || c.Corpse.BonesRound > roundNow {
+			continue
+		}
+
+		corpse := *c.Corpse
+		corpse.Bones = true
+		c.Corpse = &corpse
+		r.Containers[name] = c
+
+		if c.DespawnRound > roundNow {
+			r.SendText(fmt.Sprintf(`The <ansi fg="container">%s</ansi> rots away, leaving only bones.`, name))
+		}
+	}
+}
+
+// Returns the names of any corpses in the room
+func (r *Room) GetCorpses() []string {
+	names := []string{}
+	for name, c := range r.Containers {
+		if c.Corpse != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Removes a corpse from the room. Anything it held is left on the floor.
+func (r *Room) RemoveCorpse(containerName string) (Corpse, bool) {
+
+	c, ok := r.Containers[containerName]
+	if !ok || c.Corpse == nil {
+		return Corpse{}, false
+	}
+
+	for _, itm := range c.Items {
+		r.AddItem(itm, false)
+	}
+	r.Gold += c.Gold
+
+	delete(r.Containers, containerName)
+
+	return *c.Corpse, true
+}
+
+// Finds any corpses a player has left around the world
+func GetPlayerCorpses(userId int) []CorpseLocation {
+
+	found := []CorpseLocation{}
+
+	for roomId := range playerCorpseRooms[userId] {
+
+		r := LoadRoom(roomId)
+		if r == nil {
+			delete(playerCorpseRooms[userId], roomId)
+			continue
+		}
+
+		foundInRoom := false
+		for name, c := range r.Containers {
+			if c.Corpse != nil && c.Corpse.UserId == userId {
+				found = append(found, CorpseLocation{RoomId: roomId, ContainerName: name})
+				foundInRoom = true
+			}
+		}
+
+		if !foundInRoom {
+			delete(playerCorpseRooms[userId], roomId)
+		}
+	}
+
+	if len(playerCorpseRooms[userId]) == 0 {
+		delete(playerCorpseRooms, userId)
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].RoomId == found[j].RoomId {
+			return found[i].ContainerName < found[j].ContainerName
+		}
+		return found[i].RoomId < found[j].RoomId
+	})
+
+	return found
+}
+
+// Moves all of a player's corpses into a room. Returns how many were moved.
+func RetrieveCorpses(userId int, toRoom *Room) int {
+
+	moved := 0
+
+	for _, loc := range GetPlayerCorpses(userId) {
+
+		if loc.RoomId == toRoom.RoomId {
+			continue
+		}
+
+		fromRoom := LoadRoom(loc.RoomId)
+		if fromRoom == nil {
+			continue
+		}
+
+		c := fromRoom.Containers[loc.ContainerName]
+		delete(fromRoom.Containers, loc.ContainerName)
+
+		fromRoom.SendText(fmt.Sprintf(`The <ansi fg="container">%s</ansi> vanishes.`, loc.ContainerName))
+
+		newName := toRoom.uniqueContainerName(`corpse of ` + c.Corpse.Name)
+		if toRoom.Containers == nil {
+			toRoom.Containers = make(map[string]Container)
+		}
+		toRoom.Containers[newName] = c
+
+		toRoom.SendText(fmt.Sprintf(`The <ansi fg="container">%s</ansi> appears.`, newName))
+
+		moved++
+	}
+
+	if moved > 0 {
+		toRoom.indexPlayerCorpses()
+	}
+
+	return moved
+}
@@ -0,0 +1,481 @@
+package rooms
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/volte6/gomud/internal/characters"
+	"github.com/volte6/gomud/internal/configs"
+	"github.com/volte6/gomud/internal/mobs"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+//
+// Duels.
+// Two players agree to fight, anywhere, even where PVP isn't normally allowed.
+// Whoever drops to the yield threshold first loses. Nobody dies, and nobody loses experience.
+//
+
+type DuelState int
+
+const (
+	DuelPending   DuelState = iota // Waiting for the challenged player to accept
+	DuelCountdown                  // Accepted, counting down to the fight
+	DuelFighting                   // Fighting until someone yields
+
+	duelChallengeSeconds = 60 // How long a challenge stays open
+	duelCountdownRounds  = 3  // Rounds between accepting and the fight starting
+)
+
+var (
+	ErrDuelPvpDisabled = errors.New(`duels aren't allowed while PVP is disabled`)
+	ErrDuelSelf        = errors.New(`you can't duel yourself`)
+	ErrDuelNotHere     = errors.New(`they aren't here`)
+	ErrDuelBusy        = errors.New(`you are already in a duel`)
+	ErrDuelTargetBusy  = errors.New(`they are already in a duel`)
+	ErrDuelFighting    = errors.New(`you can't start a duel in the middle of a fight`)
+	ErrDuelArena       = errors.New(`save it for the arena`)
+	ErrDuelNoWagers    = errors.New(`wagers aren't allowed`)
+	ErrDuelWagerLimit  = errors.New(`that wager is too high`)
+	ErrDuelNoGold      = errors.New(`not enough gold on hand to cover the wager`)
+	ErrDuelNoChallenge = errors.New(`nobody has challenged you to a duel`)
+
+	duels = []*Duel{}
+)
+
+type Duel struct {
+	ChallengerId int
+	TargetId     int
+	RoomId       int
+	Wager        int       // Gold each duelist puts up
+	Escrow       int       // Gold being held until the duel ends
+	State        DuelState //
+	ExpireRound  uint64    // When an unanswered challenge is withdrawn
+	StartRound   uint64    // When the fighting starts
+}
+
+// Returns the other duelist
+func (d *Duel) GetOpponent(userId int) int {
+	if d.ChallengerId == userId {
+		return d.TargetId
+	}
+	return d.ChallengerId
+}
+
+func (d *Duel) IsDuelist(userId int) bool {
+	return d.ChallengerId == userId || d.TargetId == userId
+}
+
+// Returns the duel a user is challenging, challenged to, or fighting in
+func GetDuel(userId int) *Duel {
+	for _, d := range duels {
+		if d.IsDuelist(userId) {
+			return d
+		}
+	}
+	return nil
+}
+
+// Challenges another player in the same room to a duel
+func ChallengeDuel(challenger *users.UserRecord, target *users.UserRecord, wager int) error {
+
+	c := configs.GetConfig()
+
+	if c.PVP == configs.PVPDisabled {
+		return ErrDuelPvpDisabled
+	}
+
+	if challenger.UserId == target.UserId {
+		return ErrDuelSelf
+	}
+
+	if challenger.Character.RoomId != target.Character.RoomId {
+		return ErrDuelNotHere
+	}
+
+	if err := checkDuelEligible(challenger); err != nil {
+		return err
+	}
+
+	if err := checkDuelEligible(target); err != nil {
+		if err == ErrDuelBusy {
+			return ErrDuelTargetBusy
+		}
+		return fmt.Errorf(`%s: %w`, target.Character.Name, err)
+	}
+
+	if wager > 0 {
+		if c.DuelMaxWager == 0 {
+			return ErrDuelNoWagers
+		}
+		if wager > int(c.DuelMaxWager) {
+			return ErrDuelWagerLimit
+		}
+		if challenger.Character.Gold < wager {
+			return ErrDuelNoGold
+		}
+	}
+
+	duels = append(duels, &Duel{
+		ChallengerId: challenger.UserId,
+		TargetId:     target.UserId,
+		RoomId:       challenger.Character.RoomId,
+		Wager:        max(wager, 0),
+		State:        DuelPending,
+		ExpireRound:  util.GetRoundCount() + uint64(c.SecondsToRounds(duelChallengeSeconds)),
+	})
+
+	return nil
+}
+
+func checkDuelEligible(u *users.UserRecord) error {
+
+	if GetDuel(u.UserId) != nil {
+		return ErrDuelBusy
+	}
+
+	if GetArenaMatchForUser(u.UserId) != nil {
+		return ErrDuelArena
+	}
+
+	if r := LoadRoom(u.Character.RoomId); r != nil && r.arenaMatchId > 0 {
+		return ErrDuelArena
+	}
+
+	if u.Character.Aggro != nil || u.Character.Health < 1 {
+		return ErrDuelFighting
+	}
+
+	return nil
+}
+
+// Accepts a duel challenge. The wager is taken from both duelists and held until the duel ends.
+func AcceptDuel(user *users.UserRecord) (*Duel, error) {
+
+	d := GetDuel(user.UserId)
+	if d == nil || d.State != DuelPending || d.TargetId != user.UserId {
+		return nil, ErrDuelNoChallenge
+	}
+
+	challenger := users.GetByUserId(d.ChallengerId)
+	if challenger == nil || challenger.Character.RoomId != user.Character.RoomId {
+		removeDuel(d)
+		return nil, ErrDuelNotHere
+	}
+
+	if user.Character.Aggro != nil || challenger.Character.Aggro != nil {
+		return nil, ErrDuelFighting
+	}
+
+	if d.Wager > 0 {
+		if user.Character.Gold < d.Wager {
+			return nil, ErrDuelNoGold
+		}
+		if challenger.Character.Gold < d.Wager {
+			return nil, fmt.Errorf(`%s: %w`, challenger.Character.Name, ErrDuelNoGold)
+		}
+
+		user.Character.Gold -= d.Wager
+		challenger.Character.Gold -= d.Wager
+		d.Escrow = d.Wager * 2
+	}
+
+	d.State = DuelCountdown
+	d.RoomId = user.Character.RoomId
+	d.StartRound = util.GetRoundCount() + duelCountdownRounds
+
+	return d, nil
+}
+
+// Declines a challenge, or withdraws one that was made. Fights can't be declined, only yielded.
+func DeclineDuel(userId int) (*Duel, bool) {
+
+	d := GetDuel(userId)
+	if d == nil || d.State != DuelPending {
+		return nil, false
+	}
+
+	removeDuel(d)
+
+	return d, true
+}
+
+// Gives up a duel. Before the fighting starts this just calls it off.
+func YieldDuel(userId int) bool {
+
+	d := GetDuel(userId)
+	if d == nil || d.State == DuelPending {
+		return false
+	}
+
+	if d.State == DuelCountdown {
+		d.cancel(`The duel has been called off.`)
+		return true
+	}
+
+	d.finish(d.GetOpponent(userId), userId, `yields`)
+
+	return true
+}
+
+// Called when a user would die. If they were fighting a duel they yield instead, and true is returned.
+func DuelDefeat(userId int) bool {
+
+	d := GetDuel(userId)
+	if d == nil || d.State != DuelFighting {
+		return false
+	}
+
+	d.finish(d.GetOpponent(userId), userId, `collapses and yields`)
+
+	return true
+}
+
+// Duelists can always fight each other once their duel has started
+func canDuelPvp(attUserId int, defUserId int) (bool, error) {
+
+	d := GetDuel(attUserId)
+	if d == nil || d.GetOpponent(attUserId) != defUserId {
+		return false, nil
+	}
+
+	if d.State == DuelPending {
+		return false, nil
+	}
+
+	if d.State == DuelCountdown {
+		return true, errors.New(`The duel hasn't started yet.`)
+	}
+
+	return true, nil
+}
+
+// Runs once a round, after combat. Counts down duels, checks whether anyone has yielded,
+// and calls off any duels that have been interrupted.
+func DuelRoundTick(roundNumber uint64) {
+
+	for _, d := range append([]*Duel{}, duels...) {
+
+		challenger := users.GetByUserId(d.ChallengerId)
+		target := users.GetByUserId(d.TargetId)
+
+		if d.State == DuelPending {
+
+			if challenger == nil || target == nil || challenger.Character.RoomId != d.RoomId || target.Character.RoomId != d.RoomId {
+				removeDuel(d)
+				continue
+			}
+
+			if roundNumber >= d.ExpireRound {
+				removeDuel(d)
+				challenger.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> never answered your challenge.`, target.Character.Name))
+				target.SendText(fmt.Sprintf(`The duel challenge from <ansi fg="username">%s</ansi> has been withdrawn.`, challenger.Character.Name))
+			}
+
+			continue
+		}
+
+		if challenger == nil || target == nil {
+			d.cancel(`The duel has been called off because a duelist is gone.`)
+			continue
+		}
+
+		if challenger.Character.RoomId != d.RoomId || target.Character.RoomId != d.RoomId {
+			d.cancel(`The duel has been called off because a duelist left.`)
+			continue
+		}
+
+		if name := d.findInterference(); name != `` {
+			d.cancel(fmt.Sprintf(`The duel has been called off because <ansi fg="username">%s</ansi> interfered.`, name))
+			continue
+		}
+
+		room := LoadRoom(d.RoomId)
+		if room == nil {
+			d.cancel(``)
+			continue
+		}
+
+		if d.State == DuelCountdown {
+
+			if roundNumber < d.StartRound {
+				room.SendText(fmt.Sprintf(`<ansi fg="yellow">%d...</ansi>`, d.StartRound-roundNumber))
+				continue
+			}
+
+			d.State = DuelFighting
+			room.SendText(`<ansi fg="red-bold">The duel begins!</ansi>`)
+
+			challenger.Character.SetAggro(target.UserId, 0, characters.DefaultAttack)
+			target.Character.SetAggro(challenger.UserId, 0, characters.DefaultAttack)
+
+			continue
+		}
+
+		// Whoever is closest to death yields
+		yieldPct := int(configs.GetConfig().DuelYieldPercent)
+		challengerPct := challenger.Character.Health * 100 / max(challenger.Character.HealthMax.Value, 1)
+		targetPct := target.Character.Health * 100 / max(target.Character.HealthMax.Value, 1)
+
+		if challengerPct > yieldPct && targetPct > yieldPct {
+			continue
+		}
+
+		if challengerPct <= targetPct {
+			d.finish(d.TargetId, d.ChallengerId, `yields`)
+		} else {
+			d.finish(d.ChallengerId, d.TargetId, `yields`)
+		}
+	}
+}
+
+// Returns the name of anyone who has joined in on the duel, or an empty string
+func (d *Duel) findInterference() string {
+
+	room := LoadRoom(d.RoomId)
+	if room == nil {
+		return ``
+	}
+
+	for _, userId := range room.GetPlayers() {
+
+		u := users.GetByUserId(userId)
+		if u == nil || u.Character.Aggro == nil {
+			continue
+		}
+
+		if d.IsDuelist(userId) {
+			// Duelists can only fight each other
+			if u.Character.Aggro.MobInstanceId > 0 || (u.Character.Aggro.UserId > 0 && u.Character.Aggro.UserId != d.GetOpponent(userId)) {
+				return u.Character.Name
+			}
+			continue
+		}
+
+		if d.IsDuelist(u.Character.Aggro.UserId) {
+			return u.Character.Name
+		}
+	}
+
+	for _, mobInstanceId := range room.GetMobs() {
+		if mob := mobs.GetInstance(mobInstanceId); mob != nil && mob.Character.Aggro != nil {
+			if d.IsDuelist(mob.Character.Aggro.UserId) {
+				return mob.Character.Name
+			}
+		}
+	}
+
+	return ``
+}
+
+// Ends a duel with a winner, paying out any wager
+func (d *Duel) finish(winnerId int, loserId int, reason string) {
+
+	removeDuel(d)
+
+	winner := users.GetByUserId(winnerId)
+	loser := users.GetByUserId(loserId)
+	if winner == nil || loser == nil {
+		d.refund()
+		return
+	}
+
+	d.stopFighting()
+
+	// Both could have been knocked down in the same round
+	for _, u := range []*users.UserRecord{winner, loser} {
+		if u.Character.Health < 1 {
+			u.Character.Health = 1
+		}
+	}
+
+	winner.Character.KD.AddDuelWin(loser.UserId, loser.Character.Name)
+	loser.Character.KD.AddDuelLoss(winner.UserId, winner.Character.Name)
+
+	if room := LoadRoom(d.RoomId); room != nil {
+		room.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> %s! <ansi fg="username">%s</ansi> wins the duel.`, loser.Character.Name, reason, winner.Character.Name), winnerId, loserId)
+	}
+
+	loser.SendText(fmt.Sprintf(`<ansi fg="red">You yield. <ansi fg="username">%s</ansi> wins the duel.</ansi>`, winner.Character.Name))
+	winner.SendText(fmt.Sprintf(`<ansi fg="green"><ansi fg="username">%s</ansi> %s. You win the duel!</ansi>`, loser.Character.Name, reason))
+
+	if d.Escrow > 0 {
+		winner.Character.Gold += d.Escrow
+		winner.SendText(fmt.Sprintf(`You collect the wager of <ansi fg="gold">%d gold</ansi>.`, d.Escrow))
+		d.Escrow = 0
+	}
+
+	slog.Info("Duel.finish()", "winner", winnerId, "loser", loserId, "wager", d.Wager)
+}
+
+// Calls off a duel without a winner, giving back any wager
+func (d *Duel) cancel(reason string) {
+
+	removeDuel(d)
+
+	d.stopFighting()
+	d.refund()
+
+	if reason == `` {
+		return
+	}
+
+	for _, userId := range []int{d.ChallengerId, d.TargetId} {
+		if u := users.GetByUserId(userId); u != nil {
+			u.SendText(`<ansi fg="yellow">` + reason + `</ansi>`)
+		}
+	}
+
+	if room := LoadRoom(d.RoomId); room != nil {
+		room.SendText(`<ansi fg="yellow">`+reason+`</ansi>`, d.ChallengerId, d.TargetId)
+	}
+}
+
+// Gives each duelist back what they put up
+func (d *Duel) refund() {
+
+	if d.Escrow < 1 {
+		return
+	}
+
+	for _, userId := range []int{d.ChallengerId, d.TargetId} {
+		withUser(userId, func(u *users.UserRecord, online bool) {
+			u.Character.Gold += d.Escrow / 2
+			if online {
+				u.SendText(fmt.Sprintf(`Your wager of <ansi fg="gold">%d gold</ansi> is returned.`, d.Escrow/2))
+			}
+		})
+	}
+
+	d.Escrow = 0
+}
+
+// Makes the duelists stop fighting each other, and forget the damage they did
+func (d *Duel) stopFighting() {
+	for _, userId := range []int{d.ChallengerId, d.TargetId} {
+
+		u := users.GetByUserId(userId)
+		if u == nil {
+			continue
+		}
+
+		opponentId := d.GetOpponent(userId)
+
+		if u.Character.Aggro != nil && u.Character.Aggro.UserId == opponentId {
+			u.Character.EndAggro()
+		}
+
+		delete(u.Character.PlayerDamage, opponentId)
+	}
+}
+
+func removeDuel(d *Duel) {
+	for i, existing := range duels {
+		if existing == d {
+			duels = append(duels[:i], duels[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package rooms
+
+import (
+	"testing"
+
+	"github.com/volte6/gomud/internal/characters"
+)
+
+func addTestDuel(t *testing.T, d *Duel) {
+	t.Helper()
+
+	oldDuels := duels
+	t.Cleanup(func() { duels = oldDuels })
+
+	duels = append([]*Duel{}, d)
+}
+
+func TestDuelDefeat(t *testing.T) {
+
+	winner := newTestUser(t, 9101, 9100)
+	loser := newTestUser(t, 9102, 9100)
+
+	winner.Character.Gold = 50
+	loser.Character.Gold = 50
+
+	addTestDuel(t, &Duel{
+		ChallengerId: winner.UserId,
+		TargetId:     loser.UserId,
+		RoomId:       9100,
+		Wager:        50,
+		Escrow:       100,
+		State:        DuelFighting,
+	})
+
+	winner.Character.SetAggro(loser.UserId, 0, characters.DefaultAttack)
+	loser.Character.SetAggro(winner.UserId, 0, characters.DefaultAttack)
+	loser.Character.Health = -4
+
+	// A killing blow makes them yield instead
+	if !DuelDefeat(loser.UserId) {
+		t.Fatalf("DuelDefeat() = false; want true while dueling")
+	}
+
+	if loser.Character.Health != 1 {
+		t.Errorf("Loser has %d health; want 1", loser.Character.Health)
+	}
+
+	if winner.Character.Aggro != nil || loser.Character.Aggro != nil {
+		t.Errorf("Duelists are still fighting after the duel ended")
+	}
+
+	if winner.Character.Gold != 150 || loser.Character.Gold != 50 {
+		t.Errorf("Gold is %d/%d after the duel; want 150/50", winner.Character.Gold, loser.Character.Gold)
+	}
+
+	if winner.Character.KD.TotalDuelWins != 1 || loser.Character.KD.TotalDuelLosses != 1 {
+		t.Errorf("Duel records are %d wins/%d losses; want 1/1", winner.Character.KD.TotalDuelWins, loser.Character.KD.TotalDuelLosses)
+	}
+
+	if GetDuel(winner.UserId) != nil || GetDuel(loser.UserId) != nil {
+		t.Errorf("Duel is still going after it ended")
+	}
+
+	// Without a duel, dying is up to the normal death handling
+	if DuelDefeat(loser.UserId) {
+		t.Errorf("DuelDefeat() = true; want false after the duel ended")
+	}
+}
+
+func TestDuelYieldBeforeFighting(t *testing.T) {
+
+	challenger := newTestUser(t, 9111, 9110)
+	target := newTestUser(t, 9112, 9110)
+
+	addTestDuel(t, &Duel{
+		ChallengerId: challenger.UserId,
+		TargetId:     target.UserId,
+		RoomId:       9110,
+		Wager:        20,
+		Escrow:       40,
+		State:        DuelCountdown,
+	})
+
+	// Nobody can be knocked out before the fighting starts
+	if DuelDefeat(target.UserId) {
+		t.Errorf("DuelDefeat() = true during the countdown; want false")
+	}
+
+	// Yielding now calls it off, and hands the wagers back
+	if !YieldDuel(target.UserId) {
+		t.Fatalf("YieldDuel() = false; want true")
+	}
+
+	if challenger.Character.Gold != 20 || target.Character.Gold != 20 {
+		t.Errorf("Gold is %d/%d after calling off the duel; want 20/20", challenger.Character.Gold, target.Character.Gold)
+	}
+
+	if challenger.Character.KD.TotalDuelWins != 0 || target.Character.KD.TotalDuelLosses != 0 {
+		t.Errorf("A called off duel was recorded as a win or loss")
+	}
+
+	if GetDuel(challenger.UserId) != nil {
+		t.Errorf("Duel is still going after it was called off")
+	}
+}
@@ -0,0 +1,74 @@
+package rooms
+
+import (
+	"github.com/volte6/gomud/internal/users"
+)
+
+//
+// Exploration is remembered by each character. Public maps show rooms any character has explored,
+// so those are gathered up from every character the first time they're needed.
+//
+
+var (
+	exploredRoomIds map[int]struct{} = nil
+)
+
+// Whether any character has ever been to a room
+func IsExplored(roomId int) bool {
+	loadExplored()
+	_, ok := exploredRoomIds[roomId]
+	return ok
+}
+
+// Zones that have at least one explored room
+func GetExploredZoneNames() []string {
+
+	loadExplored()
+
+	zoneNames := []string{}
+	for _, zoneName := range GetAllZoneNames() {
+		for _, roomId := range GetZoneRoomIds(zoneName) {
+			if _, ok := exploredRoomIds[roomId]; ok {
+				zoneNames = append(zoneNames, zoneName)
+				break
+			}
+		}
+	}
+
+	return zoneNames
+}
+
+func markExplored(userId int, roomId int) {
+
+	user := users.GetByUserId(userId)
+	if user == nil || !user.Character.MarkExplored(roomId) {
+		return
+	}
+
+	// If it hasn't been loaded yet, the character will be picked up when it is
+	if exploredRoomIds != nil {
+		exploredRoomIds[roomId] = struct{}{}
+	}
+}
+
+func loadExplored() {
+
+	if exploredRoomIds != nil {
+		return
+	}
+
+	exploredRoomIds = map[int]struct{}{}
+
+	addCharacter := func(u *users.UserRecord) bool {
+		for roomId := range u.Character.Explored {
+			exploredRoomIds[roomId] = struct{}{}
+		}
+		return true
+	}
+
+	for _, u := range users.GetAllActiveUsers() {
+		addCharacter(u)
+	}
+
+	users.SearchOfflineUsers(addCharacter)
+}
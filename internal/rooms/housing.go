@@ -0,0 +1,454 @@
+package rooms
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/volte6/gomud/internal/configs"
+	"github.com/volte6/gomud/internal/gametime"
+	"github.com/volte6/gomud/internal/items"
+	"github.com/volte6/gomud/internal/users"
+)
+
+//
+// Player housing.
+// Houses are rooms in the housing zone, built off of its root room when bought.
+// When a house is given up or repossessed the room is kept, and sold again to the next buyer.
+//
+
+const (
+	HousingZone = `Housing`
+
+	rentMissedLimit = 3 // How many payments can be missed before the house is repossessed
+
+	vacantHouseTitle       = `An Empty House`
+	vacantHouseDescription = `This house is empty, and waiting for a new owner.`
+	newHouseDescription    = `A cozy, sparsely furnished house. The walls are bare, and the floorboards creak underfoot.`
+)
+
+var (
+	ErrAlreadyOwnsHouse = errors.New(`you already own a house`)
+	ErrNoHousingZone    = errors.New(`there is no housing zone`)
+	ErrHouseExitTaken   = errors.New(`there is no room on the lane for a house by that name`)
+	ErrHouseLocked      = errors.New(`only the owner and their guests can go in`)
+
+	// The earliest round any rent is due, so houses aren't all loaded every round
+	nextRentRound uint64 = 0
+)
+
+type House struct {
+	OwnerUserId  int          `yaml:"owneruserid,omitempty"`  // Zero when the house is for sale
+	OwnerName    string       `yaml:"ownername,omitempty"`    // Character name of the owner
+	ExitName     string       `yaml:"exitname,omitempty"`     // Name of the exit leading in from the lane
+	Guests       []string     `yaml:"guests,omitempty"`       // Character names the owner lets in
+	Decorations  []items.Item `yaml:"decorations,omitempty"`  // Items placed by the owner. Shown in the room, and never cleaned up.
+	RentDueRound uint64       `yaml:"rentdueround,omitempty"` // When rent is next taken from the owner's bank
+	RentOwed     int          `yaml:"rentowed,omitempty"`     // Rent that couldn't be paid
+}
+
+func (h *House) IsOwner(userId int) bool {
+	return h.OwnerUserId > 0 && h.OwnerUserId == userId
+}
+
+func (h *House) IsGuest(characterName string) bool {
+	for _, name := range h.Guests {
+		if strings.EqualFold(name, characterName) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *House) AddGuest(characterName string) bool {
+	if h.IsGuest(characterName) {
+		return false
+	}
+	h.Guests = append(h.Guests, characterName)
+	sort.Strings(h.Guests)
+	return true
+}
+
+func (h *House) RemoveGuest(characterName string) bool {
+	for i, name := range h.Guests {
+		if strings.EqualFold(name, characterName) {
+			h.Guests = append(h.Guests[:i], h.Guests[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Whether a user is allowed inside. Empty houses can be looked around by anyone.
+func (h *House) CanEnter(user *users.UserRecord) bool {
+
+	if h.OwnerUserId == 0 || h.IsOwner(user.UserId) {
+		return true
+	}
+
+	if user.Permission == users.PermissionAdmin {
+		return true
+	}
+
+	return h.IsGuest(user.Character.Name)
+}
+
+// Removes a decoration by name and returns it
+func (h *House) RemoveDecoration(search string) (items.Item, bool) {
+
+	search = strings.ToLower(search)
+
+	for i, itm := range h.Decorations {
+		if strings.Contains(strings.ToLower(itm.DisplayName()), search) {
+			h.Decorations = append(h.Decorations[:i], h.Decorations[i+1:]...)
+			return itm, true
+		}
+	}
+
+	return items.Item{}, false
+}
+
+// All rooms in the housing zone that are houses, owned or not
+func GetAllHouses() []*Room {
+
+	ret := []*Room{}
+	for _, roomId := range GetZoneRoomIds(HousingZone) {
+		if room := LoadRoom(roomId); room != nil && room.House != nil {
+			ret = append(ret, room)
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].RoomId < ret[j].RoomId
+	})
+
+	return ret
+}
+
+// Returns the house a user owns, or nil
+func GetHouseByOwner(userId int) *Room {
+	for _, room := range GetAllHouses() {
+		if room.House.IsOwner(userId) {
+			return room
+		}
+	}
+	return nil
+}
+
+// Finds an owned house by the owner's name or the room id
+func FindHouse(search string) *Room {
+
+	roomId, _ := strconv.Atoi(search)
+
+	for _, room := range GetAllHouses() {
+		if room.House.OwnerUserId == 0 {
+			continue
+		}
+		if room.RoomId == roomId || strings.EqualFold(room.House.OwnerName, search) {
+			return room
+		}
+	}
+
+	return nil
+}
+
+// Gives a user a house. An empty house is reused if there is one, otherwise a new one is built.
+// Payment is up to the caller.
+func BuyHouse(user *users.UserRecord) (*Room, error) {
+
+	if GetHouseByOwner(user.UserId) != nil {
+		return nil, ErrAlreadyOwnsHouse
+	}
+
+	rootRoomId, err := GetZoneRoot(HousingZone)
+	if err != nil || rootRoomId == 0 {
+		return nil, ErrNoHousingZone
+	}
+
+	exitName, err := houseExitName(rootRoomId, user.Character.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var houseRoom *Room
+	for _, room := range GetAllHouses() {
+		if room.House.OwnerUserId == 0 {
+			houseRoom = room
+			break
+		}
+	}
+
+	if houseRoom != nil {
+		if err := ConnectRoom(rootRoomId, houseRoom.RoomId, exitName); err != nil {
+			return nil, err
+		}
+	} else {
+		if houseRoom, err = BuildRoom(rootRoomId, exitName); err != nil {
+			return nil, err
+		}
+		if err := ConnectRoom(houseRoom.RoomId, rootRoomId, `out`); err != nil {
+			return nil, err
+		}
+	}
+
+	houseRoom.Title = fmt.Sprintf(`%s's House`, user.Character.Name)
+	houseRoom.Description = newHouseDescription
+	houseRoom.Biome = `house`
+	houseRoom.MapSymbol = `H`
+	houseRoom.MapLegend = `House`
+	houseRoom.IsStorage = true
+	houseRoom.SpawnInfo = nil
+	houseRoom.House = &House{
+		OwnerUserId: user.UserId,
+		OwnerName:   user.Character.Name,
+		ExitName:    exitName,
+	}
+
+	c := configs.GetConfig()
+	if c.HouseRent > 0 {
+		houseRoom.House.RentDueRound = gametime.GetDate().AddPeriod(string(c.HouseRentPeriod))
+		if houseRoom.House.RentDueRound < nextRentRound {
+			nextRentRound = houseRoom.House.RentDueRound
+		}
+	}
+
+	SaveRoom(*houseRoom)
+
+	return houseRoom, nil
+}
+
+// Picks a name for the exit leading into a house that won't clobber an exit already on the lane.
+// The owner's name is used if it's free.
+func houseExitName(rootRoomId int, ownerName string) (string, error) {
+
+	rootRoom := LoadRoom(rootRoomId)
+	if rootRoom == nil {
+		return ``, ErrNoHousingZone
+	}
+
+	for _, exitName := range []string{strings.ToLower(ownerName), `house-` + strings.ToLower(ownerName)} {
+		if _, ok := rootRoom.Exits[exitName]; !ok {
+			return exitName, nil
+		}
+	}
+
+	return ``, ErrHouseExitTaken
+}
+
+// Takes a house away from its owner, letting them know why.
+func RepossessHouse(houseRoom *Room, reason string) {
+
+	if houseRoom.House == nil || houseRoom.House.OwnerUserId == 0 {
+		return
+	}
+
+	slog.Info("RepossessHouse()", "roomId", houseRoom.RoomId, "owner", houseRoom.House.OwnerName, "reason", reason)
+
+	mailUser(houseRoom.House.OwnerUserId, users.Message{
+		FromName: `The Realtor`,
+		Message:  fmt.Sprintf(`Your house has been repossessed: %s`, reason),
+	})
+
+	VacateHouse(houseRoom)
+}
+
+// Puts a house back up for sale.
+// Anyone inside is put out on the street. Decorations and anything left lying around are returned to the owner.
+func VacateHouse(houseRoom *Room) {
+
+	if houseRoom.House == nil || houseRoom.House.OwnerUserId == 0 {
+		return
+	}
+
+	h := houseRoom.House
+
+	rootRoomId, _ := GetZoneRoot(HousingZone)
+
+	if rootRoom := LoadRoom(rootRoomId); rootRoom != nil {
+		for exitName, exitInfo := range rootRoom.Exits {
+			if exitInfo.RoomId == houseRoom.RoomId {
+				delete(rootRoom.Exits, exitName)
+			}
+		}
+		SaveRoom(*rootRoom)
+
+		for _, userId := range houseRoom.GetPlayers() {
+			if u := users.GetByUserId(userId); u != nil {
+				u.SendText(`You are shown out of the house.`)
+			}
+			MoveToRoom(userId, rootRoomId)
+		}
+	}
+
+	leftBehind := append([]items.Item{}, h.Decorations...)
+	leftBehind = append(leftBehind, houseRoom.Items...)
+	leftBehind = append(leftBehind, houseRoom.Stash...)
+	houseRoom.Items = []items.Item{}
+	houseRoom.Stash = []items.Item{}
+
+	mail := []users.Message{}
+	for _, itm := range leftBehind {
+		returned := itm
+		mail = append(mail, users.Message{
+			FromName: `The Realtor`,
+			Message:  `Returned from your old house.`,
+			Item:     &returned,
+		})
+	}
+
+	if houseRoom.Gold > 0 {
+		mail = append(mail, users.Message{
+			FromName: `The Realtor`,
+			Message:  `Found lying around your old house.`,
+			Gold:     houseRoom.Gold,
+		})
+		houseRoom.Gold = 0
+	}
+
+	if len(mail) > 0 {
+		mailUser(h.OwnerUserId, mail...)
+	}
+
+	houseRoom.Title = vacantHouseTitle
+	houseRoom.Description = vacantHouseDescription
+	houseRoom.House = &House{}
+
+	// Signs hung by the owner go with them
+	signs := []Sign{}
+	for _, sign := range houseRoom.Signs {
+		if sign.VisibleUserId != 0 {
+			signs = append(signs, sign)
+		}
+	}
+	houseRoom.Signs = signs
+
+	SaveRoom(*houseRoom)
+}
+
+// Takes rent out of the bank of every house owner whose rent is due.
+// Owners who can't pay build up a debt, and lose the house if it gets too big.
+func ChargeHouseRent(roundNumber uint64) {
+
+	c := configs.GetConfig()
+	if c.HouseRent < 1 {
+		return
+	}
+
+	if nextRentRound != 0 && roundNumber < nextRentRound {
+		return
+	}
+
+	// Nothing is due again until the earliest of the new due dates
+	nextRentRound = math.MaxUint64
+
+	for _, houseRoom := range GetAllHouses() {
+
+		chargeRent(houseRoom, roundNumber)
+
+		if h := houseRoom.House; h.OwnerUserId != 0 && h.RentDueRound < nextRentRound {
+			nextRentRound = h.RentDueRound
+		}
+	}
+}
+
+// Charges rent for a single house if it is due
+func chargeRent(houseRoom *Room, roundNumber uint64) {
+
+	c := configs.GetConfig()
+
+	h := houseRoom.House
+	if h.OwnerUserId == 0 {
+		return
+	}
+
+	if h.RentDueRound == 0 {
+		h.RentDueRound = gametime.GetDate(roundNumber).AddPeriod(string(c.HouseRentPeriod))
+		SaveRoom(*houseRoom)
+		return
+	}
+
+	if roundNumber < h.RentDueRound {
+		return
+	}
+
+	h.RentDueRound = gametime.GetDate(h.RentDueRound).AddPeriod(string(c.HouseRentPeriod))
+
+	rent := int(c.HouseRent)
+	amountDue := rent + h.RentOwed
+
+	paid := false
+	found := withUser(h.OwnerUserId, func(u *users.UserRecord, online bool) {
+
+		if u.Character.Bank >= amountDue {
+			u.Character.Bank -= amountDue
+			h.RentOwed = 0
+			paid = true
+			u.SendText(fmt.Sprintf(`<ansi fg="yellow">Rent of <ansi fg="gold">%d gold</ansi> for your house was taken from your bank.</ansi>`, amountDue))
+			return
+		}
+
+		h.RentOwed += rent
+
+		msg := fmt.Sprintf(`You couldn't pay the rent on your house. You owe <ansi fg="gold">%d gold</ansi>. Deposit enough in the bank to cover it by the next payment, or use <ansi fg="command">house pay</ansi>.`, h.RentOwed)
+		if online {
+			u.SendText(`<ansi fg="red">` + msg + `</ansi>`)
+		} else {
+			u.Inbox.Add(users.Message{FromName: `The Realtor`, Message: msg})
+		}
+	})
+
+	if !found {
+		RepossessHouse(houseRoom, `the owner could not be found.`)
+		return
+	}
+
+	if !paid && h.RentOwed >= rent*rentMissedLimit {
+		RepossessHouse(houseRoom, fmt.Sprintf(`%d rent payments were missed.`, rentMissedLimit))
+		return
+	}
+
+	SaveRoom(*houseRoom)
+}
+
+// Runs a function against a user whether they are online or not.
+// Offline users are saved afterwards. Returns false if the user doesn't exist.
+func withUser(userId int, f func(u *users.UserRecord, online bool)) bool {
+
+	if u := users.GetByUserId(userId); u != nil {
+		f(u, true)
+		return true
+	}
+
+	var offlineUser *users.UserRecord
+	users.SearchOfflineUsers(func(u *users.UserRecord) bool {
+		if u.UserId == userId {
+			offlineUser = u
+			return false
+		}
+		return true
+	})
+
+	if offlineUser == nil {
+		return false
+	}
+
+	f(offlineUser, false)
+	users.SaveUser(*offlineUser)
+
+	return true
+}
+
+func mailUser(userId int, messages ...users.Message) {
+	withUser(userId, func(u *users.UserRecord, online bool) {
+		for _, msg := range messages {
+			u.Inbox.Add(msg)
+		}
+		if online {
+			u.SendText(`<ansi fg="yellow">You have new mail.</ansi> Type <ansi fg="command">inbox</ansi> to read it.`)
+		}
+	})
+}
@@ -0,0 +1,450 @@
+package rooms
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/volte6/gomud/internal/gametime"
+	"github.com/volte6/gomud/internal/mobs"
+	"github.com/volte6/gomud/internal/parties"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// Instanced rooms get temporary roomIds starting from here.
+	// They are never saved to disk.
+	InstanceRoomIdStart = 1000000000
+
+	defaultInstanceIdleTimeout = `5 real minutes`
+)
+
+var (
+	instances          = map[int]*ZoneInstance{}
+	nextInstanceId     = 1
+	nextInstanceRoomId = InstanceRoomIdStart
+)
+
+// A private copy of an instanced zone, owned by a party (or solo player)
+type ZoneInstance struct {
+	InstanceId      int
+	Zone            string
+	EntranceRoomId  int              // Where players are sent when the instance closes
+	UserIds         map[int]struct{} // Users that belong to this instance
+	RoomIds         map[int]int      // Original roomId => instance roomId
+	CreatedRound    uint64
+	LastResetRound  uint64
+	EmptySinceRound uint64 // When the last player left. 0 if occupied.
+}
+
+func (zi *ZoneInstance) HasUser(userId int) bool {
+	_, ok := zi.UserIds[userId]
+	return ok
+}
+
+// Makes a user part of the instance, such as when they enter it to join their party
+func (zi *ZoneInstance) AddUser(userId int) {
+	zi.UserIds[userId] = struct{}{}
+}
+
+// Returns the instance roomId for an original roomId, if it's part of this instance
+func (zi *ZoneInstance) GetRoomId(originalRoomId int) (int, bool) {
+	roomId, ok := zi.RoomIds[originalRoomId]
+	return roomId, ok
+}
+
+// Returns a list of users currently inside the instance
+func (zi *ZoneInstance) GetPlayers() []int {
+	userIds := []int{}
+	for _, roomId := range zi.RoomIds {
+		if r, ok := roomManager.rooms[roomId]; ok {
+			userIds = append(userIds, r.players...)
+		}
+	}
+	return userIds
+}
+
+// Restores the rooms of the instance to the state of the original zone.
+// Living mobs are left alone, missing ones will respawn.
+func (zi *ZoneInstance) Reset() {
+
+	for originalRoomId, roomId := range zi.RoomIds {
+
+		r, ok := roomManager.rooms[roomId]
+		if !ok {
+			continue
+		}
+
+		srcRoom := LoadRoom(originalRoomId)
+		if srcRoom == nil {
+			continue
+		}
+
+		freshRoom, err := copyRoomForInstance(srcRoom, roomId, zi)
+		if err != nil {
+			slog.Error("ZoneInstance.Reset()", "roomId", roomId, "error", err)
+			continue
+		}
+
+		r.Containers = freshRoom.Containers
+		r.Items = freshRoom.Items
+		r.Stash = freshRoom.Stash
+		r.Gold = freshRoom.Gold
+		r.Exits = freshRoom.Exits
+		r.Mutators = freshRoom.Mutators
+
+		r.Prepare(false)
+	}
+
+	zi.LastResetRound = util.GetRoundCount()
+}
+
+// Finds the instance of a zone that a user belongs to, or that their party belongs to.
+// Party members can always join up with the rest of the party, but this doesn't add them. See ZoneInstance.AddUser()
+func GetInstanceForUser(zone string, userId int) *ZoneInstance {
+
+	party := parties.Get(userId)
+	if party != nil && !party.IsMember(userId) {
+		party = nil
+	}
+
+	for _, zi := range instances {
+
+		if zi.Zone != zone {
+			continue
+		}
+
+		if zi.HasUser(userId) {
+			return zi
+		}
+
+		if party == nil {
+			continue
+		}
+
+		for _, memberId := range party.GetMembers() {
+			if zi.HasUser(memberId) {
+				return zi
+			}
+		}
+	}
+
+	return nil
+}
+
+func GetInstance(instanceId int) *ZoneInstance {
+	return instances[instanceId]
+}
+
+func GetAllInstances() []*ZoneInstance {
+	ret := []*ZoneInstance{}
+	for _, zi := range instances {
+		ret = append(ret, zi)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].InstanceId < ret[j].InstanceId })
+	return ret
+}
+
+func IsInstanceRoomId(roomId int) bool {
+	return roomId >= InstanceRoomIdStart
+}
+
+// If the roomId belongs to an instanced zone, returns the roomId of the
+// matching room in the users instance (if they have one).
+// Otherwise returns the roomId unchanged.
+func GetInstanceRoomId(userId int, roomId int) int {
+
+	if IsInstanceRoomId(roomId) {
+		return roomId
+	}
+
+	r := LoadRoom(roomId)
+	if r == nil {
+		return roomId
+	}
+
+	if zi := GetInstanceForUser(r.Zone, userId); zi != nil {
+		if instRoomId, ok := zi.GetRoomId(roomId); ok {
+			return instRoomId
+		}
+	}
+
+	return roomId
+}
+
+// Works out where a user actually ends up when moving into a room.
+// Moving into an instanced zone from outside of it creates a new instance if needed.
+func getInstanceDestination(userId int, fromRoom *Room, toRoomId int) int {
+
+	if IsInstanceRoomId(toRoomId) {
+		return toRoomId
+	}
+
+	toRoom := LoadRoom(toRoomId)
+	if toRoom == nil {
+		return toRoomId
+	}
+
+	zoneConfig := GetZoneConfig(toRoom.Zone)
+	if zoneConfig == nil || !zoneConfig.Instanced {
+		return toRoomId
+	}
+
+	zi := GetInstanceForUser(toRoom.Zone, userId)
+	if zi == nil {
+
+		entranceRoomId := fromRoom.RoomId
+		// Entering from somewhere odd (another instance, or the original zone itself)?
+		// Fall back to the start room.
+		if fromRoom.isTemporary() || fromRoom.Zone == toRoom.Zone {
+			entranceRoomId = StartRoomIdAlias
+		}
+
+		userIds := []int{userId}
+		if party := parties.Get(userId); party != nil && party.IsMember(userId) {
+			userIds = party.GetMembers()
+		}
+
+		var err error
+		if zi, err = CreateInstance(toRoom.Zone, entranceRoomId, userIds...); err != nil {
+			slog.Error("getInstanceDestination()", "zone", toRoom.Zone, "error", err)
+			return toRoomId
+		}
+	}
+
+	if instRoomId, ok := zi.GetRoomId(toRoomId); ok {
+		return instRoomId
+	}
+
+	return toRoomId
+}
+
+// Creates a private copy of every room in a zone.
+func CreateInstance(zone string, entranceRoomId int, userIds ...int) (*ZoneInstance, error) {
+
+	zoneInfo, ok := roomManager.zones[zone]
+	if !ok {
+		return nil, fmt.Errorf(`zone %s does not exist`, zone)
+	}
+
+	if len(zoneInfo.RoomIds) == 0 {
+		return nil, errors.New(`zone has no rooms`)
+	}
+
+	roundNow := util.GetRoundCount()
+
+	zi := &ZoneInstance{
+		InstanceId:     nextInstanceId,
+		Zone:           zone,
+		EntranceRoomId: entranceRoomId,
+		UserIds:        map[int]struct{}{},
+		RoomIds:        map[int]int{},
+		CreatedRound:   roundNow,
+		LastResetRound: roundNow,
+	}
+	nextInstanceId++
+
+	for _, userId := range userIds {
+		zi.UserIds[userId] = struct{}{}
+	}
+
+	// Allocate all of the roomIds first so that exits can be remapped
+	for roomId := range zoneInfo.RoomIds {
+		zi.RoomIds[roomId] = nextInstanceRoomId
+		nextInstanceRoomId++
+	}
+
+	for originalRoomId, roomId := range zi.RoomIds {
+
+		srcRoom := LoadRoom(originalRoomId)
+		if srcRoom == nil {
+			delete(zi.RoomIds, originalRoomId)
+			continue
+		}
+
+		newRoom, err := copyRoomForInstance(srcRoom, roomId, zi)
+		if err != nil {
+			slog.Error("CreateInstance()", "roomId", originalRoomId, "error", err)
+			delete(zi.RoomIds, originalRoomId)
+			continue
+		}
+
+		addInstanceRoomToMemory(newRoom)
+	}
+
+	instances[zi.InstanceId] = zi
+
+	slog.Info("CreateInstance()", "instanceId", zi.InstanceId, "zone", zone, "roomCount", len(zi.RoomIds), "userIds", userIds)
+
+	return zi, nil
+}
+
+// Removes an instance from the world. Anyone still inside is returned to the entrance.
+func DestroyInstance(instanceId int) {
+
+	zi, ok := instances[instanceId]
+	if !ok {
+		return
+	}
+
+	for _, roomId := range zi.RoomIds {
+
+		r, ok := roomManager.rooms[roomId]
+		if !ok {
+			continue
+		}
+
+		for _, userId := range r.GetPlayers() {
+			if user := users.GetByUserId(userId); user != nil {
+				user.SendText(`<ansi fg="yellow">The area around you fades away, and you find yourself back where you started.</ansi>`)
+
+				MoveToRoom(userId, zi.EntranceRoomId)
+
+				if entranceRoom := LoadRoom(user.Character.RoomId); entranceRoom != nil {
+					for _, mobInstId := range r.GetMobs(FindCharmed) {
+						if mob := mobs.GetInstance(mobInstId); mob != nil && mob.Character.IsCharmed(userId) {
+							r.RemoveMob(mobInstId)
+							entranceRoom.AddMob(mobInstId)
+						}
+					}
+				}
+
+				user.Command(`look`)
+			}
+		}
+
+		for _, mobInstanceId := range r.mobs {
+			mobs.DestroyInstance(mobInstanceId)
+		}
+
+		for _, spawnDetails := range r.SpawnInfo {
+			if spawnDetails.InstanceId > 0 {
+				if m := mobs.GetInstance(spawnDetails.InstanceId); m != nil && m.Character.RoomId == r.RoomId {
+					mobs.DestroyInstance(spawnDetails.InstanceId)
+				}
+			}
+		}
+
+		delete(roomManager.roomsWithUsers, roomId)
+		delete(roomManager.roomsWithMobs, roomId)
+		delete(roomManager.rooms, roomId)
+	}
+
+	delete(instances, instanceId)
+
+	slog.Info("DestroyInstance()", "instanceId", instanceId, "zone", zi.Zone)
+}
+
+// Handles resetting and cleaning up instances
+func instanceMaintenance(roundNow uint64) {
+
+	for _, zi := range instances {
+
+		zoneConfig := GetZoneConfig(zi.Zone)
+		if zoneConfig == nil {
+			DestroyInstance(zi.InstanceId)
+			continue
+		}
+
+		if len(zi.GetPlayers()) > 0 {
+			zi.EmptySinceRound = 0
+		} else if zi.EmptySinceRound == 0 {
+			zi.EmptySinceRound = roundNow
+		}
+
+		// Empty for too long?
+		if zi.EmptySinceRound > 0 {
+			idleTimeout := zoneConfig.InstanceIdleTimeout
+			if idleTimeout == `` {
+				idleTimeout = defaultInstanceIdleTimeout
+			}
+			if roundNow >= gametime.GetDate(zi.EmptySinceRound).AddPeriod(idleTimeout) {
+				DestroyInstance(zi.InstanceId)
+				continue
+			}
+		}
+
+		// Has it been around too long?
+		if zoneConfig.InstanceLifetime != `` {
+			if roundNow >= gametime.GetDate(zi.CreatedRound).AddPeriod(zoneConfig.InstanceLifetime) {
+				DestroyInstance(zi.InstanceId)
+				continue
+			}
+		}
+
+		if zoneConfig.InstanceReset != `` {
+			if roundNow >= gametime.GetDate(zi.LastResetRound).AddPeriod(zoneConfig.InstanceReset) {
+				zi.Reset()
+			}
+		}
+	}
+
+}
+
+// Makes a deep copy of a room under a new roomId, without anyone in it
+func copyRoom(srcRoom *Room, roomId int) (*Room, error) {
+
+	tmpRoom := *srcRoom
+	tmpRoom.Description = srcRoom.GetDescription()
+
+	data, err := yaml.Marshal(&tmpRoom)
+	if err != nil {
+		return nil, err
+	}
+
+	newRoom := &Room{}
+	if err = yaml.Unmarshal(data, newRoom); err != nil {
+		return nil, err
+	}
+
+	newRoom.RoomId = roomId
+	newRoom.instanceSourceRoomId = srcRoom.RoomId
+	newRoom.players = []int{}
+	newRoom.mobs = []int{}
+	newRoom.visitors = make(map[VisitorType]map[int]uint64)
+	newRoom.tempDataStore = make(map[string]any)
+	newRoom.Effects = map[EffectType]AreaEffect{}
+	newRoom.lastVisited = util.GetRoundCount()
+
+	return newRoom, nil
+}
+
+// Makes a deep copy of a room, with any exits leading within the zone pointed at the instance
+func copyRoomForInstance(srcRoom *Room, roomId int, zi *ZoneInstance) (*Room, error) {
+
+	newRoom, err := copyRoom(srcRoom, roomId)
+	if err != nil {
+		return nil, err
+	}
+
+	newRoom.instanceId = zi.InstanceId
+
+	for exitName, exitInfo := range newRoom.Exits {
+		if instRoomId, ok := zi.RoomIds[exitInfo.RoomId]; ok {
+			exitInfo.RoomId = instRoomId
+			newRoom.Exits[exitName] = exitInfo
+		}
+	}
+
+	// Clears the ZoneConfig (the original root room still holds it) and validates items etc.
+	if err = newRoom.Validate(); err != nil {
+		return nil, err
+	}
+
+	return newRoom, nil
+}
+
+// Instance and arena rooms only live in memory, and don't belong to the zone index.
+func addInstanceRoomToMemory(r *Room) {
+
+	roomManager.rooms[r.RoomId] = r
+
+	hash := util.Hash(r.Description)
+	if _, ok := roomManager.roomDescriptionCache[hash]; !ok {
+		roomManager.roomDescriptionCache[hash] = r.Description
+	}
+	r.Description = fmt.Sprintf(`h:%s`, hash)
+}
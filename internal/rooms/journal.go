@@ -0,0 +1,191 @@
+package rooms
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/volte6/gomud/internal/audit"
+	"github.com/volte6/gomud/internal/exit"
+	"gopkg.in/yaml.v2"
+)
+
+//
+// Room and zone snapshots for the builder journal.
+// Only the parts a builder edits are kept. Items, gold, signs, houses and container contents
+// come and go during play, so they are left out and left alone when undoing.
+//
+
+// Returns yaml snapshots of the room and, if it is a zone root, its zone config.
+func builderSnapshot(r Room) (roomSnapshot string, zoneSnapshot string) {
+
+	if strings.HasPrefix(r.Description, `h:`) {
+		hash := strings.TrimPrefix(r.Description, `h:`)
+		if description, ok := roomManager.roomDescriptionCache[hash]; ok {
+			r.Description = description
+		}
+	}
+
+	if r.ZoneConfig.RoomId != 0 {
+		zoneSnapshot = audit.Snapshot(r.ZoneConfig)
+	}
+
+	r.ZoneConfig = ZoneConfig{}
+	r.Items = nil
+	r.Stash = nil
+	r.Gold = 0
+	r.Signs = nil
+	r.LongTermDataStore = nil
+	r.House = nil
+
+	containers := map[string]Container{}
+	for name, container := range r.Containers {
+		// Temporary chests aren't part of the room
+		if container.DespawnRound > 0 {
+			continue
+		}
+		container.Items = nil
+		container.Gold = 0
+		containers[name] = container
+	}
+	r.Containers = containers
+
+	return audit.Snapshot(r), zoneSnapshot
+}
+
+// Journals a room being saved, comparing it to what is on disk now.
+func journalRoomSave(r Room, roomFilePath string) {
+
+	if !audit.Active() {
+		return
+	}
+
+	beforeRoom, beforeZone := ``, ``
+	if oldData, err := os.ReadFile(roomFilePath); err == nil {
+		oldRoom := Room{}
+		if err := yaml.Unmarshal(oldData, &oldRoom); err == nil {
+			// Fill in the same defaults the live room got when it loaded
+			oldRoom.Validate()
+			beforeRoom, beforeZone = builderSnapshot(oldRoom)
+		}
+	}
+
+	afterRoom, afterZone := builderSnapshot(r)
+
+	audit.Capture(audit.KindRoom, strconv.Itoa(r.RoomId), beforeRoom, afterRoom)
+	audit.Capture(audit.KindZone, r.Zone, beforeZone, afterZone)
+}
+
+func GetRoomSnapshot(roomId int) (string, error) {
+
+	room := LoadRoom(roomId)
+	if room == nil || room.isTemporary() {
+		return ``, fmt.Errorf(`room %d does not exist`, roomId)
+	}
+
+	roomSnapshot, _ := builderSnapshot(*room)
+	return roomSnapshot, nil
+}
+
+// Puts the builder edited parts of a room back to how they were in a snapshot, and saves it.
+func RestoreRoomSnapshot(roomId int, snapshot string) error {
+
+	room := LoadRoom(roomId)
+	if room == nil || room.isTemporary() {
+		return fmt.Errorf(`room %d does not exist`, roomId)
+	}
+
+	oldRoom := Room{}
+	if err := yaml.Unmarshal([]byte(snapshot), &oldRoom); err != nil {
+		return err
+	}
+
+	if oldRoom.RoomId != roomId {
+		return fmt.Errorf(`snapshot is for room %d, not room %d`, oldRoom.RoomId, roomId)
+	}
+
+	if oldRoom.Zone != room.Zone {
+		if err := MoveToZone(roomId, oldRoom.Zone); err != nil {
+			return fmt.Errorf(`could not move room back to %s: %w`, oldRoom.Zone, err)
+		}
+	}
+
+	room.IsBank = oldRoom.IsBank
+	room.IsStorage = oldRoom.IsStorage
+	room.IsCharacterRoom = oldRoom.IsCharacterRoom
+	room.Title = oldRoom.Title
+	room.Description = oldRoom.Description
+	room.MapSymbol = oldRoom.MapSymbol
+	room.MapLegend = oldRoom.MapLegend
+	room.Biome = oldRoom.Biome
+	room.Coordinates = oldRoom.Coordinates
+	room.Exits = oldRoom.Exits
+	room.Nouns = oldRoom.Nouns
+	room.SpawnInfo = oldRoom.SpawnInfo
+	room.SkillTraining = oldRoom.SkillTraining
+	room.IdleMessages = oldRoom.IdleMessages
+	room.Mutators = oldRoom.Mutators
+	room.Pvp = oldRoom.Pvp
+
+	if room.Exits == nil {
+		room.Exits = map[string]exit.RoomExit{}
+	}
+
+	// Containers keep whatever is in them now
+	containers := map[string]Container{}
+	for name, container := range room.Containers {
+		if container.DespawnRound > 0 {
+			containers[name] = container
+		}
+	}
+	for name, container := range oldRoom.Containers {
+		if current, ok := room.Containers[name]; ok {
+			container.Items = current.Items
+			container.Gold = current.Gold
+		}
+		containers[name] = container
+	}
+	room.Containers = containers
+
+	return SaveRoom(*room)
+}
+
+func GetZoneSnapshot(zone string) (string, error) {
+
+	zoneConfig := GetZoneConfig(zone)
+	if zoneConfig == nil {
+		return ``, fmt.Errorf(`zone %s does not exist`, zone)
+	}
+
+	return audit.Snapshot(*zoneConfig), nil
+}
+
+// Puts a zone config back to how it was in a snapshot, and saves the root room.
+func RestoreZoneSnapshot(zone string, snapshot string) error {
+
+	zoneInfo, ok := roomManager.zones[zone]
+	if !ok {
+		return fmt.Errorf(`zone %s does not exist`, zone)
+	}
+
+	room := LoadRoom(zoneInfo.RootRoomId)
+	if room == nil {
+		return fmt.Errorf(`zone %s has no root room`, zone)
+	}
+
+	oldConfig := ZoneConfig{}
+	if err := yaml.Unmarshal([]byte(snapshot), &oldConfig); err != nil {
+		return err
+	}
+
+	if oldConfig.RoomId != room.RoomId {
+		return errors.New(`the zone root room has changed since then`)
+	}
+
+	oldConfig.Validate()
+	room.ZoneConfig = oldConfig
+
+	return SaveRoom(*room)
+}
@@ -8,10 +8,10 @@ func GetMemoryUsage() map[string]util.MemoryResult {
 
 	ret := map[string]util.MemoryResult{}
 
-	ret["rooms"] = util.MemoryResult{util.MemoryUsage(roomManager.rooms), len(roomManager.rooms)}
-	ret["zones"] = util.MemoryResult{util.MemoryUsage(roomManager.zones), len(roomManager.zones)}
-	ret["roomsWithUsers"] = util.MemoryResult{util.MemoryUsage(roomManager.roomsWithUsers), len(roomManager.roomsWithUsers)}
-	ret["roomIdToFileCache"] = util.MemoryResult{util.MemoryUsage(roomManager.roomIdToFileCache), len(roomManager.roomIdToFileCache)}
+	ret["rooms"] = util.MemoryResult{Memory: util.MemoryUsage(roomManager.rooms), Count: len(roomManager.rooms)}
+	ret["zones"] = util.MemoryResult{Memory: util.MemoryUsage(roomManager.zones), Count: len(roomManager.zones)}
+	ret["roomsWithUsers"] = util.MemoryResult{Memory: util.MemoryUsage(roomManager.roomsWithUsers), Count: len(roomManager.roomsWithUsers)}
+	ret["roomIdToFileCache"] = util.MemoryResult{Memory: util.MemoryUsage(roomManager.roomIdToFileCache), Count: len(roomManager.roomIdToFileCache)}
 
 	return ret
 }
@@ -0,0 +1,285 @@
+package rooms
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/volte6/gomud/internal/characters"
+	"github.com/volte6/gomud/internal/items"
+	"github.com/volte6/gomud/internal/races"
+	"github.com/volte6/gomud/internal/skills"
+	"github.com/volte6/gomud/internal/util"
+)
+
+type MovementMode string
+
+const (
+	MovementWalk    MovementMode = `walk`
+	MovementSwim    MovementMode = `swim`
+	MovementClimb   MovementMode = `climb`
+	MovementFly     MovementMode = `fly`
+	MovementSqueeze MovementMode = `squeeze`
+
+	DrowningBuffId = 43
+	SoakedBuffId   = 40
+)
+
+type MovementInfo struct {
+	ActionCost      int             // Action points it takes. Walking is 10.
+	Stat            string          // Stat rolled against to make it. Empty means no roll.
+	Difficulty      int             // Subtracted from the chance to make it
+	FlyingPasses    bool            // Anything that can fly gets through without a roll
+	BreathingPasses bool            // Anything that can breathe water gets through without a roll
+	NeedsFlight     bool            // Only fliers can go this way at all
+	MaxSize         races.Size      // Anything bigger doesn't fit. Empty means no limit.
+	Skill           skills.SkillTag // Skill that helps. Each level adds 10 to the chance to make it.
+	SkillLevel      int             // Skill level needed to try at all. Zero means anyone can try.
+	FailDamage      string          // Dice roll of damage taken on failing, such as from a fall
+	FailBuffId      int             // Buff given on failing, such as drowning
+	FailPushBack    bool            // Failing leaves them where they started. Otherwise they still make it, but suffer for it.
+	SuccessBuffId   int             // Buff given on making it, such as being soaked
+	// Messages. The user messages get the exit name, the room messages get the character name then the exit name.
+	SuccessText     string
+	FailText        string
+	BlockedText     string
+	RoomSuccessText string
+	RoomFailText    string
+}
+
+var (
+	MovementModes = map[MovementMode]MovementInfo{
+		MovementWalk: {
+			ActionCost: 10,
+		},
+		MovementSwim: {
+			ActionCost:      20,
+			Stat:            `speed`,
+			Difficulty:      10,
+			FlyingPasses:    true,
+			BreathingPasses: true,
+			Skill:           skills.Swimming,
+			SkillLevel:      1,
+			FailBuffId:      DrowningBuffId,
+			FailPushBack:    true,
+			SuccessBuffId:   SoakedBuffId,
+			SuccessText:     `You swim towards the <ansi fg="exit">%s</ansi> exit.`,
+			FailText:        `You try to swim towards the <ansi fg="exit">%s</ansi> exit, but the water drags you under and pushes you back!`,
+			BlockedText:     `You'd need to know how to <ansi fg="skill">swim</ansi> to go <ansi fg="exit">%s</ansi>.`,
+			RoomSuccessText: `<ansi fg="username">%s</ansi> swims towards the <ansi fg="exit">%s</ansi> exit.`,
+			RoomFailText:    `<ansi fg="username">%s</ansi> tries to swim towards the <ansi fg="exit">%s</ansi> exit, but is dragged under and washed back.`,
+		},
+		MovementClimb: {
+			ActionCost:      25,
+			Stat:            `strength`,
+			Difficulty:      10,
+			FlyingPasses:    true,
+			Skill:           skills.Climbing,
+			SkillLevel:      1,
+			FailDamage:      `2d6`,
+			FailPushBack:    true,
+			SuccessText:     `You climb towards the <ansi fg="exit">%s</ansi> exit.`,
+			FailText:        `You try to climb towards the <ansi fg="exit">%s</ansi> exit, but lose your grip and fall!`,
+			BlockedText:     `You'd need to know how to <ansi fg="skill">climb</ansi> to go <ansi fg="exit">%s</ansi>.`,
+			RoomSuccessText: `<ansi fg="username">%s</ansi> climbs towards the <ansi fg="exit">%s</ansi> exit.`,
+			RoomFailText:    `<ansi fg="username">%s</ansi> tries to climb towards the <ansi fg="exit">%s</ansi> exit, but loses their grip and falls.`,
+		},
+		MovementFly: {
+			ActionCost:      15,
+			NeedsFlight:     true,
+			SuccessText:     `You fly towards the <ansi fg="exit">%s</ansi> exit.`,
+			BlockedText:     `You'd need to fly to go <ansi fg="exit">%s</ansi>.`,
+			RoomSuccessText: `<ansi fg="username">%s</ansi> flies towards the <ansi fg="exit">%s</ansi> exit.`,
+		},
+		MovementSqueeze: {
+			ActionCost:      20,
+			Stat:            `speed`,
+			Difficulty:      0,
+			MaxSize:         races.Medium,
+			Skill:           skills.Skulduggery,
+			FailPushBack:    true,
+			SuccessText:     `You squeeze through towards the <ansi fg="exit">%s</ansi> exit.`,
+			FailText:        `You try to squeeze through towards the <ansi fg="exit">%s</ansi> exit, but get stuck and have to back out.`,
+			BlockedText:     `You're far too big to squeeze through the <ansi fg="exit">%s</ansi> exit.`,
+			RoomSuccessText: `<ansi fg="username">%s</ansi> squeezes through towards the <ansi fg="exit">%s</ansi> exit.`,
+			RoomFailText:    `<ansi fg="username">%s</ansi> gets stuck trying to squeeze through the <ansi fg="exit">%s</ansi> exit.`,
+		},
+	}
+)
+
+func GetMovement(mode MovementMode) (MovementInfo, bool) {
+	info, ok := MovementModes[MovementMode(strings.ToLower(string(mode)))]
+	return info, ok
+}
+
+// How an exit is traveled.
+// Mutators on the room it leads to come first (a frozen lake can be walked across),
+// then the exit itself, then the biome of the room it leads to.
+func (r *Room) GetExitMovement(exitName string) MovementMode {
+
+	exitInfo, ok := r.GetExitInfo(exitName)
+	if !ok {
+		return MovementWalk
+	}
+
+	destRoom := LoadRoom(exitInfo.RoomId)
+	if destRoom != nil {
+		for mut := range destRoom.ActiveMutators {
+			if spec := mut.GetSpec(); spec.Movement != `` {
+				return MovementMode(strings.ToLower(spec.Movement))
+			}
+		}
+	}
+
+	if exitInfo.Movement != `` {
+		return MovementMode(strings.ToLower(exitInfo.Movement))
+	}
+
+	if destRoom != nil {
+		return destRoom.GetBiome().Movement()
+	}
+
+	return MovementWalk
+}
+
+// Changes how an exit is traveled. An empty mode goes back to using the biome it leads to.
+// Setting it to walk lets a bridge cross deep water, for example.
+func (r *Room) SetExitMovement(exitName string, mode MovementMode) bool {
+
+	exitInfo, ok := r.Exits[exitName]
+	if !ok {
+		return false
+	}
+
+	exitInfo.Movement = string(mode)
+	r.Exits[exitName] = exitInfo
+
+	return true
+}
+
+type MovementResult struct {
+	Mode     MovementMode
+	Info     MovementInfo
+	Blocked  bool       // Could never have made it (no wings, too big, untrained)
+	Failed   bool       // Tried and failed
+	Damage   int        // Damage taken from failing
+	UsedItem items.Item // Item that got them through, if any
+}
+
+// Whether anything at all stops the character from moving this way.
+// Mobs only check this, since they don't roll.
+func (m MovementInfo) IsBlocked(c *characters.Character) bool {
+
+	if m.NeedsFlight && !c.CanFly() {
+		return true
+	}
+
+	if m.MaxSize != `` {
+		if r := races.GetRace(c.RaceId); r != nil && sizeRank(r.Size) > sizeRank(m.MaxSize) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Works out whether a character makes it moving a certain way.
+// The biome of where they are going can let them through if they carry its required item.
+// Nothing is applied to the character here, that is up to the caller.
+func AttemptMovement(c *characters.Character, mode MovementMode, destBiome BiomeInfo) MovementResult {
+
+	info, ok := GetMovement(mode)
+	if !ok {
+		mode = MovementWalk
+		info = MovementModes[MovementWalk]
+	}
+
+	result := MovementResult{
+		Mode: mode,
+		Info: info,
+	}
+
+	if info.IsBlocked(c) {
+		result.Blocked = true
+		return result
+	}
+
+	if info.Stat == `` {
+		return result
+	}
+
+	// Fliers just fly over it instead
+	if info.FlyingPasses && c.CanFly() {
+		result.Mode = MovementFly
+		result.Info = MovementModes[MovementFly]
+		return result
+	}
+
+	if info.BreathingPasses && c.CanBreatheWater() {
+		return result
+	}
+
+	// Boats, climbing gear and so on
+	if destBiome.Movement() == mode && destBiome.RequiredItemId() > 0 {
+		for _, itm := range c.GetAllBackpackItems() {
+			if itm.ItemId == destBiome.RequiredItemId() {
+				result.UsedItem = itm
+				return result
+			}
+		}
+	}
+
+	skillLevel := 0
+	if info.Skill != `` {
+		skillLevel = c.GetSkillLevel(info.Skill)
+	}
+
+	if skillLevel < info.SkillLevel {
+		result.Blocked = true
+		return result
+	}
+
+	targetVal := 50 + statValue(c, info.Stat) + (skillLevel * 10) - info.Difficulty
+	roll := util.Rand(100)
+
+	util.LogRoll(fmt.Sprintf(`Movement (%s)`, mode), roll, targetVal)
+
+	if roll < targetVal {
+		return result
+	}
+
+	result.Failed = true
+	if info.FailDamage != `` {
+		attacks, dCount, dSides, bonus, _ := util.ParseDiceRoll(info.FailDamage)
+		result.Damage = util.RollDice(attacks*dCount, dSides) + bonus
+	}
+
+	return result
+}
+
+func statValue(c *characters.Character, statName string) int {
+	switch statName {
+	case `strength`:
+		return c.Stats.Strength.ValueAdj
+	case `speed`:
+		return c.Stats.Speed.ValueAdj
+	case `smarts`:
+		return c.Stats.Smarts.ValueAdj
+	case `vitality`:
+		return c.Stats.Vitality.ValueAdj
+	case `mysticism`:
+		return c.Stats.Mysticism.ValueAdj
+	case `perception`:
+		return c.Stats.Perception.ValueAdj
+	}
+	return 0
+}
+
+func sizeRank(s races.Size) int {
+	switch s {
+	case races.Small:
+		return 1
+	case races.Large:
+		return 3
+	}
+	return 2
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/volte6/gomud/internal/term"
 	"github.com/volte6/gomud/internal/users"
 	"github.com/volte6/gomud/internal/util"
+	"github.com/volte6/gomud/internal/weather"
 )
 
 type RoomTemplateDetails struct {
@@ -98,6 +99,10 @@ func GetDetails(r *Room, user *users.UserRecord) RoomTemplateDetails {
 		details.RoomAlerts = append(details.RoomAlerts, ` <ansi fg="yellow-bold">This is an item storage location!</ansi> Type <ansi fg="command">storage</ansi> to store/unstore.`)
 	}
 
+	if r.House != nil && r.House.IsOwner(user.UserId) {
+		details.RoomAlerts = append(details.RoomAlerts, `            <ansi fg="yellow-bold">This is your house!</ansi> Type <ansi fg="command">house</ansi> to manage it.`)
+	}
+
 	if r.IsCharacterRoom {
 		details.RoomAlerts = append(details.RoomAlerts, `      <ansi fg="yellow-bold">This is a character room!</ansi> Type <ansi fg="command">character</ansi> to interact.`)
 	}
@@ -164,6 +169,24 @@ func GetDetails(r *Room, user *users.UserRecord) RoomTemplateDetails {
 		details.Description = colorpatterns.ApplyColorPattern(details.Description, `flame`, colorpatterns.Words)
 	}
 
+	// Weather is always appended to the description
+	if wInfo, ok := weather.GetConditionInfo(r.GetWeather()); ok && wInfo.Description != `` {
+		details.Description = details.Description +
+			term.CRLFStr +
+			colorpatterns.ApplyColorPattern(wInfo.Description, wInfo.ColorPattern)
+	}
+
+	// Decorations in a house are part of the room, so they go with the description
+	if r.House != nil && len(r.House.Decorations) > 0 {
+		decorationNames := []string{}
+		for _, itm := range r.House.Decorations {
+			decorationNames = append(decorationNames, `<ansi fg="itemname">`+itm.DisplayName()+`</ansi>`)
+		}
+		details.Description = details.Description +
+			term.CRLFStr +
+			`On display here: ` + strings.Join(decorationNames, `, `) + `.`
+	}
+
 	for mut := range r.ActiveMutators {
 		mutSpec := mut.GetSpec()
 
@@ -17,6 +17,7 @@ type roomNode struct {
 	SecretExits map[string]struct{} // Just a flag for whether an exit key is secret
 	xPos        int                 // Its x position relative to the root node
 	yPos        int                 // Its y position relative to the root node
+	zPos        int                 // Its z level relative to the root node. Only rooms with Coordinates leave level 0.
 	Sprawl      int                 // how far from the start point this is
 	MobIds      []int               // all mob instance ids in this room
 	UserIds     []int               // all user ids in this room
@@ -43,6 +44,7 @@ type RoomGraph struct {
 	maxSprawl       int                    // The maximum node distance to search
 	roomLimits      map[int]struct{}       // An optional list of room ids that the map should be restricted to (if any).
 	forceRoomSymbol map[int]symbolOverride // An optional list of room ids that the map should be restricted to (if any).
+	origin          *Coordinates           // Coordinates of the root room, if it has any. Rooms with Coordinates are placed relative to this.
 }
 type foundRoomExits struct {
 	roomNode *roomNode
@@ -254,6 +256,13 @@ func (r *RoomGraph) RoomIdsWithMobs() []int {
 	return allRoomIds
 }
 
+// Only crawl through these rooms. Rooms they lead to are still included, but go no further.
+func (r *RoomGraph) LimitToRooms(roomIds ...int) {
+	for _, roomId := range roomIds {
+		r.roomLimits[roomId] = struct{}{}
+	}
+}
+
 // Whatever the room normally shows, it will show this instead.
 func (r *RoomGraph) AddRoomSymbolOverrides(symbol rune, legend string, roomIds ...int) {
 	for _, roomId := range roomIds {
@@ -322,6 +331,10 @@ func (r *RoomGraph) Generate2DMap(mapMaxWidth int, mapMaxHeight int, centerRoomI
 		if r.maxSprawl > 0 && roomNode.Sprawl > r.maxSprawl {
 			continue
 		}
+		// Only the level the center room is on
+		if roomNode.zPos != centerRoom.zPos {
+			continue
+		}
 		if boundaryCheck(roomNode.xPos, roomNode.yPos, xStart, xEnd, yStart, yEnd) {
 
 			symbol := roomNode.Symbol
@@ -459,9 +472,15 @@ func (r *RoomGraph) addNode(sourceRoomNode *roomNode, direction string, roomId i
 	newRoomNode.Sprawl = sourceRoomNode.Sprawl + 1
 
 	// Track the position relative to the source room, for the new room.
-	if exitDelta, ok := DirectionDeltas[direction]; ok {
+	// Rooms with saved Coordinates go exactly where they say they are.
+	if r.origin != nil && newRoomData.Coordinates != nil {
+		newRoomNode.xPos = newRoomData.Coordinates.X - r.origin.X
+		newRoomNode.yPos = newRoomData.Coordinates.Y - r.origin.Y
+		newRoomNode.zPos = newRoomData.Coordinates.Z - r.origin.Z
+	} else if exitDelta, ok := DirectionDeltas[direction]; ok {
 		newRoomNode.xPos += sourceRoomNode.xPos + exitDelta.Dx
 		newRoomNode.yPos += sourceRoomNode.yPos + exitDelta.Dy
+		newRoomNode.zPos = sourceRoomNode.zPos
 	}
 
 	// Mark it as tracked so that we don't recurse into it again
@@ -492,6 +511,13 @@ func (r *RoomGraph) addNode(sourceRoomNode *roomNode, direction string, roomId i
 	r.width = r.maxX - r.minX + 1
 	r.height = r.maxY - r.minY + 1
 
+	// Rooms outside of the limits are shown, but not crawled any further
+	if len(r.roomLimits) > 0 {
+		if _, ok := r.roomLimits[newRoomNode.RoomId]; !ok {
+			return nil
+		}
+	}
+
 	// Finally, we crawl the new room's exit data
 	// If they don't sit outside the boundaries of the graph, we add them to the returned stack items
 	newRoomsToAdd := make(map[string]exit.RoomExit)
@@ -505,6 +531,9 @@ func (r *RoomGraph) addNode(sourceRoomNode *roomNode, direction string, roomId i
 		if exitInfo.MapDirection != `` {
 			exitDirection = exitInfo.MapDirection
 		}
+		if !r.canCrawl(exitDirection, exitInfo.RoomId) {
+			continue
+		}
 		if dInfo, ok := DirectionDeltas[exitDirection]; ok {
 			// Make sure it stays within boundaries
 			if graphMaxedWidth {
@@ -517,10 +546,10 @@ func (r *RoomGraph) addNode(sourceRoomNode *roomNode, direction string, roomId i
 					continue
 				}
 			}
-
-			// Contains .RoomId and .Secret
-			newRoomsToAdd[exitDirection] = newRoomData.Exits[realExitDirection]
 		}
+
+		// Contains .RoomId and .Secret
+		newRoomsToAdd[exitDirection] = newRoomData.Exits[realExitDirection]
 	}
 
 	/*
@@ -555,6 +584,19 @@ func (r *RoomGraph) addNode(sourceRoomNode *roomNode, direction string, roomId i
 
 }
 
+// Whether an exit should be followed when building the graph.
+// Up/down exits are only followed when both ends have Coordinates, since otherwise there is no way to tell where the room sits.
+func (r *RoomGraph) canCrawl(direction string, toRoomId int) bool {
+	if _, ok := DirectionDeltas[direction]; ok {
+		return true
+	}
+	if _, ok := VerticalDeltas[direction]; !ok || r.origin == nil {
+		return false
+	}
+	toRoom := LoadRoom(toRoomId)
+	return toRoom != nil && toRoom.Coordinates != nil
+}
+
 func (r *RoomGraph) Build(rootRoomId int, overrideRoomIdSymbols map[int]rune) error {
 
 	if r.Root != nil {
@@ -584,6 +626,11 @@ func (r *RoomGraph) Build(rootRoomId int, overrideRoomIdSymbols map[int]rune) er
 	r.Root = newRoomNode                          // Make it the root.
 	r.trackedRoomIds[r.Root.RoomId] = newRoomNode // Mark it tracked
 
+	if roomNow.Coordinates != nil {
+		origin := *roomNow.Coordinates
+		r.origin = &origin
+	}
+
 	roomStack := make([]*foundRoomExits, 0, 100)
 
 	// Now start the crawl by adding exits.
@@ -593,7 +640,7 @@ func (r *RoomGraph) Build(rootRoomId int, overrideRoomIdSymbols map[int]rune) er
 			directionName = exitInfo.MapDirection
 		}
 
-		if _, ok := DirectionDeltas[directionName]; ok {
+		if r.canCrawl(directionName, exitInfo.RoomId) {
 			if addlExits := r.addNode(r.Root, directionName, exitInfo.RoomId, exitInfo.Secret); addlExits != nil {
 				roomStack = append(roomStack, addlExits)
 			}
@@ -651,7 +698,7 @@ func (r *RoomGraph) Changed() bool {
 	}
 
 	if totalRooms == len(r.trackedRoomIds) {
-		slog.Info("RoomGraph::Changed()", "Updated needed, mismatched room counts", "totalRooms", totalRooms, "trackedRoomIds", len(r.trackedRoomIds))
+		slog.Info("RoomGraph::Changed()", "reason", "Updated needed, mismatched room counts", "totalRooms", totalRooms, "trackedRoomIds", len(r.trackedRoomIds))
 		//	return true
 	}
 
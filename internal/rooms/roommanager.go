@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -37,6 +38,7 @@ var (
 		roomsWithMobs:        make(map[int]int),
 		roomDescriptionCache: make(map[string]string),
 		roomIdToFileCache:    make(map[int]string),
+		authoredRooms:        make(map[int][]byte),
 	}
 )
 
@@ -48,6 +50,7 @@ type RoomManager struct {
 	topRoomItems         []int               // list of the top room items
 	roomDescriptionCache map[string]string   // key is a hash, value is the description
 	roomIdToFileCache    map[int]string      // key is room id, value is the file path
+	authoredRooms        map[int][]byte      // key is room id, value is the room as it was first loaded from disk. Used by zone resets.
 }
 
 const (
@@ -61,6 +64,7 @@ type ZoneInfo struct {
 	DefaultBiome    string // city, swamp etc. see biomes.go
 	HasZoneMutators bool   // does it have any zone mutators assigned?
 	RoomIds         map[int]struct{}
+	LastResetRound  uint64 // when the zone was last reset (0 if never)
 }
 
 func GetNextRoomId() int {
@@ -218,6 +222,11 @@ func RoomMaintenance() bool {
 			continue
 		}
 
+		// Instance and arena rooms are cleaned up with their instance or match
+		if room.isTemporary() {
+			continue
+		}
+
 		// Consider unloading rooms from memory?
 		if roundCount%roomUnloadTimeoutRounds == 0 {
 			if room.lastVisited < unloadRoundThreshold {
@@ -234,6 +243,12 @@ func RoomMaintenance() bool {
 		roomsUpdated = true
 	}
 
+	instanceMaintenance(roundCount)
+
+	arenaMaintenance(roundCount)
+
+	zoneResetMaintenance(roundCount)
+
 	return roomsUpdated
 }
 
@@ -280,11 +295,34 @@ func MoveToRoom(userId int, toRoomId int, isSpawn ...bool) error {
 		}
 	}
 
+	// Instanced zones send each party to their own private copy
+	toRoomId = getInstanceDestination(userId, currentRoom, toRoomId)
+
 	newRoom := LoadRoom(toRoomId)
 	if newRoom == nil {
 		return fmt.Errorf(`room %d not found`, toRoomId)
 	}
 
+	// Houses are private however someone gets there (following, portals, scripts...)
+	if newRoom.House != nil && !newRoom.House.CanEnter(user) {
+		return ErrHouseLocked
+	}
+
+	// Going into an instance makes them part of it, and they remember how to get out
+	// in case it goes away while they are offline
+	if newRoom.instanceId > 0 {
+		if zi := GetInstance(newRoom.instanceId); zi != nil {
+			zi.AddUser(userId)
+			user.Character.SetMiscData(`InstanceEntrance`, zi.EntranceRoomId)
+		}
+	} else if newRoom.arenaMatchId > 0 {
+		if m := GetArenaMatch(newRoom.arenaMatchId); m != nil {
+			user.Character.SetMiscData(`InstanceEntrance`, m.ReturnRoomIds[userId])
+		}
+	} else {
+		user.Character.SetMiscData(`InstanceEntrance`, nil)
+	}
+
 	// r.prepare locks, so do it before the upcoming lock
 	if len(newRoom.players) == 0 {
 		newRoom.Prepare(true)
@@ -452,6 +490,11 @@ func MoveToRoom(userId int, toRoomId int, isSpawn ...bool) error {
 		roomInfoStr.WriteString(`}, `)
 		// End exits
 
+		// Placed rooms tell mappers exactly where they are
+		if newRoom.Coordinates != nil {
+			roomInfoStr.WriteString(`"coords": { "x": ` + strconv.Itoa(newRoom.Coordinates.X) + `, "y": ` + strconv.Itoa(newRoom.Coordinates.Y) + `, "z": ` + strconv.Itoa(newRoom.Coordinates.Z) + ` }, `)
+		}
+
 		// build details
 		roomInfoStr.WriteString(`"details": [`)
 
@@ -613,9 +656,18 @@ func SaveAllRooms() error {
 		saveModes = append(saveModes, fileloader.SaveCareful)
 	}
 
-	saveCt, err := fileloader.SaveAllFlatFiles[int, *Room](roomDataFilesPath, roomManager.rooms, saveModes...)
+	// Instanced and arena rooms are temporary and never saved
+	saveRooms := make(map[int]*Room, len(roomManager.rooms))
+	for roomId, loadedRoom := range roomManager.rooms {
+		if loadedRoom.isTemporary() {
+			continue
+		}
+		saveRooms[roomId] = loadedRoom
+	}
+
+	saveCt, err := fileloader.SaveAllFlatFiles[int, *Room](roomDataFilesPath, saveRooms, saveModes...)
 
-	slog.Info("SaveAllRooms()", "savedCount", saveCt, "expectedCt", len(roomManager.rooms), "Time Taken", time.Since(start))
+	slog.Info("SaveAllRooms()", "savedCount", saveCt, "expectedCt", len(saveRooms), "Time Taken", time.Since(start))
 
 	return err
 }
@@ -676,6 +728,9 @@ func loadAllRoomZones() error {
 		// Cache the file path for every roomId
 		roomManager.roomIdToFileCache[loadedRoom.RoomId] = loadedRoom.Filepath()
 
+		// Player corpses can be looked up without the room being in memory
+		loadedRoom.indexPlayerCorpses()
+
 		// Update the zone info cache
 		if _, ok := roomManager.zones[loadedRoom.Zone]; !ok {
 			roomManager.zones[loadedRoom.Zone] = ZoneInfo{
@@ -789,6 +844,49 @@ func addRoomToMemory(r *Room) {
 
 }
 
+// Makes rooms written to disk while the server is running (such as by a zone import) loadable.
+// Copies already in memory are dropped without saving, so they don't write over the new files.
+// Nobody should be in any of them.
+func RegisterRooms(newRooms []Room) {
+
+	for _, r := range newRooms {
+
+		if loadedRoom, ok := roomManager.rooms[r.RoomId]; ok {
+			for _, mobInstanceId := range loadedRoom.mobs {
+				mobs.DestroyInstance(mobInstanceId)
+			}
+			delete(roomManager.rooms, r.RoomId)
+		}
+
+		// Zone resets should go back to the new version
+		delete(roomManager.authoredRooms, r.RoomId)
+
+		roomManager.roomIdToFileCache[r.RoomId] = r.Filepath()
+
+		if _, ok := roomManager.zones[r.Zone]; !ok {
+			roomManager.zones[r.Zone] = ZoneInfo{
+				RootRoomId: 0,
+				RoomIds:    make(map[int]struct{}),
+			}
+		}
+
+		zoneInfo := roomManager.zones[r.Zone]
+		zoneInfo.RoomIds[r.RoomId] = struct{}{}
+
+		if r.ZoneConfig.RoomId == r.RoomId {
+			zoneInfo.RootRoomId = r.RoomId
+			zoneInfo.DefaultBiome = r.Biome
+			zoneInfo.HasZoneMutators = len(r.ZoneConfig.Mutators) > 0
+		}
+
+		roomManager.zones[r.Zone] = zoneInfo
+
+		if r.RoomId >= GetNextRoomId() {
+			SetNextRoomId(r.RoomId + 1)
+		}
+	}
+}
+
 func findRoomFile(roomId int) string {
 
 	foundFilePath := ``
@@ -826,8 +924,18 @@ func loadRoomFromFile(roomFilePath string) (*Room, error) {
 	// Automatically set the last visitor to now (reset the timer)
 	roomPtr.lastVisited = util.GetRoundCount()
 
+	// Remember how the room looked the first time it was loaded, so that zone resets can restore it.
+	if _, ok := roomManager.authoredRooms[roomPtr.RoomId]; !ok {
+		if data, err := yaml.Marshal(roomPtr); err == nil {
+			roomManager.authoredRooms[roomPtr.RoomId] = data
+		}
+	}
+
 	addRoomToMemory(roomPtr)
 
+	roomPtr.syncDoors()
+	roomPtr.indexPlayerCorpses()
+
 	return roomPtr, err
 }
 
@@ -840,6 +948,22 @@ func GetZoneRoot(zone string) (int, error) {
 	return 0, fmt.Errorf("zone %s does not exist.", zone)
 }
 
+// Returns all roomIds that belong to a zone, sorted
+func GetZoneRoomIds(zone string) []int {
+
+	roomIds := []int{}
+
+	if zoneInfo, ok := roomManager.zones[zone]; ok {
+		for roomId := range zoneInfo.RoomIds {
+			roomIds = append(roomIds, roomId)
+		}
+	}
+
+	sort.Ints(roomIds)
+
+	return roomIds
+}
+
 func GetZoneConfig(zone string) *ZoneConfig {
 
 	zoneInfo, ok := roomManager.zones[zone]
@@ -875,6 +999,11 @@ func LoadRoom(roomId int) *Room {
 		return room
 	}
 
+	// Instance rooms only exist in memory
+	if IsInstanceRoomId(roomId) {
+		return nil
+	}
+
 	filename := findRoomFile(roomId)
 	retRoom, _ := loadRoomFromFile(util.FilePath(roomDataFilesPath, `/`, filename))
 
@@ -883,6 +1012,10 @@ func LoadRoom(roomId int) *Room {
 
 func SaveRoom(r Room) error {
 
+	if r.isTemporary() {
+		return nil
+	}
+
 	if strings.HasPrefix(r.Description, `h:`) {
 		hash := strings.TrimPrefix(r.Description, `h:`)
 		if description, ok := roomManager.roomDescriptionCache[hash]; ok {
@@ -890,6 +1023,10 @@ func SaveRoom(r Room) error {
 		}
 	}
 
+	// Temporary mutators go away on their own, and shouldn't come back from the file
+	r.Mutators = r.Mutators.Permanent()
+	r.ZoneConfig.Mutators = r.ZoneConfig.Mutators.Permanent()
+
 	data, err := yaml.Marshal(&r)
 	if err != nil {
 		return err
@@ -899,6 +1036,8 @@ func SaveRoom(r Room) error {
 
 	roomFilePath := util.FilePath(roomDataFilesPath, `/`, fmt.Sprintf("%s%d.yaml", zone, r.RoomId))
 
+	journalRoomSave(r, roomFilePath)
+
 	if err = os.WriteFile(roomFilePath, data, 0777); err != nil {
 		return err
 	}
@@ -1098,6 +1237,17 @@ func BuildRoom(fromRoomId int, exitName string, mapDirection ...string) (room *R
 	}
 	fromRoom.Exits[exitName] = newExit
 
+	// Rooms built off of placed rooms get placed too
+	if fromRoom.Coordinates != nil {
+		if offset, ok := ExitOffset(exitName, newExit); ok {
+			newRoom.Coordinates = &Coordinates{
+				X: fromRoom.Coordinates.X + offset.X,
+				Y: fromRoom.Coordinates.Y + offset.Y,
+				Z: fromRoom.Coordinates.Z + offset.Z,
+			}
+		}
+	}
+
 	//if _, ok := roomManager.rooms[newRoom.RoomId]; !ok {
 	//	roomManager.rooms[newRoom.RoomId] = newRoom
 	//}
@@ -18,6 +18,7 @@ import (
 	"github.com/volte6/gomud/internal/mutators"
 	"github.com/volte6/gomud/internal/users"
 	"github.com/volte6/gomud/internal/util"
+	"github.com/volte6/gomud/internal/weather"
 )
 
 const roomDataFilesPath = "_datafiles/rooms"
@@ -72,10 +73,11 @@ type Room struct {
 	IsCharacterRoom   bool       `yaml:"ischaracterroom,omitempty"` // Is this a room where characters can create new characters to swap between them?
 	Title             string
 	Description       string
-	MapSymbol         string               `yaml:"mapsymbol,omitempty"`  // The symbol to use when generating a map of the zone
-	MapLegend         string               `yaml:"maplegend,omitempty"`  // The text to display in the legend for this room. Should be one word.
-	Biome             string               `yaml:"biome,omitempty"`      // The biome of the room. Used for weather generation.
-	Containers        map[string]Container `yaml:"containers,omitempty"` // If this room has a chest, what is in it?
+	MapSymbol         string               `yaml:"mapsymbol,omitempty"`   // The symbol to use when generating a map of the zone
+	MapLegend         string               `yaml:"maplegend,omitempty"`   // The text to display in the legend for this room. Should be one word.
+	Biome             string               `yaml:"biome,omitempty"`       // The biome of the room. Used for weather generation.
+	Coordinates       *Coordinates         `yaml:"coordinates,omitempty"` // Optional fixed position of the room. Used by maps instead of working it out from exits.
+	Containers        map[string]Container `yaml:"containers,omitempty"`  // If this room has a chest, what is in it?
 	Exits             map[string]exit.RoomExit
 	ExitsTemp         map[string]exit.TemporaryRoomExit `yaml:"-"`               // Temporary exits that will be removed after a certain time. Don't bother saving on sever shutting down.
 	Nouns             map[string]string                 `yaml:"nouns,omitempty"` // Interesting nouns to highlight in the room or reveal on succesful searches.
@@ -85,6 +87,7 @@ type Room struct {
 	SpawnInfo         []SpawnInfo                       `yaml:"spawninfo,omitempty"`         // key is creature ID, value is spawn chance
 	SkillTraining     map[string]TrainingRange          `yaml:"skilltraining,omitempty"`     // list of skills that can be trained in this room
 	Signs             []Sign                            `yaml:"sign,omitempty"`              // list of scribbles in the room
+	House             *House                            `yaml:"house,omitempty"`             // If this room is a player house, who owns it and what's on display
 	IdleMessages      []string                          `yaml:"idlemessages,omitempty"`      // list of messages that can be displayed to players in the room
 	LastIdleMessage   uint8                             `yaml:"-"`                           // index of the last idle message displayed
 	LongTermDataStore map[string]any                    `yaml:"longtermdatastore,omitempty"` // Long term data store for the room
@@ -96,6 +99,10 @@ type Room struct {
 	visitors          map[VisitorType]map[int]uint64    `yaml:"-"`             // list of user IDs that have visited this room, and the last round they did
 	lastVisited       uint64                            `yaml:"-"`             // last round a visitor was in the room
 	tempDataStore     map[string]any                    `yaml:"-"`             // Temporary data store for the room
+	// Instanced zone tracking
+	instanceId           int // If part of a zone instance, which one
+	instanceSourceRoomId int // The original roomId this room was copied from
+	arenaMatchId         int // If this is a private arena, which match it is for
 }
 
 type TrainingRange struct {
@@ -153,6 +160,11 @@ func (r *Room) GetVisibility() int {
 		}
 	}
 
+	// Fog, storms etc. can make it harder to see
+	if wInfo, ok := weather.GetConditionInfo(r.GetWeather()); ok {
+		visibility += wInfo.LightMod
+	}
+
 	// min/max visibility
 	if visibility < 0 {
 		visibility = 0
@@ -218,6 +230,11 @@ func (r *Room) SendTextToExits(txt string, isQuiet bool, excludeUserIds ...int)
 				continue
 			}
 
+			// Closed doors muffle the sound
+			if tExit.IsClosed() {
+				continue
+			}
+
 			events.AddToQueue(events.Message{
 				RoomId:         tgtRoom.RoomId,
 				Text:           fmt.Sprintf(`(From <ansi fg="exit">%s</ansi>) `, exitName) + txt + "\n",
@@ -300,10 +317,40 @@ func (r *Room) GetScript() string {
 
 func (r *Room) GetScriptPath() string {
 
+	// Instanced rooms share the script of the room they were copied from
+	if r.instanceSourceRoomId > 0 {
+		zone := ZoneNameSanitize(r.Zone)
+		return util.FilePath(roomDataFilesPath, `/`, zone, `/`, fmt.Sprintf("%d.js", r.instanceSourceRoomId))
+	}
+
 	// Load any script for the room
 	return strings.Replace(roomDataFilesPath+`/`+r.Filepath(), `.yaml`, `.js`, 1)
 }
 
+// Returns the instanceId if this room is part of a private zone instance, otherwise 0
+func (r *Room) GetInstanceId() int {
+	return r.instanceId
+}
+
+// Returns the arena matchId if this room is a private arena, otherwise 0
+func (r *Room) GetArenaMatchId() int {
+	return r.arenaMatchId
+}
+
+// Instance and arena rooms only live in memory and are never saved
+func (r *Room) isTemporary() bool {
+	return r.instanceId > 0 || r.arenaMatchId > 0
+}
+
+// Returns the roomId of the room this was copied from if it is part of an instance.
+// Otherwise returns its own roomId.
+func (r *Room) GetSourceRoomId() int {
+	if r.instanceSourceRoomId > 0 {
+		return r.instanceSourceRoomId
+	}
+	return r.RoomId
+}
+
 func (r *Room) FindTemporaryExitByUserId(userId int) (exit.TemporaryRoomExit, bool) {
 
 	if r.ExitsTemp != nil {
@@ -377,6 +424,35 @@ func (r *Room) ApplyBuffIdToPlayers(buffId ...int) {
 
 }
 
+// applies weather buffs to any players in the room that don't
+// already have them, skipping anyone protected from the weather
+func (r *Room) applyWeatherBuffs(wInfo weather.ConditionInfo) {
+
+	if len(wInfo.PlayerBuffIds) == 0 {
+		return
+	}
+
+	for _, uid := range r.GetPlayers() {
+
+		u := users.GetByUserId(uid)
+		if u == nil {
+			continue
+		}
+
+		if wInfo.ProtectedFlag != `` && u.Character.HasBuffFlag(buffs.Flag(wInfo.ProtectedFlag)) {
+			continue
+		}
+
+		for _, bId := range wInfo.PlayerBuffIds {
+			if u.Character.HasBuff(bId) {
+				continue
+			}
+			u.AddBuff(bId)
+		}
+	}
+
+}
+
 // applies buffs to any mobs in the room that don't
 // already have it
 func (r *Room) ApplyBuffIdToMobs(buffId ...int) {
@@ -438,9 +514,21 @@ func (r *Room) SpawnTempContainer(name string, duration string, lockDifficulty i
 		c.Lock.TrapBuffIds = trapBuffIds
 	}
 
+	containerName := r.uniqueContainerName(name)
+
+	if r.Containers == nil {
+		r.Containers = make(map[string]Container)
+	}
+	r.Containers[containerName] = c
+
+	return containerName
+}
+
+// Returns the name with a number added if a container by that name is already in the room
+func (r *Room) uniqueContainerName(name string) string {
+
 	containerName := name
 
-	// make sure name is unique
 	i := 1
 	_, ok := r.Containers[containerName]
 	for ok {
@@ -449,11 +537,6 @@ func (r *Room) SpawnTempContainer(name string, duration string, lockDifficulty i
 		_, ok = r.Containers[containerName]
 	}
 
-	if r.Containers == nil {
-		r.Containers = make(map[string]Container)
-	}
-	r.Containers[containerName] = c
-
 	return containerName
 }
 
@@ -467,6 +550,7 @@ func (r *Room) Prepare(checkAdjacentRooms bool) {
 	r.Mutators.Update(roundNow)
 
 	if len(r.Containers) > 0 {
+		r.decayCorpses(roundNow)
 		for k, c := range r.Containers {
 			if c.DespawnRound > 0 && c.DespawnRound <= roundNow {
 				r.SendText(fmt.Sprintf(`The <ansi fg="container">%s</ansi> crumbles to dust, and is gone.`, k))
@@ -711,6 +795,13 @@ func (r *Room) RemoveMob(mobInstanceId int) {
 		}
 	}
 
+	// Out of sight, out of mind
+	if mob := mobs.GetInstance(mobInstanceId); mob != nil {
+		for _, userId := range r.players {
+			mob.Character.ClearThreat(userId)
+		}
+	}
+
 	if len(r.mobs) < 1 {
 		delete(roomManager.roomsWithMobs, r.RoomId)
 	}
@@ -760,6 +851,72 @@ func (r *Room) SetExitLock(exitName string, locked bool) {
 
 }
 
+// Opens or closes the door in an exit.
+// Any door on the other side leading back to this room is set to match.
+// Returns false if there is no door.
+func (r *Room) SetExitOpen(exitName string, open bool) bool {
+
+	exitInfo, ok := r.Exits[exitName]
+	if !ok || !exitInfo.HasDoor() {
+		return false
+	}
+
+	exitInfo.Door.SetOpen(open)
+
+	if otherRoom := LoadRoom(exitInfo.RoomId); otherRoom != nil {
+		for _, otherExit := range otherRoom.Exits {
+			if otherExit.RoomId == r.RoomId && otherExit.HasDoor() {
+				otherExit.Door.SetOpen(open)
+			}
+		}
+	}
+
+	return true
+}
+
+// Door state isn't saved, so a freshly loaded room takes it from any neighbour still in memory.
+// This keeps both sides of a door in agreement when only one side was unloaded.
+func (r *Room) syncDoors() {
+	for _, exitInfo := range r.Exits {
+
+		if !exitInfo.HasDoor() {
+			continue
+		}
+
+		otherRoom, ok := roomManager.rooms[exitInfo.RoomId]
+		if !ok || otherRoom == r {
+			continue
+		}
+
+		for _, otherExit := range otherRoom.Exits {
+			if otherExit.RoomId == r.RoomId && otherExit.HasDoor() {
+				exitInfo.Door.Open = otherExit.Door.Open
+				exitInfo.Door.OpenedRound = otherExit.Door.OpenedRound
+				break
+			}
+		}
+	}
+}
+
+// Returns the exit name in another room that leads back to this one through a door
+func (r *Room) GetDoorReturnExit(exitName string) (otherRoom *Room, otherExitName string) {
+
+	exitInfo, ok := r.Exits[exitName]
+	if !ok || !exitInfo.HasDoor() {
+		return nil, ``
+	}
+
+	if otherRoom = LoadRoom(exitInfo.RoomId); otherRoom != nil {
+		for otherName, otherExit := range otherRoom.Exits {
+			if otherExit.RoomId == r.RoomId && otherExit.HasDoor() {
+				return otherRoom, otherName
+			}
+		}
+	}
+
+	return otherRoom, ``
+}
+
 func (r *Room) GetExitInfo(exitName string) (exitInfo exit.RoomExit, ok bool) {
 
 	// Do mutators first to allow for ephemeral/temporary "taking over" of exits.
@@ -788,6 +945,9 @@ func (r *Room) GetRandomExit() (exitName string, roomId int) {
 		if exit.Lock.IsLocked() {
 			continue
 		}
+		if exit.IsClosed() {
+			continue
+		}
 
 		allExits[exitName] = roomId
 	}
@@ -903,6 +1063,10 @@ func (r *Room) MarkVisited(id int, vType VisitorType, subtrackTurns ...int) {
 
 	r.visitors[vType][id] = lastSeen
 	r.lastVisited = util.GetRoundCount()
+
+	if vType == VisitorUser && r.arenaMatchId == 0 {
+		markExplored(id, r.GetSourceRoomId())
+	}
 }
 
 func (r *Room) MobCt() int {
@@ -1851,6 +2015,27 @@ func (r *Room) RoundTick() {
 	// Done adding mutator buffs
 	//
 
+	// Apply any weather related buffs
+	if wInfo, ok := weather.GetConditionInfo(r.GetWeather()); ok {
+		r.applyWeatherBuffs(wInfo)
+	}
+
+	// Shut any doors that close on their own
+	for exitName, exitInfo := range r.Exits {
+		if !exitInfo.HasDoor() || !exitInfo.Door.ShouldAutoClose(roundNow) {
+			continue
+		}
+
+		otherRoom, otherExitName := r.GetDoorReturnExit(exitName)
+
+		r.SetExitOpen(exitName, false)
+		r.SendText(fmt.Sprintf(`The %s to the <ansi fg="exit">%s</ansi> swings shut.`, exitInfo.Door.GetName(), exitName))
+
+		if otherRoom != nil && otherExitName != `` {
+			otherRoom.SendText(fmt.Sprintf(`The %s to the <ansi fg="exit">%s</ansi> swings shut.`, exitInfo.Door.GetName(), otherExitName))
+		}
+	}
+
 	for idx, spawnInfo := range r.SpawnInfo {
 
 		// Make sure to clean up any instances that may be dead
@@ -1888,6 +2073,14 @@ func (r *Room) RemovePlayer(userId int) (int, bool) {
 	for i, v := range r.players {
 		if v == userId {
 			r.players = append(r.players[:i], r.players[i+1:]...)
+
+			// Mobs forget about anyone who leaves
+			for _, mobInstanceId := range r.mobs {
+				if mob := mobs.GetInstance(mobInstanceId); mob != nil {
+					mob.Character.ClearThreat(userId)
+				}
+			}
+
 			return len(r.players), true
 		}
 	}
@@ -2052,6 +2245,13 @@ func (r *Room) Validate() error {
 		r.Containers[cName] = c
 	}
 
+	// Doors start out in their default state
+	for _, exitInfo := range r.Exits {
+		if exitInfo.HasDoor() {
+			exitInfo.Door.Reset()
+		}
+	}
+
 	if r.ZoneConfig.RoomId != r.RoomId {
 		r.ZoneConfig = ZoneConfig{}
 	} else {
@@ -2092,6 +2292,15 @@ func (r *Room) GetBiome() BiomeInfo {
 	return bInfo
 }
 
+// Returns the weather the room is exposed to.
+// Sheltered biomes (houses, caves etc.) have no weather.
+func (r *Room) GetWeather() weather.Condition {
+	if r.GetBiome().IsSheltered() {
+		return weather.None
+	}
+	return weather.Get(r.Zone)
+}
+
 func (r *Room) ActiveMutators(yield func(mutators.Mutator) bool) {
 
 	var activeMutators mutators.MutatorList
@@ -2123,6 +2332,16 @@ func (r *Room) IsPvp() bool {
 // Returns an error with a reason why they cannot PVP, or nil
 func (r *Room) CanPvp(attUser *users.UserRecord, defUser *users.UserRecord) error {
 
+	// Arena matches have their own rules
+	if r.arenaMatchId > 0 {
+		return canArenaPvp(r.arenaMatchId, attUser.UserId, defUser.UserId)
+	}
+
+	// Duelists can fight each other anywhere
+	if isDuel, err := canDuelPvp(attUser.UserId, defUser.UserId); isDuel {
+		return err
+	}
+
 	c := configs.GetConfig()
 
 	// Possible settings are `enabled`, `disabled`, `limited`
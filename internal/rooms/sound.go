@@ -0,0 +1,189 @@
+package rooms
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/volte6/gomud/internal/events"
+	"github.com/volte6/gomud/internal/exit"
+)
+
+//
+// Sounds spread out through exits, getting fainter the further they go.
+// SendTextToExits only reaches the rooms next door, this reaches as far as the sound is loud.
+//
+
+const (
+	closedDoorMuffle = 2 // A closed door makes a sound seem this many rooms further away
+)
+
+type Sound struct {
+	Description     string // What is heard, such as "fighting" or "a bell ringing"
+	Volume          int    // How many rooms away it can be heard
+	NearText        string // (optional) what the rooms right next door hear instead, given the exit name. Shouts use this so the words can be made out.
+	NearTextNoExit  string // (optional) NearText for a room next door with no exit back, such as the far end of a one-way exit
+	IsQuiet         bool   // Only those with superior hearing can hear it at all
+	IsCommunication bool   // Deafened players can't hear it
+}
+
+// A sneaking version of the sound, which doesn't carry as far
+func (s Sound) Sneaking() Sound {
+	s.Volume--
+	return s
+}
+
+type soundHop struct {
+	distance   int
+	fromRoomId int
+	muffled    bool // Came through a closed door somewhere along the way
+}
+
+// Spreads a sound out from this room through its exits.
+// Rooms further away hear it more faintly, closed doors muffle it, and anyone with superior hearing
+// can hear it one room further than everyone else.
+// The room itself is not sent anything.
+func (r *Room) PropagateSound(s Sound, excludeUserIds ...int) {
+
+	if s.Volume < 1 {
+		return
+	}
+
+	maxDistance := s.Volume + 1
+
+	heard := map[int]soundHop{r.RoomId: {distance: 0}}
+	queue := []int{r.RoomId}
+
+	for len(queue) > 0 {
+
+		roomId := queue[0]
+		queue = queue[1:]
+
+		room := LoadRoom(roomId)
+		if room == nil {
+			continue
+		}
+
+		here := heard[roomId]
+
+		for _, exitInfo := range room.soundExits() {
+
+			distance := here.distance + 1
+			muffled := here.muffled
+			if exitInfo.IsClosed() {
+				distance += closedDoorMuffle
+				muffled = true
+			}
+
+			if distance > maxDistance {
+				continue
+			}
+
+			// Only the loudest way it gets there counts
+			if existing, ok := heard[exitInfo.RoomId]; ok && existing.distance <= distance {
+				continue
+			}
+
+			heard[exitInfo.RoomId] = soundHop{distance: distance, fromRoomId: roomId, muffled: muffled}
+			queue = append(queue, exitInfo.RoomId)
+		}
+	}
+
+	roomIds := make([]int, 0, len(heard))
+	for roomId := range heard {
+		if roomId != r.RoomId {
+			roomIds = append(roomIds, roomId)
+		}
+	}
+	sort.Ints(roomIds)
+
+	for _, roomId := range roomIds {
+
+		hop := heard[roomId]
+
+		exitName := ``
+		if hearingRoom := LoadRoom(roomId); hearingRoom != nil {
+			exitName = hearingRoom.FindExitTo(hop.fromRoomId)
+		}
+
+		events.AddToQueue(events.Message{
+			RoomId:          roomId,
+			Text:            s.textAt(hop.distance, hop.muffled, exitName) + "\n",
+			IsQuiet:         s.IsQuiet || hop.distance > s.Volume,
+			IsCommunication: s.IsCommunication,
+			ExcludeUserIds:  excludeUserIds,
+		})
+	}
+}
+
+// What a sound sounds like a certain number of rooms away, coming through an exit
+func (s Sound) textAt(distance int, muffled bool, exitName string) string {
+
+	if distance == 1 && s.NearText != `` {
+		if exitName == `` {
+			if s.NearTextNoExit != `` {
+				return s.NearTextNoExit
+			}
+		} else {
+			return fmt.Sprintf(s.NearText, exitName)
+		}
+	}
+
+	direction := soundDirection(exitName)
+	if muffled {
+		direction += `, muffled by a door`
+	}
+
+	switch {
+	case distance <= 1:
+		return fmt.Sprintf(`You hear %s nearby%s.`, s.Description, direction)
+	case distance == 2:
+		return fmt.Sprintf(`You hear %s not far off%s.`, s.Description, direction)
+	case distance <= s.Volume:
+		return fmt.Sprintf(`You hear %s in the distance%s.`, s.Description, direction)
+	}
+
+	return fmt.Sprintf(`You can just barely make out %s%s.`, s.Description, direction)
+}
+
+func soundDirection(exitName string) string {
+
+	if exitName == `` {
+		return ``
+	}
+
+	switch exitName {
+	case `up`:
+		return ` from above`
+	case `down`:
+		return ` from below`
+	}
+
+	if _, ok := DirectionDeltas[exitName]; ok {
+		return fmt.Sprintf(` to the <ansi fg="exit">%s</ansi>`, exitName)
+	}
+
+	return fmt.Sprintf(` through the <ansi fg="exit">%s</ansi> exit`, exitName)
+}
+
+// Every way sound can leave the room, secret or not
+func (r *Room) soundExits() []exit.RoomExit {
+
+	soundExits := []exit.RoomExit{}
+
+	for _, exitInfo := range r.Exits {
+		soundExits = append(soundExits, exitInfo)
+	}
+
+	for _, tempExit := range r.ExitsTemp {
+		soundExits = append(soundExits, exit.RoomExit{RoomId: tempExit.RoomId})
+	}
+
+	for mut := range r.ActiveMutators {
+		spec := mut.GetSpec()
+		for _, exitInfo := range spec.Exits {
+			soundExits = append(soundExits, exitInfo)
+		}
+	}
+
+	return soundExits
+}
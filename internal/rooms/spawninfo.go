@@ -17,6 +17,6 @@ type SpawnInfo struct {
 	Level        int      `yaml:"level,omitempty"`           // (optional) force this mob to a specific level
 	LevelMod     int      `yaml:"levelmod,omitempty"`        // (optional) modify this mobs level by this amount
 	// spawn tracking and rate
-	DespawnedRound uint64 `-`                          // When this mob was last despawned (killed)
-	RespawnRate    string `yaml:respawnrate:omitempty` // How long until it respawns when not present?
+	DespawnedRound uint64 `yaml:"despawnedround,omitempty"` // When this mob was last despawned (killed)
+	RespawnRate    string `yaml:"respawnrate,omitempty"`    // How long until it respawns when not present?
 }
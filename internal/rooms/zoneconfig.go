@@ -1,6 +1,8 @@
 package rooms
 
 import (
+	"strings"
+
 	"github.com/volte6/gomud/internal/mutators"
 	"github.com/volte6/gomud/internal/util"
 )
@@ -11,9 +13,23 @@ type ZoneConfig struct {
 		Minimum int `yaml:"minimum,omitempty"` // level scaling minimum
 		Maximum int `yaml:"maximum,omitempty"` // level scaling maximum
 	} `yaml:"autoscale,omitempty"` // level scaling range if any
-	Mutators mutators.MutatorList `yaml:"mutators,omitempty"` // mutators defined here apply to entire zone
+	Mutators            mutators.MutatorList `yaml:"mutators,omitempty"`            // mutators defined here apply to entire zone
+	Instanced           bool                 `yaml:"instanced,omitempty"`           // if true, each party entering the zone gets its own private copy
+	InstanceIdleTimeout string               `yaml:"instanceidletimeout,omitempty"` // how long an empty instance survives before being destroyed (default: 5 real minutes)
+	InstanceLifetime    string               `yaml:"instancelifetime,omitempty"`    // (optional) max time an instance can exist before anyone inside is sent back out
+	InstanceReset       string               `yaml:"instancereset,omitempty"`       // (optional) how often an instance restores its containers, items and spawns
+	ResetPolicy         string               `yaml:"resetpolicy,omitempty"`         // (optional) when the zone is restored to how it was authored: manual, interval or empty (default: manual)
+	ResetInterval       string               `yaml:"resetinterval,omitempty"`       // (optional) how often an interval or empty reset happens (default: 1 hour)
 }
 
+const (
+	ResetManual   = `manual`   // Only reset on demand (zone reset)
+	ResetInterval = `interval` // Reset every ResetInterval, even if players are present
+	ResetEmpty    = `empty`    // Reset every ResetInterval, but wait until no players are present
+
+	defaultZoneResetInterval = `1 hour`
+)
+
 func (z *ZoneConfig) Validate() {
 	if z.MobAutoScale.Minimum < 0 {
 		z.MobAutoScale.Minimum = 0
@@ -35,6 +51,27 @@ func (z *ZoneConfig) Validate() {
 		}
 	}
 
+	z.ResetPolicy = strings.ToLower(z.ResetPolicy)
+	if z.ResetPolicy != ResetInterval && z.ResetPolicy != ResetEmpty {
+		z.ResetPolicy = ``
+	}
+
+}
+
+// Returns the reset policy, defaulting to manual
+func (z *ZoneConfig) GetResetPolicy() string {
+	if z.ResetPolicy == `` {
+		return ResetManual
+	}
+	return z.ResetPolicy
+}
+
+// Returns how often the zone resets when using an interval or empty policy
+func (z *ZoneConfig) GetResetInterval() string {
+	if z.ResetInterval == `` {
+		return defaultZoneResetInterval
+	}
+	return z.ResetInterval
 }
 
 // Generates a random number between min and max
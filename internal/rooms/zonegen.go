@@ -0,0 +1,411 @@
+package rooms
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"strings"
+
+	"github.com/volte6/gomud/internal/configs"
+	"github.com/volte6/gomud/internal/exit"
+	"github.com/volte6/gomud/internal/util"
+)
+
+const (
+	ZoneGenMinRooms = 2
+	ZoneGenMaxRooms = 200
+)
+
+// Options for procedurally generating a zone.
+type ZoneGenOptions struct {
+	ZoneName         string // Name of the new zone
+	Biome            string // Biome of every room, and which phrase table to pull text from
+	RoomCount        int    // How many rooms to generate
+	Seed             string // (optional) if empty, the server seed is used
+	MobIds           []int  // (optional) mobs to randomly spawn around the zone
+	MobChance        int    // (optional) percent chance a room gets a mob spawn (default 25)
+	ContainerItemIds []int  // (optional) items that may spawn inside generated containers
+	ContainerChance  int    // (optional) percent chance a room gets a container (default 0 = none)
+	BossMobId        int    // (optional) if set, the room furthest from the entrance becomes a boss room
+}
+
+type ZoneGenResult struct {
+	Seed        string
+	RootRoomId  int
+	BossRoomId  int
+	RoomIds     []int
+	MobCt       int
+	ContainerCt int
+}
+
+// Text used to describe generated rooms.
+// Titles are "<adjective> <noun>", descriptions are an opening, some details and a closing.
+type zoneGenPhrases struct {
+	adjectives     []string
+	nouns          []string
+	openings       []string
+	details        []string
+	closings       []string
+	bossTitles     []string
+	bossDetails    []string
+	containerNames []string
+}
+
+var (
+	zoneGenDirections = []string{`north`, `east`, `south`, `west`}
+	zoneGenOpposites  = map[string]string{`north`: `south`, `south`: `north`, `east`: `west`, `west`: `east`}
+
+	zoneGenPhraseTables = map[string]zoneGenPhrases{
+		`forest`: {
+			adjectives:     []string{`Shadowed`, `Mossy`, `Quiet`, `Tangled`, `Ancient`, `Sun-dappled`, `Overgrown`},
+			nouns:          []string{`Glade`, `Thicket`, `Clearing`, `Grove`, `Trail`, `Hollow`, `Copse`},
+			openings:       []string{`Tall trees crowd together here, their branches knitting a canopy overhead.`, `A narrow path winds between thick trunks and low ferns.`, `The forest opens slightly, revealing a patch of soft, mossy ground.`},
+			details:        []string{`Birds call to one another somewhere out of sight.`, `Fallen leaves crunch softly underfoot.`, `Thick roots break through the earth like knotted fingers.`, `A fallen log lies rotting beneath a blanket of mushrooms.`, `The smell of pine and damp earth hangs in the air.`},
+			closings:       []string{`The trees seem to watch as you pass.`, `Paths lead off deeper into the woods.`, `Something rustles in the undergrowth nearby.`},
+			bossTitles:     []string{`The Heart of the Wood`, `The Elder Grove`},
+			bossDetails:    []string{`Bones lie scattered among the roots of an enormous, twisted tree.`, `The air here is heavy and still, as if the forest is holding its breath.`},
+			containerNames: []string{`hollow log`, `moss-covered chest`, `stump`},
+		},
+		`cave`: {
+			adjectives:     []string{`Damp`, `Narrow`, `Echoing`, `Dripping`, `Jagged`, `Low`, `Winding`},
+			nouns:          []string{`Tunnel`, `Cavern`, `Passage`, `Grotto`, `Chamber`, `Crawlway`},
+			openings:       []string{`Rough stone walls close in around you.`, `The tunnel widens into a small, irregular chamber.`, `A cold draft flows through this rocky passage.`},
+			details:        []string{`Water drips steadily from the ceiling.`, `Pale crystals glint faintly in the rock.`, `Stalactites hang like teeth from above.`, `The floor is slick with mineral deposits.`, `Faint scratches mark the walls.`},
+			closings:       []string{`Every sound echoes into the darkness.`, `The passage continues into blackness.`, `The air tastes of stone and dust.`},
+			bossTitles:     []string{`The Deep Lair`, `The Great Cavern`},
+			bossDetails:    []string{`Gnawed bones are piled against the far wall.`, `A foul stench rises from a dark pit in the center of the chamber.`},
+			containerNames: []string{`rock pile`, `old crate`, `crevice`},
+		},
+		`swamp`: {
+			adjectives:     []string{`Murky`, `Sodden`, `Reeking`, `Misty`, `Sunken`, `Stagnant`},
+			nouns:          []string{`Bog`, `Mire`, `Fen`, `Marsh`, `Slough`, `Bank`},
+			openings:       []string{`Thick, sucking mud pulls at every step.`, `Stagnant water pools between clumps of reeds.`, `A rotten boardwalk crosses the murky ground here.`},
+			details:        []string{`Insects buzz incessantly around your head.`, `Bubbles rise slowly from the dark water.`, `Twisted trees droop with hanging moss.`, `Frogs croak from somewhere in the reeds.`},
+			closings:       []string{`The stench of decay is everywhere.`, `It is hard to tell where solid ground ends.`, `Something slips beneath the water's surface.`},
+			bossTitles:     []string{`The Drowned Hollow`, `The Black Pool`},
+			bossDetails:    []string{`A wide pool of black water dominates the area, utterly still.`, `Half-sunken remains poke out of the mud.`},
+			containerNames: []string{`sunken chest`, `rotting barrel`},
+		},
+		`mountains`: {
+			adjectives:     []string{`Rocky`, `Windswept`, `Steep`, `Craggy`, `High`, `Narrow`},
+			nouns:          []string{`Pass`, `Ridge`, `Ledge`, `Slope`, `Trail`, `Outcrop`},
+			openings:       []string{`The trail climbs steeply between jagged rocks.`, `A narrow ledge clings to the mountainside.`, `Loose scree shifts beneath your feet.`},
+			details:        []string{`The wind howls across the stone.`, `Far below, the land stretches into the distance.`, `Hardy shrubs cling to cracks in the rock.`, `A hawk circles high overhead.`},
+			closings:       []string{`The air is thin and cold.`, `The path continues along the rocks.`, `Each step demands care.`},
+			bossTitles:     []string{`The Summit`, `The Eyrie`},
+			bossDetails:    []string{`Huge claw marks score the rock around a wide, flat peak.`, `The remains of many nests litter the ground.`},
+			containerNames: []string{`cairn`, `abandoned pack`},
+		},
+		`snow`: {
+			adjectives:     []string{`Frozen`, `Icy`, `Snowbound`, `Bitter`, `White`, `Frostbitten`},
+			nouns:          []string{`Drift`, `Expanse`, `Field`, `Hollow`, `Trail`, `Ridge`},
+			openings:       []string{`Snow lies deep and undisturbed here.`, `A frozen wind sweeps across the ice.`, `The ground is buried under a thick white blanket.`},
+			details:        []string{`Icicles hang from nearby rocks.`, `Your breath fogs in the frigid air.`, `The snow creaks beneath your feet.`, `A half-buried tree pokes through the drifts.`},
+			closings:       []string{`The cold gnaws at you.`, `Everything is silent beneath the snow.`, `Tracks lead off in several directions.`},
+			bossTitles:     []string{`The Frozen Throne`, `The Ice Den`},
+			bossDetails:    []string{`Frozen figures stand trapped in sheets of ice.`, `A massive den has been carved into the ice.`},
+			containerNames: []string{`frozen chest`, `snow-covered pack`},
+		},
+		`desert`: {
+			adjectives:     []string{`Scorching`, `Dusty`, `Barren`, `Shifting`, `Sunbaked`, `Endless`},
+			nouns:          []string{`Dunes`, `Flats`, `Wastes`, `Basin`, `Gulch`, `Sands`},
+			openings:       []string{`Sand stretches endlessly in every direction.`, `Cracked earth bakes beneath the relentless sun.`, `Wind-carved rocks rise from the shifting sand.`},
+			details:        []string{`Heat shimmers across the horizon.`, `Bleached bones lie half-buried in the sand.`, `A lone cactus stands against the sky.`, `Sand hisses as the wind shifts it.`},
+			closings:       []string{`There is no shade to be found.`, `The dunes roll on and on.`, `Your throat is dry with dust.`},
+			bossTitles:     []string{`The Sunken Ruin`, `The Scorpion Pit`},
+			bossDetails:    []string{`Crumbling pillars rise from the sand around a dark hole.`, `The sand here is churned as if something huge lives beneath it.`},
+			containerNames: []string{`half-buried chest`, `old saddlebag`},
+		},
+		`city`: {
+			adjectives:     []string{`Busy`, `Narrow`, `Cobbled`, `Crowded`, `Quiet`, `Grimy`},
+			nouns:          []string{`Street`, `Alley`, `Square`, `Lane`, `Row`, `Corner`},
+			openings:       []string{`Cobblestones line the way between close-packed buildings.`, `Shuttered shops crowd either side of the street.`, `A small square opens up between the buildings.`},
+			details:        []string{`Lanterns hang from iron hooks on the walls.`, `Refuse gathers in the gutters.`, `Laundry hangs between upper windows.`, `Voices drift out from a nearby doorway.`},
+			closings:       []string{`The city bustles around you.`, `Streets lead off in several directions.`, `Someone watches from a window above.`},
+			bossTitles:     []string{`The Guildhall`, `The Hideout`},
+			bossDetails:    []string{`A heavy table sits at the center of the room, covered in maps and coins.`, `Armed figures have clearly made this place their headquarters.`},
+			containerNames: []string{`crate`, `barrel`, `strongbox`},
+		},
+		`shore`: {
+			adjectives:     []string{`Sandy`, `Rocky`, `Windy`, `Salt-stained`, `Pebbled`},
+			nouns:          []string{`Beach`, `Shore`, `Cove`, `Strand`, `Tidepools`},
+			openings:       []string{`Waves roll steadily onto the shore.`, `Smooth pebbles cover the ground near the water's edge.`, `The land slopes gently down into the water.`},
+			details:        []string{`Gulls cry overhead.`, `Driftwood lies tangled in seaweed.`, `Crabs scuttle between the rocks.`, `The smell of salt fills the air.`},
+			closings:       []string{`The tide whispers endlessly.`, `The shoreline continues on.`, `Spray mists the air.`},
+			bossTitles:     []string{`The Wreck`, `The Sea Cave`},
+			bossDetails:    []string{`The broken hull of a ship lies on its side, half-filled with water.`, `Something large has dragged itself up onto the sand here.`},
+			containerNames: []string{`washed-up chest`, `barnacled crate`},
+		},
+		`farmland`: {
+			adjectives:     []string{`Tilled`, `Golden`, `Overgrown`, `Muddy`, `Peaceful`},
+			nouns:          []string{`Field`, `Pasture`, `Furrows`, `Orchard`, `Track`},
+			openings:       []string{`Neat rows of crops stretch across the field.`, `A wooden fence borders an open pasture.`, `A dirt track runs between the fields.`},
+			details:        []string{`A scarecrow stands watch nearby.`, `Crickets chirp in the grass.`, `A rusty plow has been left in the dirt.`, `The smell of hay hangs in the air.`},
+			closings:       []string{`It is peaceful here.`, `The fields continue in every direction.`, `A farmhouse is visible in the distance.`},
+			bossTitles:     []string{`The Old Barn`, `The Blighted Field`},
+			bossDetails:    []string{`The crops here have withered and blackened.`, `The barn doors hang broken, and something moves within.`},
+			containerNames: []string{`feed bin`, `hay bale`},
+		},
+		`spiderweb`: {
+			adjectives:     []string{`Webbed`, `Silken`, `Sticky`, `Shrouded`, `Tangled`},
+			nouns:          []string{`Nest`, `Tunnel`, `Lair`, `Hollow`, `Web`},
+			openings:       []string{`Thick webs cover every surface.`, `Strands of silk hang from above like curtains.`, `The passage is clogged with sticky webbing.`},
+			details:        []string{`Wrapped bundles hang from the walls.`, `Something skitters just out of sight.`, `Dried husks of insects litter the floor.`},
+			closings:       []string{`You feel as though you are being watched.`, `The webs tremble slightly.`, `Every step risks becoming stuck.`},
+			bossTitles:     []string{`The Brood Chamber`, `The Queen's Lair`},
+			bossDetails:    []string{`Egg sacs line the walls of this vast, silk-draped chamber.`, `An enormous web stretches from wall to wall.`},
+			containerNames: []string{`silk cocoon`, `wrapped bundle`},
+		},
+	}
+
+	// Biomes without their own phrase table borrow from a similar one
+	zoneGenPhraseAliases = map[string]string{
+		`fort`:   `city`,
+		`road`:   `city`,
+		`house`:  `city`,
+		`water`:  `shore`,
+		`cliffs`: `mountains`,
+	}
+)
+
+func getZoneGenPhrases(biome string) zoneGenPhrases {
+	biome = strings.ToLower(biome)
+	if alias, ok := zoneGenPhraseAliases[biome]; ok {
+		biome = alias
+	}
+	if p, ok := zoneGenPhraseTables[biome]; ok {
+		return p
+	}
+	return zoneGenPhraseTables[`forest`]
+}
+
+// Converts a seed string into a rand source, the same way the server seed is calculated
+func zoneGenRand(seed string) *rand.Rand {
+	var seedInt int64 = 0
+	for i, num := range util.Md5Bytes([]byte(seed)) {
+		seedInt += int64(num) << i
+	}
+	return rand.New(rand.NewSource(seedInt))
+}
+
+func zoneGenPick(rng *rand.Rand, list []string) string {
+	if len(list) == 0 {
+		return ``
+	}
+	return list[rng.Intn(len(list))]
+}
+
+func zoneGenDescription(rng *rand.Rand, p zoneGenPhrases, extra ...string) string {
+	parts := []string{zoneGenPick(rng, p.openings)}
+
+	// One or two unique details
+	detailCt := 1 + rng.Intn(2)
+	for _, idx := range rng.Perm(len(p.details)) {
+		if detailCt < 1 {
+			break
+		}
+		parts = append(parts, p.details[idx])
+		detailCt--
+	}
+
+	parts = append(parts, extra...)
+	parts = append(parts, zoneGenPick(rng, p.closings))
+
+	return strings.Join(parts, ` `)
+}
+
+// Generates a new zone of connected rooms, using the biome phrase tables for text.
+// The same seed, zone name, biome and options will always generate the same layout.
+// Rooms are laid out on a grid and connected with compass exits, so they map cleanly.
+func GenerateZone(opts ZoneGenOptions) (ZoneGenResult, error) {
+
+	result := ZoneGenResult{}
+
+	opts.ZoneName = strings.TrimSpace(opts.ZoneName)
+	opts.Biome = strings.ToLower(strings.TrimSpace(opts.Biome))
+
+	if _, ok := GetBiome(opts.Biome); !ok {
+		return result, fmt.Errorf(`invalid biome: %s`, opts.Biome)
+	}
+
+	if opts.RoomCount < ZoneGenMinRooms || opts.RoomCount > ZoneGenMaxRooms {
+		return result, fmt.Errorf(`room count must be between %d and %d`, ZoneGenMinRooms, ZoneGenMaxRooms)
+	}
+
+	if _, ok := roomManager.zones[opts.ZoneName]; ok {
+		return result, errors.New(`zone already exists`)
+	}
+
+	if opts.Seed == `` {
+		opts.Seed = string(configs.GetConfig().Seed)
+	}
+
+	if opts.MobChance <= 0 {
+		opts.MobChance = 25
+	}
+
+	result.Seed = opts.Seed
+
+	rng := zoneGenRand(opts.Seed + `:` + opts.ZoneName + `:` + opts.Biome)
+	phrases := getZoneGenPhrases(opts.Biome)
+
+	rootRoomId, err := CreateZone(opts.ZoneName)
+	if err != nil {
+		return result, err
+	}
+
+	rootRoom := LoadRoom(rootRoomId)
+	if rootRoom == nil {
+		return result, fmt.Errorf(`room %d not found`, rootRoomId)
+	}
+
+	type gridPos struct{ x, y int }
+
+	allRooms := []*Room{rootRoom}
+	positions := map[int]gridPos{rootRoom.RoomId: {0, 0}}
+	grid := map[gridPos]*Room{{0, 0}: rootRoom}
+
+	//
+	// Lay out the rooms by growing off of random existing rooms
+	//
+	attempts := opts.RoomCount * 50
+	for len(allRooms) < opts.RoomCount && attempts > 0 {
+		attempts--
+
+		fromRoom := allRooms[rng.Intn(len(allRooms))]
+		direction := zoneGenDirections[rng.Intn(len(zoneGenDirections))]
+		delta := DirectionDeltas[direction]
+
+		pos := positions[fromRoom.RoomId]
+		newPos := gridPos{pos.x + delta.Dx, pos.y + delta.Dy}
+
+		if _, taken := grid[newPos]; taken {
+			continue
+		}
+
+		newRoom := NewRoom(opts.ZoneName)
+		addRoomToMemory(newRoom)
+
+		fromRoom.Exits[direction] = exit.RoomExit{RoomId: newRoom.RoomId}
+		newRoom.Exits[zoneGenOpposites[direction]] = exit.RoomExit{RoomId: fromRoom.RoomId}
+
+		allRooms = append(allRooms, newRoom)
+		positions[newRoom.RoomId] = newPos
+		grid[newPos] = newRoom
+	}
+
+	//
+	// Join some neighboring rooms so the zone isn't just a tree
+	//
+	for _, r := range allRooms {
+		pos := positions[r.RoomId]
+		for _, direction := range zoneGenDirections {
+			if _, ok := r.Exits[direction]; ok {
+				continue
+			}
+			delta := DirectionDeltas[direction]
+			neighbor, ok := grid[gridPos{pos.x + delta.Dx, pos.y + delta.Dy}]
+			if !ok || rng.Intn(100) >= 15 {
+				continue
+			}
+			r.Exits[direction] = exit.RoomExit{RoomId: neighbor.RoomId}
+			neighbor.Exits[zoneGenOpposites[direction]] = exit.RoomExit{RoomId: r.RoomId}
+		}
+	}
+
+	//
+	// Find the room furthest from the entrance for the boss
+	//
+	var bossRoom *Room = nil
+	if opts.BossMobId > 0 && len(allRooms) > 1 {
+		depth := map[int]int{rootRoom.RoomId: 0}
+		queue := []*Room{rootRoom}
+		bossRoom = rootRoom
+		for len(queue) > 0 {
+			r := queue[0]
+			queue = queue[1:]
+			for _, direction := range zoneGenDirections {
+				rExit, ok := r.Exits[direction]
+				if !ok {
+					continue
+				}
+				if _, seen := depth[rExit.RoomId]; seen {
+					continue
+				}
+				depth[rExit.RoomId] = depth[r.RoomId] + 1
+				next := LoadRoom(rExit.RoomId)
+				if depth[next.RoomId] > depth[bossRoom.RoomId] {
+					bossRoom = next
+				}
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	//
+	// Fill in the text, spawns and containers
+	//
+	for _, r := range allRooms {
+
+		r.Biome = opts.Biome
+		r.Title = zoneGenPick(rng, phrases.adjectives) + ` ` + zoneGenPick(rng, phrases.nouns)
+
+		var containerName string
+		if opts.ContainerChance > 0 && r != rootRoom && r != bossRoom && rng.Intn(100) < opts.ContainerChance {
+			containerName = zoneGenPick(rng, phrases.containerNames)
+		}
+
+		if r == bossRoom {
+			r.Title = zoneGenPick(rng, phrases.bossTitles)
+			r.Description = zoneGenDescription(rng, phrases, zoneGenPick(rng, phrases.bossDetails))
+			r.SpawnInfo = append(r.SpawnInfo, SpawnInfo{MobId: opts.BossMobId, ForceHostile: true, RespawnRate: `1 real hour`})
+			result.MobCt++
+			result.BossRoomId = r.RoomId
+		} else if containerName != `` {
+			r.Description = zoneGenDescription(rng, phrases, fmt.Sprintf(`A %s sits here.`, containerName))
+		} else {
+			r.Description = zoneGenDescription(rng, phrases)
+		}
+
+		if containerName != `` {
+			if r.Containers == nil {
+				r.Containers = map[string]Container{}
+			}
+			r.Containers[containerName] = Container{}
+
+			if len(opts.ContainerItemIds) > 0 {
+				r.SpawnInfo = append(r.SpawnInfo, SpawnInfo{Container: containerName, ItemId: opts.ContainerItemIds[rng.Intn(len(opts.ContainerItemIds))]})
+			}
+			r.SpawnInfo = append(r.SpawnInfo, SpawnInfo{Container: containerName, Gold: 5 + rng.Intn(26)})
+			result.ContainerCt++
+		}
+
+		if r != rootRoom && r != bossRoom && len(opts.MobIds) > 0 && rng.Intn(100) < opts.MobChance {
+			r.SpawnInfo = append(r.SpawnInfo, SpawnInfo{MobId: opts.MobIds[rng.Intn(len(opts.MobIds))]})
+			result.MobCt++
+		}
+
+		// Descriptions are stored hashed once in memory, so refresh the cache with the new text
+		hash := util.Hash(r.Description)
+		if _, ok := roomManager.roomDescriptionCache[hash]; !ok {
+			roomManager.roomDescriptionCache[hash] = r.Description
+		}
+
+		if err := r.Validate(); err != nil {
+			slog.Error("GenerateZone", "roomId", r.RoomId, "error", err)
+		}
+
+		r.Description = fmt.Sprintf(`h:%s`, hash)
+
+		SaveRoom(*r)
+
+		result.RoomIds = append(result.RoomIds, r.RoomId)
+	}
+
+	result.RootRoomId = rootRoom.RoomId
+
+	slog.Info("GenerateZone", "zone", opts.ZoneName, "biome", opts.Biome, "seed", opts.Seed, "rooms", len(allRooms), "mobs", result.MobCt, "containers", result.ContainerCt)
+
+	return result, nil
+}
@@ -0,0 +1,196 @@
+package rooms
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/volte6/gomud/internal/events"
+	"github.com/volte6/gomud/internal/exit"
+	"github.com/volte6/gomud/internal/gametime"
+	"github.com/volte6/gomud/internal/util"
+	"gopkg.in/yaml.v2"
+)
+
+// Restores every room in a zone to the state it was in when first loaded from disk.
+// Containers, floor items, stashes, gold, mutators, doors and locks are restored.
+// Living mobs are left alone, missing ones respawn right away.
+// Returns the roomIds that were reset.
+func ResetZone(zone string) ([]int, error) {
+
+	zoneInfo, ok := roomManager.zones[zone]
+	if !ok {
+		return nil, fmt.Errorf("zone %s does not exist.", zone)
+	}
+
+	resetRoomIds := []int{}
+
+	for _, roomId := range GetZoneRoomIds(zone) {
+
+		// Rooms that were never loaded are still as they were authored
+		if _, ok := roomManager.authoredRooms[roomId]; !ok {
+			continue
+		}
+
+		r := LoadRoom(roomId)
+		if r == nil {
+			continue
+		}
+
+		if err := r.resetToAuthored(); err != nil {
+			slog.Error("ResetZone()", "zone", zone, "roomId", roomId, "error", err)
+			continue
+		}
+
+		resetRoomIds = append(resetRoomIds, roomId)
+	}
+
+	zoneInfo.LastResetRound = util.GetRoundCount()
+	roomManager.zones[zone] = zoneInfo
+
+	events.AddToQueue(events.ZoneReset{
+		Zone:    zone,
+		RoomIds: resetRoomIds,
+	})
+
+	return resetRoomIds, nil
+}
+
+// Returns the round the zone was last reset, or 0 if it hasn't been
+func GetZoneLastReset(zone string) uint64 {
+	if zoneInfo, ok := roomManager.zones[zone]; ok {
+		return zoneInfo.LastResetRound
+	}
+	return 0
+}
+
+// Returns how many players are currently somewhere in the zone
+func GetZonePlayerCount(zone string) int {
+	zoneInfo, ok := roomManager.zones[zone]
+	if !ok {
+		return 0
+	}
+
+	playerCt := 0
+	for roomId := range zoneInfo.RoomIds {
+		if r, ok := roomManager.rooms[roomId]; ok {
+			playerCt += len(r.players)
+		}
+	}
+	return playerCt
+}
+
+func (r *Room) resetToAuthored() error {
+
+	data, ok := roomManager.authoredRooms[r.RoomId]
+	if !ok {
+		return fmt.Errorf("no authored copy of room %d", r.RoomId)
+	}
+
+	authored := &Room{}
+	if err := yaml.Unmarshal(data, authored); err != nil {
+		return err
+	}
+
+	// Validates items, resets doors etc.
+	if err := authored.Validate(); err != nil {
+		return err
+	}
+
+	// Temporary containers (chests, remains etc.) clean themselves up.
+	for name, c := range r.Containers {
+		if c.DespawnRound == 0 {
+			continue
+		}
+		if _, ok := authored.Containers[name]; !ok {
+			authored.Containers[name] = c
+		}
+	}
+
+	r.Containers = authored.Containers
+	r.Items = authored.Items
+	r.Stash = authored.Stash
+	r.Gold = authored.Gold
+	r.Mutators = authored.Mutators
+
+	if r.ZoneConfig.RoomId == r.RoomId {
+		r.ZoneConfig.Mutators = authored.ZoneConfig.Mutators
+	}
+
+	// Keep the current exits (a builder may have added some), but close/lock them up again.
+	for exitName, exitInfo := range r.Exits {
+		if exitInfo.Door != nil {
+			exitInfo.Door.Reset()
+			r.resetReturnDoor(exitInfo)
+		}
+		exitInfo.Lock.SetLocked()
+		r.Exits[exitName] = exitInfo
+	}
+
+	// Anything that isn't around anymore should come right back
+	for idx, spawnInfo := range r.SpawnInfo {
+		if spawnInfo.InstanceId == 0 {
+			spawnInfo.DespawnedRound = 0
+			r.SpawnInfo[idx] = spawnInfo
+		}
+	}
+
+	r.Prepare(false)
+
+	return nil
+}
+
+func zoneResetMaintenance(roundNow uint64) {
+
+	for zone, zoneInfo := range roomManager.zones {
+
+		// Only loaded root rooms are checked, to avoid loading every zone from disk.
+		rootRoom, ok := roomManager.rooms[zoneInfo.RootRoomId]
+		if !ok {
+			continue
+		}
+
+		zoneConfig := rootRoom.ZoneConfig
+
+		policy := zoneConfig.GetResetPolicy()
+		if policy == ResetManual {
+			continue
+		}
+
+		// Start counting from the first time we see it
+		if zoneInfo.LastResetRound == 0 {
+			zoneInfo.LastResetRound = roundNow
+			roomManager.zones[zone] = zoneInfo
+			continue
+		}
+
+		if roundNow < gametime.GetDate(zoneInfo.LastResetRound).AddPeriod(zoneConfig.GetResetInterval()) {
+			continue
+		}
+
+		if policy == ResetEmpty && GetZonePlayerCount(zone) > 0 {
+			continue
+		}
+
+		if _, err := ResetZone(zone); err != nil {
+			slog.Error("zoneResetMaintenance()", "zone", zone, "error", err)
+		}
+	}
+
+}
+
+// The other side of a door may be in another zone (or not reset yet), so match it to this side.
+// Unloaded rooms are left alone, since syncDoors() picks up the state when they load.
+func (r *Room) resetReturnDoor(exitInfo exit.RoomExit) {
+
+	otherRoom, ok := roomManager.rooms[exitInfo.RoomId]
+	if !ok || otherRoom == r {
+		return
+	}
+
+	for _, otherExit := range otherRoom.Exits {
+		if otherExit.RoomId == r.RoomId && otherExit.HasDoor() {
+			otherExit.Door.Open = exitInfo.Door.Open
+			otherExit.Door.OpenedRound = exitInfo.Door.OpenedRound
+		}
+	}
+}
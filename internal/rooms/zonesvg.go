@@ -0,0 +1,303 @@
+package rooms
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+)
+
+//
+// Renders a RoomGraph as an SVG image, for viewing zone layouts in a browser.
+//
+
+const (
+	svgCellSize   = 48 // Distance between room centers
+	svgRoomSize   = 26 // Width/height of a room square
+	svgMargin     = 24
+	svgLegendLine = 20
+)
+
+var (
+	// Colors for biomes and common map legends. Anything else gets a color picked from svgPalette.
+	svgLegendColors = map[string]string{
+		`room`:       `#9e9e9e`,
+		`city`:       `#d9d9d9`,
+		`fort`:       `#a6a6a6`,
+		`road`:       `#b39b72`,
+		`house`:      `#c9a227`,
+		`shore`:      `#7fb2e5`,
+		`deep water`: `#2a5db0`,
+		`forest`:     `#3f8f34`,
+		`mountains`:  `#8c6a3f`,
+		`cliffs`:     `#7a6a5a`,
+		`swamp`:      `#5d7045`,
+		`snow`:       `#f2f7ff`,
+		`spiderweb`:  `#c8c8c8`,
+		`cave`:       `#5a5050`,
+		`desert`:     `#e3c16f`,
+		`farmland`:   `#9ccc65`,
+		`shop`:       `#2e9e44`,
+		`bank`:       `#8e44ad`,
+		`trainer`:    `#3b7dd8`,
+		`wall`:       `#37474f`,
+		`bridge`:     `#a1887f`,
+	}
+
+	svgPalette = []string{`#e57373`, `#ba68c8`, `#4fc3f7`, `#4db6ac`, `#aed581`, `#ffb74d`, `#f06292`, `#7986cb`, `#dce775`, `#a1887f`}
+)
+
+type SVGMapOptions struct {
+	Zone        string // If set, rooms outside this zone are faded out
+	ShowSecrets bool   // Draw secret exits (dashed) and secret up/down markers
+	ShowRoomIds bool   // Include RoomIds and titles when hovering over rooms
+	ZLevel      int    // Which level to draw. Only rooms with Coordinates can be on anything but 0.
+	Explored    bool   // Only draw rooms that players have been to
+}
+
+func svgLegendColor(legend string) string {
+	legend = strings.ToLower(legend)
+	if color, ok := svgLegendColors[legend]; ok {
+		return color
+	}
+	hash := 0
+	for _, r := range legend {
+		hash = hash*31 + int(r)
+	}
+	if hash < 0 {
+		hash = -hash
+	}
+	return svgPalette[hash%len(svgPalette)]
+}
+
+// Draws every room in the graph as a square colored by its map legend (biome or MapSymbol),
+// with lines for exits and small arrows for up/down exits.
+func DrawZoneSVG(rGraph *RoomGraph, title string, opts SVGMapOptions) string {
+
+	if rGraph.Root == nil {
+		return ``
+	}
+
+	originZ := 0
+	if rGraph.origin != nil {
+		originZ = rGraph.origin.Z
+	}
+
+	isExplored := func(roomId int) bool {
+		if !opts.Explored {
+			return true
+		}
+		return IsExplored(roomId)
+	}
+
+	nodes := make([]*roomNode, 0, len(rGraph.trackedRoomIds))
+	drawnRoomIds := map[int]struct{}{}
+	minX, maxX, minY, maxY := 0, 0, 0, 0
+	for _, node := range rGraph.trackedRoomIds {
+		if node.zPos+originZ != opts.ZLevel {
+			continue
+		}
+		if !isExplored(node.RoomId) {
+			continue
+		}
+		drawnRoomIds[node.RoomId] = struct{}{}
+		if len(nodes) == 0 {
+			minX, maxX, minY, maxY = node.xPos, node.xPos, node.yPos, node.yPos
+		}
+		minX, maxX = min(minX, node.xPos), max(maxX, node.xPos)
+		minY, maxY = min(minY, node.yPos), max(maxY, node.yPos)
+		nodes = append(nodes, node)
+	}
+	// Keep the output the same between requests
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].RoomId < nodes[j].RoomId
+	})
+
+	posX := func(n *roomNode) int {
+		return svgMargin + (n.xPos-minX)*svgCellSize + svgCellSize/2
+	}
+	posY := func(n *roomNode) int {
+		return svgMargin*2 + (n.yPos-minY)*svgCellSize + svgCellSize/2
+	}
+
+	legend := map[string]string{}
+
+	links := strings.Builder{}
+	rooms := strings.Builder{}
+	markers := strings.Builder{}
+
+	drawnLinks := map[[2]int]struct{}{}
+
+	for _, node := range nodes {
+
+		// Exits, only drawn once between any two rooms
+		exitNames := make([]string, 0, len(node.Exits))
+		for exitName := range node.Exits {
+			exitNames = append(exitNames, exitName)
+		}
+		sort.Strings(exitNames)
+
+		for _, exitName := range exitNames {
+
+			toNode := node.Exits[exitName]
+
+			// Exits to other levels are shown as up/down markers instead
+			if toNode.zPos != node.zPos {
+				continue
+			}
+
+			if _, ok := drawnRoomIds[toNode.RoomId]; !ok {
+				continue
+			}
+
+			_, isSecret := node.SecretExits[exitName]
+			if isSecret && !opts.ShowSecrets {
+				continue
+			}
+
+			linkKey := [2]int{min(node.RoomId, toNode.RoomId), max(node.RoomId, toNode.RoomId)}
+			if _, ok := drawnLinks[linkKey]; ok {
+				continue
+			}
+			drawnLinks[linkKey] = struct{}{}
+
+			dash := ``
+			if isSecret {
+				dash = ` stroke-dasharray="4 3"`
+			}
+
+			links.WriteString(fmt.Sprintf(`<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#777" stroke-width="2"%s/>`+"\n",
+				posX(node), posY(node), posX(toNode), posY(toNode), dash))
+		}
+
+		symbol := string(node.Symbol)
+		legendName := node.Legend
+		if override, ok := rGraph.forceRoomSymbol[node.RoomId]; ok {
+			symbol = string(override.Symbol)
+			legendName = override.Legend
+		}
+		if newSymbol, ok := MapSymbolOverrides[symbol]; ok {
+			symbol = newSymbol
+		}
+
+		color := svgLegendColor(legendName)
+		legend[legendName] = color
+
+		opacity := `1`
+		hoverText := ``
+
+		if room := LoadRoom(node.RoomId); room != nil {
+
+			if opts.Zone != `` && room.Zone != opts.Zone {
+				opacity = `0.35`
+			}
+
+			if opts.ShowRoomIds {
+				hoverText = fmt.Sprintf(`#%d %s (%s)`, room.RoomId, room.Title, room.Zone)
+			} else {
+				hoverText = room.Title
+			}
+
+			// Up and down can't be placed on a flat map, so they are marked on the room instead
+			for exitName, exitInfo := range room.Exits {
+				if exitInfo.Secret && !opts.ShowSecrets {
+					continue
+				}
+				if !isExplored(exitInfo.RoomId) {
+					continue
+				}
+				x, y := posX(node)+svgRoomSize/2, posY(node)
+				switch exitName {
+				case `up`:
+					markers.WriteString(fmt.Sprintf(`<polygon points="%d,%d %d,%d %d,%d" fill="#fff" stroke="#333" opacity="%s"><title>up to %d</title></polygon>`+"\n",
+						x-4, y-svgRoomSize/2+8, x+4, y-svgRoomSize/2+8, x, y-svgRoomSize/2, opacity, exitInfo.RoomId))
+				case `down`:
+					markers.WriteString(fmt.Sprintf(`<polygon points="%d,%d %d,%d %d,%d" fill="#fff" stroke="#333" opacity="%s"><title>down to %d</title></polygon>`+"\n",
+						x-4, y+svgRoomSize/2-8, x+4, y+svgRoomSize/2-8, x, y+svgRoomSize/2, opacity, exitInfo.RoomId))
+				}
+			}
+		}
+
+		rooms.WriteString(fmt.Sprintf(`<g opacity="%s"><title>%s</title>`, opacity, html.EscapeString(hoverText)))
+		rooms.WriteString(fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d" rx="4" fill="%s" stroke="#222"/>`,
+			posX(node)-svgRoomSize/2, posY(node)-svgRoomSize/2, svgRoomSize, svgRoomSize, color))
+		rooms.WriteString(fmt.Sprintf(`<text x="%d" y="%d" text-anchor="middle" dominant-baseline="central" font-size="14" fill="#111">%s</text>`,
+			posX(node), posY(node), html.EscapeString(symbol)))
+		rooms.WriteString("</g>\n")
+	}
+
+	legendNames := make([]string, 0, len(legend))
+	for name := range legend {
+		legendNames = append(legendNames, name)
+	}
+	sort.Strings(legendNames)
+
+	mapWidth := (maxX-minX+1)*svgCellSize + svgMargin*2
+	mapHeight := (maxY-minY+1)*svgCellSize + svgMargin*3
+
+	width := max(mapWidth, 240)
+	height := mapHeight + len(legendNames)*svgLegendLine + svgMargin
+
+	out := strings.Builder{}
+	out.WriteString(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">`+"\n", width, height, width, height))
+	out.WriteString(`<rect width="100%" height="100%" fill="#1e1e1e"/>` + "\n")
+	out.WriteString(fmt.Sprintf(`<text x="%d" y="%d" font-size="16" fill="#eee">%s</text>`+"\n", svgMargin, svgMargin, html.EscapeString(title)))
+	out.WriteString(links.String())
+	out.WriteString(rooms.String())
+	out.WriteString(markers.String())
+
+	for idx, name := range legendNames {
+		y := mapHeight + idx*svgLegendLine
+		out.WriteString(fmt.Sprintf(`<rect x="%d" y="%d" width="14" height="14" rx="3" fill="%s" stroke="#222"/>`, svgMargin, y, legend[name]))
+		out.WriteString(fmt.Sprintf(`<text x="%d" y="%d" font-size="12" fill="#ccc">%s</text>`+"\n", svgMargin+22, y+11, html.EscapeString(name)))
+	}
+
+	out.WriteString(`</svg>`)
+
+	return out.String()
+}
+
+// Returns an SVG map of every room in a zone that can be reached from its root room.
+// Rooms with Coordinates are drawn on their own level, so zLevel picks which one is drawn.
+func GetZoneSVG(zone string, zLevel int, showSecrets bool, showRoomIds bool, exploredOnly bool) (string, error) {
+
+	rootRoomId, err := GetZoneRoot(zone)
+	if err != nil {
+		return ``, err
+	}
+
+	zoneRoomIds := GetZoneRoomIds(zone)
+
+	// Zones without a root room start from their lowest RoomId
+	if rootRoomId == 0 {
+		if len(zoneRoomIds) == 0 {
+			return ``, fmt.Errorf("zone %s has no rooms", zone)
+		}
+		rootRoomId = zoneRoomIds[0]
+	}
+
+	mapMode := MapModeAllButSecrets
+	if showSecrets {
+		mapMode = MapModeAll
+	}
+
+	rGraph := NewRoomGraph(500, 500, 0, mapMode)
+	rGraph.LimitToRooms(zoneRoomIds...)
+
+	if err := rGraph.Build(rootRoomId, nil); err != nil {
+		return ``, err
+	}
+
+	title := zone
+	if zLevel != 0 {
+		title = fmt.Sprintf(`%s (level %d)`, zone, zLevel)
+	}
+
+	return DrawZoneSVG(rGraph, title, SVGMapOptions{
+		Zone:        zone,
+		ShowSecrets: showSecrets,
+		ShowRoomIds: showRoomIds,
+		ZLevel:      zLevel,
+		Explored:    exploredOnly,
+	}), nil
+}
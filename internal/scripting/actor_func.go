@@ -9,6 +9,7 @@ import (
 	"github.com/volte6/gomud/internal/combat"
 	"github.com/volte6/gomud/internal/configs"
 	"github.com/volte6/gomud/internal/events"
+	"github.com/volte6/gomud/internal/items"
 	"github.com/volte6/gomud/internal/mobs"
 	"github.com/volte6/gomud/internal/parties"
 	"github.com/volte6/gomud/internal/pets"
@@ -285,7 +286,22 @@ func (a ScriptActor) AddGold(amt int, bankAmt ...int) {
 }
 
 func (a ScriptActor) AddHealth(amt int) int {
-	return a.characterRecord.ApplyHealthChange(amt)
+	amt = a.characterRecord.ApplyHealthChange(amt)
+	tryDamagedEvent(&a, amt*-1)
+	return amt
+}
+
+// Hurts the actor with damage of an element, after their resistance to it.
+// Returns how much damage was actually done.
+func (a ScriptActor) TakeDamage(amt int, element string) int {
+	amt = a.characterRecord.ResistDamage(amt, items.Element(strings.ToLower(element)))
+	a.characterRecord.ApplyHealthChange(amt * -1)
+	tryDamagedEvent(&a, amt)
+	return amt
+}
+
+func (a ScriptActor) GetResistance(element string) int {
+	return a.characterRecord.GetResistance(items.Element(strings.ToLower(element)))
 }
 
 func (a ScriptActor) AddMana(amt int) int {
@@ -343,6 +359,8 @@ func (a ScriptActor) GetSkillLevel(skillName string) int {
 
 func (a ScriptActor) MoveRoom(destRoomId int, leaveCharmedMobs ...bool) {
 
+	destRoomId = instanceRoomId(destRoomId)
+
 	if a.userRecord != nil {
 
 		rmNow := rooms.LoadRoom(a.characterRecord.RoomId)
@@ -350,6 +368,13 @@ func (a ScriptActor) MoveRoom(destRoomId int, leaveCharmedMobs ...bool) {
 		if rmNext := rooms.LoadRoom(destRoomId); rmNext != nil {
 			rooms.MoveToRoom(a.userId, destRoomId)
 
+			// May have been sent to a zone instance instead
+			if a.characterRecord.RoomId != rmNext.RoomId {
+				if rmActual := rooms.LoadRoom(a.characterRecord.RoomId); rmActual != nil {
+					rmNext = rmActual
+				}
+			}
+
 			if len(leaveCharmedMobs) < 1 || !leaveCharmedMobs[0] {
 				for _, mobInstId := range a.characterRecord.GetCharmIds() {
 					rmNow.RemoveMob(mobInstId)
@@ -401,16 +426,28 @@ func (a ScriptActor) HasBuff(buffId int) bool {
 	return a.characterRecord.HasBuff(buffId)
 }
 
-func (a ScriptActor) GiveBuff(buffId int) {
+func (a ScriptActor) GiveBuff(buffId int, source ...ScriptActor) {
 
-	events.AddToQueue(events.Buff{
+	buffEvent := events.Buff{
 		UserId:        a.userId,
 		MobInstanceId: a.mobInstanceId,
 		BuffId:        buffId,
-	})
+	}
+
+	// Who it came from matters for buffs that stack per source
+	if len(source) > 0 {
+		buffEvent.SourceUserId = source[0].userId
+		buffEvent.SourceMobInstanceId = source[0].mobInstanceId
+	}
+
+	events.AddToQueue(buffEvent)
 
 }
 
+func (a ScriptActor) GetBuffStacks(buffId int) int {
+	return a.characterRecord.GetBuffStacks(buffId)
+}
+
 func (a ScriptActor) GetStatMod(statModName string) int {
 	return a.characterRecord.StatMod(statModName)
 }
@@ -494,6 +531,22 @@ func (a ScriptActor) IsAggro(actor ScriptActor) bool {
 	return a.characterRecord.IsAggro(actor.UserId(), actor.InstanceId())
 }
 
+func (a ScriptActor) GetThreat(actor ScriptActor) int {
+	return a.characterRecord.GetThreat(actor.threatUserId())
+}
+
+func (a ScriptActor) AddThreat(actor ScriptActor, amount int) {
+	a.characterRecord.AddThreat(actor.threatUserId(), amount)
+}
+
+// Threat is tracked by player. Charmed mobs count as their master.
+func (a ScriptActor) threatUserId() int {
+	if a.userId > 0 {
+		return a.userId
+	}
+	return a.characterRecord.GetCharmedUserId()
+}
+
 func (a ScriptActor) GetMobKills(mobId int) int {
 	return a.characterRecord.KD.GetMobKills(mobId)
 }
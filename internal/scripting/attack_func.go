@@ -0,0 +1,87 @@
+package scripting
+
+import (
+	"github.com/volte6/gomud/internal/buffs"
+	"github.com/volte6/gomud/internal/combat"
+)
+
+// An attack that hasn't done any damage yet, so scripts can change or cancel it
+type ScriptAttack struct {
+	attackResult *combat.AttackResult
+}
+
+func newScriptAttack(attackResult *combat.AttackResult) ScriptAttack {
+	return ScriptAttack{attackResult: attackResult}
+}
+
+func (a ScriptAttack) IsHit() bool {
+	return a.attackResult.Hit
+}
+
+func (a ScriptAttack) IsCrit() bool {
+	return a.attackResult.Crit
+}
+
+func (a ScriptAttack) IsCancelled() bool {
+	return a.attackResult.Cancelled
+}
+
+func (a ScriptAttack) GetDamage() int {
+	return a.attackResult.DamageToTarget
+}
+
+func (a ScriptAttack) SetDamage(amount int) int {
+	if amount < 0 {
+		amount = 0
+	}
+	a.attackResult.DamageToTarget = amount
+	return amount
+}
+
+func (a ScriptAttack) AddDamage(amount int) int {
+	return a.SetDamage(a.attackResult.DamageToTarget + amount)
+}
+
+func (a ScriptAttack) GetSelfDamage() int {
+	return a.attackResult.DamageToSource
+}
+
+func (a ScriptAttack) SetSelfDamage(amount int) int {
+	if amount < 0 {
+		amount = 0
+	}
+	a.attackResult.DamageToSource = amount
+	return amount
+}
+
+func (a ScriptAttack) Cancel() {
+	a.attackResult.Cancel()
+}
+
+func (a ScriptAttack) AddTargetBuff(buffId int) bool {
+	if a.attackResult.Cancelled || buffs.GetBuffSpec(buffId) == nil {
+		return false
+	}
+	a.attackResult.BuffTarget = append(a.attackResult.BuffTarget, buffId)
+	return true
+}
+
+func (a ScriptAttack) AddSelfBuff(buffId int) bool {
+	if a.attackResult.Cancelled || buffs.GetBuffSpec(buffId) == nil {
+		return false
+	}
+	a.attackResult.BuffSource = append(a.attackResult.BuffSource, buffId)
+	return true
+}
+
+func (a ScriptAttack) SendToAttacker(msg string) {
+	a.attackResult.SendToSource(msg)
+}
+
+func (a ScriptAttack) SendToTarget(msg string) {
+	a.attackResult.SendToTarget(msg)
+}
+
+func (a ScriptAttack) SendToRoom(msg string) {
+	a.attackResult.SendToSourceRoom(msg)
+}
@@ -24,7 +24,10 @@ func PruneBuffVMs(instanceIds ...int) {
 	// Do not prune, they dont' get a VM per buff instance.
 }
 
-func TryBuffScriptEvent(eventName string, userId int, mobInstanceId int, buffId int) (bool, error) {
+// Any details are passed to the script function as a third argument, such as for combat events
+func TryBuffScriptEvent(eventName string, userId int, mobInstanceId int, buffId int, details ...map[string]any) (bool, error) {
+
+	defer useRoomInstance(actorRoomId(userId, mobInstanceId))()
 
 	slog.Info("TryBuffScriptEvent()", "eventName", eventName, "buffId", buffId)
 	vmw, err := getBuffVM(buffId)
@@ -49,10 +52,15 @@ func TryBuffScriptEvent(eventName string, userId int, mobInstanceId int, buffId
 			vmw.VM.Interrupt(errTimeout)
 		})
 
-		res, err := onCommandFunc(goja.Undefined(),
+		args := []goja.Value{
 			vmw.VM.ToValue(actorInfo),
 			vmw.VM.ToValue(buffTriggersLeft),
-		)
+		}
+		for _, d := range details {
+			args = append(args, vmw.VM.ToValue(d))
+		}
+
+		res, err := onCommandFunc(goja.Undefined(), args...)
 		vmw.VM.ClearInterrupt()
 		tmr.Stop()
 
@@ -87,6 +95,8 @@ func TryBuffScriptEvent(eventName string, userId int, mobInstanceId int, buffId
 
 func TryBuffCommand(cmd string, rest string, userId int, mobInstanceId int, buffId int) (bool, error) {
 
+	defer useRoomInstance(actorRoomId(userId, mobInstanceId))()
+
 	vmw, err := getBuffVM(buffId)
 	if err != nil {
 		return false, err
@@ -187,7 +197,7 @@ func getBuffVM(buffId int) (*VMWrapper, error) {
 
 	bSpec := buffs.GetBuffSpec(buffId)
 	if bSpec == nil {
-		return nil, fmt.Errorf("buff spec not found: %d", bSpec)
+		return nil, fmt.Errorf("buff spec not found: %d", buffId)
 	}
 
 	script := bSpec.GetScript()
@@ -0,0 +1,142 @@
+package scripting
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/volte6/gomud/internal/calendar"
+)
+
+var (
+	calendarVMCache       = make(map[string]*VMWrapper)
+	scriptCalendarTimeout = 50 * time.Millisecond
+)
+
+func ClearCalendarVMs() {
+	clear(calendarVMCache)
+}
+
+func PruneCalendarVMs(instanceIds ...int) {
+	// Do not prune, there is only ever one VM per event.
+}
+
+func TryCalendarScriptEvent(eventName string, eventId string) (bool, error) {
+
+	vmw, err := getCalendarVM(eventId)
+	if err != nil {
+		return false, err
+	}
+
+	eventSpec := calendar.GetEvent(eventId)
+
+	timestart := time.Now()
+	defer func() {
+		slog.Debug("TryCalendarScriptEvent()", "eventName", eventName, "eventId", eventId, "time", time.Since(timestart))
+	}()
+
+	if onCommandFunc, ok := vmw.GetFunction(eventName); ok {
+
+		eventInfo := map[string]any{
+			`EventId`:     eventSpec.EventId,
+			`Name`:        eventSpec.Name,
+			`Description`: eventSpec.Description,
+		}
+
+		tmr := time.AfterFunc(scriptCalendarTimeout, func() {
+			vmw.VM.Interrupt(errTimeout)
+		})
+
+		res, err := onCommandFunc(goja.Undefined(),
+			vmw.VM.ToValue(eventInfo),
+		)
+		vmw.VM.ClearInterrupt()
+		tmr.Stop()
+
+		if err != nil {
+
+			// Wrap the error
+			finalErr := fmt.Errorf("%s(): %w", eventName, err)
+
+			if _, ok := finalErr.(*goja.Exception); ok {
+				slog.Error("JSVM", "exception", finalErr)
+				return false, finalErr
+			} else if errors.Is(finalErr, errTimeout) {
+				slog.Error("JSVM", "interrupted", finalErr)
+				return false, finalErr
+			}
+
+			slog.Error("JSVM", "error", finalErr)
+			return false, finalErr
+		}
+
+		if boolVal, ok := res.Export().(bool); ok {
+			return boolVal, nil
+		}
+	}
+
+	return false, nil
+}
+
+func getCalendarVM(eventId string) (*VMWrapper, error) {
+
+	if vm, ok := calendarVMCache[eventId]; ok {
+		if vm == nil {
+			return nil, errNoScript
+		}
+		return vm, nil
+	}
+
+	eventSpec := calendar.GetEvent(eventId)
+	if eventSpec == nil {
+		return nil, fmt.Errorf("calendar event %s not found", eventId)
+	}
+
+	script := eventSpec.GetScript()
+	if len(script) == 0 {
+		calendarVMCache[eventId] = nil
+		return nil, errNoScript
+	}
+
+	vm := goja.New()
+	setAllScriptingFunctions(vm)
+
+	prg, err := goja.Compile(fmt.Sprintf(`calendar-%s`, eventId), script, false)
+	if err != nil {
+		finalErr := fmt.Errorf("Compile: %w", err)
+		return nil, finalErr
+	}
+
+	//
+	// Run the program
+	//
+	tmr := time.AfterFunc(scriptLoadTimeout, func() {
+		vm.Interrupt(errTimeout)
+	})
+	if _, err = vm.RunProgram(prg); err != nil {
+
+		// Wrap the error
+		finalErr := fmt.Errorf("RunProgram: %w", err)
+
+		if _, ok := finalErr.(*goja.Exception); ok {
+			slog.Error("JSVM", "exception", finalErr)
+			return nil, finalErr
+		} else if errors.Is(finalErr, errTimeout) {
+			slog.Error("JSVM", "interrupted", finalErr)
+			return nil, finalErr
+		}
+
+		slog.Error("JSVM", "error", finalErr)
+		return nil, finalErr
+	}
+	vm.ClearInterrupt()
+	tmr.Stop()
+
+	vmw := newVMWrapper(vm, 0)
+
+	calendarVMCache[eventId] = vmw
+
+	return vmw, nil
+}
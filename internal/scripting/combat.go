@@ -0,0 +1,219 @@
+package scripting
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/volte6/gomud/internal/combat"
+	"github.com/volte6/gomud/internal/items"
+)
+
+var (
+	damagedEventRunning = false
+)
+
+// Called at the start of each combat round a character attacks in, before the attack happens.
+// Returns true if a script handled the round, meaning the normal attack should be skipped.
+func TryCombatRoundEvent(userId int, mobInstanceId int, targetUserId int, targetMobInstanceId int) bool {
+
+	defer useRoomInstance(actorRoomId(userId, mobInstanceId))()
+
+	sActor := GetActor(userId, mobInstanceId)
+	if sActor == nil {
+		return false
+	}
+
+	return tryCombatScriptEvent(`onCombatRound`, sActor, GetActor(targetUserId, targetMobInstanceId), map[string]any{
+		`round`: sActor.characterRecord.CombatRounds,
+	})
+}
+
+// Returns a modifier that gives the attacker's scripts a chance to change or cancel an attack before any damage is done
+func GetAttackModifier(userId int, mobInstanceId int, targetUserId int, targetMobInstanceId int) combat.AttackModifier {
+	return func(attackResult *combat.AttackResult) {
+
+		sActor := GetActor(userId, mobInstanceId)
+		if sActor == nil {
+			return
+		}
+
+		tryCombatScriptEvent(`onAttack`, sActor, GetActor(targetUserId, targetMobInstanceId), map[string]any{
+			`attack`: newScriptAttack(attackResult),
+		})
+	}
+}
+
+// Called once an attack has done its damage.
+// Whoever took damage gets onDamaged(), and whoever dealt a killing blow gets onKill()
+func TryAttackResultEvents(userId int, mobInstanceId int, targetUserId int, targetMobInstanceId int, attackResult combat.AttackResult) {
+
+	defer useRoomInstance(actorRoomId(userId, mobInstanceId))()
+
+	if attackResult.Cancelled {
+		return
+	}
+
+	sActor := GetActor(userId, mobInstanceId)
+	sTarget := GetActor(targetUserId, targetMobInstanceId)
+	if sActor == nil || sTarget == nil {
+		return
+	}
+
+	if attackResult.DamageToTarget > 0 {
+
+		tryCombatScriptEvent(`onDamaged`, sTarget, sActor, map[string]any{
+			`amount`: attackResult.DamageToTarget,
+			`crit`:   attackResult.Crit,
+		})
+
+		if sTarget.characterRecord.Health <= 0 {
+			tryCombatScriptEvent(`onKill`, sActor, sTarget, nil)
+		}
+	}
+
+	if attackResult.DamageToSource > 0 {
+
+		tryCombatScriptEvent(`onDamaged`, sActor, sTarget, map[string]any{
+			`amount`: attackResult.DamageToSource,
+			`crit`:   false,
+		})
+
+		if sActor.characterRecord.Health <= 0 {
+			tryCombatScriptEvent(`onKill`, sTarget, sActor, nil)
+		}
+	}
+}
+
+// Called when something other than an attack hurts a character, such as a spell or a buff.
+// There is no opponent, since whatever did it may not be around anymore.
+func tryDamagedEvent(sActor *ScriptActor, amount int) {
+
+	// An onDamaged() that does damage of its own shouldn't set itself off again
+	if amount < 1 || damagedEventRunning {
+		return
+	}
+
+	damagedEventRunning = true
+	defer func() {
+		damagedEventRunning = false
+	}()
+
+	tryCombatScriptEvent(`onDamaged`, sActor, nil, map[string]any{
+		`amount`: amount,
+		`crit`:   false,
+	})
+}
+
+// Sends a combat event to everything that can react to it for a character:
+// their mob script (if a mob), the scripts of any equipment they are wearing, and the scripts of their buffs.
+// Returns true if any of them returned true.
+func tryCombatScriptEvent(eventName string, sActor *ScriptActor, sOpponent *ScriptActor, details map[string]any) bool {
+
+	if details == nil {
+		details = make(map[string]any)
+	}
+
+	sourceId, sourceType := 0, ``
+	if sOpponent != nil {
+		if sOpponent.userId > 0 {
+			sourceId, sourceType = sOpponent.userId, `user`
+		} else {
+			sourceId, sourceType = sOpponent.mobInstanceId, `mob`
+		}
+		details[`opponent`] = sOpponent
+	}
+
+	details[`sourceId`] = sourceId
+	details[`sourceType`] = sourceType
+
+	handled := false
+
+	if sActor.mobInstanceId > 0 {
+		if res, err := TryMobScriptEvent(eventName, sActor.mobInstanceId, sourceId, sourceType, details); err == nil && res {
+			handled = true
+		}
+	}
+
+	for _, itm := range sActor.characterRecord.Equipment.GetAllItems() {
+		if res, err := tryWornItemScriptEvent(eventName, itm, sActor, details); err == nil && res {
+			handled = true
+		}
+	}
+
+	buffIdsDone := map[int]struct{}{}
+	for _, b := range sActor.characterRecord.GetBuffs() {
+		if _, ok := buffIdsDone[b.BuffId]; ok {
+			continue
+		}
+		buffIdsDone[b.BuffId] = struct{}{}
+
+		if res, err := TryBuffScriptEvent(eventName, sActor.userId, sActor.mobInstanceId, b.BuffId, details); err == nil && res {
+			handled = true
+		}
+	}
+
+	return handled
+}
+
+// Like TryItemScriptEvent() but for items being worn, which mobs can have too
+func tryWornItemScriptEvent(eventName string, item items.Item, sActor *ScriptActor, details map[string]any) (bool, error) {
+
+	sItem := GetItem(item)
+
+	vmw, err := getItemVM(sItem)
+	if err != nil {
+		return false, err
+	}
+
+	timestart := time.Now()
+	defer func() {
+		slog.Debug("tryWornItemScriptEvent()", "eventName", eventName, "item", item, "time", time.Since(timestart))
+	}()
+
+	if onCommandFunc, ok := vmw.GetFunction(eventName); ok {
+
+		sRoom := GetRoom(sActor.GetRoomId())
+
+		tmr := time.AfterFunc(scriptItemTimeout, func() {
+			vmw.VM.Interrupt(errTimeout)
+		})
+		res, err := onCommandFunc(goja.Undefined(),
+			vmw.VM.ToValue(sActor),
+			vmw.VM.ToValue(sItem),
+			vmw.VM.ToValue(sRoom),
+			vmw.VM.ToValue(details),
+		)
+		vmw.VM.ClearInterrupt()
+		tmr.Stop()
+
+		if err != nil {
+
+			// Wrap the error
+			finalErr := fmt.Errorf("%s(): %w", eventName, err)
+
+			if _, ok := finalErr.(*goja.Exception); ok {
+				slog.Error("JSVM", "exception", finalErr)
+				return false, finalErr
+			} else if errors.Is(finalErr, errTimeout) {
+				slog.Error("JSVM", "interrupted", finalErr)
+				return false, finalErr
+			}
+
+			slog.Error("JSVM", "error", finalErr)
+			return false, finalErr
+		}
+
+		// Save any changed that might have happened to the item
+		sActor.characterRecord.UpdateItem(item, *sItem.itemRecord)
+
+		if boolVal, ok := res.Export().(bool); ok {
+			return boolVal, nil
+		}
+
+	}
+
+	return false, nil
+}
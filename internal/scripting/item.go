@@ -26,6 +26,8 @@ func PruneItemVMs(instanceIds ...int) {
 
 func TryItemScriptEvent(eventName string, item items.Item, userId int) (bool, error) {
 
+	defer useRoomInstance(actorRoomId(userId, 0))()
+
 	sItem := GetItem(item)
 
 	timestart := time.Now()
@@ -87,6 +89,8 @@ func TryItemScriptEvent(eventName string, item items.Item, userId int) (bool, er
 
 func TryItemCommand(cmd string, item items.Item, userId int) (bool, error) {
 
+	defer useRoomInstance(actorRoomId(userId, 0))()
+
 	sItem := GetItem(item)
 
 	timestart := time.Now()
@@ -32,7 +32,7 @@ func SendRoomMessage(roomId int, message string, excludeIds ...int) {
 		return
 	}
 
-	r := rooms.LoadRoom(roomId)
+	r := rooms.LoadRoom(instanceRoomId(roomId))
 	if r == nil {
 		return
 	}
@@ -47,7 +47,7 @@ func SendRoomExitsMessage(roomId int, message string, isQuiet bool, excludeUserI
 		return
 	}
 
-	r := rooms.LoadRoom(roomId)
+	r := rooms.LoadRoom(instanceRoomId(roomId))
 	if r == nil {
 		return
 	}
@@ -24,6 +24,8 @@ func PruneMobVMs(instanceIds ...int) {
 
 func TryMobConverse(rest string, mobInstanceId int, sourceMobInstanceId int) (bool, error) {
 
+	defer useRoomInstance(actorRoomId(0, mobInstanceId))()
+
 	sMob := GetActor(0, mobInstanceId)
 	if sMob == nil {
 		return false, errors.New("mob not found")
@@ -87,6 +89,8 @@ func TryMobConverse(rest string, mobInstanceId int, sourceMobInstanceId int) (bo
 
 func TryMobScriptEvent(eventName string, mobInstanceId int, sourceId int, sourceType string, details map[string]any) (bool, error) {
 
+	defer useRoomInstance(actorRoomId(0, mobInstanceId))()
+
 	sMob := GetActor(0, mobInstanceId)
 	if sMob == nil {
 		return false, errors.New("mob not found")
@@ -151,6 +155,8 @@ func TryMobScriptEvent(eventName string, mobInstanceId int, sourceId int, source
 
 func TryMobCommand(cmd string, rest string, mobInstanceId int, sourceId int, sourceType string) (bool, error) {
 
+	defer useRoomInstance(actorRoomId(0, mobInstanceId))()
+
 	sMob := GetActor(0, mobInstanceId)
 	if sMob == nil {
 		PruneMobVMs(mobInstanceId)
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/dop251/goja"
+	"github.com/volte6/gomud/internal/mobs"
 	"github.com/volte6/gomud/internal/rooms"
 	"github.com/volte6/gomud/internal/users"
 )
@@ -37,6 +38,8 @@ func PruneRoomVMs(roomIds ...int) {
 
 func TryRoomScriptEvent(eventName string, userId int, roomId int) (bool, error) {
 
+	defer useRoomInstance(roomId)()
+
 	vmw, err := getRoomVM(roomId)
 	if err != nil {
 		return false, err
@@ -98,6 +101,8 @@ func TryRoomScriptEvent(eventName string, userId int, roomId int) (bool, error)
 
 func TryRoomIdleEvent(roomId int) (bool, error) {
 
+	defer useRoomInstance(roomId)()
+
 	vmw, err := getRoomVM(roomId)
 	if err != nil {
 		return false, err
@@ -155,8 +160,206 @@ func TryRoomIdleEvent(roomId int) (bool, error) {
 	return false, nil
 }
 
+// Called when a door in the room is opened or closed by a player or mob.
+// eventName is `onDoorOpen` or `onDoorClose`. Returning true cancels the action.
+func TryRoomDoorEvent(eventName string, exitName string, roomId int, userId int, mobInstanceId int) (bool, error) {
+
+	defer useRoomInstance(roomId)()
+
+	vmw, err := getRoomVM(roomId)
+	if err != nil {
+		return false, err
+	}
+
+	timestart := time.Now()
+	defer func() {
+		slog.Debug("TryRoomDoorEvent()", "eventName", eventName, "exitName", exitName, "roomId", roomId, "time", time.Since(timestart))
+	}()
+
+	if onDoorFunc, ok := vmw.GetFunction(eventName); ok {
+
+		// Set forced ansi tag wrappers
+		userTextWrap.Set(`script-text`, ``, ``)
+		roomTextWrap.Set(`script-text`, ``, ``)
+
+		sActor := GetActor(userId, mobInstanceId)
+		sRoom := GetRoom(roomId)
+
+		tmr := time.AfterFunc(scriptRoomTimeout, func() {
+			vmw.VM.Interrupt(errTimeout)
+		})
+
+		res, err := onDoorFunc(goja.Undefined(),
+			vmw.VM.ToValue(exitName),
+			vmw.VM.ToValue(sActor),
+			vmw.VM.ToValue(sRoom),
+		)
+
+		vmw.VM.ClearInterrupt()
+		tmr.Stop()
+
+		userTextWrap.Reset()
+		roomTextWrap.Reset()
+
+		if err != nil {
+
+			// Wrap the error
+			finalErr := fmt.Errorf("%s(): %w", eventName, err)
+
+			if _, ok := finalErr.(*goja.Exception); ok {
+				slog.Error("JSVM", "exception", finalErr)
+				return false, finalErr
+			} else if errors.Is(finalErr, errTimeout) {
+				slog.Error("JSVM", "interrupted", finalErr)
+				return false, finalErr
+			}
+
+			slog.Error("JSVM", "error", finalErr)
+			return false, finalErr
+		}
+
+		if boolVal, ok := res.Export().(bool); ok {
+			return boolVal, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Called before a player or mob goes through an exit, with how the exit is traveled (walk, swim, climb etc.)
+// Returning true stops the move.
+func TryRoomMoveEvent(exitName string, movement string, roomId int, userId int, mobInstanceId int) (bool, error) {
+
+	defer useRoomInstance(roomId)()
+
+	vmw, err := getRoomVM(roomId)
+	if err != nil {
+		return false, err
+	}
+
+	timestart := time.Now()
+	defer func() {
+		slog.Debug("TryRoomMoveEvent()", "exitName", exitName, "movement", movement, "roomId", roomId, "time", time.Since(timestart))
+	}()
+
+	if onMoveFunc, ok := vmw.GetFunction(`onMove`); ok {
+
+		// Set forced ansi tag wrappers
+		userTextWrap.Set(`script-text`, ``, ``)
+		roomTextWrap.Set(`script-text`, ``, ``)
+
+		sActor := GetActor(userId, mobInstanceId)
+		sRoom := GetRoom(roomId)
+
+		tmr := time.AfterFunc(scriptRoomTimeout, func() {
+			vmw.VM.Interrupt(errTimeout)
+		})
+
+		res, err := onMoveFunc(goja.Undefined(),
+			vmw.VM.ToValue(exitName),
+			vmw.VM.ToValue(movement),
+			vmw.VM.ToValue(sActor),
+			vmw.VM.ToValue(sRoom),
+		)
+
+		vmw.VM.ClearInterrupt()
+		tmr.Stop()
+
+		userTextWrap.Reset()
+		roomTextWrap.Reset()
+
+		if err != nil {
+
+			// Wrap the error
+			finalErr := fmt.Errorf("onMove(): %w", err)
+
+			if _, ok := finalErr.(*goja.Exception); ok {
+				slog.Error("JSVM", "exception", finalErr)
+				return false, finalErr
+			} else if errors.Is(finalErr, errTimeout) {
+				slog.Error("JSVM", "interrupted", finalErr)
+				return false, finalErr
+			}
+
+			slog.Error("JSVM", "error", finalErr)
+			return false, finalErr
+		}
+
+		if boolVal, ok := res.Export().(bool); ok {
+			return boolVal, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Called for every room of a zone after the zone has been reset.
+func TryRoomZoneResetEvent(zone string, roomId int) (bool, error) {
+
+	defer useRoomInstance(roomId)()
+
+	vmw, err := getRoomVM(roomId)
+	if err != nil {
+		return false, err
+	}
+
+	timestart := time.Now()
+	defer func() {
+		slog.Debug("TryRoomZoneResetEvent()", "zone", zone, "roomId", roomId, "time", time.Since(timestart))
+	}()
+
+	if onZoneResetFunc, ok := vmw.GetFunction(`onZoneReset`); ok {
+
+		// Set forced ansi tag wrappers
+		userTextWrap.Set(`script-text`, ``, ``)
+		roomTextWrap.Set(`script-text`, ``, ``)
+
+		sRoom := GetRoom(roomId)
+
+		tmr := time.AfterFunc(scriptRoomTimeout, func() {
+			vmw.VM.Interrupt(errTimeout)
+		})
+
+		res, err := onZoneResetFunc(goja.Undefined(),
+			vmw.VM.ToValue(zone),
+			vmw.VM.ToValue(sRoom),
+		)
+
+		vmw.VM.ClearInterrupt()
+		tmr.Stop()
+
+		userTextWrap.Reset()
+		roomTextWrap.Reset()
+
+		if err != nil {
+
+			// Wrap the error
+			finalErr := fmt.Errorf("onZoneReset(): %w", err)
+
+			if _, ok := finalErr.(*goja.Exception); ok {
+				slog.Error("JSVM", "exception", finalErr)
+				return false, finalErr
+			} else if errors.Is(finalErr, errTimeout) {
+				slog.Error("JSVM", "interrupted", finalErr)
+				return false, finalErr
+			}
+
+			slog.Error("JSVM", "error", finalErr)
+			return false, finalErr
+		}
+
+		if boolVal, ok := res.Export().(bool); ok {
+			return boolVal, nil
+		}
+	}
+
+	return false, nil
+}
+
 func TryRoomCommand(cmd string, rest string, userId int) (bool, error) {
 
+	defer useRoomInstance(actorRoomId(userId, 0))()
+
 	user := users.GetByUserId(userId)
 	if user == nil {
 		return false, errors.New("user not found")
@@ -375,3 +578,55 @@ func getRoomVM(roomId int) (*VMWrapper, error) {
 
 	return vmw, nil
 }
+
+// Scripts are written for the original rooms of a zone. While one runs for something inside
+// a zone instance, any roomId it hands over is mapped to that instance's copy of the room.
+var scriptInstanceId int
+
+// Makes room lookups follow the instance the room belongs to, if any.
+// Returns a func that puts things back the way they were, for use with defer.
+func useRoomInstance(roomId int) func() {
+
+	lastInstanceId := scriptInstanceId
+
+	scriptInstanceId = 0
+	if rooms.IsInstanceRoomId(roomId) {
+		if r := rooms.LoadRoom(roomId); r != nil {
+			scriptInstanceId = r.GetInstanceId()
+		}
+	}
+
+	return func() {
+		scriptInstanceId = lastInstanceId
+	}
+}
+
+// Returns the instance copy of a room if the running script is inside an instance
+func instanceRoomId(roomId int) int {
+
+	if scriptInstanceId == 0 {
+		return roomId
+	}
+
+	if zi := rooms.GetInstance(scriptInstanceId); zi != nil {
+		if instRoomId, ok := zi.GetRoomId(roomId); ok {
+			return instRoomId
+		}
+	}
+
+	return roomId
+}
+
+// Where a user or mob currently is
+func actorRoomId(userId int, mobInstanceId int) int {
+	if userId > 0 {
+		if user := users.GetByUserId(userId); user != nil {
+			return user.Character.RoomId
+		}
+	} else if mobInstanceId > 0 {
+		if mob := mobs.GetInstance(mobInstanceId); mob != nil {
+			return mob.Character.RoomId
+		}
+	}
+	return 0
+}
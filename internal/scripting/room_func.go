@@ -2,6 +2,7 @@ package scripting
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/dop251/goja"
 	"github.com/volte6/gomud/internal/colorpatterns"
@@ -13,6 +14,7 @@ import (
 	"github.com/volte6/gomud/internal/rooms"
 	"github.com/volte6/gomud/internal/users"
 	"github.com/volte6/gomud/internal/util"
+	"github.com/volte6/gomud/internal/weather"
 )
 
 func setRoomFunctions(vm *goja.Runtime) {
@@ -80,6 +82,29 @@ func (r ScriptRoom) GetContainers() []string {
 	return keys
 }
 
+func (r ScriptRoom) GetCorpses() []map[string]any {
+
+	corpses := []map[string]any{}
+
+	for _, name := range r.roomRecord.GetCorpses() {
+		c := r.roomRecord.Containers[name].Corpse
+		corpses = append(corpses, map[string]any{
+			"Name":   name,
+			"MobId":  c.MobId,
+			"UserId": c.UserId,
+			"Level":  c.Level,
+			"Bones":  c.Bones,
+		})
+	}
+
+	return corpses
+}
+
+func (r ScriptRoom) RemoveCorpse(name string) bool {
+	_, ok := r.roomRecord.RemoveCorpse(name)
+	return ok
+}
+
 func (r ScriptRoom) GetExits() []map[string]any {
 
 	exits := []map[string]any{}
@@ -106,6 +131,15 @@ func (r ScriptRoom) GetExits() []map[string]any {
 			exitMap["Lock"] = nil
 		}
 
+		if exitInfo.HasDoor() {
+			exitMap["Door"] = map[string]any{
+				"Name": exitInfo.Door.GetName(),
+				"Open": !exitInfo.IsClosed(),
+			}
+		} else {
+			exitMap["Door"] = nil
+		}
+
 		exits = append(exits, exitMap)
 	}
 
@@ -134,6 +168,29 @@ func (r ScriptRoom) SetLocked(exitName string, lockIt bool) {
 	}
 }
 
+// Returns true if the exit has a door and it is open
+func (r ScriptRoom) IsDoorOpen(exitName string) bool {
+	if exitInfo, ok := r.roomRecord.Exits[exitName]; ok {
+		return exitInfo.HasDoor() && !exitInfo.IsClosed()
+	}
+	return false
+}
+
+// Opens or closes a door (and the matching door on the other side)
+func (r ScriptRoom) SetDoorOpen(exitName string, openIt bool) bool {
+	return r.roomRecord.SetExitOpen(exitName, openIt)
+}
+
+// Returns how an exit is traveled: walk, swim, climb, fly or squeeze
+func (r ScriptRoom) GetExitMovement(exitName string) string {
+	return string(r.roomRecord.GetExitMovement(exitName))
+}
+
+// Changes how an exit is traveled. An empty string goes back to the biome it leads to.
+func (r ScriptRoom) SetExitMovement(exitName string, movement string) bool {
+	return r.roomRecord.SetExitMovement(exitName, rooms.MovementMode(strings.ToLower(movement)))
+}
+
 // Returns a list of userIds found to have the questId
 // if userIdParty is specified, will only check users in the party of the user.
 func (r ScriptRoom) HasQuest(questId string, partyUserId ...int) []int {
@@ -247,6 +304,15 @@ func (r ScriptRoom) SendTextToExits(msg string, isQuiet bool, excludeUserIds ...
 	r.roomRecord.SendTextToExits(msg, isQuiet, excludeUserIds...)
 }
 
+// Spreads a sound out through the exits of the room, getting fainter the further it goes
+func (r ScriptRoom) PropagateSound(description string, volume int, isQuiet ...bool) {
+	r.roomRecord.PropagateSound(rooms.Sound{
+		Description: description,
+		Volume:      volume,
+		IsQuiet:     len(isQuiet) > 0 && isQuiet[0],
+	})
+}
+
 func (r ScriptRoom) RepeatSpawnItem(itemId int, roundFrequency int, containerName ...string) bool {
 	return r.roomRecord.RepeatSpawnItem(itemId, roundFrequency, containerName...)
 }
@@ -258,7 +324,7 @@ func (r ScriptRoom) AddTemporaryExit(exitNameSimple string, exitNameFancy string
 	}
 
 	tmpExit := exit.TemporaryRoomExit{
-		RoomId:  exitRoomId,
+		RoomId:  instanceRoomId(exitRoomId),
 		Title:   exitNameFancy,
 		UserId:  0,
 		Expires: expiresTimeString,
@@ -270,7 +336,7 @@ func (r ScriptRoom) AddTemporaryExit(exitNameSimple string, exitNameFancy string
 
 func (r ScriptRoom) RemoveTemporaryExit(exitNameSimple string, exitNameFancy string, exitRoomId int) bool {
 	tmpExit := exit.TemporaryRoomExit{
-		RoomId: exitRoomId,
+		RoomId: instanceRoomId(exitRoomId),
 		Title:  exitNameFancy,
 		UserId: 0,
 	}
@@ -295,12 +361,21 @@ func (r ScriptRoom) RemoveMutator(mutName string) {
 	}
 }
 
+func (r ScriptRoom) GetWeather() string {
+	return string(r.roomRecord.GetWeather())
+}
+
+func (r ScriptRoom) SetWeather(condition string, duration ...string) bool {
+	return weather.Set(r.roomRecord.Zone, weather.Condition(condition), duration...)
+}
+
 // ////////////////////////////////////////////////////////
 //
 // # These functions get exported to the scripting engine
 //
 // ////////////////////////////////////////////////////////
 func GetRoom(roomId int) *ScriptRoom {
+	roomId = instanceRoomId(roomId)
 	if room := rooms.LoadRoom(roomId); room != nil {
 		return &ScriptRoom{roomId, room}
 	}
@@ -327,5 +402,5 @@ func GetMap(mapRoomId int, mapSize string, mapHeight int, mapWidth int, mapName
 	// mapMarkers   - A list of strings representing custom map markers:
 	//                [roomId],[symbol],[legend text]
 	//                1,×,Here
-	return rooms.GetSpecificMap(mapRoomId, mapSize, mapHeight, mapWidth, mapName, showSecrets, mapMarkers)
+	return rooms.GetSpecificMap(instanceRoomId(mapRoomId), mapSize, mapHeight, mapWidth, mapName, showSecrets, mapMarkers)
 }
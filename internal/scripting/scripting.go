@@ -99,12 +99,14 @@ func PruneVMs(forceClear ...bool) {
 		ClearBuffVMs()
 		ClearItemVMs()
 		ClearSpellVMs()
+		ClearCalendarVMs()
 	} else {
 		PruneRoomVMs()
 		PruneMobVMs()
 		PruneBuffVMs()
 		PruneItemVMs()
 		PruneSpellVMs()
+		PruneCalendarVMs()
 	}
 
 }
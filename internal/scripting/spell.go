@@ -27,6 +27,8 @@ func PruneSpellVMs(instanceIds ...int) {
 
 func TrySpellScriptEvent(eventName string, sourceUserId int, sourceMobInstanceId int, spellAggro characters.SpellAggroInfo) (bool, error) {
 
+	defer useRoomInstance(actorRoomId(sourceUserId, sourceMobInstanceId))()
+
 	spellInfo := spells.GetSpell(spellAggro.SpellId)
 	if spellInfo == nil {
 		return false, fmt.Errorf("spell %s not found", spellAggro.SpellId)
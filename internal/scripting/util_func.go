@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/dop251/goja"
+	"github.com/volte6/gomud/internal/calendar"
 	"github.com/volte6/gomud/internal/colorpatterns"
 	"github.com/volte6/gomud/internal/configs"
 	"github.com/volte6/gomud/internal/gametime"
@@ -26,6 +27,7 @@ func setUtilFunctions(vm *goja.Runtime) {
 	vm.Set(`UtilSetTimeDay`, UtilSetTimeDay)
 	vm.Set(`UtilSetTimeNight`, UtilSetTimeNight)
 	vm.Set(`UtilIsDay`, UtilIsDay)
+	vm.Set(`UtilIsEventActive`, UtilIsEventActive)
 	vm.Set(`UtilLocateUser`, UtilLocateUser)
 	vm.Set(`UtilApplyColorPattern`, UtilApplyColorPattern)
 	vm.Set(`UtilGetConfig`, UtilGetConfig)
@@ -121,6 +123,13 @@ func UtilIsDay() bool {
 	return !gametime.IsNight()
 }
 
+func UtilIsEventActive(eventId string) bool {
+	if e := calendar.GetEvent(eventId); e != nil {
+		return e.IsActive()
+	}
+	return false
+}
+
 func UtilLocateUser(idOrName any) int {
 
 	// check if is string
@@ -34,6 +34,8 @@ const (
 	Protection  SkillTag = `protection`  // TODO
 	Tame        SkillTag = `tame`        // [LVL 1-4] Give mushroom to fairie in ROOM 558, train in ROOM 830
 	Trading     SkillTag = `trading`     // TODO
+	Swimming    SkillTag = `swimming`    // [LVL 1-4] Fishermans house - ROOM 758
+	Climbing    SkillTag = `climbing`    // [LVL 1-4] Frostwarden Rangers - ROOM 74
 )
 
 var (
@@ -56,6 +58,8 @@ var (
 			Map,
 			Portal,
 			Scribe,
+			Swimming,
+			Climbing,
 		},
 		"arcane scholar": {
 			Enchant,
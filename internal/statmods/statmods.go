@@ -22,6 +22,10 @@ var (
 	XPScale        StatName = `xpscale`        // Used for scaling xp after kills
 	HealthRecovery StatName = `healthrecovery` // Augments HP recovery speed
 	ManaRecovery   StatName = `manarecovery`   // Augments MP recovery speed
+	ResistPrefix   StatName = `resist-`        // followed by an element. Percent of that element's damage stopped. Negative is a weakness.
+	Attacks        StatName = `attacks`        // Extra swings each round in combat
+	Armor          StatName = `armor`          // Natural armor, which counts fully against every type of weapon
+	Penetration    StatName = `penetration`    // Percent of a target's armor ignored when attacking
 
 	// Stat based
 	Strength   StatName = `strength`
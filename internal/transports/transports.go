@@ -0,0 +1,368 @@
+package transports
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/volte6/gomud/internal/exit"
+	"github.com/volte6/gomud/internal/fileloader"
+	"github.com/volte6/gomud/internal/gametime"
+	"github.com/volte6/gomud/internal/items"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/users"
+)
+
+var (
+	allTransports                = map[string]*TransportSpec{}
+	transportDataFilesFolderPath = "_datafiles/transports"
+
+	// Runtime state, keyed by TransportId
+	transportStates = map[string]*transportState{}
+)
+
+// A place the transport docks at
+type Stop struct {
+	Name      string `yaml:"name"`                // Name of the stop, such as "the Southern Shore"
+	RoomId    int    `yaml:"roomid"`              // The room the transport docks at
+	DockExit  string `yaml:"dockexit,omitempty"`  // Exit added to the dock room that leads aboard. Defaults to "ferry"
+	BoardExit string `yaml:"boardexit,omitempty"` // Exit added to the transport room that leads to the dock. Defaults to "dock"
+}
+
+type TransportSpec struct {
+	TransportId  string `yaml:"transportid"`            // Unique id such as "frostlake-ferry"
+	Name         string `yaml:"name"`                   // What it's called, such as "the ferry"
+	RoomId       int    `yaml:"roomid"`                 // The room that travels between the stops
+	DockTime     string `yaml:"docktime,omitempty"`     // How long it waits at each stop, such as "2 hours". Defaults to "1 hour"
+	TravelTime   string `yaml:"traveltime,omitempty"`   // How long it takes to get between stops, such as "1 hour". Defaults to "1 hour"
+	Fare         int    `yaml:"fare,omitempty"`         // (optional) gold each passenger is charged when it departs
+	TicketItemId int    `yaml:"ticketitemid,omitempty"` // (optional) an item (sold in shops) that is used up instead of paying the fare
+	Stops        []Stop `yaml:"stops"`                  // Where it goes, in order. After the last stop it returns to the first.
+}
+
+type transportState struct {
+	initialized bool
+	stopIdx     int
+	docked      bool
+	paidUserIds map[int]struct{}
+}
+
+// Where a transport is at a given moment
+type Position struct {
+	StopIdx       int    // Index of Stop
+	Stop          Stop   // The stop it is at (if docked) or heading to (if not)
+	Docked        bool   // Whether it is sitting at the stop
+	NextStop      Stop   // The stop after the current one
+	NextChange    uint64 // The round it will next arrive or depart
+	NextChangeStr string // Game time of the next arrival or departure
+}
+
+func (t *TransportSpec) Filepath() string {
+	return fmt.Sprintf("%s.yaml", strings.ToLower(t.TransportId))
+}
+
+func (t *TransportSpec) Id() string {
+	return t.TransportId
+}
+
+func (t *TransportSpec) Validate() error {
+
+	if t.TransportId == `` {
+		return fmt.Errorf(`transportid cannot be empty`)
+	}
+
+	if t.RoomId == 0 {
+		return fmt.Errorf(`transport %s has no roomid`, t.TransportId)
+	}
+
+	if len(t.Stops) < 1 {
+		return fmt.Errorf(`transport %s has no stops`, t.TransportId)
+	}
+
+	if t.Name == `` {
+		t.Name = `the transport`
+	}
+
+	if t.DockTime == `` {
+		t.DockTime = `1 hour`
+	}
+
+	if t.TravelTime == `` {
+		t.TravelTime = `1 hour`
+	}
+
+	for i := range t.Stops {
+		if t.Stops[i].DockExit == `` {
+			t.Stops[i].DockExit = `ferry`
+		}
+		if t.Stops[i].BoardExit == `` {
+			t.Stops[i].BoardExit = `dock`
+		}
+		if t.Stops[i].Name == `` {
+			t.Stops[i].Name = fmt.Sprintf(`stop %d`, i+1)
+		}
+	}
+
+	return nil
+}
+
+func (t *TransportSpec) dockRounds() uint64 {
+	rounds := gametime.GetDate(0).AddPeriod(t.DockTime)
+	if rounds < 1 {
+		return 1
+	}
+	return rounds
+}
+
+func (t *TransportSpec) travelRounds() uint64 {
+	rounds := gametime.GetDate(0).AddPeriod(t.TravelTime)
+	if rounds < 1 {
+		return 1
+	}
+	return rounds
+}
+
+// Works out where the transport is for a given round.
+// Every transport runs on a fixed loop counted from round zero, so the schedule
+// is the same every time the server starts.
+func (t *TransportSpec) GetPosition(roundNumber uint64) Position {
+
+	dockRounds := t.dockRounds()
+	legRounds := dockRounds + t.travelRounds()
+	cycleRounds := legRounds * uint64(len(t.Stops))
+
+	cycleStart := roundNumber - (roundNumber % cycleRounds)
+	offset := roundNumber % cycleRounds
+
+	stopIdx := int(offset / legRounds)
+	legOffset := offset % legRounds
+	legStart := cycleStart + uint64(stopIdx)*legRounds
+
+	pos := Position{}
+
+	if legOffset < dockRounds {
+		pos.Docked = true
+		pos.Stop = t.Stops[stopIdx]
+		pos.NextStop = t.Stops[(stopIdx+1)%len(t.Stops)]
+		pos.NextChange = legStart + dockRounds
+	} else {
+		// Traveling towards the next stop
+		stopIdx = (stopIdx + 1) % len(t.Stops)
+		pos.Stop = t.Stops[stopIdx]
+		pos.NextStop = t.Stops[(stopIdx+1)%len(t.Stops)]
+		pos.NextChange = legStart + legRounds
+	}
+
+	pos.StopIdx = stopIdx
+
+	gd := gametime.GetDate(pos.NextChange)
+	pos.NextChangeStr = gd.String()
+
+	return pos
+}
+
+func GetTransport(transportId string) *TransportSpec {
+	return allTransports[transportId]
+}
+
+// Returns the transports that either are the room, or stop at it.
+func GetTransportsForRoom(roomId int) []*TransportSpec {
+
+	ret := []*TransportSpec{}
+
+	for _, t := range allTransports {
+		if t.RoomId == roomId {
+			ret = append(ret, t)
+			continue
+		}
+		for _, s := range t.Stops {
+			if s.RoomId == roomId {
+				ret = append(ret, t)
+				break
+			}
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].TransportId < ret[j].TransportId
+	})
+
+	return ret
+}
+
+// Moves every transport along its schedule.
+// Transports don't move the passengers, they just swap the exits around.
+func RoundTick(roundNumber uint64) {
+
+	for _, t := range allTransports {
+
+		state, ok := transportStates[t.TransportId]
+		if !ok {
+			state = &transportState{paidUserIds: map[int]struct{}{}}
+			transportStates[t.TransportId] = state
+		}
+
+		pos := t.GetPosition(roundNumber)
+
+		if !state.initialized {
+			state.initialized = true
+			state.stopIdx = pos.StopIdx
+			state.docked = pos.Docked
+		} else if state.stopIdx != pos.StopIdx || state.docked != pos.Docked {
+
+			if state.docked {
+				t.depart(state, t.Stops[state.stopIdx], pos)
+			}
+
+			if pos.Docked {
+				t.arrive(state, pos.Stop)
+			}
+
+			state.stopIdx = pos.StopIdx
+			state.docked = pos.Docked
+		}
+
+		// Make sure the exits are still in place in case the rooms were unloaded
+		if state.docked {
+			t.connect(t.Stops[state.stopIdx])
+		}
+	}
+
+}
+
+func (t *TransportSpec) tempExit(title string, roomId int) exit.TemporaryRoomExit {
+	return exit.TemporaryRoomExit{
+		RoomId:  roomId,
+		Title:   title,
+		Expires: `1 year`, // Removed by the schedule, not by time
+	}
+}
+
+func (t *TransportSpec) connect(s Stop) {
+
+	if transportRoom := rooms.LoadRoom(t.RoomId); transportRoom != nil {
+		transportRoom.AddTemporaryExit(s.BoardExit, t.tempExit(s.BoardExit, s.RoomId))
+	}
+
+	if dockRoom := rooms.LoadRoom(s.RoomId); dockRoom != nil {
+		dockRoom.AddTemporaryExit(s.DockExit, t.tempExit(s.DockExit, t.RoomId))
+	}
+}
+
+func (t *TransportSpec) disconnect(s Stop) {
+
+	if transportRoom := rooms.LoadRoom(t.RoomId); transportRoom != nil {
+		transportRoom.RemoveTemporaryExit(t.tempExit(s.BoardExit, s.RoomId))
+	}
+
+	if dockRoom := rooms.LoadRoom(s.RoomId); dockRoom != nil {
+		dockRoom.RemoveTemporaryExit(t.tempExit(s.DockExit, t.RoomId))
+	}
+}
+
+func (t *TransportSpec) arrive(state *transportState, s Stop) {
+
+	t.connect(s)
+
+	if transportRoom := rooms.LoadRoom(t.RoomId); transportRoom != nil {
+
+		transportRoom.SendText(fmt.Sprintf(`<ansi fg="yellow">%s arrives at %s.</ansi> You can disembark through the <ansi fg="exit">%s</ansi> exit.`, capitalize(t.Name), s.Name, s.BoardExit))
+
+		// Anyone who got off isn't paid up anymore
+		players := map[int]struct{}{}
+		for _, userId := range transportRoom.GetPlayers() {
+			players[userId] = struct{}{}
+		}
+		for userId := range state.paidUserIds {
+			if _, ok := players[userId]; !ok {
+				delete(state.paidUserIds, userId)
+			}
+		}
+	}
+
+	if dockRoom := rooms.LoadRoom(s.RoomId); dockRoom != nil {
+		dockRoom.SendText(fmt.Sprintf(`<ansi fg="yellow">%s arrives.</ansi> You can board through the <ansi fg="exit">%s</ansi> exit.`, capitalize(t.Name), s.DockExit))
+	}
+}
+
+func (t *TransportSpec) depart(state *transportState, s Stop, pos Position) {
+
+	transportRoom := rooms.LoadRoom(t.RoomId)
+
+	// Collect fares before leaving. Anyone who can't pay is put ashore.
+	if transportRoom != nil && (t.Fare > 0 || t.TicketItemId > 0) {
+
+		for _, userId := range transportRoom.GetPlayers() {
+
+			if _, ok := state.paidUserIds[userId]; ok {
+				continue
+			}
+
+			user := users.GetByUserId(userId)
+			if user == nil {
+				continue
+			}
+
+			if t.TicketItemId > 0 {
+				if ticket, found := user.Character.FindInBackpack(fmt.Sprintf(`!%d`, t.TicketItemId)); found {
+					user.Character.RemoveItem(ticket)
+					user.SendText(fmt.Sprintf(`Your <ansi fg="itemname">%s</ansi> is collected.`, ticket.DisplayName()))
+					state.paidUserIds[userId] = struct{}{}
+					continue
+				}
+			}
+
+			if t.Fare > 0 && user.Character.Gold >= t.Fare {
+				user.Character.Gold -= t.Fare
+				user.SendText(fmt.Sprintf(`You pay a fare of <ansi fg="gold">%d gold</ansi>.`, t.Fare))
+				state.paidUserIds[userId] = struct{}{}
+				continue
+			}
+
+			fareTxt := fmt.Sprintf(`<ansi fg="gold">%d gold</ansi>`, t.Fare)
+			if t.TicketItemId > 0 {
+				ticket := items.New(t.TicketItemId)
+				fareTxt = fmt.Sprintf(`a <ansi fg="itemname">%s</ansi>`, ticket.DisplayName())
+			}
+
+			user.SendText(fmt.Sprintf(`You can't pay the fare (%s), and are put ashore at %s.`, fareTxt, s.Name))
+			transportRoom.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> is put ashore for not paying the fare.`, user.Character.Name), userId)
+
+			rooms.MoveToRoom(userId, s.RoomId)
+		}
+	}
+
+	t.disconnect(s)
+
+	if transportRoom != nil {
+		transportRoom.SendText(fmt.Sprintf(`<ansi fg="yellow">%s departs from %s, heading for %s.</ansi>`, capitalize(t.Name), s.Name, pos.Stop.Name))
+	}
+
+	if dockRoom := rooms.LoadRoom(s.RoomId); dockRoom != nil {
+		dockRoom.SendText(fmt.Sprintf(`<ansi fg="yellow">%s departs, heading for %s.</ansi>`, capitalize(t.Name), pos.Stop.Name))
+	}
+}
+
+func capitalize(s string) string {
+	if s == `` {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// file self loads due to init()
+func LoadDataFiles() {
+
+	start := time.Now()
+
+	tmpTransports, err := fileloader.LoadAllFlatFiles[string, *TransportSpec](transportDataFilesFolderPath)
+	if err != nil {
+		panic(err)
+	}
+
+	allTransports = tmpTransports
+
+	slog.Info("transports.LoadDataFiles()", "loadedCount", len(allTransports), "Time Taken", time.Since(start))
+}
@@ -0,0 +1,241 @@
+package usercommands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/volte6/gomud/internal/buffs"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/templates"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+func Bedit(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
+
+	// args should look like one of the following:
+	// bedit <buffId|buffName>
+	// bedit new
+	args := util.SplitButRespectQuotes(rest)
+
+	if len(args) == 0 {
+		infoOutput, _ := templates.Process("admincommands/help/command.bedit", nil)
+		user.SendText(infoOutput)
+		return true, nil
+	}
+
+	allFlags := []string{}
+	for _, flag := range buffs.GetAllFlags() {
+		allFlags = append(allFlags, string(flag))
+	}
+
+	allStackPolicies := []string{}
+	for _, policy := range buffs.GetAllStackPolicies() {
+		allStackPolicies = append(allStackPolicies, string(policy))
+	}
+
+	tempKey := `olc-bedit`
+
+	buffSpec, _ := user.GetTempData(tempKey).(*buffs.BuffSpec)
+
+	// Only keep working on the copy if this is an answer to our own prompt
+	if cmdPrompt := user.GetPrompt(); buffSpec == nil || cmdPrompt == nil || cmdPrompt.Command != `bedit` || cmdPrompt.Rest != rest {
+
+		if args[0] == `new` {
+
+			buffSpec = &buffs.BuffSpec{
+				BuffId:       buffs.GetNextBuffId(),
+				Name:         `new buff`,
+				TriggerRate:  `1 round`,
+				TriggerCount: 1,
+			}
+
+			user.SendText(fmt.Sprintf(`Creating a new buff with BuffId <ansi fg="buffname">%d</ansi>.`, buffSpec.BuffId))
+
+		} else {
+
+			// BuffId 0 is valid, so only search by name if it isn't a number
+			buffId, err := strconv.Atoi(rest)
+			if err != nil {
+				buffId = -1
+				if foundIds := buffs.SearchBuffs(rest); len(foundIds) > 0 {
+					buffId = foundIds[0]
+				}
+			}
+
+			if buffSpec, err = buffs.LoadBuffSpecFile(buffId); err != nil {
+				user.SendText(fmt.Sprintf(`Could not find buff "%s".`, rest))
+				return true, nil
+			}
+		}
+
+		user.SetTempData(tempKey, buffSpec)
+	}
+
+	editor := olcEditor{
+		Command: `bedit`,
+		Rest:    rest,
+		Title:   fmt.Sprintf(`Buff #%d`, buffSpec.BuffId),
+		TempKey: tempKey,
+		Save: func() error {
+			return buffs.SaveBuffSpec(buffSpec)
+		},
+		Preview: func() string {
+			return fmt.Sprintf(`<ansi fg="buffname">%s</ansi> - %s`, buffSpec.Name, buffSpec.Description)
+		},
+	}
+
+	editor.Fields = []olcField{
+		{
+			Name: `name`,
+			Get:  func() string { return buffSpec.Name },
+			Set: func(v string) error {
+				if v == `` {
+					return errors.New(`a name is required`)
+				}
+				buffSpec.Name = v
+				return nil
+			},
+		},
+		{
+			Name: `description`,
+			Get:  func() string { return buffSpec.Description },
+			Set: func(v string) error {
+				buffSpec.Description = v
+				return nil
+			},
+		},
+		{
+			Name:   `secret`,
+			Format: `yes/no`,
+			Get:    func() string { return strconv.FormatBool(buffSpec.Secret) },
+			Set: func(v string) error {
+				secret, err := olcParseBool(v)
+				if err == nil {
+					buffSpec.Secret = secret
+				}
+				return err
+			},
+		},
+		{
+			Name:   `triggernow`,
+			Format: `yes/no`,
+			Get:    func() string { return strconv.FormatBool(buffSpec.TriggerNow) },
+			Set: func(v string) error {
+				triggerNow, err := olcParseBool(v)
+				if err == nil {
+					buffSpec.TriggerNow = triggerNow
+				}
+				return err
+			},
+		},
+		{
+			Name:   `triggerrate`,
+			Format: `time period such as "3 rounds" or "1 hour"`,
+			Get:    func() string { return buffSpec.TriggerRate },
+			Set: func(v string) error {
+				checkSpec := *buffSpec
+				checkSpec.TriggerRate = v
+				if err := checkSpec.Validate(); err != nil {
+					return err
+				}
+				buffSpec.TriggerRate = v
+				return nil
+			},
+		},
+		{
+			Name: `triggercount`,
+			Get:  func() string { return strconv.Itoa(buffSpec.TriggerCount) },
+			Set: func(v string) error {
+				triggerCount, err := olcParseInt(v, 1, 1000000)
+				if err == nil {
+					buffSpec.TriggerCount = triggerCount
+				}
+				return err
+			},
+		},
+		{
+			Name:   `statmods`,
+			Format: `name:value, name:value`,
+			Get:    func() string { return olcFormatStatMods(buffSpec.StatMods) },
+			Set: func(v string) error {
+				mods, err := olcParseStatMods(v)
+				if err == nil {
+					buffSpec.StatMods = mods
+				}
+				return err
+			},
+		},
+		{
+			Name:   `flags`,
+			Format: strings.Join(allFlags, `, `),
+			Get: func() string {
+				flagList := []string{}
+				for _, flag := range buffSpec.Flags {
+					flagList = append(flagList, string(flag))
+				}
+				return strings.Join(flagList, `, `)
+			},
+			Set: func(v string) error {
+				flags := []buffs.Flag{}
+				for _, flag := range olcParseList(strings.ToLower(v), `,`) {
+					if err := olcCheckOption(flag, allFlags); err != nil {
+						return err
+					}
+					flags = append(flags, buffs.Flag(flag))
+				}
+				buffSpec.Flags = flags
+				return nil
+			},
+		},
+		{
+			Name:   `stacking`,
+			Format: strings.Join(allStackPolicies, `, `),
+			Get:    func() string { return string(buffSpec.GetStacking()) },
+			Set: func(v string) error {
+				v = strings.ToLower(v)
+				if err := olcCheckOption(v, allStackPolicies); err != nil {
+					return err
+				}
+				buffSpec.Stacking = buffs.StackPolicy(v)
+				return nil
+			},
+		},
+		{
+			Name: `maxstacks`,
+			Get:  func() string { return strconv.Itoa(buffSpec.MaxStacks) },
+			Set: func(v string) error {
+				maxStacks, err := olcParseInt(v, 0, 100)
+				if err == nil {
+					buffSpec.MaxStacks = maxStacks
+				}
+				return err
+			},
+		},
+		{
+			Name:   `group`,
+			Format: `only one buff from a group can be applied at once`,
+			Get:    func() string { return buffSpec.Group },
+			Set: func(v string) error {
+				buffSpec.Group = strings.ToLower(v)
+				return nil
+			},
+		},
+		{
+			Name:   `diminishing`,
+			Format: `yes/no`,
+			Get:    func() string { return strconv.FormatBool(buffSpec.Diminishing) },
+			Set: func(v string) error {
+				diminishing, err := olcParseBool(v)
+				if err == nil {
+					buffSpec.Diminishing = diminishing
+				}
+				return err
+			},
+		},
+	}
+
+	return olcMenu(user, func() olcEditor { return editor })
+}
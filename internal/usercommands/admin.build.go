@@ -3,6 +3,7 @@ package usercommands
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 
 	"github.com/volte6/gomud/internal/events"
@@ -150,6 +151,78 @@ func Build(rest string, user *users.UserRecord, room *rooms.Room) (bool, error)
 			}
 		}
 
+		// #build generate "Twisted Woods" forest 20 seed:abc mobs:19,20 boss:14 containers:20 loot:10001,10002
+		if args[0] == "generate" {
+
+			if len(args) < 4 {
+				infoOutput, _ := templates.Process("admincommands/help/command.build", nil)
+				user.SendText(infoOutput)
+				return true, nil
+			}
+
+			opts := rooms.ZoneGenOptions{
+				ZoneName: args[1],
+				Biome:    args[2],
+			}
+
+			roomCt, err := strconv.Atoi(args[3])
+			if err != nil {
+				user.SendText(fmt.Sprintf(`Invalid room count: %s`, args[3]))
+				return true, nil
+			}
+			opts.RoomCount = roomCt
+
+			for _, opt := range args[4:] {
+
+				optName, optValue, found := strings.Cut(opt, `:`)
+				if !found {
+					user.SendText(fmt.Sprintf(`Invalid option: %s`, opt))
+					return true, nil
+				}
+
+				switch strings.ToLower(optName) {
+				case `seed`:
+					opts.Seed = optValue
+				case `mobs`:
+					opts.MobIds = parseIdList(optValue)
+				case `mobchance`:
+					opts.MobChance, _ = strconv.Atoi(optValue)
+				case `boss`:
+					opts.BossMobId, _ = strconv.Atoi(optValue)
+				case `containers`:
+					opts.ContainerChance, _ = strconv.Atoi(optValue)
+				case `loot`:
+					opts.ContainerItemIds = parseIdList(optValue)
+				default:
+					user.SendText(fmt.Sprintf(`Invalid option: %s`, opt))
+					return true, nil
+				}
+			}
+
+			result, err := rooms.GenerateZone(opts)
+			if err != nil {
+				user.SendText(err.Error())
+				return true, nil
+			}
+
+			user.SendText(fmt.Sprintf(`Zone <ansi fg="zone">%s</ansi> generated with <ansi fg="red">%d</ansi> rooms (seed: <ansi fg="yellow">%s</ansi>).`, opts.ZoneName, len(result.RoomIds), result.Seed))
+			user.SendText(fmt.Sprintf(`Mob spawns: <ansi fg="red">%d</ansi> Containers: <ansi fg="red">%d</ansi>`, result.MobCt, result.ContainerCt))
+			if result.BossRoomId > 0 {
+				user.SendText(fmt.Sprintf(`Boss room: <ansi fg="red">%d</ansi>`, result.BossRoomId))
+			}
+
+			if err := rooms.MoveToRoom(user.UserId, result.RootRoomId); err != nil {
+				user.SendText(err.Error())
+			} else {
+				events.AddToQueue(events.Input{
+					UserId:    user.UserId,
+					InputText: `look`,
+				}, true)
+			}
+
+			return true, nil
+		}
+
 		// #build room north <south>
 		if args[0] == "room" {
 
@@ -246,3 +319,14 @@ func Build(rest string, user *users.UserRecord, room *rooms.Room) (bool, error)
 
 	return true, nil
 }
+
+// Parses a comma separated list of ids such as "1,2,3"
+func parseIdList(list string) []int {
+	ret := []int{}
+	for _, idStr := range strings.Split(list, `,`) {
+		if id, err := strconv.Atoi(strings.TrimSpace(idStr)); err == nil && id > 0 {
+			ret = append(ret, id)
+		}
+	}
+	return ret
+}
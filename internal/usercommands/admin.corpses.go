@@ -0,0 +1,91 @@
+package usercommands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/templates"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+func Corpses(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
+
+	args := util.SplitButRespectQuotes(rest)
+
+	if len(args) == 0 {
+		infoOutput, _ := templates.Process("admincommands/help/command.corpses", nil)
+		user.SendText(infoOutput)
+		return true, nil
+	}
+
+	retrieve := false
+	if strings.ToLower(args[0]) == `retrieve` {
+		retrieve = true
+		args = args[1:]
+	}
+
+	playerName := strings.Join(args, ` `)
+
+	userId := 0
+	if u := users.GetByCharacterName(playerName); u != nil {
+		userId = u.UserId
+		playerName = u.Character.Name
+	} else {
+		userId, _ = users.CharacterNameSearch(playerName)
+	}
+
+	if userId == 0 {
+		user.SendText(fmt.Sprintf(`No player found with the name %s`, playerName))
+		return true, nil
+	}
+
+	if retrieve {
+
+		moved := rooms.RetrieveCorpses(userId, room)
+		if moved == 0 {
+			user.SendText(fmt.Sprintf(`No corpses to retrieve for <ansi fg="username">%s</ansi>.`, playerName))
+			return true, nil
+		}
+
+		user.SendText(fmt.Sprintf(`Retrieved %d corpse(s) belonging to <ansi fg="username">%s</ansi>.`, moved, playerName))
+		return true, nil
+	}
+
+	found := rooms.GetPlayerCorpses(userId)
+	if len(found) == 0 {
+		user.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> has no corpses lying around.`, playerName))
+		return true, nil
+	}
+
+	headers := []string{`Room`, `Room Title`, `Corpse`, `State`}
+	rows := [][]string{}
+
+	for _, loc := range found {
+
+		r := rooms.LoadRoom(loc.RoomId)
+		if r == nil {
+			continue
+		}
+
+		state := `fresh`
+		if c, ok := r.Containers[loc.ContainerName]; ok && c.Corpse != nil && c.Corpse.Bones {
+			state = `bones`
+		}
+
+		rows = append(rows, []string{
+			strconv.Itoa(loc.RoomId),
+			r.Title,
+			loc.ContainerName,
+			state,
+		})
+	}
+
+	tblData := templates.GetTable(fmt.Sprintf(`Corpses of %s`, playerName), headers, rows)
+	tplTxt, _ := templates.Process("tables/generic", tblData)
+	user.SendText(tplTxt)
+
+	return true, nil
+}
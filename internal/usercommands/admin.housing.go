@@ -0,0 +1,77 @@
+package usercommands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/volte6/gomud/internal/configs"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/templates"
+	"github.com/volte6/gomud/internal/users"
+)
+
+func Housing(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
+
+	if rest == `help` {
+		infoOutput, _ := templates.Process("admincommands/help/command.housing", nil)
+		user.SendText(infoOutput)
+		return true, nil
+	}
+
+	args := strings.SplitN(rest, ` `, 2)
+
+	if args[0] == `repossess` {
+
+		if len(args) < 2 {
+			user.SendText(`Repossess whose house? Give the owner's name or the room id.`)
+			return true, nil
+		}
+
+		houseRoom := rooms.FindHouse(strings.TrimSpace(args[1]))
+		if houseRoom == nil {
+			user.SendText(fmt.Sprintf(`No house found for "%s".`, args[1]))
+			return true, nil
+		}
+
+		ownerName := houseRoom.House.OwnerName
+		rooms.RepossessHouse(houseRoom, `by order of the administrators.`)
+
+		user.SendText(fmt.Sprintf(`The house of <ansi fg="username">%s</ansi> (room #%d) has been repossessed.`, ownerName, houseRoom.RoomId))
+		return true, nil
+	}
+
+	if rest != `` {
+		user.SendText(`Try <ansi fg="command">housing help</ansi> for more information.`)
+		return true, nil
+	}
+
+	rentPeriod := string(configs.GetConfig().HouseRentPeriod)
+
+	headers := []string{"Room", "Owner", "Title", "Guests", "Decorations", "Rent Owed"}
+	rows := [][]string{}
+
+	for _, houseRoom := range rooms.GetAllHouses() {
+
+		h := houseRoom.House
+		if h.OwnerUserId == 0 {
+			rows = append(rows, []string{strconv.Itoa(houseRoom.RoomId), `(for sale)`, ``, ``, ``, ``})
+			continue
+		}
+
+		rows = append(rows, []string{
+			strconv.Itoa(houseRoom.RoomId),
+			h.OwnerName,
+			houseRoom.Title,
+			strconv.Itoa(len(h.Guests)),
+			strconv.Itoa(len(h.Decorations)),
+			strconv.Itoa(h.RentOwed),
+		})
+	}
+
+	tableData := templates.GetTable(fmt.Sprintf(`Houses (rent is due every %s)`, rentPeriod), headers, rows)
+	tplTxt, _ := templates.Process("tables/generic", tableData)
+	user.SendText(tplTxt)
+
+	return true, nil
+}
@@ -0,0 +1,416 @@
+package usercommands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/volte6/gomud/internal/buffs"
+	"github.com/volte6/gomud/internal/items"
+	"github.com/volte6/gomud/internal/quests"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/templates"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+func Iedit(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
+
+	// args should look like one of the following:
+	// iedit <itemId|itemName>
+	// iedit new <type>
+	args := util.SplitButRespectQuotes(rest)
+
+	if len(args) == 0 {
+		infoOutput, _ := templates.Process("admincommands/help/command.iedit", nil)
+		user.SendText(infoOutput)
+		return true, nil
+	}
+
+	itemTypes := []string{}
+	for _, typeInfo := range items.ItemTypes() {
+		itemTypes = append(itemTypes, typeInfo.Type)
+	}
+
+	itemSubtypes := []string{}
+	for _, typeInfo := range items.ItemSubtypes() {
+		itemSubtypes = append(itemSubtypes, typeInfo.Type)
+	}
+
+	elements := items.ElementNames()
+	armorTypes := items.ArmorTypeNames()
+
+	tempKey := `olc-iedit`
+
+	itemSpec, _ := user.GetTempData(tempKey).(*items.ItemSpec)
+
+	// Only keep working on the copy if this is an answer to our own prompt
+	if cmdPrompt := user.GetPrompt(); itemSpec == nil || cmdPrompt == nil || cmdPrompt.Command != `iedit` || cmdPrompt.Rest != rest {
+
+		if args[0] == `new` {
+
+			if len(args) < 2 {
+				user.SendText(fmt.Sprintf(`A type is required for a new item: %s`, strings.Join(itemTypes, `, `)))
+				return true, nil
+			}
+
+			if err := olcCheckOption(args[1], itemTypes); err != nil {
+				user.SendText(err.Error())
+				return true, nil
+			}
+
+			// The type decides which range the ItemId comes from
+			itemType := items.ItemType(args[1])
+
+			itemSpec = &items.ItemSpec{
+				ItemId:  items.GetNextItemId(itemType),
+				Name:    `new item`,
+				Type:    itemType,
+				Subtype: items.Mundane,
+			}
+
+			if itemType == items.Weapon {
+				itemSpec.Subtype = items.Generic
+				itemSpec.Hands = items.OneHanded
+				itemSpec.Damage.DiceRoll = `1d4`
+			}
+
+			user.SendText(fmt.Sprintf(`Creating a new %s with ItemId <ansi fg="itemname">%d</ansi>.`, itemType, itemSpec.ItemId))
+
+		} else {
+
+			itemId, _ := strconv.Atoi(rest)
+			if itemId == 0 {
+				itemId = items.FindItemByName(rest)
+			}
+
+			var err error
+			if itemSpec, err = items.LoadItemSpecFile(itemId); err != nil {
+				user.SendText(fmt.Sprintf(`Could not find item "%s".`, rest))
+				return true, nil
+			}
+		}
+
+		user.SetTempData(tempKey, itemSpec)
+	}
+
+	editor := olcEditor{
+		Command: `iedit`,
+		Rest:    rest,
+		Title:   fmt.Sprintf(`Item #%d`, itemSpec.ItemId),
+		TempKey: tempKey,
+		Save: func() error {
+			return items.SaveItemSpec(itemSpec)
+		},
+		Preview: func() string {
+			displayName := itemSpec.DisplayName
+			if displayName == `` {
+				displayName = itemSpec.Name
+			}
+			return fmt.Sprintf(`<ansi fg="itemname">%s</ansi> (%s/%s)%s%s`, util.ConvertColorShortTags(displayName), itemSpec.Type, itemSpec.Subtype, "\n", itemSpec.Description)
+		},
+	}
+
+	editor.Fields = []olcField{
+		{
+			Name: `name`,
+			Get:  func() string { return itemSpec.Name },
+			Set: func(v string) error {
+				if v == `` {
+					return errors.New(`a name is required`)
+				}
+				itemSpec.Name = v
+				return nil
+			},
+		},
+		{
+			Name:   `displayname`,
+			Format: `name with color tags, optional`,
+			Get:    func() string { return itemSpec.DisplayName },
+			Set: func(v string) error {
+				itemSpec.DisplayName = v
+				return nil
+			},
+		},
+		{
+			Name:   `namesimple`,
+			Format: `short name such as "sword"`,
+			Get:    func() string { return itemSpec.NameSimple },
+			Set: func(v string) error {
+				itemSpec.NameSimple = v
+				return nil
+			},
+		},
+		{
+			Name: `description`,
+			Get:  func() string { return itemSpec.Description },
+			Set: func(v string) error {
+				itemSpec.Description = v
+				return nil
+			},
+		},
+		{
+			Name:   `type`,
+			Format: strings.Join(itemTypes, `, `),
+			Get:    func() string { return string(itemSpec.Type) },
+			Set: func(v string) error {
+				if err := olcCheckOption(v, itemTypes); err != nil {
+					return err
+				}
+				// ItemIds are allocated by type, so a type can't be moved to another id range.
+				oldMin, _ := items.ItemIdRange(itemSpec.Type)
+				newMin, _ := items.ItemIdRange(items.ItemType(v))
+				if oldMin != newMin {
+					return fmt.Errorf(`%s items don't belong in the id range of item %d, create a new item instead`, v, itemSpec.ItemId)
+				}
+				itemSpec.Type = items.ItemType(v)
+				return nil
+			},
+		},
+		{
+			Name:   `subtype`,
+			Format: strings.Join(itemSubtypes, `, `),
+			Get:    func() string { return string(itemSpec.Subtype) },
+			Set: func(v string) error {
+				if err := olcCheckOption(v, itemSubtypes); err != nil {
+					return err
+				}
+				itemSpec.Subtype = items.ItemSubType(v)
+				return nil
+			},
+		},
+		{
+			Name:   `value`,
+			Format: `gold, 0 to calculate automatically`,
+			Get:    func() string { return strconv.Itoa(itemSpec.Value) },
+			Set: func(v string) error {
+				value, err := olcParseInt(v, 0, 10000000)
+				if err == nil {
+					itemSpec.Value = value
+				}
+				return err
+			},
+		},
+		{
+			Name: `uses`,
+			Get:  func() string { return strconv.Itoa(itemSpec.Uses) },
+			Set: func(v string) error {
+				uses, err := olcParseInt(v, 0, 1000)
+				if err == nil {
+					itemSpec.Uses = uses
+				}
+				return err
+			},
+		},
+		{
+			Name:   `damage`,
+			Format: `[attacks@]dice[+bonus][#critBuffId,critBuffId] such as 2@1d6+1#3`,
+			Get: func() string {
+				if len(itemSpec.Damage.CritBuffIds) == 0 {
+					return itemSpec.Damage.DiceRoll
+				}
+				return itemSpec.Damage.DiceRoll + `#` + strings.ReplaceAll(olcFormatIntList(itemSpec.Damage.CritBuffIds), ` `, ``)
+			},
+			Set: func(v string) error {
+				if v == `` {
+					itemSpec.Damage = items.Damage{}
+					return nil
+				}
+
+				attacks, dCount, dSides, bonus, critBuffIds := util.ParseDiceRoll(v)
+				if attacks < 1 || dCount == 0 || dSides < 1 {
+					return fmt.Errorf(`"%s" is not a valid dice roll`, v)
+				}
+				for _, buffId := range critBuffIds {
+					if buffs.GetBuffSpec(buffId) == nil {
+						return fmt.Errorf(`crit buffId %d does not exist`, buffId)
+					}
+				}
+
+				// Crit buffs are kept in their own list, the same as in the datafiles
+				itemSpec.Damage = items.Damage{
+					Attacks:     attacks,
+					DiceRoll:    util.FormatDiceRoll(attacks, dCount, dSides, bonus, nil),
+					CritBuffIds: critBuffIds,
+					DiceCount:   dCount,
+					SideCount:   dSides,
+					BonusDamage: bonus,
+					Penetration: itemSpec.Damage.Penetration,
+				}
+				return nil
+			},
+		},
+		{
+			Name:   `damagereduction`,
+			Format: `0-100 percent`,
+			Get:    func() string { return strconv.Itoa(itemSpec.DamageReduction) },
+			Set: func(v string) error {
+				reduction, err := olcParseInt(v, 0, 100)
+				if err == nil {
+					itemSpec.DamageReduction = reduction
+				}
+				return err
+			},
+		},
+		{
+			Name:   `armor`,
+			Format: `0 or more`,
+			Get:    func() string { return strconv.Itoa(itemSpec.Armor) },
+			Set: func(v string) error {
+				armor, err := olcParseInt(v, 0, 1000)
+				if err == nil {
+					itemSpec.Armor = armor
+				}
+				return err
+			},
+		},
+		{
+			Name:   `armortype`,
+			Format: strings.Join(armorTypes, `, `),
+			Get:    func() string { return string(itemSpec.ArmorType) },
+			Set: func(v string) error {
+				if v != `` {
+					if err := olcCheckOption(v, armorTypes); err != nil {
+						return err
+					}
+				}
+				itemSpec.ArmorType = items.ArmorType(v)
+				return nil
+			},
+		},
+		{
+			Name:   `penetration`,
+			Format: `0-100 percent of armor ignored`,
+			Get:    func() string { return strconv.Itoa(itemSpec.Damage.Penetration) },
+			Set: func(v string) error {
+				penetration, err := olcParseInt(v, 0, 100)
+				if err == nil {
+					itemSpec.Damage.Penetration = penetration
+				}
+				return err
+			},
+		},
+		{
+			Name:   `hands`,
+			Format: `1 or 2`,
+			Get:    func() string { return strconv.Itoa(itemSpec.Hands) },
+			Set: func(v string) error {
+				hands, err := olcParseInt(v, 0, 2)
+				if err == nil {
+					itemSpec.Hands = hands
+				}
+				return err
+			},
+		},
+		{
+			Name:   `speed`,
+			Format: `swings per round, 0.5 is every other round (0 for the default of 1)`,
+			Get:    func() string { return strconv.FormatFloat(itemSpec.Speed, 'f', -1, 64) },
+			Set: func(v string) error {
+				speed, err := olcParseFloat(v, 0, 10)
+				if err == nil {
+					itemSpec.Speed = speed
+				}
+				return err
+			},
+		},
+		{
+			Name:   `element`,
+			Format: strings.Join(elements, `, `),
+			Get:    func() string { return string(itemSpec.Element) },
+			Set: func(v string) error {
+				if v != `` {
+					if err := olcCheckOption(v, elements); err != nil {
+						return err
+					}
+				}
+				itemSpec.Element = items.Element(v)
+				return nil
+			},
+		},
+		{
+			Name:   `statmods`,
+			Format: `name:value, name:value`,
+			Get:    func() string { return olcFormatStatMods(itemSpec.StatMods) },
+			Set: func(v string) error {
+				mods, err := olcParseStatMods(v)
+				if err == nil {
+					itemSpec.StatMods = mods
+				}
+				return err
+			},
+		},
+		{
+			Name:   `buffids`,
+			Format: `comma separated buffIds applied when used`,
+			Get:    func() string { return olcFormatIntList(itemSpec.BuffIds) },
+			Set: func(v string) error {
+				buffIds, err := olcParseBuffIds(v)
+				if err == nil {
+					itemSpec.BuffIds = buffIds
+				}
+				return err
+			},
+		},
+		{
+			Name:   `wornbuffids`,
+			Format: `comma separated buffIds applied while worn`,
+			Get:    func() string { return olcFormatIntList(itemSpec.WornBuffIds) },
+			Set: func(v string) error {
+				buffIds, err := olcParseBuffIds(v)
+				if err == nil {
+					itemSpec.WornBuffIds = buffIds
+				}
+				return err
+			},
+		},
+		{
+			Name:   `breakchance`,
+			Format: `0-100`,
+			Get:    func() string { return strconv.Itoa(int(itemSpec.BreakChance)) },
+			Set: func(v string) error {
+				breakChance, err := olcParseInt(v, 0, 100)
+				if err == nil {
+					itemSpec.BreakChance = uint8(breakChance)
+				}
+				return err
+			},
+		},
+		{
+			Name:   `cursed`,
+			Format: `yes/no`,
+			Get:    func() string { return strconv.FormatBool(itemSpec.Cursed) },
+			Set: func(v string) error {
+				cursed, err := olcParseBool(v)
+				if err == nil {
+					itemSpec.Cursed = cursed
+				}
+				return err
+			},
+		},
+		{
+			Name:   `questtoken`,
+			Format: `questId-step such as 2-start`,
+			Get:    func() string { return itemSpec.QuestToken },
+			Set: func(v string) error {
+				if v != `` && quests.GetQuest(v) == nil {
+					return fmt.Errorf(`quest token "%s" does not exist`, v)
+				}
+				itemSpec.QuestToken = v
+				return nil
+			},
+		},
+		{
+			Name:   `keylockid`,
+			Format: `roomId-exitname such as 778-north`,
+			Get:    func() string { return itemSpec.KeyLockId },
+			Set: func(v string) error {
+				itemSpec.KeyLockId = v
+				return nil
+			},
+		},
+	}
+
+	return olcMenu(user, func() olcEditor { return editor })
+}
@@ -0,0 +1,296 @@
+package usercommands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/volte6/gomud/internal/characters"
+	"github.com/volte6/gomud/internal/mobs"
+	"github.com/volte6/gomud/internal/races"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/templates"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+func Medit(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
+
+	// args should look like one of the following:
+	// medit <mobId|mobName>
+	// medit new
+	args := util.SplitButRespectQuotes(rest)
+
+	if len(args) == 0 {
+		infoOutput, _ := templates.Process("admincommands/help/command.medit", nil)
+		user.SendText(infoOutput)
+		return true, nil
+	}
+
+	tempKey := `olc-medit`
+
+	mobSpec, _ := user.GetTempData(tempKey).(*mobs.Mob)
+
+	// Only keep working on the copy if this is an answer to our own prompt
+	if cmdPrompt := user.GetPrompt(); mobSpec == nil || cmdPrompt == nil || cmdPrompt.Command != `medit` || cmdPrompt.Rest != rest {
+
+		if args[0] == `new` {
+
+			mobSpec = &mobs.Mob{
+				MobId:         mobs.GetNextMobId(),
+				Zone:          room.Zone,
+				ActivityLevel: 1,
+				Character: characters.Character{
+					Name:        `new mob`,
+					Description: `They seem thoroughly uninteresting.`,
+					RaceId:      1,
+					Level:       1,
+				},
+			}
+
+			user.SendText(fmt.Sprintf(`Creating a new mob with MobId <ansi fg="mobname">%d</ansi>.`, mobSpec.MobId))
+
+		} else {
+
+			mobId, _ := strconv.Atoi(rest)
+			if mobId == 0 {
+				mobId = int(mobs.MobIdByName(rest))
+			}
+
+			var err error
+			if mobSpec, err = mobs.LoadMobSpecFile(mobs.MobId(mobId)); err != nil {
+				user.SendText(fmt.Sprintf(`Could not find mob "%s".`, rest))
+				return true, nil
+			}
+		}
+
+		user.SetTempData(tempKey, mobSpec)
+	}
+
+	editor := olcEditor{
+		Command: `medit`,
+		Rest:    rest,
+		Title:   fmt.Sprintf(`Mob #%d`, mobSpec.MobId),
+		TempKey: tempKey,
+		Save: func() error {
+			return mobs.SaveMobSpec(mobSpec)
+		},
+		Preview: func() string {
+			raceName := `unknown`
+			if raceInfo := races.GetRace(mobSpec.Character.RaceId); raceInfo != nil {
+				raceName = raceInfo.Name
+			}
+			return fmt.Sprintf(`<ansi fg="mobname">%s</ansi> (level %d %s)%s%s`, mobSpec.Character.Name, mobSpec.Character.Level, raceName, "\n", mobSpec.Character.Description)
+		},
+	}
+
+	editor.Fields = []olcField{
+		{
+			Name: `name`,
+			Get:  func() string { return mobSpec.Character.Name },
+			Set: func(v string) error {
+				if v == `` {
+					return errors.New(`a name is required`)
+				}
+				mobSpec.Character.Name = v
+				return nil
+			},
+		},
+		{
+			Name: `description`,
+			Get:  func() string { return mobSpec.Character.Description },
+			Set: func(v string) error {
+				if v == `` {
+					return errors.New(`a description is required`)
+				}
+				mobSpec.Character.Description = v
+				return nil
+			},
+		},
+		{
+			Name:   `zone`,
+			Format: `name of an existing zone`,
+			Get:    func() string { return mobSpec.Zone },
+			Set: func(v string) error {
+				for _, zoneName := range rooms.GetAllZoneNames() {
+					if strings.EqualFold(zoneName, v) {
+						mobSpec.Zone = zoneName
+						return nil
+					}
+				}
+				return fmt.Errorf(`zone "%s" does not exist`, v)
+			},
+		},
+		{
+			Name: `level`,
+			Get:  func() string { return strconv.Itoa(mobSpec.Character.Level) },
+			Set: func(v string) error {
+				level, err := olcParseInt(v, 1, 1000)
+				if err == nil {
+					mobSpec.Character.Level = level
+				}
+				return err
+			},
+		},
+		{
+			Name:   `race`,
+			Format: `race name`,
+			Get: func() string {
+				if raceInfo := races.GetRace(mobSpec.Character.RaceId); raceInfo != nil {
+					return raceInfo.Name
+				}
+				return strconv.Itoa(mobSpec.Character.RaceId)
+			},
+			Set: func(v string) error {
+				raceInfo, found := races.FindRace(v)
+				if !found {
+					return fmt.Errorf(`race "%s" does not exist`, v)
+				}
+				mobSpec.Character.RaceId = raceInfo.RaceId
+				return nil
+			},
+		},
+		{
+			Name: `alignment`,
+			Get:  func() string { return strconv.Itoa(int(mobSpec.Character.Alignment)) },
+			Set: func(v string) error {
+				alignment, err := olcParseInt(v, int(characters.AlignmentMinimum), int(characters.AlignmentMaximum))
+				if err == nil {
+					mobSpec.Character.Alignment = int8(alignment)
+				}
+				return err
+			},
+		},
+		{
+			Name:   `hostile`,
+			Format: `yes/no`,
+			Get:    func() string { return strconv.FormatBool(mobSpec.Hostile) },
+			Set: func(v string) error {
+				hostile, err := olcParseBool(v)
+				if err == nil {
+					mobSpec.Hostile = hostile
+				}
+				return err
+			},
+		},
+		{
+			Name:   `activitylevel`,
+			Format: `1-10`,
+			Get:    func() string { return strconv.Itoa(mobSpec.ActivityLevel) },
+			Set: func(v string) error {
+				activityLevel, err := olcParseInt(v, 1, 10)
+				if err == nil {
+					mobSpec.ActivityLevel = activityLevel
+				}
+				return err
+			},
+		},
+		{
+			Name:   `maxwander`,
+			Format: `rooms from home, -1 for unlimited`,
+			Get:    func() string { return strconv.Itoa(mobSpec.MaxWander) },
+			Set: func(v string) error {
+				maxWander, err := olcParseInt(v, -1, 1000)
+				if err == nil {
+					mobSpec.MaxWander = maxWander
+				}
+				return err
+			},
+		},
+		{
+			Name:   `itemdropchance`,
+			Format: `0-100`,
+			Get:    func() string { return strconv.Itoa(mobSpec.ItemDropChance) },
+			Set: func(v string) error {
+				dropChance, err := olcParseInt(v, 0, 100)
+				if err == nil {
+					mobSpec.ItemDropChance = dropChance
+				}
+				return err
+			},
+		},
+		{
+			Name: `gold`,
+			Get:  func() string { return strconv.Itoa(mobSpec.Character.Gold) },
+			Set: func(v string) error {
+				gold, err := olcParseInt(v, 0, 1000000)
+				if err == nil {
+					mobSpec.Character.Gold = gold
+				}
+				return err
+			},
+		},
+		{
+			Name:   `groups`,
+			Format: `comma separated`,
+			Get:    func() string { return strings.Join(mobSpec.Groups, `, `) },
+			Set: func(v string) error {
+				mobSpec.Groups = olcParseList(v, `,`)
+				return nil
+			},
+		},
+		{
+			Name:   `hates`,
+			Format: `comma separated groups or races`,
+			Get:    func() string { return strings.Join(mobSpec.Hates, `, `) },
+			Set: func(v string) error {
+				mobSpec.Hates = olcParseList(v, `,`)
+				return nil
+			},
+		},
+		{
+			Name:   `idlecommands`,
+			Format: `commands separated by |`,
+			Get:    func() string { return strings.Join(mobSpec.IdleCommands, ` | `) },
+			Set: func(v string) error {
+				mobSpec.IdleCommands = olcParseList(v, `|`)
+				return nil
+			},
+		},
+		{
+			Name:   `angrycommands`,
+			Format: `commands separated by |`,
+			Get:    func() string { return strings.Join(mobSpec.AngryCommands, ` | `) },
+			Set: func(v string) error {
+				mobSpec.AngryCommands = olcParseList(v, `|`)
+				return nil
+			},
+		},
+		{
+			Name:   `combatcommands`,
+			Format: `commands separated by |`,
+			Get:    func() string { return strings.Join(mobSpec.CombatCommands, ` | `) },
+			Set: func(v string) error {
+				mobSpec.CombatCommands = olcParseList(v, `|`)
+				return nil
+			},
+		},
+		{
+			Name:   `buffids`,
+			Format: `comma separated buffIds`,
+			Get:    func() string { return olcFormatIntList(mobSpec.BuffIds) },
+			Set: func(v string) error {
+				buffIds, err := olcParseBuffIds(v)
+				if err == nil {
+					mobSpec.BuffIds = buffIds
+				}
+				return err
+			},
+		},
+		{
+			Name:   `scripttag`,
+			Format: `letters and numbers only`,
+			Get:    func() string { return mobSpec.ScriptTag },
+			Set: func(v string) error {
+				if v != util.ConvertForFilename(v) {
+					return fmt.Errorf(`"%s" can't be used in a filename`, v)
+				}
+				mobSpec.ScriptTag = v
+				return nil
+			},
+		},
+	}
+
+	return olcMenu(user, func() olcEditor { return editor })
+}
@@ -0,0 +1,315 @@
+package usercommands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/volte6/gomud/internal/buffs"
+	"github.com/volte6/gomud/internal/items"
+	"github.com/volte6/gomud/internal/quests"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/templates"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+func Qedit(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
+
+	// args should look like one of the following:
+	// qedit <questId>
+	// qedit new
+	args := util.SplitButRespectQuotes(rest)
+
+	if len(args) == 0 {
+		infoOutput, _ := templates.Process("admincommands/help/command.qedit", nil)
+		user.SendText(infoOutput)
+		return true, nil
+	}
+
+	tempKey := `olc-qedit`
+
+	questInfo, _ := user.GetTempData(tempKey).(*quests.Quest)
+
+	// Only keep working on the copy if this is an answer to our own prompt
+	if cmdPrompt := user.GetPrompt(); questInfo == nil || cmdPrompt == nil || cmdPrompt.Command != `qedit` || cmdPrompt.Rest != rest {
+
+		if args[0] == `new` {
+
+			questInfo = &quests.Quest{
+				QuestId: quests.GetNextQuestId(),
+				Name:    `new quest`,
+				Steps: []quests.QuestStep{
+					{Id: `start`},
+					{Id: `end`},
+				},
+			}
+
+			user.SendText(fmt.Sprintf(`Creating a new quest with QuestId <ansi fg="yellow">%d</ansi>.`, questInfo.QuestId))
+
+		} else {
+
+			questId, err := strconv.Atoi(rest)
+			if err != nil {
+				user.SendText(fmt.Sprintf(`"%s" is not a QuestId.`, rest))
+				return true, nil
+			}
+
+			if questInfo, err = quests.LoadQuestFile(questId); err != nil {
+				user.SendText(fmt.Sprintf(`Could not find quest "%s".`, rest))
+				return true, nil
+			}
+		}
+
+		user.SetTempData(tempKey, questInfo)
+	}
+
+	// The step fields change along with the steps, so the editor is rebuilt each time
+	return olcMenu(user, func() olcEditor {
+
+		editor := olcEditor{
+			Command: `qedit`,
+			Rest:    rest,
+			Title:   fmt.Sprintf(`Quest #%d`, questInfo.QuestId),
+			TempKey: tempKey,
+			Save: func() error {
+				// A quest can only be completed by reaching its end step
+				for _, step := range questInfo.Steps {
+					if step.Id == `end` {
+						return quests.SaveQuest(questInfo)
+					}
+				}
+				return errors.New(`a quest must have an "end" step`)
+			},
+			Preview: func() string {
+				stepsOut := strings.Builder{}
+				for _, step := range questInfo.Steps {
+					stepsOut.WriteString(fmt.Sprintf("\n  <ansi fg=\"yellow\">%s</ansi>: %s", quests.PartsToToken(questInfo.QuestId, step.Id), step.Description))
+				}
+				return fmt.Sprintf(`<ansi fg="yellow">%s</ansi> - %s%s`, questInfo.Name, questInfo.Description, stepsOut.String())
+			},
+		}
+
+		editor.Fields = []olcField{
+			{
+				Name: `name`,
+				Get:  func() string { return questInfo.Name },
+				Set: func(v string) error {
+					if v == `` {
+						return errors.New(`a name is required`)
+					}
+					questInfo.Name = v
+					return nil
+				},
+			},
+			{
+				Name: `description`,
+				Get:  func() string { return questInfo.Description },
+				Set: func(v string) error {
+					questInfo.Description = v
+					return nil
+				},
+			},
+			{
+				Name:   `secret`,
+				Format: `yes/no`,
+				Get:    func() string { return strconv.FormatBool(questInfo.Secret) },
+				Set: func(v string) error {
+					secret, err := olcParseBool(v)
+					if err == nil {
+						questInfo.Secret = secret
+					}
+					return err
+				},
+			},
+			{
+				Name:   `steps`,
+				Format: `comma separated step ids in order, such as start, return, end`,
+				Get: func() string {
+					stepIds := []string{}
+					for _, step := range questInfo.Steps {
+						stepIds = append(stepIds, step.Id)
+					}
+					return strings.Join(stepIds, `, `)
+				},
+				Set: func(v string) error {
+					// Keep the description and hint of any step that is staying
+					oldSteps := map[string]quests.QuestStep{}
+					for _, step := range questInfo.Steps {
+						oldSteps[step.Id] = step
+					}
+
+					newSteps := []quests.QuestStep{}
+					seen := map[string]bool{}
+					for _, stepId := range olcParseList(strings.ToLower(v), `,`) {
+						if strings.Contains(stepId, quests.QuestTokenSeparator) || strings.Contains(stepId, ` `) {
+							return fmt.Errorf(`step id "%s" can't contain spaces or "%s"`, stepId, quests.QuestTokenSeparator)
+						}
+						if seen[stepId] {
+							return fmt.Errorf(`step id "%s" is listed more than once`, stepId)
+						}
+						seen[stepId] = true
+
+						step, ok := oldSteps[stepId]
+						if !ok {
+							step = quests.QuestStep{Id: stepId}
+						}
+						newSteps = append(newSteps, step)
+					}
+
+					if len(newSteps) == 0 {
+						return errors.New(`at least one step is required`)
+					}
+
+					questInfo.Steps = newSteps
+					return nil
+				},
+			},
+		}
+
+		// Each step gets its own description and hint field
+		for idx := range questInfo.Steps {
+			step := &questInfo.Steps[idx]
+			editor.Fields = append(editor.Fields,
+				olcField{
+					Name: `step-` + step.Id,
+					Get:  func() string { return step.Description },
+					Set: func(v string) error {
+						step.Description = v
+						return nil
+					},
+				},
+				olcField{
+					Name: `hint-` + step.Id,
+					Get:  func() string { return step.Hint },
+					Set: func(v string) error {
+						step.Hint = v
+						return nil
+					},
+				},
+			)
+		}
+
+		editor.Fields = append(editor.Fields, []olcField{
+			{
+				Name:   `rewardquest`,
+				Format: `quest token given on completion, such as 2-start`,
+				Get:    func() string { return questInfo.Rewards.QuestId },
+				Set: func(v string) error {
+					if v != `` && quests.GetQuest(v) == nil {
+						return fmt.Errorf(`quest token "%s" does not exist`, v)
+					}
+					questInfo.Rewards.QuestId = v
+					return nil
+				},
+			},
+			{
+				Name: `rewardgold`,
+				Get:  func() string { return strconv.Itoa(questInfo.Rewards.Gold) },
+				Set: func(v string) error {
+					gold, err := olcParseInt(v, 0, 10000000)
+					if err == nil {
+						questInfo.Rewards.Gold = gold
+					}
+					return err
+				},
+			},
+			{
+				Name: `rewardxp`,
+				Get:  func() string { return strconv.Itoa(questInfo.Rewards.Experience) },
+				Set: func(v string) error {
+					experience, err := olcParseInt(v, 0, 100000000)
+					if err == nil {
+						questInfo.Rewards.Experience = experience
+					}
+					return err
+				},
+			},
+			{
+				Name:   `rewarditem`,
+				Format: `itemId`,
+				Get:    func() string { return strconv.Itoa(questInfo.Rewards.ItemId) },
+				Set: func(v string) error {
+					itemId, err := olcParseInt(v, 0, 1000000)
+					if err != nil {
+						return err
+					}
+					if itemId != 0 && items.GetItemSpec(itemId) == nil {
+						return fmt.Errorf(`itemId %d does not exist`, itemId)
+					}
+					questInfo.Rewards.ItemId = itemId
+					return nil
+				},
+			},
+			{
+				Name:   `rewardbuff`,
+				Format: `buffId`,
+				Get:    func() string { return strconv.Itoa(questInfo.Rewards.BuffId) },
+				Set: func(v string) error {
+					buffId, err := olcParseInt(v, 0, 1000000)
+					if err != nil {
+						return err
+					}
+					if buffId != 0 && buffs.GetBuffSpec(buffId) == nil {
+						return fmt.Errorf(`buffId %d does not exist`, buffId)
+					}
+					questInfo.Rewards.BuffId = buffId
+					return nil
+				},
+			},
+			{
+				Name:   `rewardskill`,
+				Format: `skill:level such as map:1`,
+				Get:    func() string { return questInfo.Rewards.SkillInfo },
+				Set: func(v string) error {
+					if v != `` {
+						parts := strings.Split(v, `:`)
+						if len(parts) != 2 {
+							return fmt.Errorf(`"%s" should look like skill:level`, v)
+						}
+						if _, err := strconv.Atoi(parts[1]); err != nil {
+							return fmt.Errorf(`"%s" is not a skill level`, parts[1])
+						}
+					}
+					questInfo.Rewards.SkillInfo = v
+					return nil
+				},
+			},
+			{
+				Name: `rewardplayermessage`,
+				Get:  func() string { return questInfo.Rewards.PlayerMessage },
+				Set: func(v string) error {
+					questInfo.Rewards.PlayerMessage = v
+					return nil
+				},
+			},
+			{
+				Name: `rewardroommessage`,
+				Get:  func() string { return questInfo.Rewards.RoomMessage },
+				Set: func(v string) error {
+					questInfo.Rewards.RoomMessage = v
+					return nil
+				},
+			},
+			{
+				Name:   `rewardroom`,
+				Format: `roomId the player is moved to`,
+				Get:    func() string { return strconv.Itoa(questInfo.Rewards.RoomId) },
+				Set: func(v string) error {
+					roomId, err := olcParseInt(v, 0, 10000000)
+					if err != nil {
+						return err
+					}
+					if roomId != 0 && rooms.LoadRoom(roomId) == nil {
+						return fmt.Errorf(`roomId %d does not exist`, roomId)
+					}
+					questInfo.Rewards.RoomId = roomId
+					return nil
+				},
+			},
+		}...)
+
+		return editor
+	})
+}
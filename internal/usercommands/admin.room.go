@@ -204,6 +204,28 @@ func Room(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 			user.SendText(fmt.Sprintf("Exit %s not found.", direction))
 		}
 
+	} else if len(args) >= 2 && roomCmd == "exitmovement" {
+
+		// exitmovement north swim <- Set how the exit is traveled
+		// exitmovement north <- Go back to using the biome of the room it leads to
+		direction := args[1]
+		mode := rooms.MovementMode(``)
+		if len(args) > 2 {
+			mode = rooms.MovementMode(strings.ToLower(args[2]))
+			if _, ok := rooms.GetMovement(mode); !ok {
+				user.SendText(fmt.Sprintf("Movement must be one of: %s, %s, %s, %s, %s", rooms.MovementWalk, rooms.MovementSwim, rooms.MovementClimb, rooms.MovementFly, rooms.MovementSqueeze))
+				return handled, nil
+			}
+		}
+
+		if !room.SetExitMovement(direction, mode) {
+			user.SendText(fmt.Sprintf("Exit %s not found.", direction))
+			return handled, nil
+		}
+
+		rooms.SaveRoom(*room)
+		user.SendText(fmt.Sprintf("Exit %s is now traveled by: %s", direction, room.GetExitMovement(direction)))
+
 	} else if len(args) >= 2 && roomCmd == "set" {
 
 		propertyName := args[1]
@@ -284,6 +306,18 @@ func Room(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 		} else if propertyName == "legend" || propertyName == "maplegend" {
 			room.MapLegend = propertyValue
 			rooms.SaveRoom(*room)
+		} else if propertyName == "coords" || propertyName == "coordinates" {
+			if propertyValue == `` || propertyValue == `clear` {
+				room.Coordinates = nil
+			} else {
+				coords, err := rooms.ParseCoordinates(propertyValue)
+				if err != nil {
+					user.SendText(err.Error())
+					return handled, nil
+				}
+				room.Coordinates = &coords
+			}
+			rooms.SaveRoom(*room)
 		} else if propertyName == "zone" {
 			// Try moving it to the new zone.
 			if err := rooms.MoveToZone(room.RoomId, propertyValue); err != nil {
@@ -246,7 +246,7 @@ func Server(rest string, user *users.UserRecord, room *rooms.Room) (bool, error)
 			}
 		}
 
-		memoryReportCache[name] = util.MemoryResult{memRepTotalTotal, 0} // Cache the new val
+		memoryReportCache[name] = util.MemoryResult{Memory: memRepTotalTotal, Count: 0} // Cache the new val
 
 		bFormatted := util.FormatBytes(memRepTotalTotal)
 		if strings.Contains(bFormatted, `KB`) {
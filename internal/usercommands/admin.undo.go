@@ -0,0 +1,221 @@
+package usercommands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/volte6/gomud/internal/audit"
+	"github.com/volte6/gomud/internal/buffs"
+	"github.com/volte6/gomud/internal/items"
+	"github.com/volte6/gomud/internal/mobs"
+	"github.com/volte6/gomud/internal/quests"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/templates"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+	"gopkg.in/yaml.v2"
+)
+
+func Undo(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
+
+	// args should look like one of the following:
+	// undo room
+	// undo <kind> <id> [count]
+	args := util.SplitButRespectQuotes(rest)
+
+	if len(args) == 0 {
+		infoOutput, _ := templates.Process("admincommands/help/command.undo", nil)
+		user.SendText(infoOutput)
+		return true, nil
+	}
+
+	kind, entityId, args, err := journalTarget(args, room)
+	if err != nil {
+		user.SendText(err.Error())
+		return true, nil
+	}
+
+	count := 1
+	if len(args) > 0 {
+		if count, err = strconv.Atoi(args[0]); err != nil || count < 1 {
+			user.SendText(fmt.Sprintf(`"%s" is not a number of changes to undo.`, args[0]))
+			return true, nil
+		}
+	}
+
+	toUndo := audit.GetUndoable(kind, entityId, count)
+
+	// Creating something can't be undone here, only the changes made since
+	if len(toUndo) > 0 && toUndo[len(toUndo)-1].Before == `` {
+		toUndo = toUndo[:len(toUndo)-1]
+		user.SendText(fmt.Sprintf(`The first change to %s %s created it, and won't be undone.`, kind, entityId))
+	}
+
+	if len(toUndo) == 0 {
+		user.SendText(fmt.Sprintf(`There are no changes to %s %s to undo.`, kind, entityId))
+		return true, nil
+	}
+
+	// Warn if the thing was changed some way that wasn't journaled
+	if current, err := journalSnapshot(kind, entityId); err == nil {
+		if latest := audit.GetHistory(kind, entityId, 1); len(latest) > 0 && latest[0].After != current {
+			user.SendText(fmt.Sprintf(`<ansi fg="yellow">%s %s has changed since change #%d was journaled. Those changes will be lost too.</ansi>`, kind, entityId, latest[0].EntryId))
+		}
+	}
+
+	undoIds := []int{}
+	for _, e := range toUndo {
+		undoIds = append(undoIds, e.EntryId)
+	}
+
+	// Going back to how it was before the oldest change undoes all of them
+	audit.Undoing(kind, entityId, undoIds)
+
+	if err := journalRestore(kind, entityId, toUndo[len(toUndo)-1].Before); err != nil {
+		user.SendText(fmt.Sprintf(`<ansi fg="red">Could not undo: %s</ansi>`, err.Error()))
+		return true, nil
+	}
+
+	for _, e := range toUndo {
+		user.SendText(fmt.Sprintf(`Undid change <ansi fg="yellow">#%d</ansi> by <ansi fg="username">%s</ansi>: %s`, e.EntryId, e.Username, e.Action))
+	}
+
+	return true, nil
+}
+
+// Works out which thing a journal command is about.
+// Rooms and zones default to wherever the user is standing.
+// Returns any args left over.
+func journalTarget(args []string, room *rooms.Room) (audit.Kind, string, []string, error) {
+
+	kind := audit.Kind(strings.ToLower(args[0]))
+	args = args[1:]
+
+	kindNames := []string{}
+	validKind := false
+	for _, k := range audit.AllKinds() {
+		kindNames = append(kindNames, string(k))
+		if k == kind {
+			validKind = true
+		}
+	}
+
+	if !validKind {
+		return kind, ``, args, fmt.Errorf(`"%s" should be one of: %s`, kind, strings.Join(kindNames, `, `))
+	}
+
+	if len(args) == 0 {
+		switch kind {
+		case audit.KindRoom:
+			return kind, strconv.Itoa(room.RoomId), args, nil
+		case audit.KindZone:
+			return kind, room.Zone, args, nil
+		}
+		return kind, ``, args, fmt.Errorf(`Which %s? An id is required.`, kind)
+	}
+
+	entityId := args[0]
+	args = args[1:]
+
+	if kind == audit.KindZone {
+		if zoneName := rooms.FindZoneName(entityId); zoneName != `` {
+			entityId = zoneName
+		}
+		return kind, entityId, args, nil
+	}
+
+	if _, err := strconv.Atoi(entityId); err != nil {
+		return kind, entityId, args, fmt.Errorf(`"%s" is not a %s id.`, entityId, kind)
+	}
+
+	return kind, entityId, args, nil
+}
+
+// Returns a snapshot of how something is right now, the same way it was journaled
+func journalSnapshot(kind audit.Kind, entityId string) (string, error) {
+
+	if kind == audit.KindZone {
+		return rooms.GetZoneSnapshot(entityId)
+	}
+
+	id, err := strconv.Atoi(entityId)
+	if err != nil {
+		return ``, err
+	}
+
+	switch kind {
+	case audit.KindRoom:
+		return rooms.GetRoomSnapshot(id)
+	case audit.KindMob:
+		mobSpec, err := mobs.LoadMobSpecFile(mobs.MobId(id))
+		if err != nil {
+			return ``, err
+		}
+		return audit.Snapshot(mobSpec), nil
+	case audit.KindItem:
+		itemSpec, err := items.LoadItemSpecFile(id)
+		if err != nil {
+			return ``, err
+		}
+		return audit.Snapshot(itemSpec), nil
+	case audit.KindBuff:
+		buffSpec, err := buffs.LoadBuffSpecFile(id)
+		if err != nil {
+			return ``, err
+		}
+		return audit.Snapshot(buffSpec), nil
+	case audit.KindQuest:
+		questInfo, err := quests.LoadQuestFile(id)
+		if err != nil {
+			return ``, err
+		}
+		return audit.Snapshot(questInfo), nil
+	}
+
+	return ``, fmt.Errorf(`unknown kind: %s`, kind)
+}
+
+// Puts something back the way it was in a snapshot
+func journalRestore(kind audit.Kind, entityId string, snapshot string) error {
+
+	if kind == audit.KindZone {
+		return rooms.RestoreZoneSnapshot(entityId, snapshot)
+	}
+
+	id, err := strconv.Atoi(entityId)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case audit.KindRoom:
+		return rooms.RestoreRoomSnapshot(id, snapshot)
+	case audit.KindMob:
+		mobSpec := &mobs.Mob{}
+		if err := yaml.Unmarshal([]byte(snapshot), mobSpec); err != nil {
+			return err
+		}
+		return mobs.SaveMobSpec(mobSpec)
+	case audit.KindItem:
+		itemSpec := &items.ItemSpec{}
+		if err := yaml.Unmarshal([]byte(snapshot), itemSpec); err != nil {
+			return err
+		}
+		return items.SaveItemSpec(itemSpec)
+	case audit.KindBuff:
+		buffSpec := &buffs.BuffSpec{}
+		if err := yaml.Unmarshal([]byte(snapshot), buffSpec); err != nil {
+			return err
+		}
+		return buffs.SaveBuffSpec(buffSpec)
+	case audit.KindQuest:
+		questInfo := &quests.Quest{}
+		if err := yaml.Unmarshal([]byte(snapshot), questInfo); err != nil {
+			return err
+		}
+		return quests.SaveQuest(questInfo)
+	}
+
+	return fmt.Errorf(`unknown kind: %s`, kind)
+}
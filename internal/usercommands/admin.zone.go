@@ -5,10 +5,12 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/volte6/gomud/internal/gametime"
 	"github.com/volte6/gomud/internal/rooms"
 	"github.com/volte6/gomud/internal/templates"
 	"github.com/volte6/gomud/internal/users"
 	"github.com/volte6/gomud/internal/util"
+	"github.com/volte6/gomud/internal/zonebundle"
 )
 
 func Zone(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
@@ -31,6 +33,78 @@ func Zone(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 	roomCmd := strings.ToLower(args[0])
 	args = args[1:]
 
+	if roomCmd == `export` {
+
+		zoneName := room.Zone
+		if len(args) > 0 {
+			zoneName = strings.Join(args, ` `)
+			for _, name := range rooms.GetAllZoneNames() {
+				if strings.EqualFold(name, zoneName) {
+					zoneName = name
+					break
+				}
+			}
+		}
+
+		bundlePath, manifest, err := zonebundle.Export(zoneName, user.Character.Name)
+		if err != nil {
+			user.SendText(fmt.Sprintf(`Export failed: <ansi fg="red">%s</ansi>`, err.Error()))
+			return true, nil
+		}
+
+		user.SendText(fmt.Sprintf(`Exported <ansi fg="red">%s</ansi> to <ansi fg="yellow">%s</ansi>`, manifest.Zone, bundlePath))
+		user.SendText(fmt.Sprintf(`  <ansi fg="red">%d</ansi> rooms, <ansi fg="red">%d</ansi> mobs, <ansi fg="red">%d</ansi> items, <ansi fg="red">%d</ansi> buffs, <ansi fg="red">%d</ansi> quests, <ansi fg="red">%d</ansi> conversations`,
+			len(manifest.Rooms), len(manifest.Mobs), len(manifest.Items), len(manifest.Buffs), len(manifest.Quests), len(manifest.Conversations)))
+
+		return true, nil
+	}
+
+	if roomCmd == `import` {
+
+		if len(args) == 0 {
+			bundleFiles := zonebundle.GetBundleFiles()
+			if len(bundleFiles) == 0 {
+				user.SendText(fmt.Sprintf(`No bundles found in <ansi fg="yellow">%s</ansi>`, zonebundle.BundleFolder))
+				return true, nil
+			}
+			user.SendText(`Bundles available to import:`)
+			for _, bundleFile := range bundleFiles {
+				user.SendText(fmt.Sprintf(`  <ansi fg="yellow">%s</ansi>`, bundleFile))
+			}
+			return true, nil
+		}
+
+		dryRun := len(args) > 1 && strings.ToLower(args[1]) == `dryrun`
+
+		report, err := zonebundle.Import(args[0], dryRun)
+		if report != nil {
+
+			if report.DryRun {
+				user.SendText(fmt.Sprintf(`<ansi fg="yellow-bold">Dry run of importing <ansi fg="red">%s</ansi>. Nothing has been written.</ansi>`, report.Zone))
+			} else if err != nil {
+				user.SendText(fmt.Sprintf(`<ansi fg="yellow-bold">Could not import <ansi fg="red">%s</ansi>. Nothing has been written.</ansi>`, report.Zone))
+			} else {
+				user.SendText(fmt.Sprintf(`<ansi fg="yellow-bold">Imported <ansi fg="red">%s</ansi>.</ansi>`, report.Zone))
+			}
+
+			for _, change := range report.Changes {
+				user.SendText(`  ` + change)
+			}
+
+			for _, warning := range report.Warnings {
+				user.SendText(`  <ansi fg="red">Warning:</ansi> ` + warning)
+			}
+
+			user.SendText(fmt.Sprintf(`  NextRoomId: <ansi fg="red">%d</ansi>`, report.NextRoomId))
+		}
+
+		if err != nil {
+			user.SendText(fmt.Sprintf(`Import failed: <ansi fg="red">%s</ansi>`, err.Error()))
+		}
+
+		return true, nil
+	}
+
 	zoneConfig := rooms.GetZoneConfig(room.Zone)
 	if zoneConfig == nil {
 		user.SendText(fmt.Sprintf(`Couldn't find zone info for <ansi fg="red">%s</ansi>`, room.Zone))
@@ -49,11 +123,94 @@ func Zone(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 			user.SendText(fmt.Sprintf(`  <ansi fg="yellow-bold">Mob AutoScale:</ansi>    <ansi fg="red">%d</ansi> - <ansi fg="red">%d</ansi>`, zoneConfig.MobAutoScale.Minimum, zoneConfig.MobAutoScale.Maximum))
 		}
 
+		if !zoneConfig.Instanced {
+			user.SendText(`  <ansi fg="yellow-bold">Instanced:</ansi>        <ansi fg="red">[disabled]</ansi>`)
+		} else {
+			user.SendText(`  <ansi fg="yellow-bold">Instanced:</ansi>        <ansi fg="red">yes</ansi>`)
+
+			for _, zi := range rooms.GetAllInstances() {
+				if zi.Zone != room.Zone {
+					continue
+				}
+
+				userNames := []string{}
+				for userId := range zi.UserIds {
+					if u := users.GetByUserId(userId); u != nil {
+						userNames = append(userNames, u.Character.Name)
+					}
+				}
+
+				user.SendText(fmt.Sprintf(`    <ansi fg="yellow-bold">Instance #%d:</ansi> <ansi fg="red">%d</ansi> rooms, <ansi fg="red">%d</ansi> players inside. Members: %s`, zi.InstanceId, len(zi.RoomIds), len(zi.GetPlayers()), strings.Join(userNames, `, `)))
+			}
+		}
+
+		resetInfo := zoneConfig.GetResetPolicy()
+		if zoneConfig.GetResetPolicy() != rooms.ResetManual {
+			resetInfo += ` every ` + zoneConfig.GetResetInterval()
+		}
+		if lastReset := rooms.GetZoneLastReset(room.Zone); lastReset > 0 {
+			resetInfo += fmt.Sprintf(` (last reset at %s)`, gametime.GetDate(lastReset).String())
+		}
+		user.SendText(fmt.Sprintf(`  <ansi fg="yellow-bold">Reset Policy:</ansi>     <ansi fg="red">%s</ansi>`, resetInfo))
+
 		user.SendText(``)
 
 		return true, nil
 	}
 
+	if roomCmd == `reset` {
+
+		resetRoomIds, err := rooms.ResetZone(room.Zone)
+		if err != nil {
+			user.SendText(err.Error())
+			return true, nil
+		}
+
+		user.SendText(fmt.Sprintf(`Zone <ansi fg="red">%s</ansi> reset. <ansi fg="red">%d</ansi> rooms restored.`, room.Zone, len(resetRoomIds)))
+		return true, nil
+	}
+
+	if roomCmd == `coords` {
+
+		subCmd := ``
+		if len(args) > 0 {
+			subCmd = strings.ToLower(args[0])
+		}
+
+		switch subCmd {
+		case ``:
+			conflicts := rooms.CheckZoneCoordinates(room.Zone)
+			user.SendText(fmt.Sprintf(`Saved coordinates for <ansi fg="red">%s</ansi>:`, room.Zone))
+			sendCoordinateConflicts(user, conflicts)
+
+		case `layout`:
+			layout, conflicts, err := rooms.LayoutZone(room.Zone)
+			if err != nil {
+				user.SendText(err.Error())
+				return true, nil
+			}
+
+			user.SendText(fmt.Sprintf(`Laid out <ansi fg="red">%d</ansi> of <ansi fg="red">%d</ansi> rooms in <ansi fg="red">%s</ansi>:`, len(layout), len(rooms.GetZoneRoomIds(room.Zone)), room.Zone))
+			sendCoordinateConflicts(user, conflicts)
+
+			if len(args) > 1 && strings.ToLower(args[1]) == `save` {
+				savedCt := rooms.ApplyZoneLayout(layout)
+				user.SendText(fmt.Sprintf(`Saved coordinates to <ansi fg="red">%d</ansi> rooms.`, savedCt))
+			} else {
+				user.SendText(`Nothing saved. Use <ansi fg="command">zone coords layout save</ansi> to keep it.`)
+			}
+
+		case `clear`:
+			clearedCt := rooms.ClearZoneCoordinates(room.Zone)
+			user.SendText(fmt.Sprintf(`Cleared coordinates from <ansi fg="red">%d</ansi> rooms.`, clearedCt))
+
+		default:
+			user.SendText(`Try <ansi fg="command">zone coords</ansi>, <ansi fg="command">zone coords layout [save]</ansi> or <ansi fg="command">zone coords clear</ansi>.`)
+		}
+
+		return true, nil
+	}
+
 	// Everthing after this point requires additional args
 	if len(args) < 1 {
 		user.SendText(`Not enough arguments provided.`)
@@ -88,7 +245,62 @@ func Zone(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 			return true, nil
 		}
 
+		if setWhat == `instanced` {
+
+			zoneConfig.Instanced = strings.ToLower(args[0]) == `on` || strings.ToLower(args[0]) == `true`
+
+			user.SendText(`Done!`)
+			return true, nil
+		}
+
+		if setWhat == `resetpolicy` {
+
+			policy := strings.ToLower(args[0])
+			if policy != rooms.ResetManual && policy != rooms.ResetInterval && policy != rooms.ResetEmpty {
+				user.SendText(`Reset policy must be one of: <ansi fg="command">manual</ansi>, <ansi fg="command">interval</ansi>, <ansi fg="command">empty</ansi>`)
+				return true, nil
+			}
+
+			zoneConfig.ResetPolicy = policy
+			zoneConfig.Validate()
+
+			user.SendText(`Done!`)
+			return true, nil
+		}
+
+		if setWhat == `resetinterval` {
+
+			// A bare unit such as "day" means one of them, but an explicit quantity has to be at least 1
+			if qty, err := strconv.Atoi(args[0]); err == nil && qty < 1 {
+				user.SendText(`Reset interval must be at least 1, such as <ansi fg="command">1 hour</ansi> or <ansi fg="command">3 real minutes</ansi>`)
+				return true, nil
+			}
+
+			zoneConfig.ResetInterval = strings.Join(args, ` `)
+
+			user.SendText(`Done!`)
+			return true, nil
+		}
+
 	}
 
 	return true, nil
 }
+
+func sendCoordinateConflicts(user *users.UserRecord, conflicts []rooms.CoordinateConflict) {
+
+	if len(conflicts) == 0 {
+		user.SendText(`  <ansi fg="green">No conflicts found.</ansi>`)
+		return
+	}
+
+	for _, c := range conflicts {
+		if c.OtherRoomId == 0 {
+			user.SendText(fmt.Sprintf(`  Room <ansi fg="red">%d</ansi> %s`, c.RoomId, c.Reason))
+		} else {
+			user.SendText(fmt.Sprintf(`  Rooms <ansi fg="red">%d</ansi> and <ansi fg="red">%d</ansi>: %s`, c.RoomId, c.OtherRoomId, c.Reason))
+		}
+	}
+
+	user.SendText(fmt.Sprintf(`  <ansi fg="yellow">%d</ansi> conflicts found.`, len(conflicts)))
+}
@@ -0,0 +1,189 @@
+package usercommands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/volte6/gomud/internal/configs"
+	"github.com/volte6/gomud/internal/parties"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/templates"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+func Arena(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
+
+	c := configs.GetConfig()
+
+	args := util.SplitButRespectQuotes(strings.ToLower(rest))
+
+	action := ``
+	if len(args) > 0 {
+		action = args[0]
+		args = args[1:]
+	}
+
+	switch action {
+
+	case `queue`, `join`:
+
+		mode := rooms.ArenaSolo
+		if len(args) > 0 {
+			mode = args[0]
+		}
+
+		if err := rooms.QueueForArena(user, mode); err != nil {
+			user.SendText(fmt.Sprintf(`You can't queue for the arena: %s.`, err))
+			return true, nil
+		}
+
+		if mode == rooms.ArenaTeam {
+			if party := parties.Get(user.UserId); party != nil {
+				for _, userId := range party.GetMembers() {
+					if userId == user.UserId {
+						continue
+					}
+					if u := users.GetByUserId(userId); u != nil {
+						u.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> has queued your party for a team arena match.`, user.Character.Name))
+					}
+				}
+			}
+		}
+
+		user.SendText(fmt.Sprintf(`You join the <ansi fg="yellow">%s</ansi> arena queue. You will be summoned when a match is found.`, mode))
+		return true, nil
+
+	case `leave`:
+
+		if !rooms.LeaveArenaQueue(user.UserId) {
+			user.SendText(`You aren't in the arena queue.`)
+			return true, nil
+		}
+
+		user.SendText(`You leave the arena queue.`)
+		return true, nil
+
+	case `forfeit`, `yield`:
+
+		if !rooms.ForfeitArenaMatch(user.UserId) {
+			user.SendText(`You aren't fighting in the arena.`)
+			return true, nil
+		}
+
+		user.SendText(`You throw down your weapon and forfeit the match.`)
+		return true, nil
+
+	case `list`, `matches`:
+
+		matches := rooms.GetAllArenaMatches()
+		if len(matches) == 0 {
+			user.SendText(`There are no arena matches going on right now.`)
+			return true, nil
+		}
+
+		headers := []string{`Match`, `Mode`, `Fighters`, `Watching`}
+		rows := [][]string{}
+		formatting := []string{
+			`<ansi fg="red">%s</ansi>`,
+			`<ansi fg="yellow">%s</ansi>`,
+			`%s`,
+			`<ansi fg="157">%s</ansi>`,
+		}
+
+		for _, m := range matches {
+			rows = append(rows, []string{
+				`#` + strconv.Itoa(m.MatchId),
+				m.Mode,
+				m.GetTeamNames(0) + ` vs ` + m.GetTeamNames(1),
+				strconv.Itoa(len(m.Spectators)),
+			})
+		}
+
+		tbl := templates.GetTable(`Arena Matches`, headers, rows, formatting)
+		tplTxt, _ := templates.Process("tables/generic", tbl)
+		user.SendText(tplTxt)
+		user.SendText(`Type <ansi fg="command">arena watch [#match]</ansi> to watch one.`)
+
+		return true, nil
+
+	case `watch`, `spectate`:
+
+		if len(args) == 0 {
+			user.SendText(`Watch which match? Type <ansi fg="command">arena list</ansi> to see what's on.`)
+			return true, nil
+		}
+
+		matchId := 0
+		if strings.HasPrefix(args[0], `#`) {
+			matchId, _ = strconv.Atoi(args[0][1:])
+		} else if n, err := strconv.Atoi(args[0]); err == nil {
+			matchId = n
+		} else if u := users.GetByCharacterName(args[0]); u != nil {
+			if m := rooms.GetArenaMatchForUser(u.UserId); m != nil {
+				matchId = m.MatchId
+			}
+		}
+
+		if err := rooms.WatchArenaMatch(user.UserId, matchId); err != nil {
+			user.SendText(fmt.Sprintf(`You can't watch that: %s.`, err))
+			return true, nil
+		}
+
+		m := rooms.GetArenaMatch(matchId)
+		user.SendText(fmt.Sprintf(`You start watching arena match <ansi fg="red">#%d</ansi>: %s vs %s.`, m.MatchId, m.GetTeamNames(0), m.GetTeamNames(1)))
+		user.SendText(`Type <ansi fg="command">arena unwatch</ansi> to stop.`)
+
+		return true, nil
+
+	case `unwatch`:
+
+		if !rooms.StopWatchingArena(user.UserId) {
+			user.SendText(`You aren't watching an arena match.`)
+			return true, nil
+		}
+
+		user.SendText(`You stop watching the arena.`)
+		return true, nil
+
+	case ``, `status`:
+
+		// Make sure the stats shown are for the current season
+		user.Character.Arena.SetSeason(int(c.ArenaSeason))
+		stats := user.Character.Arena
+
+		user.SendText(``)
+		user.SendText(fmt.Sprintf(`<ansi fg="yellow">Arena Season %d</ansi>`, c.ArenaSeason))
+		user.SendText(fmt.Sprintf(`  Rating:  <ansi fg="yellow-bold">%d</ansi>`, stats.GetRating()))
+		user.SendText(fmt.Sprintf(`  Record:  <ansi fg="green">%d</ansi> wins, <ansi fg="red">%d</ansi> losses, %d draws`, stats.Wins, stats.Losses, stats.Draws))
+
+		if len(stats.PastSeasons) > 0 {
+			for season := 1; season < stats.Season; season++ {
+				if past, ok := stats.PastSeasons[season]; ok {
+					user.SendText(fmt.Sprintf(`  Season %d: finished on <ansi fg="yellow-bold">%d</ansi> (%d-%d-%d)`, season, past.Rating, past.Wins, past.Losses, past.Draws))
+				}
+			}
+		}
+
+		user.SendText(``)
+
+		if m := rooms.GetArenaMatchForUser(user.UserId); m != nil {
+			user.SendText(fmt.Sprintf(`You are fighting in arena match <ansi fg="red">#%d</ansi>.`, m.MatchId))
+		} else if mode, waited, ok := rooms.GetArenaQueueStatus(user.UserId); ok {
+			user.SendText(fmt.Sprintf(`You have been waiting in the <ansi fg="yellow">%s</ansi> queue for %d seconds.`, mode, c.RoundsToSeconds(int(waited))))
+		} else if err := rooms.ArenaAvailable(); err != nil {
+			user.SendText(fmt.Sprintf(`The arena is closed: %s.`, err))
+		} else {
+			user.SendText(`Type <ansi fg="command">arena queue</ansi> to find a match.`)
+		}
+
+		user.SendText(``)
+
+		return true, nil
+	}
+
+	user.SendText(`Unknown arena command. Type <ansi fg="command">help arena</ansi> for help.`)
+
+	return true, nil
+}
@@ -3,9 +3,11 @@ package usercommands
 import (
 	"fmt"
 
+	"github.com/volte6/gomud/internal/gametime"
 	"github.com/volte6/gomud/internal/rooms"
 	"github.com/volte6/gomud/internal/templates"
 	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/weather"
 )
 
 func Biome(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
@@ -14,10 +16,23 @@ func Biome(rest string, user *users.UserRecord, room *rooms.Room) (bool, error)
 
 	if !ok {
 		user.SendText(`No biome information found about this area.`)
-		return false, fmt.Errorf(`biome %d not found`, room.Biome)
+		return false, fmt.Errorf(`biome %s not found`, room.Biome)
 	}
 
-	biomeTxt, _ := templates.Process("descriptions/biome", biome)
+	biomeInfo := struct {
+		rooms.BiomeInfo
+		Season  weather.Season
+		Weather string
+	}{
+		BiomeInfo: biome,
+		Season:    weather.GetSeason(gametime.GetDate().Month),
+	}
+
+	if wInfo, ok := weather.GetConditionInfo(room.GetWeather()); ok {
+		biomeInfo.Weather = wInfo.Name
+	}
+
+	biomeTxt, _ := templates.Process("descriptions/biome", biomeInfo)
 	user.SendText(biomeTxt)
 
 	return true, nil
@@ -10,7 +10,7 @@ import (
 func Break(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 
 	if user.Character.Aggro != nil {
-		user.Character.Aggro = nil
+		user.Character.EndAggro()
 		user.SendText(`You break off combat.`)
 		room.SendText(
 			fmt.Sprintf(`<ansi fg="username">%s</ansi> breaks off combat.`, user.Character.Name),
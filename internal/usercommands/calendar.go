@@ -0,0 +1,147 @@
+package usercommands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/volte6/gomud/internal/calendar"
+	"github.com/volte6/gomud/internal/gametime"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+func Calendar(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
+
+	roundNow := util.GetRoundCount()
+	gd := gametime.GetDate(roundNow)
+
+	allEvents := []*calendar.EventSpec{}
+	for _, e := range calendar.GetAllEvents() {
+		if !e.Hidden {
+			allEvents = append(allEvents, e)
+		}
+	}
+
+	// Details of a single event
+	if rest != `` {
+
+		for _, e := range allEvents {
+
+			if !strings.EqualFold(e.EventId, rest) && !strings.Contains(strings.ToLower(e.Name), strings.ToLower(rest)) {
+				continue
+			}
+
+			user.SendText(``)
+			user.SendText(fmt.Sprintf(`<ansi fg="yellow-bold">%s</ansi>`, strings.Title(e.Name)))
+			if e.Description != `` {
+				user.SendText(`  ` + e.Description)
+			}
+			user.SendText(fmt.Sprintf(`  Held %s, for %s.`, e.Schedule.String(), e.Duration))
+
+			if o, ok := e.GetCurrent(roundNow); ok && e.IsActive() {
+				user.SendText(fmt.Sprintf(`  <ansi fg="green">Happening now!</ansi> It ends %s.`, calendarDate(o.End, roundNow)))
+			} else if o, ok := e.GetNext(roundNow); ok {
+				user.SendText(fmt.Sprintf(`  Next held %s.`, calendarDate(o.Start, roundNow)))
+			}
+			user.SendText(``)
+
+			return true, nil
+		}
+
+		user.SendText(fmt.Sprintf(`There is no event called "%s" on the calendar.`, rest))
+		return true, nil
+	}
+
+	user.SendText(``)
+	user.SendText(fmt.Sprintf(`It is day <ansi fg="230">%d</ansi> of <ansi fg="230">%s</ansi>, year <ansi fg="230">%d</ansi>. The moon is <ansi fg="230">%s</ansi>.`,
+		gd.MonthDay, gametime.MonthName(gd.Month), gd.Year, gd.MoonPhase()))
+
+	if len(allEvents) == 0 {
+		user.SendText(`Nothing is on the calendar.`)
+		user.SendText(``)
+		return true, nil
+	}
+
+	happening := []string{}
+
+	type upcomingEvent struct {
+		start uint64
+		text  string
+	}
+	upcoming := []upcomingEvent{}
+
+	for _, e := range allEvents {
+
+		if o, ok := e.GetCurrent(roundNow); ok && e.IsActive() {
+			happening = append(happening, fmt.Sprintf(`  <ansi fg="yellow">%s</ansi> - ends %s`, strings.Title(e.Name), calendarDate(o.End, roundNow)))
+			continue
+		}
+
+		if o, ok := e.GetNext(roundNow); ok {
+			upcoming = append(upcoming, upcomingEvent{
+				start: o.Start,
+				text:  fmt.Sprintf(`  <ansi fg="yellow">%s</ansi> - %s`, strings.Title(e.Name), calendarDate(o.Start, roundNow)),
+			})
+		}
+	}
+
+	if len(happening) > 0 {
+		user.SendText(``)
+		user.SendText(`<ansi fg="green">Happening now:</ansi>`)
+		for _, txt := range happening {
+			user.SendText(txt)
+		}
+	}
+
+	if len(upcoming) > 0 {
+
+		sort.Slice(upcoming, func(i, j int) bool {
+			return upcoming[i].start < upcoming[j].start
+		})
+
+		user.SendText(``)
+		user.SendText(`<ansi fg="magenta">Coming up:</ansi>`)
+		for _, u := range upcoming {
+			user.SendText(u.text)
+		}
+	}
+
+	user.SendText(``)
+	user.SendText(`Use <ansi fg="command">calendar [event]</ansi> for more about an event.`)
+	user.SendText(``)
+
+	return true, nil
+}
+
+// Describes a round as a calendar date, along with how long from now it is
+func calendarDate(roundNumber uint64, roundNow uint64) string {
+
+	gd := gametime.GetDate(roundNumber)
+
+	dateStr := fmt.Sprintf(`on day <ansi fg="230">%d</ansi> of <ansi fg="230">%s</ansi> at %s`, gd.MonthDay, gametime.MonthName(gd.Month), gd.String())
+
+	if roundNumber <= roundNow {
+		return dateStr
+	}
+
+	roundsPerHour := uint64(gd.RoundsPerDay) / 24
+	if roundsPerHour < 1 {
+		roundsPerHour = 1
+	}
+	hours := (roundNumber - roundNow) / roundsPerHour
+
+	switch {
+	case hours >= 48:
+		dateStr += fmt.Sprintf(` (in %d days)`, hours/24)
+	case hours >= 24:
+		dateStr += ` (in 1 day)`
+	case hours >= 2:
+		dateStr += fmt.Sprintf(` (in %d hours)`, hours)
+	default:
+		dateStr += ` (very soon)`
+	}
+
+	return dateStr
+}
@@ -1,6 +1,7 @@
 package usercommands
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/volte6/gomud/internal/buffs"
@@ -15,20 +16,30 @@ func Conditions(rest string, user *users.UserRecord, room *rooms.Room) (bool, er
 		Name        string
 		Description string
 		RoundsLeft  int
+		Stacks      int
 	}
 
 	afflictions := []buffInfo{}
 
 	charBuffs := user.Character.GetBuffs()
+	seenBuffs := map[int]int{} // buffId => index in afflictions
 	for _, buff := range charBuffs {
 
 		spec := buffs.GetBuffSpec(buff.BuffId)
 		totalRounds := int(math.Ceil(float64(buff.TriggersLeft) * float64(spec.RoundInterval)))
+		roundsLeft := totalRounds - (buff.RoundCounter)
+
+		// Independent copies of the same buff are shown together
+		if idx, ok := seenBuffs[buff.BuffId]; ok {
+			afflictions[idx].RoundsLeft = max(afflictions[idx].RoundsLeft, roundsLeft)
+			continue
+		}
 
 		newAffliction := buffInfo{
 			Name:        spec.Name,
 			Description: spec.Description,
-			RoundsLeft:  totalRounds - (buff.RoundCounter),
+			RoundsLeft:  roundsLeft,
+			Stacks:      user.Character.GetBuffStacks(buff.BuffId),
 		}
 
 		if spec.Secret {
@@ -36,6 +47,11 @@ func Conditions(rest string, user *users.UserRecord, room *rooms.Room) (bool, er
 			newAffliction.Description = "Unknown"
 		}
 
+		if newAffliction.Stacks > 1 {
+			newAffliction.Name = fmt.Sprintf(`%s x%d`, newAffliction.Name, newAffliction.Stacks)
+		}
+
+		seenBuffs[buff.BuffId] = len(afflictions)
 		afflictions = append(afflictions, newAffliction)
 	}
 
@@ -2,10 +2,14 @@ package usercommands
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/volte6/gomud/internal/characters"
 	"github.com/volte6/gomud/internal/combat"
+	"github.com/volte6/gomud/internal/items"
 	"github.com/volte6/gomud/internal/mobs"
 	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/statmods"
 	"github.com/volte6/gomud/internal/users"
 	"github.com/volte6/gomud/internal/util"
 )
@@ -33,6 +37,7 @@ func Consider(rest string, user *users.UserRecord, room *rooms.Room) (bool, erro
 
 			considerType := "mob"
 			considerName := "nobody"
+			var considerChar *characters.Character
 
 			if playerId > 0 {
 				u := users.GetByUserId(playerId)
@@ -43,6 +48,7 @@ func Consider(rest string, user *users.UserRecord, room *rooms.Room) (bool, erro
 				ratio = p1 / p2
 				considerType = "user"
 				considerName = u.Character.Name
+				considerChar = u.Character
 
 			} else if mobId > 0 {
 
@@ -54,6 +60,7 @@ func Consider(rest string, user *users.UserRecord, room *rooms.Room) (bool, erro
 				ratio = p1 / p2
 				considerType = "mob"
 				considerName = m.Character.Name
+				considerChar = &m.Character
 			}
 
 			prediction := `Unknown`
@@ -79,6 +86,64 @@ func Consider(rest string, user *users.UserRecord, room *rooms.Room) (bool, erro
 			user.SendText(
 				fmt.Sprintf(`It is estimated that your chances to kill <ansi fg="%sname">%s</ansi> are %s (%f)`, considerType, considerName, prediction, ratio),
 			)
+
+			// Elemental strengths and weaknesses
+			resistances := map[string][]string{}
+			for _, elementName := range items.ElementNames() {
+				element := items.Element(elementName)
+				if desc := items.ResistanceDescription(considerChar.GetResistance(element)); desc != `` {
+					resistances[desc] = append(resistances[desc], element.ColorName())
+				}
+			}
+
+			for _, desc := range []string{`very weak`, `weak`, `resistant`, `very resistant`, `immune`} {
+				if len(resistances[desc]) > 0 {
+					user.SendText(fmt.Sprintf(`<ansi fg="%sname">%s</ansi> looks %s to %s.`, considerType, considerName, desc, strings.Join(resistances[desc], `, `)))
+				}
+			}
+
+			weaponSpec := user.Character.Equipment.Weapon.GetSpec()
+
+			weaponType := items.Generic
+			if weaponSpec.Subtype != `` {
+				weaponType = weaponSpec.Subtype
+			}
+			penetration := weaponSpec.Damage.Penetration + user.Character.StatMod(string(statmods.Penetration))
+			if mitigation := considerChar.GetArmorMitigation(weaponType, penetration); mitigation > 0 {
+				user.SendText(fmt.Sprintf(`<ansi fg="%sname">%s</ansi>'s armor looks like it will soak up about %d%% of your blows.`, considerType, considerName, mitigation))
+			}
+
+			// Who the mob is most angry with
+			if mobId > 0 {
+				threatList := considerChar.ThreatList()
+				if len(threatList) > 5 {
+					threatList = threatList[:5]
+				}
+
+				threatNames := []string{}
+				for _, uId := range threatList {
+					name := `someone`
+					if uId == user.UserId {
+						name = `<ansi fg="username">you</ansi>`
+					} else if u := users.GetByUserId(uId); u != nil {
+						name = fmt.Sprintf(`<ansi fg="username">%s</ansi>`, u.Character.Name)
+					}
+					if considerChar.Aggro != nil && considerChar.Aggro.UserId == uId {
+						name += ` (target)`
+					}
+					threatNames = append(threatNames, fmt.Sprintf(`%s <ansi fg="red">%d</ansi>`, name, considerChar.GetThreat(uId)))
+				}
+
+				if len(threatNames) > 0 {
+					user.SendText(fmt.Sprintf(`<ansi fg="mobname">%s</ansi>'s threat: %s`, considerName, strings.Join(threatNames, `, `)))
+				}
+			}
+
+			if element := weaponSpec.Element; element != `` {
+				if desc := items.ResistanceDescription(considerChar.GetResistance(element)); desc != `` {
+					user.SendText(fmt.Sprintf(`Your weapon deals %s damage, which <ansi fg="%sname">%s</ansi> is %s to.`, element.ColorName(), considerType, considerName, desc))
+				}
+			}
 		}
 	}
 
@@ -0,0 +1,149 @@
+package usercommands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/volte6/gomud/internal/configs"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+func Duel(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
+
+	args := util.SplitButRespectQuotes(strings.ToLower(rest))
+
+	if len(args) == 0 {
+
+		d := rooms.GetDuel(user.UserId)
+		if d == nil {
+			user.SendText(`Challenge someone with <ansi fg="command">duel [player] [wager]</ansi>. Type <ansi fg="command">help duel</ansi> for more.`)
+			return true, nil
+		}
+
+		opponentName := `someone`
+		if u := users.GetByUserId(d.GetOpponent(user.UserId)); u != nil {
+			opponentName = u.Character.Name
+		}
+
+		switch {
+		case d.State == rooms.DuelPending && d.ChallengerId == user.UserId:
+			user.SendText(fmt.Sprintf(`You are waiting for <ansi fg="username">%s</ansi> to accept your challenge.`, opponentName))
+		case d.State == rooms.DuelPending:
+			user.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> has challenged you to a duel. Type <ansi fg="command">duel accept</ansi> or <ansi fg="command">duel decline</ansi>.`, opponentName))
+		default:
+			user.SendText(fmt.Sprintf(`You are dueling <ansi fg="username">%s</ansi>. Type <ansi fg="command">duel yield</ansi> to give up.`, opponentName))
+		}
+
+		if d.Wager > 0 {
+			user.SendText(fmt.Sprintf(`The wager is <ansi fg="gold">%d gold</ansi> each.`, d.Wager))
+		}
+
+		return true, nil
+	}
+
+	switch args[0] {
+
+	case `accept`:
+
+		d, err := rooms.AcceptDuel(user)
+		if err != nil {
+			user.SendText(fmt.Sprintf(`You can't accept: %s.`, err))
+			return true, nil
+		}
+
+		challenger := users.GetByUserId(d.ChallengerId)
+
+		if d.Wager > 0 {
+			room.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> and <ansi fg="username">%s</ansi> each hand over <ansi fg="gold">%d gold</ansi> to be held until the duel is decided.`, challenger.Character.Name, user.Character.Name, d.Wager))
+		}
+
+		user.SendText(fmt.Sprintf(`You accept the challenge from <ansi fg="username">%s</ansi>. Ready yourself!`, challenger.Character.Name))
+		challenger.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> accepts your challenge. Ready yourself!`, user.Character.Name))
+		room.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> and <ansi fg="username">%s</ansi> square off for a duel.`, challenger.Character.Name, user.Character.Name), user.UserId, challenger.UserId)
+
+		return true, nil
+
+	case `decline`, `withdraw`:
+
+		d, ok := rooms.DeclineDuel(user.UserId)
+		if !ok {
+			user.SendText(`You don't have a challenge to decline.`)
+			return true, nil
+		}
+
+		other := users.GetByUserId(d.GetOpponent(user.UserId))
+
+		if d.ChallengerId == user.UserId {
+			user.SendText(`You withdraw your challenge.`)
+			if other != nil {
+				other.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> withdraws their challenge.`, user.Character.Name))
+			}
+		} else {
+			user.SendText(`You decline the challenge.`)
+			if other != nil {
+				other.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> declines your challenge.`, user.Character.Name))
+			}
+		}
+
+		return true, nil
+
+	case `yield`, `forfeit`:
+
+		if !rooms.YieldDuel(user.UserId) {
+			user.SendText(`You aren't in a duel.`)
+			return true, nil
+		}
+
+		return true, nil
+	}
+
+	// Anything else is a challenge, with an optional wager at the end
+	wager := 0
+	if len(args) > 1 {
+
+		wagerStr := args[len(args)-1]
+		if wagerStr == `gold` && len(args) > 2 {
+			args = args[:len(args)-1]
+			wagerStr = args[len(args)-1]
+		}
+
+		if n, err := strconv.Atoi(wagerStr); err == nil {
+			if n < 0 {
+				user.SendText(`You can't wager a negative amount of gold.`)
+				return true, nil
+			}
+			wager = n
+			args = args[:len(args)-1]
+		}
+	}
+
+	playerId, _ := room.FindByName(strings.Join(args, ` `))
+	targetUser := users.GetByUserId(playerId)
+	if targetUser == nil {
+		user.SendText(`Duel whom?`)
+		return true, nil
+	}
+
+	if err := rooms.ChallengeDuel(user, targetUser, wager); err != nil {
+		if err == rooms.ErrDuelWagerLimit {
+			user.SendText(fmt.Sprintf(`You can't challenge them: the most you can wager is <ansi fg="gold">%d gold</ansi>.`, configs.GetConfig().DuelMaxWager))
+		} else {
+			user.SendText(fmt.Sprintf(`You can't challenge them: %s.`, err))
+		}
+		return true, nil
+	}
+
+	wagerStr := ``
+	if wager > 0 {
+		wagerStr = fmt.Sprintf(` for <ansi fg="gold">%d gold</ansi>`, wager)
+	}
+
+	user.SendText(fmt.Sprintf(`You challenge <ansi fg="username">%s</ansi> to a duel%s.`, targetUser.Character.Name, wagerStr))
+	targetUser.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> challenges you to a duel%s! Type <ansi fg="command">duel accept</ansi> or <ansi fg="command">duel decline</ansi>.`, user.Character.Name, wagerStr))
+	room.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> challenges <ansi fg="username">%s</ansi> to a duel%s.`, user.Character.Name, targetUser.Character.Name, wagerStr), user.UserId, targetUser.UserId)
+
+	return true, nil
+}
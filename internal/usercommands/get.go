@@ -129,6 +129,11 @@ func Get(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 	if containerName != `` {
 		container := room.Containers[containerName]
 
+		if !container.CanLoot(user) {
+			user.SendText(fmt.Sprintf(`You have no right to loot the <ansi fg="container">%s</ansi>.`, containerName))
+			return true, nil
+		}
+
 		goldName := `gold`
 		if args[0] == goldName || (len(args[0]) < 5 && goldName[0:len(args[0])-1] == args[0]) {
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/volte6/gomud/internal/buffs"
 	"github.com/volte6/gomud/internal/configs"
+	"github.com/volte6/gomud/internal/events"
 	"github.com/volte6/gomud/internal/items"
 	"github.com/volte6/gomud/internal/mobs"
 	"github.com/volte6/gomud/internal/parties"
@@ -40,10 +41,37 @@ func Go(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 			return true, nil
 		}
 
-		actionCost := 10
+		if exitInfo, _ := room.GetExitInfo(exitName); exitInfo.IsClosed() {
+			user.SendText(fmt.Sprintf(`The %s to the <ansi fg="exit">%s</ansi> is closed.`, exitInfo.Door.GetName(), exitName))
+			return true, nil
+		}
+
+		if goRoom := rooms.LoadRoom(goRoomId); goRoom != nil && goRoom.House != nil && !goRoom.House.CanEnter(user) {
+			user.SendText(fmt.Sprintf(`The door is locked. Only %s and their guests can go in.`, goRoom.House.OwnerName))
+			return true, nil
+		}
+
+		// How the exit is traveled. The room script gets a chance to stop it first.
+		movement := room.GetExitMovement(exitName)
+		if stopped, err := scripting.TryRoomMoveEvent(exitName, string(movement), room.RoomId, user.UserId, 0); err == nil && stopped {
+			return true, nil
+		}
+
+		destBiome := rooms.BiomeInfo{}
+		if goRoom := rooms.LoadRoom(goRoomId); goRoom != nil {
+			destBiome = goRoom.GetBiome()
+		}
+
+		moveResult := rooms.AttemptMovement(user.Character, movement, destBiome)
+		if moveResult.Blocked {
+			user.SendText(fmt.Sprintf(moveResult.Info.BlockedText, exitName))
+			return true, nil
+		}
+
+		actionCost := moveResult.Info.ActionCost
 		encumbered := false
 		if len(user.Character.Items) > user.Character.CarryCapacity() {
-			actionCost = 50
+			actionCost *= 5
 			encumbered = true
 		}
 
@@ -126,6 +154,49 @@ func Go(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 
 		}
 
+		if moveResult.Failed {
+
+			user.SendText(fmt.Sprintf(moveResult.Info.FailText, exitName))
+			room.SendText(fmt.Sprintf(moveResult.Info.RoomFailText, user.Character.Name, exitName), user.UserId)
+
+			if moveResult.Damage > 0 {
+				user.Character.ApplyHealthChange(moveResult.Damage * -1)
+				user.SendText(fmt.Sprintf(`You take <ansi fg="damage">%d damage</ansi>!`, moveResult.Damage))
+
+				if user.Character.Health <= -10 {
+					user.Command(`suicide`) // suicide drops all money/items and transports to land of the dead.
+					return true, nil
+				}
+
+				if user.Character.Health < 1 {
+					user.SendText(`<ansi fg="red">you drop to the ground!</ansi>`)
+					room.SendText(
+						fmt.Sprintf(`<ansi fg="username">%s</ansi> <ansi fg="red">drops to the ground!</ansi>`, user.Character.Name),
+						user.UserId)
+					return true, nil
+				}
+			}
+
+			if moveResult.Info.FailBuffId > 0 {
+				events.AddToQueue(events.Buff{
+					UserId:        user.UserId,
+					MobInstanceId: 0,
+					BuffId:        moveResult.Info.FailBuffId,
+				})
+			}
+
+			if moveResult.Info.FailPushBack {
+				return true, nil
+			}
+		}
+
+		if moveResult.UsedItem.ItemId > 0 {
+			user.SendText(fmt.Sprintf(`You make use of your <ansi fg="item">%s</ansi>.`, moveResult.UsedItem.DisplayName()))
+			if destBiome.UsesItem() {
+				user.Character.UseItem(moveResult.UsedItem)
+			}
+		}
+
 		// Load current room details
 		destRoom := rooms.LoadRoom(goRoomId)
 		if destRoom == nil {
@@ -153,8 +224,23 @@ func Go(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 			user.SendText("Oops, couldn't move there!")
 		} else {
 
+			// They may have ended up in a zone instance rather than the room the exit points to
+			if destRoom.RoomId != user.Character.RoomId {
+				if actualRoom := rooms.LoadRoom(user.Character.RoomId); actualRoom != nil {
+					destRoom = actualRoom
+				}
+			}
+
 			scripting.TryRoomScriptEvent(`onExit`, user.UserId, originRoomId)
 
+			if moveResult.Info.SuccessBuffId > 0 && !moveResult.Failed {
+				events.AddToQueue(events.Buff{
+					UserId:        user.UserId,
+					MobInstanceId: 0,
+					BuffId:        moveResult.Info.SuccessBuffId,
+				})
+			}
+
 			c := configs.GetConfig()
 
 			// Tell the player they are moving
@@ -164,10 +250,15 @@ func Go(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 						fmt.Sprintf(`You <ansi fg="black-bold">sneak</ansi> towards the <ansi fg="exit">%s</ansi> exit.`, exitName),
 					))
 			} else {
+
+				moveText := fmt.Sprintf(`You head towards the <ansi fg="exit">%s</ansi> exit.`, exitName)
+				if moveResult.Info.SuccessText != `` {
+					moveText = fmt.Sprintf(moveResult.Info.SuccessText, exitName)
+				}
+
 				user.SendText(
-					fmt.Sprintf(string(c.ExitRoomMessageWrapper),
-						fmt.Sprintf(`You head towards the <ansi fg="exit">%s</ansi> exit.`, exitName),
-					))
+					fmt.Sprintf(string(c.ExitRoomMessageWrapper), moveText),
+				)
 
 				// Tell the old room they are leaving
 				if user.Character.Pet.Exists() {
@@ -179,10 +270,14 @@ func Go(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 						user.UserId)
 
 				} else {
+
+					leaveText := fmt.Sprintf(`<ansi fg="username">%s</ansi> leaves towards the <ansi fg="exit">%s</ansi> exit.`, user.Character.Name, exitName)
+					if moveResult.Info.RoomSuccessText != `` {
+						leaveText = fmt.Sprintf(moveResult.Info.RoomSuccessText, user.Character.Name, exitName)
+					}
+
 					room.SendText(
-						fmt.Sprintf(string(c.ExitRoomMessageWrapper),
-							fmt.Sprintf(`<ansi fg="username">%s</ansi> leaves towards the <ansi fg="exit">%s</ansi> exit.`, user.Character.Name, exitName),
-						),
+						fmt.Sprintf(string(c.ExitRoomMessageWrapper), leaveText),
 						user.UserId)
 				}
 
@@ -208,7 +303,7 @@ func Go(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 
 				}
 
-				destRoom.SendTextToExits(`You hear someone moving around.`, true, room.GetPlayers(rooms.FindAll)...)
+				destRoom.PropagateSound(rooms.Sound{Description: `someone moving around`, Volume: 1, IsQuiet: true}, room.GetPlayers(rooms.FindAll)...)
 			}
 
 			if currentParty := parties.Get(user.UserId); currentParty != nil {
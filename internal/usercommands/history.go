@@ -1,14 +1,45 @@
 package usercommands
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/volte6/gomud/internal/audit"
 	"github.com/volte6/gomud/internal/configs"
 	"github.com/volte6/gomud/internal/rooms"
 	"github.com/volte6/gomud/internal/templates"
 	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+const (
+	journalDefaultCount  = 5
+	journalMaxDiffLength = 20
 )
 
 func History(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 
+	// Builders can also look through the journal of world changes
+	if user.Permission == users.PermissionAdmin || user.HasAdminCommand(`undo`) {
+		if args := util.SplitButRespectQuotes(rest); len(args) > 0 {
+			if args[0] == `builds` {
+				return journalHistory(audit.GetRecent(journalCount(args[1:])), false, user)
+			}
+			for _, kind := range audit.AllKinds() {
+				if strings.ToLower(args[0]) == string(kind) {
+					kind, entityId, args, err := journalTarget(args, room)
+					if err != nil {
+						user.SendText(err.Error())
+						return true, nil
+					}
+					user.SendText(fmt.Sprintf(`Changes to %s <ansi fg="yellow">%s</ansi>:`, kind, entityId))
+					return journalHistory(audit.GetHistory(kind, entityId, journalCount(args)), true, user)
+				}
+			}
+		}
+	}
+
 	headers := []string{`Type` /*`Round`,*/, `Time`, `Log`}
 
 	rows := [][]string{}
@@ -43,3 +74,61 @@ func History(rest string, user *users.UserRecord, room *rooms.Room) (bool, error
 
 	return true, nil
 }
+
+func journalCount(args []string) int {
+	if len(args) > 0 {
+		if count, err := strconv.Atoi(args[0]); err == nil && count > 0 {
+			return count
+		}
+	}
+	return journalDefaultCount
+}
+
+// Shows journal entries, newest first, optionally with what changed
+func journalHistory(entries []audit.Entry, showDiff bool, user *users.UserRecord) (bool, error) {
+
+	if len(entries) == 0 {
+		user.SendText(`No changes have been journaled.`)
+		return true, nil
+	}
+
+	tFormat := string(configs.GetConfig().TimeFormatShort)
+
+	for _, e := range entries {
+
+		status := ``
+		if e.IsUndo() {
+			undoneIds := []string{}
+			for _, entryId := range e.UndoneIds {
+				undoneIds = append(undoneIds, `#`+strconv.Itoa(entryId))
+			}
+			status = fmt.Sprintf(` <ansi fg="cyan">[undo of %s]</ansi>`, strings.Join(undoneIds, `, `))
+		} else if e.IsUndone() {
+			status = ` <ansi fg="black-bold">[undone]</ansi>`
+		} else if e.Before == `` {
+			status = ` <ansi fg="green">[created]</ansi>`
+		}
+
+		user.SendText(fmt.Sprintf(`<ansi fg="yellow">#%d</ansi> <ansi fg="magenta">%s</ansi> %s %s by <ansi fg="username">%s</ansi>: <ansi fg="command">%s</ansi>%s`,
+			e.EntryId, e.Time.Format(tFormat), e.Kind, e.EntityId, e.Username, e.Action, status))
+
+		if !showDiff {
+			continue
+		}
+
+		diff := audit.Diff(e.Before, e.After)
+		for idx, line := range diff {
+			if idx >= journalMaxDiffLength {
+				user.SendText(fmt.Sprintf(`    <ansi fg="black-bold">... %d more lines</ansi>`, len(diff)-idx))
+				break
+			}
+			if strings.HasPrefix(line, `-`) {
+				user.SendText(`    <ansi fg="red">` + line + `</ansi>`)
+			} else {
+				user.SendText(`    <ansi fg="green">` + line + `</ansi>`)
+			}
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,354 @@
+package usercommands
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Volte6/ansitags"
+
+	"github.com/volte6/gomud/internal/configs"
+	"github.com/volte6/gomud/internal/gametime"
+	"github.com/volte6/gomud/internal/mobs"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+func House(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
+
+	c := configs.GetConfig()
+
+	args := util.SplitButRespectQuotes(rest)
+
+	action := ``
+	if len(args) > 0 {
+		action = strings.ToLower(args[0])
+		rest = strings.TrimSpace(rest[len(args[0]):])
+	}
+
+	houseRoom := rooms.GetHouseByOwner(user.UserId)
+
+	// Buying and selling is done through a realtor
+	if action == `buy` || action == `sell` {
+
+		var realtor *mobs.Mob
+		for _, mobInstanceId := range room.GetMobs(rooms.FindNeutral) {
+			if mob := mobs.GetInstance(mobInstanceId); mob != nil && mob.Realtor {
+				realtor = mob
+				break
+			}
+		}
+
+		if realtor == nil {
+			user.SendText(`There is no realtor here.`)
+			return true, nil
+		}
+
+		if action == `buy` {
+
+			if houseRoom != nil {
+				realtor.Command(fmt.Sprintf(`sayto %s You already own a house. One is plenty.`, user.ShorthandId()))
+				return true, nil
+			}
+
+			price := int(c.HousePrice)
+			if user.Character.Gold < price {
+				realtor.Command(fmt.Sprintf(`sayto %s A house costs %d gold. Come back when you have it on hand.`, user.ShorthandId(), price))
+				return true, nil
+			}
+
+			newHouse, err := rooms.BuyHouse(user)
+			if err != nil {
+				realtor.Command(fmt.Sprintf(`sayto %s I'm afraid there's nothing for sale right now.`, user.ShorthandId()))
+				return true, err
+			}
+
+			user.Character.Gold -= price
+
+			user.EventLog.Add(`house`, fmt.Sprintf(`Bought a house for <ansi fg="gold">%d gold</ansi>`, price))
+
+			user.SendText(fmt.Sprintf(`You pay <ansi fg="gold">%d gold</ansi> and are handed the keys to <ansi fg="room-title">%s</ansi>.`, price, newHouse.Title))
+			user.SendText(fmt.Sprintf(`Your front door is the <ansi fg="exit">%s</ansi> exit on <ansi fg="room-title">%s</ansi>.`, newHouse.House.ExitName, room.Title))
+			if c.HouseRent > 0 {
+				user.SendText(fmt.Sprintf(`Rent of <ansi fg="gold">%d gold</ansi> will be taken from your bank every %s.`, c.HouseRent, c.HouseRentPeriod))
+			}
+			room.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> buys a house from the realtor.`, user.Character.Name), user.UserId)
+
+			return true, nil
+		}
+
+		if houseRoom == nil {
+			realtor.Command(fmt.Sprintf(`sayto %s You don't own a house to sell.`, user.ShorthandId()))
+			return true, nil
+		}
+
+		if rest != `confirm` {
+			user.SendText(fmt.Sprintf(`The realtor will buy your house back for <ansi fg="gold">%d gold</ansi>. Anything on display will be mailed back to you.`, c.HousePrice/2))
+			user.SendText(`Type <ansi fg="command">house sell confirm</ansi> if you're sure.`)
+			return true, nil
+		}
+
+		refund := int(c.HousePrice)/2 - houseRoom.House.RentOwed
+		if refund < 0 {
+			refund = 0
+		}
+
+		rooms.VacateHouse(houseRoom)
+
+		user.Character.Gold += refund
+
+		user.EventLog.Add(`house`, fmt.Sprintf(`Sold your house for <ansi fg="gold">%d gold</ansi>`, refund))
+
+		user.SendText(fmt.Sprintf(`You hand back your keys and receive <ansi fg="gold">%d gold</ansi>.`, refund))
+		room.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> sells their house back to the realtor.`, user.Character.Name), user.UserId)
+
+		return true, nil
+	}
+
+	if houseRoom == nil {
+		user.SendText(`You don't own a house. Find a realtor to <ansi fg="command">house buy</ansi> one.`)
+		return true, nil
+	}
+
+	h := houseRoom.House
+
+	if action == `` {
+
+		user.SendText(``)
+		user.SendText(fmt.Sprintf(`You own <ansi fg="room-title">%s</ansi>.`, houseRoom.Title))
+
+		if len(h.Guests) > 0 {
+			user.SendText(fmt.Sprintf(`  Guests:      <ansi fg="username">%s</ansi>`, strings.Join(h.Guests, `</ansi>, <ansi fg="username">`)))
+		} else {
+			user.SendText(`  Guests:      none`)
+		}
+
+		user.SendText(fmt.Sprintf(`  Decorations: %d of %d`, len(h.Decorations), c.HouseMaxDecorations))
+
+		if c.HouseRent > 0 && h.RentDueRound > 0 {
+			rentDate := gametime.GetDate(h.RentDueRound)
+			user.SendText(fmt.Sprintf(`  Rent:        <ansi fg="gold">%d gold</ansi>, next due on day %d of %s`, c.HouseRent, rentDate.MonthDay, gametime.MonthName(rentDate.Month)))
+		}
+
+		if h.RentOwed > 0 {
+			user.SendText(fmt.Sprintf(`  <ansi fg="red">You owe <ansi fg="gold">%d gold</ansi> in rent.</ansi> Type <ansi fg="command">house pay</ansi> to pay it from your bank.`, h.RentOwed))
+		}
+
+		user.SendText(``)
+		user.SendText(`Type <ansi fg="command">help house</ansi> to see what you can do with it.`)
+		user.SendText(``)
+
+		return true, nil
+	}
+
+	if action == `pay` {
+
+		if h.RentOwed < 1 {
+			user.SendText(`You don't owe any rent.`)
+			return true, nil
+		}
+
+		if user.Character.Bank < h.RentOwed {
+			user.SendText(fmt.Sprintf(`You need <ansi fg="gold">%d gold</ansi> in the bank to pay what you owe.`, h.RentOwed))
+			return true, nil
+		}
+
+		user.Character.Bank -= h.RentOwed
+		user.SendText(fmt.Sprintf(`You pay the <ansi fg="gold">%d gold</ansi> you owe from your bank.`, h.RentOwed))
+		h.RentOwed = 0
+
+		rooms.SaveRoom(*houseRoom)
+
+		return true, nil
+	}
+
+	if action == `guests` {
+
+		if len(h.Guests) == 0 {
+			user.SendText(`You haven't let anyone in. Use <ansi fg="command">house guest add [name]</ansi> to add a guest.`)
+			return true, nil
+		}
+
+		user.SendText(fmt.Sprintf(`Your guests: <ansi fg="username">%s</ansi>`, strings.Join(h.Guests, `</ansi>, <ansi fg="username">`)))
+		return true, nil
+	}
+
+	if action == `guest` {
+
+		guestArgs := strings.SplitN(rest, ` `, 2)
+		if len(guestArgs) < 2 || (guestArgs[0] != `add` && guestArgs[0] != `remove`) {
+			user.SendText(`Try <ansi fg="command">house guest add [name]</ansi> or <ansi fg="command">house guest remove [name]</ansi>.`)
+			return true, nil
+		}
+
+		guestName := strings.TrimSpace(guestArgs[1])
+
+		if guestArgs[0] == `remove` {
+			if !h.RemoveGuest(guestName) {
+				user.SendText(fmt.Sprintf(`%s isn't one of your guests.`, guestName))
+				return true, nil
+			}
+			user.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> is no longer welcome in your house.`, guestName))
+			rooms.SaveRoom(*houseRoom)
+			return true, nil
+		}
+
+		if guestUser := users.GetByCharacterName(guestName); guestUser != nil {
+			guestName = guestUser.Character.Name
+		} else if foundUserId, _ := users.CharacterNameSearch(guestName); foundUserId == 0 {
+			user.SendText(fmt.Sprintf(`There is nobody called "%s".`, guestName))
+			return true, nil
+		}
+
+		if strings.EqualFold(guestName, user.Character.Name) {
+			user.SendText(`It's your house. You can always go in.`)
+			return true, nil
+		}
+
+		if !h.AddGuest(guestName) {
+			user.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> is already a guest.`, guestName))
+			return true, nil
+		}
+
+		user.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> is now welcome in your house.`, guestName))
+		rooms.SaveRoom(*houseRoom)
+
+		return true, nil
+	}
+
+	// Everything else is done from inside the house
+	if room.RoomId != houseRoom.RoomId {
+		user.SendText(`You need to be in your house to do that.`)
+		return true, nil
+	}
+
+	switch action {
+
+	case `title`:
+
+		rest = cleanHouseText(rest)
+
+		if rest == `` {
+			user.SendText(`Give your house a title. For example: <ansi fg="command">house title The Cozy Cottage</ansi>`)
+			return true, nil
+		}
+
+		if len(rest) > 50 {
+			user.SendText(`That title is too long. Keep it under 50 letters.`)
+			return true, nil
+		}
+
+		houseRoom.Title = rest
+		user.SendText(fmt.Sprintf(`Your house is now called <ansi fg="room-title">%s</ansi>.`, rest))
+
+	case `describe`:
+
+		rest = cleanHouseText(rest)
+
+		if rest == `` {
+			user.SendText(`Describe your house. For example: <ansi fg="command">house describe A warm fire crackles in the hearth.</ansi>`)
+			return true, nil
+		}
+
+		if len(rest) > 1000 {
+			user.SendText(`That description is too long. Keep it under 1000 letters.`)
+			return true, nil
+		}
+
+		houseRoom.Description = rest
+		user.SendText(`You rearrange your house to match your new description.`)
+
+	case `sign`:
+
+		rest = cleanHouseText(rest)
+
+		if rest == `` {
+			user.SendText(`What should the sign say? Use <ansi fg="command">house sign clear</ansi> to take it down.`)
+			return true, nil
+		}
+
+		if rest == `clear` {
+			signs := []rooms.Sign{}
+			for _, sign := range houseRoom.Signs {
+				if sign.VisibleUserId != 0 {
+					signs = append(signs, sign)
+				}
+			}
+			houseRoom.Signs = signs
+			user.SendText(`You take down the sign.`)
+			break
+		}
+
+		// Signs in a house last until they're taken down
+		houseRoom.AddSign(rest, 0, 3650)
+		user.SendText(`You hang up a sign.`)
+		room.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> hangs up a sign.`, user.Character.Name), user.UserId)
+
+	case `decorate`:
+
+		if rest == `` {
+			user.SendText(`Put what on display?`)
+			return true, nil
+		}
+
+		if len(h.Decorations) >= int(c.HouseMaxDecorations) {
+			user.SendText(fmt.Sprintf(`You can only have %d decorations on display.`, c.HouseMaxDecorations))
+			return true, nil
+		}
+
+		itm, found := user.Character.FindInBackpack(rest)
+		if !found {
+			user.SendText(fmt.Sprintf(`You don't have a "%s".`, rest))
+			return true, nil
+		}
+
+		user.Character.RemoveItem(itm)
+		h.Decorations = append(h.Decorations, itm)
+
+		user.SendText(fmt.Sprintf(`You put the <ansi fg="itemname">%s</ansi> on display.`, itm.DisplayName()))
+		room.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> puts a <ansi fg="itemname">%s</ansi> on display.`, user.Character.Name, itm.DisplayName()), user.UserId)
+
+	case `undecorate`:
+
+		if rest == `` {
+			user.SendText(`Take what off display?`)
+			return true, nil
+		}
+
+		itm, found := h.RemoveDecoration(rest)
+		if !found {
+			user.SendText(fmt.Sprintf(`There is no "%s" on display.`, rest))
+			return true, nil
+		}
+
+		if !user.Character.StoreItem(itm) {
+			h.Decorations = append(h.Decorations, itm)
+			user.SendText(`You can't carry any more.`)
+			return true, nil
+		}
+
+		user.SendText(fmt.Sprintf(`You take the <ansi fg="itemname">%s</ansi> off display.`, itm.DisplayName()))
+		room.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> takes the <ansi fg="itemname">%s</ansi> off display.`, user.Character.Name, itm.DisplayName()), user.UserId)
+
+	default:
+		user.SendText(`Try <ansi fg="command">help house</ansi> for more information about your house.`)
+		return true, nil
+	}
+
+	rooms.SaveRoom(*houseRoom)
+
+	return true, nil
+}
+
+// Players don't get to put colors or control codes into room text
+func cleanHouseText(text string) string {
+	text = ansitags.Parse(text, ansitags.StripTags)
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, text)
+	return strings.TrimSpace(text)
+}
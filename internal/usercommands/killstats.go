@@ -61,6 +61,10 @@ func Killstats(rest string, user *users.UserRecord, room *rooms.Room) (bool, err
 		totalPVPKills++
 	}
 
+	if rest == `duel` || rest == `duels` {
+		return killstatsDuels(user)
+	}
+
 	renderStats := mobKills
 	totalKills := totalMobKills
 	totalDeaths := user.Character.KD.GetMobDeaths()
@@ -98,6 +102,8 @@ func Killstats(rest string, user *users.UserRecord, room *rooms.Room) (bool, err
 		otherSuggestions = append(otherSuggestions, `<ansi fg="command">killstats race</ansi>`)
 	}
 
+	otherSuggestions = append(otherSuggestions, `<ansi fg="command">killstats duels</ansi>`)
+
 	headers = []string{strings.Title(rest), `Quantity`, `%`}
 
 	for name, killCt := range renderStats {
@@ -144,3 +150,64 @@ func Killstats(rest string, user *users.UserRecord, room *rooms.Room) (bool, err
 
 	return true, nil
 }
+
+// Duel wins and losses against each opponent
+func killstatsDuels(user *users.UserRecord) (bool, error) {
+
+	kd := user.Character.KD
+
+	opponents := map[string][2]int{}
+
+	for userIdNameStr, ct := range kd.DuelWins {
+		parts := strings.Split(userIdNameStr, `:`)
+		record := opponents[parts[1]]
+		record[0] += ct
+		opponents[parts[1]] = record
+	}
+
+	for userIdNameStr, ct := range kd.DuelLosses {
+		parts := strings.Split(userIdNameStr, `:`)
+		record := opponents[parts[1]]
+		record[1] += ct
+		opponents[parts[1]] = record
+	}
+
+	headers := []string{`Opponent`, `Won`, `Lost`}
+
+	formatting := []string{
+		`<ansi fg="username">%s</ansi>`,
+		`<ansi fg="green">%s</ansi>`,
+		`<ansi fg="red">%s</ansi>`,
+	}
+
+	rows := [][]string{}
+	for name, record := range opponents {
+		rows = append(rows, []string{
+			name,
+			fmt.Sprintf("%d", record[0]),
+			fmt.Sprintf("%d", record[1]),
+		})
+	}
+
+	rows = append(rows, []string{
+		``,
+		``,
+		``,
+	})
+
+	rows = append(rows, []string{
+		`Total`,
+		fmt.Sprintf("%d", kd.TotalDuelWins),
+		fmt.Sprintf("%d", kd.TotalDuelLosses),
+	})
+
+	searchResultsTable := templates.GetTable(`Duel Stats`, headers, rows, formatting)
+	tplTxt, _ := templates.Process("tables/generic", searchResultsTable)
+	tplTxt += fmt.Sprintf("Also try: %s\n", strings.Join([]string{
+		`<ansi fg="command">killstats</ansi>`,
+		`<ansi fg="command">killstats pvp</ansi>`,
+	}, `, `))
+	user.SendText(tplTxt)
+
+	return true, nil
+}
@@ -26,6 +26,11 @@ func Leaderboards(rest string, user *users.UserRecord, room *rooms.Room) (bool,
 		valueName := strings.Title(lbName)
 		title := fmt.Sprintf(`%s Leaderboard`, valueName)
 
+		if lbName == "arena" {
+			valueName = `Rating`
+			title = fmt.Sprintf(`Arena Season %d Leaderboard`, configs.GetConfig().ArenaSeason)
+		}
+
 		headers := []string{`Rank`, `Character`, `Profession`, `Level`, valueName}
 
 		rows := [][]string{}
@@ -43,6 +48,8 @@ func Leaderboards(rest string, user *users.UserRecord, room *rooms.Room) (bool,
 			formatting = append(formatting, `<ansi fg="gold">%s</ansi>`)
 		} else if lbName == "kills" {
 			formatting = append(formatting, `<ansi fg="red">%s</ansi>`)
+		} else if lbName == "arena" {
+			formatting = append(formatting, `<ansi fg="yellow-bold">%s</ansi>`)
 		}
 
 		for i, entry := range entries {
@@ -59,6 +66,8 @@ func Leaderboards(rest string, user *users.UserRecord, room *rooms.Room) (bool,
 				newRow = append(newRow, strconv.Itoa(entry.Gold))
 			} else if lbName == "kills" {
 				newRow = append(newRow, strconv.Itoa(entry.Kills))
+			} else if lbName == "arena" {
+				newRow = append(newRow, strconv.Itoa(entry.ArenaRating))
 			}
 
 			rows = append(rows, newRow)
@@ -77,6 +77,11 @@ func Lock(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 			return true, nil
 		}
 
+		if exitInfo.HasDoor() && !exitInfo.IsClosed() {
+			user.SendText(fmt.Sprintf(`You'll need to close the %s first.`, exitInfo.Door.GetName()))
+			return true, nil
+		}
+
 		lockId := fmt.Sprintf(`%d-%s`, room.RoomId, exitName)
 		hasKey, _ := user.Character.HasKey(lockId, int(exitInfo.Lock.Difficulty))
 
@@ -208,6 +208,11 @@ func Look(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 			return true, nil
 		}
 
+		if exitInfo.IsClosed() {
+			user.SendText(fmt.Sprintf("The %s to the %s is closed.", exitInfo.Door.GetName(), exitName))
+			return true, nil
+		}
+
 		user.SendText(fmt.Sprintf("You peer toward the %s.", exitName))
 		if !isSneaking {
 			room.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> peers toward the %s.`, user.Character.Name, exitName), user.UserId)
@@ -400,6 +405,10 @@ func lookRoom(user *users.UserRecord, roomId int, secretLook bool) {
 			}
 		}
 
+		if container.Corpse != nil && container.Corpse.Bones {
+			chestName += ` <ansi fg="white">(bones)</ansi>`
+		}
+
 		groundStuff = append(groundStuff, chestName)
 
 	}
@@ -0,0 +1,270 @@
+package usercommands
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/volte6/gomud/internal/buffs"
+	"github.com/volte6/gomud/internal/statmods"
+	"github.com/volte6/gomud/internal/templates"
+	"github.com/volte6/gomud/internal/users"
+)
+
+//
+// Shared menu loop for the online editors (medit, iedit, bedit, qedit)
+//
+// The copy being edited is kept in the users temp data until it is saved or discarded.
+// Each pass through the menu asks which field to edit, then asks for the new value.
+// Changes only touch the copy until "save" is chosen.
+//
+
+const (
+	olcNone             = `none` // Entered to clear a field
+	olcMaxDisplayLength = 60
+)
+
+type olcField struct {
+	Name   string
+	Format string // Hint for how the value should be entered
+	Get    func() string
+	Set    func(value string) error
+}
+
+type olcEditor struct {
+	Command string
+	Rest    string
+	Title   string
+	Fields  []olcField
+	Preview func() string // Optional: how the thing looks in game
+	Save    func() error
+	TempKey string // Temp data key holding the copy being edited
+}
+
+// buildEditor is called on every pass through the menu, since some fields depend on others (quest steps etc.)
+func olcMenu(user *users.UserRecord, buildEditor func() olcEditor) (bool, error) {
+
+	editor := buildEditor()
+
+	cmdPrompt, isNew := user.StartPrompt(editor.Command, editor.Rest)
+	if isNew {
+		olcShowFields(editor, user)
+	}
+
+	menuOptions := []string{}
+	for _, field := range editor.Fields {
+		menuOptions = append(menuOptions, field.Name)
+	}
+	menuOptions = append(menuOptions, `preview`, `save`, `quit`)
+
+	question := cmdPrompt.Ask(`Edit which field?`, menuOptions)
+	if !question.Done {
+		return true, nil
+	}
+
+	switch question.Response {
+
+	case `quit`:
+		user.ClearPrompt()
+		user.SetTempData(editor.TempKey, nil)
+		user.SendText(`Changes discarded.`)
+		return true, nil
+
+	case `preview`:
+		olcShowFields(editor, user)
+		if editor.Preview != nil {
+			user.SendText(editor.Preview())
+		}
+		question.RejectResponse()
+		return true, nil
+
+	case `save`:
+		if err := editor.Save(); err != nil {
+			user.SendText(fmt.Sprintf(`<ansi fg="red">Could not save: %s</ansi>`, err.Error()))
+			question.RejectResponse()
+			return true, nil
+		}
+		user.ClearPrompt()
+		user.SetTempData(editor.TempKey, nil)
+		user.SendText(fmt.Sprintf(`<ansi fg="alert-3">%s saved.</ansi>`, editor.Title))
+		return true, nil
+	}
+
+	var field olcField
+	for _, f := range editor.Fields {
+		if f.Name == question.Response {
+			field = f
+			break
+		}
+	}
+
+	fieldQuestion := cmdPrompt.Ask(fmt.Sprintf(`New %s?`, field.Name), []string{}, field.Get())
+	if !fieldQuestion.Done {
+		user.SendText(fmt.Sprintf(`Current %s: <ansi fg="yellow">%s</ansi>`, field.Name, field.Get()))
+		if field.Format != `` {
+			user.SendText(fmt.Sprintf(`<ansi fg="black-bold">Format: %s (Enter keeps the current value, "%s" clears it)</ansi>`, field.Format, olcNone))
+		}
+		return true, nil
+	}
+
+	newValue := fieldQuestion.Response
+	if strings.ToLower(newValue) == olcNone {
+		newValue = ``
+	}
+
+	if err := field.Set(newValue); err != nil {
+		user.SendText(fmt.Sprintf(`<ansi fg="red">%s</ansi>`, err.Error()))
+		fieldQuestion.RejectResponse()
+		return true, nil
+	}
+
+	user.SendText(fmt.Sprintf(`%s is now: <ansi fg="yellow">%s</ansi>`, field.Name, field.Get()))
+
+	// Start the menu over with a fresh prompt
+	user.ClearPrompt()
+
+	return olcMenu(user, buildEditor)
+}
+
+func olcShowFields(editor olcEditor, user *users.UserRecord) {
+
+	rows := [][]string{}
+	for _, field := range editor.Fields {
+		value := field.Get()
+		if len(value) > olcMaxDisplayLength {
+			value = value[:olcMaxDisplayLength-3] + `...`
+		}
+		rows = append(rows, []string{field.Name, value})
+	}
+
+	fieldTable := templates.GetTable(editor.Title, []string{`Field`, `Value`}, rows)
+	tplTxt, _ := templates.Process("tables/generic", fieldTable)
+	user.SendText(tplTxt)
+}
+
+//
+// Parsing and formatting helpers for editor fields
+//
+
+func olcParseInt(value string, minValue int, maxValue int) (int, error) {
+	if value == `` {
+		value = `0`
+	}
+	num, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf(`"%s" is not a number`, value)
+	}
+	if num < minValue || num > maxValue {
+		return 0, fmt.Errorf(`%d must be between %d and %d`, num, minValue, maxValue)
+	}
+	return num, nil
+}
+
+func olcParseFloat(value string, minValue float64, maxValue float64) (float64, error) {
+	if value == `` {
+		value = `0`
+	}
+	num, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf(`"%s" is not a number`, value)
+	}
+	if num < minValue || num > maxValue {
+		return 0, fmt.Errorf(`%g must be between %g and %g`, num, minValue, maxValue)
+	}
+	return num, nil
+}
+
+func olcParseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case `y`, `yes`, `true`, `on`, `1`:
+		return true, nil
+	case ``, `n`, `no`, `false`, `off`, `0`:
+		return false, nil
+	}
+	return false, fmt.Errorf(`"%s" should be yes or no`, value)
+}
+
+// Splits a list on a separator, dropping any empty entries
+func olcParseList(value string, separator string) []string {
+	list := []string{}
+	for _, entry := range strings.Split(value, separator) {
+		if entry = strings.TrimSpace(entry); entry != `` {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+// Parses a comma separated list of buffIds, making sure they all exist
+func olcParseBuffIds(value string) ([]int, error) {
+	buffIds := []int{}
+	for _, entry := range olcParseList(value, `,`) {
+		buffId, err := strconv.Atoi(entry)
+		if err != nil {
+			return nil, fmt.Errorf(`"%s" is not a buffId`, entry)
+		}
+		if buffs.GetBuffSpec(buffId) == nil {
+			return nil, fmt.Errorf(`buffId %d does not exist`, buffId)
+		}
+		buffIds = append(buffIds, buffId)
+	}
+	return buffIds, nil
+}
+
+func olcFormatIntList(list []int) string {
+	strList := []string{}
+	for _, num := range list {
+		strList = append(strList, strconv.Itoa(num))
+	}
+	return strings.Join(strList, `, `)
+}
+
+// Parses statmods in the form "strength:2, speed:-1"
+func olcParseStatMods(value string) (statmods.StatMods, error) {
+	mods := statmods.StatMods{}
+	for _, entry := range olcParseList(value, `,`) {
+		parts := strings.Split(entry, `:`)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf(`"%s" should look like name:value`, entry)
+		}
+		modValue, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf(`"%s" is not a number`, parts[1])
+		}
+		// Statmods aren't a fixed list (skills, spell schools etc.), but names are always a single word
+		modName := strings.ToLower(strings.TrimSpace(parts[0]))
+		if modName == `` || strings.Contains(modName, ` `) {
+			return nil, fmt.Errorf(`"%s" is not a valid statmod name`, parts[0])
+		}
+		mods[modName] = modValue
+	}
+	if len(mods) == 0 {
+		return nil, nil
+	}
+	return mods, nil
+}
+
+func olcFormatStatMods(mods statmods.StatMods) string {
+	modNames := []string{}
+	for name := range mods {
+		modNames = append(modNames, name)
+	}
+	sort.Strings(modNames)
+
+	strList := []string{}
+	for _, name := range modNames {
+		strList = append(strList, fmt.Sprintf(`%s:%d`, name, mods[name]))
+	}
+	return strings.Join(strList, `, `)
+}
+
+// Returns an error if a value isn't one of the allowed options
+func olcCheckOption(value string, options []string) error {
+	for _, option := range options {
+		if value == option {
+			return nil
+		}
+	}
+	return fmt.Errorf(`"%s" should be one of: %s`, value, strings.Join(options, `, `))
+}
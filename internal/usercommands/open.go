@@ -0,0 +1,82 @@
+package usercommands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/volte6/gomud/internal/keywords"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/scripting"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+func Open(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
+
+	args := util.SplitButRespectQuotes(strings.ToLower(rest))
+
+	if len(args) < 1 {
+		user.SendText("Open what?")
+		return true, nil
+	}
+
+	// Opening a container just means unlocking it
+	if containerName := room.FindContainerByName(args[0]); containerName != `` {
+		return Unlock(rest, user, room)
+	}
+
+	exitName, _ := room.FindExitByName(args[0])
+
+	// If nothing found, consider directional aliases
+	if exitName == `` {
+		if alias := keywords.TryDirectionAlias(args[0]); alias != args[0] {
+			exitName, _ = room.FindExitByName(alias)
+		}
+	}
+
+	exitInfo, ok := room.Exits[exitName]
+	if !ok {
+		user.SendText("There is no such exit.")
+		return true, nil
+	}
+
+	if !exitInfo.HasDoor() {
+		if exitInfo.HasLock() {
+			return Unlock(rest, user, room)
+		}
+		user.SendText(fmt.Sprintf(`There's nothing to open to the <ansi fg="exit">%s</ansi>.`, exitName))
+		return true, nil
+	}
+
+	doorName := exitInfo.Door.GetName()
+
+	if !exitInfo.IsClosed() {
+		user.SendText(fmt.Sprintf(`The %s to the <ansi fg="exit">%s</ansi> is already open.`, doorName, exitName))
+		return true, nil
+	}
+
+	// Try to unlock it first
+	if exitInfo.Lock.IsLocked() {
+		Unlock(rest, user, room)
+		if exitInfo = room.Exits[exitName]; exitInfo.Lock.IsLocked() {
+			return true, nil
+		}
+	}
+
+	if handled, err := scripting.TryRoomDoorEvent(`onDoorOpen`, exitName, room.RoomId, user.UserId, 0); err == nil && handled {
+		return true, nil
+	}
+
+	otherRoom, otherExitName := room.GetDoorReturnExit(exitName)
+
+	room.SetExitOpen(exitName, true)
+
+	user.SendText(fmt.Sprintf(`You open the %s to the <ansi fg="exit">%s</ansi>.`, doorName, exitName))
+	room.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> opens the %s to the <ansi fg="exit">%s</ansi>.`, user.Character.Name, doorName, exitName), user.UserId)
+
+	if otherRoom != nil && otherExitName != `` {
+		otherRoom.SendText(fmt.Sprintf(`The %s to the <ansi fg="exit">%s</ansi> opens.`, doorName, otherExitName))
+	}
+
+	return true, nil
+}
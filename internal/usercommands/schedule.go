@@ -0,0 +1,47 @@
+package usercommands
+
+import (
+	"fmt"
+
+	"github.com/volte6/gomud/internal/items"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/transports"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+func Schedule(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
+
+	allTransports := transports.GetTransportsForRoom(room.RoomId)
+
+	if len(allTransports) == 0 {
+		user.SendText(`No transports stop here.`)
+		return true, nil
+	}
+
+	roundNow := util.GetRoundCount()
+
+	for _, t := range allTransports {
+
+		pos := t.GetPosition(roundNow)
+
+		if pos.Docked {
+			user.SendText(fmt.Sprintf(`<ansi fg="yellow">%s</ansi> is docked at %s. It departs for %s at %s.`, t.Name, pos.Stop.Name, pos.NextStop.Name, pos.NextChangeStr))
+		} else {
+			user.SendText(fmt.Sprintf(`<ansi fg="yellow">%s</ansi> is on its way to %s, arriving at %s.`, t.Name, pos.Stop.Name, pos.NextChangeStr))
+		}
+
+		if t.TicketItemId > 0 {
+			ticket := items.New(t.TicketItemId)
+			if t.Fare > 0 {
+				user.SendText(fmt.Sprintf(`  Passengers need a <ansi fg="itemname">%s</ansi> or <ansi fg="gold">%d gold</ansi> to ride.`, ticket.DisplayName(), t.Fare))
+			} else {
+				user.SendText(fmt.Sprintf(`  Passengers need a <ansi fg="itemname">%s</ansi> to ride.`, ticket.DisplayName()))
+			}
+		} else if t.Fare > 0 {
+			user.SendText(fmt.Sprintf(`  The fare is <ansi fg="gold">%d gold</ansi>.`, t.Fare))
+		}
+	}
+
+	return true, nil
+}
@@ -47,6 +47,11 @@ func Shoot(rest string, user *users.UserRecord, room *rooms.Room) (bool, error)
 			return true, nil
 		}
 
+		if exitInfo.IsClosed() {
+			user.SendText(fmt.Sprintf("The %s to the %s is closed.", exitInfo.Door.GetName(), exitName))
+			return true, nil
+		}
+
 		if adjacentRoom := rooms.LoadRoom(attackRoomId); adjacentRoom != nil {
 			attackPlayerId, attackMobInstanceId = adjacentRoom.FindByName(strings.Join(args, ` `))
 		}
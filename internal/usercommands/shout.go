@@ -32,35 +32,18 @@ func Shout(rest string, user *users.UserRecord, room *rooms.Room) (bool, error)
 		room.SendTextCommunication(fmt.Sprintf(`<ansi fg="username">%s</ansi> shouts, "<ansi fg="yellow">%s</ansi>"`, user.Character.Name, rest), user.UserId)
 	}
 
-	for _, roomInfo := range room.Exits {
-		if otherRoom := rooms.LoadRoom(roomInfo.RoomId); otherRoom != nil {
-			if sourceExit := otherRoom.FindExitTo(room.RoomId); sourceExit != `` {
-				otherRoom.SendTextCommunication(fmt.Sprintf(`Someone shouts from the <ansi fg="exit">%s</ansi> direction, "<ansi fg="yellow">%s</ansi>"`, sourceExit, rest), user.UserId)
-			}
-		}
+	// The rooms next door can make out the words, further away it's just shouting
+	shoutSound := rooms.Sound{
+		Description:     `someone shouting`,
+		Volume:          3,
+		NearText:        `Someone shouts from the <ansi fg="exit">%s</ansi> direction, "<ansi fg="yellow">` + strings.ReplaceAll(rest, `%`, `%%`) + `</ansi>"`,
+		NearTextNoExit:  `Someone shouts from nearby, "<ansi fg="yellow">` + rest + `</ansi>"`,
+		IsCommunication: true,
 	}
-
-	for _, roomInfo := range room.ExitsTemp {
-		if otherRoom := rooms.LoadRoom(roomInfo.RoomId); otherRoom != nil {
-			if sourceExit := otherRoom.FindExitTo(room.RoomId); sourceExit != `` {
-				otherRoom.SendTextCommunication(fmt.Sprintf(`Someone shouts from the <ansi fg="exit">%s</ansi> direction, "<ansi fg="yellow">%s</ansi>"`, sourceExit, rest), user.UserId)
-			}
-		}
-	}
-
-	for mut := range room.ActiveMutators {
-		spec := mut.GetSpec()
-		if len(spec.Exits) == 0 {
-			continue
-		}
-		for _, exitInfo := range spec.Exits {
-			if otherRoom := rooms.LoadRoom(exitInfo.RoomId); otherRoom != nil {
-				if sourceExit := otherRoom.FindExitTo(room.RoomId); sourceExit != `` {
-					otherRoom.SendTextCommunication(fmt.Sprintf(`Someone shouts from the <ansi fg="exit">%s</ansi> direction, "<ansi fg="yellow">%s</ansi>"`, sourceExit, rest), user.UserId)
-				}
-			}
-		}
+	if isSneaking {
+		shoutSound = shoutSound.Sneaking()
 	}
+	room.PropagateSound(shoutSound, user.UserId)
 
 	user.SendText(fmt.Sprintf(`You shout, "<ansi fg="yellow">%s</ansi>"`, rest))
 
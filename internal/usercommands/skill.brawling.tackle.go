@@ -67,6 +67,7 @@ func Tackle(rest string, user *users.UserRecord, room *rooms.Room) (bool, error)
 					UserId:        0,
 					MobInstanceId: attackMobInstanceId,
 					BuffId:        12, // buff 12 is tackled
+					SourceUserId:  user.UserId,
 				})
 
 			} else {
@@ -118,6 +119,7 @@ func Tackle(rest string, user *users.UserRecord, room *rooms.Room) (bool, error)
 					UserId:        attackPlayerId,
 					MobInstanceId: 0,
 					BuffId:        12, // buff 12 is tackled
+					SourceUserId:  user.UserId,
 				})
 
 			} else {
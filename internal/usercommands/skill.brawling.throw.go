@@ -168,6 +168,11 @@ func Throw(rest string, user *users.UserRecord, room *rooms.Room) (bool, error)
 				return true, nil
 			}
 
+			if exitInfo.IsClosed() {
+				user.SendText(fmt.Sprintf(`The %s to the %s is closed.`, exitInfo.Door.GetName(), exitName))
+				return true, nil
+			}
+
 			user.Character.CancelBuffsWithFlag(buffs.Hidden)
 
 			throwToRoom := rooms.LoadRoom(throwRoomId)
@@ -0,0 +1,65 @@
+package usercommands
+
+import (
+	"fmt"
+
+	"github.com/volte6/gomud/internal/mobs"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/skills"
+	"github.com/volte6/gomud/internal/users"
+)
+
+/*
+Protection Skill
+Level 2 - Taunt an enemy into attacking you
+*/
+func Taunt(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
+
+	skillLevel := user.Character.GetSkillLevel(skills.Protection)
+
+	// If they don't have a skill, act like it's not a valid command
+	if skillLevel < 2 {
+		return false, nil
+	}
+
+	mobInstanceId := 0
+	if rest != `` {
+		_, mobInstanceId = room.FindByName(rest)
+	} else if user.Character.Aggro != nil {
+		mobInstanceId = user.Character.Aggro.MobInstanceId
+	}
+
+	m := mobs.GetInstance(mobInstanceId)
+	if m == nil || m.Character.RoomId != room.RoomId {
+		user.SendText(`Taunt who?`)
+		return true, nil
+	}
+
+	if m.Character.IsCharmed() {
+		user.SendText(fmt.Sprintf(`<ansi fg="mobname">%s</ansi> is on your side.`, m.Character.Name))
+		return true, nil
+	}
+
+	if m.Character.Aggro == nil {
+		user.SendText(fmt.Sprintf(`<ansi fg="mobname">%s</ansi> isn't fighting anyone.`, m.Character.Name))
+		return true, nil
+	}
+
+	if !user.Character.TryCooldown(skills.Protection.String(`taunt`), "4 rounds") {
+		user.SendText(fmt.Sprintf("You need to wait %d rounds before you can do that again!", user.Character.GetCooldown(skills.Protection.String(`taunt`))))
+		return true, nil
+	}
+
+	// The more practiced, the longer they stay angry
+	m.Character.SetTaunt(user.UserId, skillLevel)
+
+	user.SendText(fmt.Sprintf(`You taunt <ansi fg="mobname">%s</ansi>, drawing its attention to you!`, m.Character.Name))
+	room.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> taunts <ansi fg="mobname">%s</ansi>, drawing its attention!`, user.Character.Name, m.Character.Name), user.UserId)
+
+	// Stand and fight
+	if user.Character.Aggro == nil {
+		return Attack(fmt.Sprintf(`#%d`, m.InstanceId), user, room)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,77 @@
+package usercommands
+
+import (
+	"fmt"
+
+	"github.com/volte6/gomud/internal/mobs"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/skills"
+	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/util"
+)
+
+/*
+SkullDuggery Skill
+Level 2 - Feint, making an enemy lose interest in you
+*/
+func Feint(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
+
+	skillLevel := user.Character.GetSkillLevel(skills.Skulduggery)
+
+	// If they don't have a skill, act like it's not a valid command
+	if skillLevel < 2 {
+		return false, nil
+	}
+
+	mobInstanceId := 0
+	if rest != `` {
+		_, mobInstanceId = room.FindByName(rest)
+	} else if user.Character.Aggro != nil {
+		mobInstanceId = user.Character.Aggro.MobInstanceId
+	}
+
+	m := mobs.GetInstance(mobInstanceId)
+	if m == nil || m.Character.RoomId != room.RoomId {
+		user.SendText(`Feint against who?`)
+		return true, nil
+	}
+
+	if m.Character.GetThreat(user.UserId) < 1 {
+		user.SendText(fmt.Sprintf(`<ansi fg="mobname">%s</ansi> isn't paying you any attention.`, m.Character.Name))
+		return true, nil
+	}
+
+	if !user.Character.TryCooldown(skills.Skulduggery.String(`feint`), "5 rounds") {
+		user.SendText(fmt.Sprintf("You need to wait %d rounds before you can do that again!", user.Character.GetCooldown(skills.Skulduggery.String(`feint`))))
+		return true, nil
+	}
+
+	chanceIn100 := 50 + user.Character.Stats.Smarts.ValueAdj - m.Character.Stats.Perception.ValueAdj
+	if chanceIn100 < 10 {
+		chanceIn100 = 10
+	} else if chanceIn100 > 90 {
+		chanceIn100 = 90
+	}
+	roll := util.Rand(100)
+
+	util.LogRoll(`Feint`, roll, chanceIn100)
+
+	if roll >= chanceIn100 {
+		user.SendText(fmt.Sprintf(`You feint, but <ansi fg="mobname">%s</ansi> doesn't fall for it.`, m.Character.Name))
+		room.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> makes a clumsy feint at <ansi fg="mobname">%s</ansi>.`, user.Character.Name, m.Character.Name), user.UserId)
+		return true, nil
+	}
+
+	// Drop half of the threat, or three quarters with mastery
+	threat := m.Character.GetThreat(user.UserId)
+	if skillLevel >= 4 {
+		m.Character.AddThreat(user.UserId, -threat*3/4)
+	} else {
+		m.Character.AddThreat(user.UserId, -threat/2)
+	}
+
+	user.SendText(fmt.Sprintf(`You feint and slip out of the way. <ansi fg="mobname">%s</ansi> loses track of you.`, m.Character.Name))
+	room.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> feints and slips out of <ansi fg="mobname">%s</ansi>'s way.`, user.Character.Name, m.Character.Name), user.UserId)
+
+	return true, nil
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/volte6/gomud/internal/colorpatterns"
 	"github.com/volte6/gomud/internal/configs"
 	"github.com/volte6/gomud/internal/events"
+	"github.com/volte6/gomud/internal/items"
+	"github.com/volte6/gomud/internal/mobs"
 	"github.com/volte6/gomud/internal/rooms"
 	"github.com/volte6/gomud/internal/templates"
 	"github.com/volte6/gomud/internal/term"
@@ -26,6 +28,16 @@ func Suicide(rest string, user *users.UserRecord, room *rooms.Room) (bool, error
 		return true, errors.New(`already dead`)
 	}
 
+	// Nobody dies in the arena, they just lose the match
+	if rooms.ArenaDefeat(user.UserId) {
+		return true, nil
+	}
+
+	// Same goes for duels
+	if rooms.DuelDefeat(user.UserId) {
+		return true, nil
+	}
+
 	if user.Character.HasBuffFlag(buffs.ReviveOnDeath) {
 
 		user.Character.Health = user.Character.HealthMax.Value
@@ -83,6 +95,15 @@ func Suicide(rest string, user *users.UserRecord, room *rooms.Room) (bool, error
 		Text: msg,
 	})
 
+	// Whatever is dropped stays with the corpse
+	corpse := rooms.Corpse{
+		UserId: user.UserId,
+		Name:   user.Character.Name,
+		Level:  user.Character.Level,
+		Pvp:    dmgCt > 0,
+	}
+	corpseItems := []items.Item{}
+
 	// If permadeath is enabled, do some extra bookkeeping
 	if config.PermaDeath {
 
@@ -98,12 +119,22 @@ func Suicide(rest string, user *users.UserRecord, room *rooms.Room) (bool, error
 			textOut, _ := templates.Process("character/permadeath", nil)
 			user.SendText(colorpatterns.ApplyColorPattern(textOut, `red`))
 
-			// Unequip everything
+			// Everything goes into the corpse
 			for _, itm := range user.Character.GetAllWornItems() {
-				Remove(itm.Name(), user, room)
+				if user.Character.RemoveFromBody(itm) {
+					corpseItems = append(corpseItems, itm)
+				}
+			}
+			for _, itm := range user.Character.GetAllBackpackItems() {
+				if user.Character.RemoveItem(itm) {
+					corpseItems = append(corpseItems, itm)
+				}
+			}
+
+			if len(corpseItems) > 0 || user.Character.Gold > 0 {
+				room.SpawnCorpse(corpse, user.Character.Gold, corpseItems)
+				user.Character.Gold = 0
 			}
-			// drop all items / gold
-			Drop("all", user, room)
 
 			rooms.MoveToRoom(user.UserId, -1)
 
@@ -121,21 +152,26 @@ func Suicide(rest string, user *users.UserRecord, room *rooms.Room) (bool, error
 		for _, itm := range user.Character.GetAllWornItems() {
 			if util.Rand(100) < chanceInt {
 
-				Remove(itm.Name(), user, room)
-
-				Drop(itm.Name(), user, room)
+				if user.Character.RemoveFromBody(itm) {
+					corpseItems = append(corpseItems, itm)
+				}
 
 			}
 		}
 	}
 
-	if user.Character.Gold > 0 {
-		user.EventLog.Add(`death`, fmt.Sprintf(`Dropped <ansi fg="gold">%d gold</ansi> on death`, user.Character.Gold))
-		Drop(fmt.Sprintf(`%d gold`, user.Character.Gold), user, room)
+	corpseGold := user.Character.Gold
+	if corpseGold > 0 {
+		user.EventLog.Add(`death`, fmt.Sprintf(`Dropped <ansi fg="gold">%d gold</ansi> on death`, corpseGold))
+		user.Character.Gold = 0
 	}
 
 	if config.OnDeathAlwaysDropBackpack {
-		Drop("all", user, room)
+		for _, itm := range user.Character.GetAllBackpackItems() {
+			if user.Character.RemoveItem(itm) {
+				corpseItems = append(corpseItems, itm)
+			}
+		}
 
 		user.EventLog.Add(`death`, `Dropped <ansi fg="alert-3">everthing in your backpack</ansi> on death`)
 
@@ -143,12 +179,21 @@ func Suicide(rest string, user *users.UserRecord, room *rooms.Room) (bool, error
 		chanceInt := int(config.OnDeathEquipmentDropChance * 100)
 		for _, itm := range user.Character.GetAllBackpackItems() {
 			if util.Rand(100) < chanceInt {
-				Drop(itm.Name(), user, room)
-				user.EventLog.Add(`death`, fmt.Sprintf(`Dropped your <ansi fg="itemname">%s</ansi> on death`, itm.Name()))
+				if user.Character.RemoveItem(itm) {
+					corpseItems = append(corpseItems, itm)
+					user.EventLog.Add(`death`, fmt.Sprintf(`Dropped your <ansi fg="itemname">%s</ansi> on death`, itm.Name()))
+				}
 			}
 		}
 	}
 
+	user.Character.Validate()
+
+	if len(corpseItems) > 0 || corpseGold > 0 {
+		corpseName := room.SpawnCorpse(corpse, corpseGold, corpseItems)
+		user.SendText(fmt.Sprintf(`Anything you dropped was left with the <ansi fg="container">%s</ansi>.`, corpseName))
+	}
+
 	if user.Character.Level > 1 {
 
 		setting, lossPct := config.GetDeathXPPenalty()
@@ -183,6 +228,8 @@ func Suicide(rest string, user *users.UserRecord, room *rooms.Room) (bool, error
 
 	clear(user.Character.PlayerDamage)
 
+	mobs.ClearThreat(user.UserId)
+
 	rooms.MoveToRoom(user.UserId, 75)
 
 	return true, nil
@@ -2,10 +2,12 @@ package usercommands
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/volte6/gomud/internal/gametime"
 	"github.com/volte6/gomud/internal/rooms"
 	"github.com/volte6/gomud/internal/users"
+	"github.com/volte6/gomud/internal/weather"
 )
 
 func Time(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
@@ -31,5 +33,12 @@ func Time(rest string, user *users.UserRecord, room *rooms.Room) (bool, error) {
 		gametime.GetZodiac(gd.Year),
 	))
 
+	weatherTxt := `You are sheltered from the weather here.`
+	if wInfo, ok := weather.GetConditionInfo(room.GetWeather()); ok {
+		weatherTxt = fmt.Sprintf(`The weather here is <ansi fg="230">%s %s</ansi>.`, wInfo.Symbol, strings.ToLower(wInfo.Name))
+	}
+
+	user.SendText(fmt.Sprintf(`It is <ansi fg="230">%s</ansi>, and the moon is <ansi fg="230">%s</ansi>. %s`, weather.GetSeason(gd.Month), gd.MoonPhase(), weatherTxt))
+
 	return true, nil
 }
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/volte6/gomud/internal/audit"
 	"github.com/volte6/gomud/internal/buffs"
 	"github.com/volte6/gomud/internal/keywords"
 	"github.com/volte6/gomud/internal/rooms"
@@ -32,12 +33,15 @@ var (
 		`alias`:       {Alias, true, false},
 		`appraise`:    {Appraise, false, false},
 		`ask`:         {Ask, false, false},
+		`arena`:       {Arena, true, false},
 		`attack`:      {Attack, false, false},
 		`auction`:     {Auction, true, false},
 		`backstab`:    {Backstab, false, false},
 		`badcommands`: {BadCommands, true, true}, // Admin only
+		`bedit`:       {Bedit, true, true},       // Admin only
 		`biome`:       {Biome, true, false},
 		`broadcast`:   {Broadcast, true, false},
+		`calendar`:    {Calendar, true, false},
 		`character`:   {Character, true, false},
 		`tackle`:      {Tackle, false, false},
 		`bank`:        {Bank, false, false},
@@ -48,21 +52,25 @@ var (
 		`bump`:        {Bump, false, false},
 		`buy`:         {Buy, false, false},
 		`cast`:        {Cast, false, false},
+		`close`:       {Close, false, false},
 		`cooldowns`:   {Cooldowns, true, false},
 		`command`:     {Command, false, true}, // Admin only
 		`conditions`:  {Conditions, true, false},
 		`consider`:    {Consider, true, false},
-		`deafen`:      {Deafen, true, true}, // Admin only
+		`corpses`:     {Corpses, true, true}, // Admin only
+		`deafen`:      {Deafen, true, true},  // Admin only
 		`default`:     {Default, false, false},
 		`disarm`:      {Disarm, false, false},
 		`drop`:        {Drop, true, false},
 		`drink`:       {Drink, false, false},
+		`duel`:        {Duel, true, false},
 		`eat`:         {Eat, false, false},
 		`emote`:       {Emote, true, false},
 		`enchant`:     {Enchant, false, false},
 		`exits`:       {Exits, true, false},
 		`experience`:  {Experience, true, false},
 		`equip`:       {Equip, false, false},
+		`feint`:       {Feint, false, false},
 		`flee`:        {Flee, false, false},
 		`follow`:      {Follow, false, false},
 		`gearup`:      {Gearup, false, false},
@@ -74,7 +82,10 @@ var (
 		`keyring`:     {KeyRing, true, false},
 		`killstats`:   {Killstats, true, false},
 		`history`:     {History, true, false},
+		`house`:       {House, true, false},
+		`housing`:     {Housing, true, true}, // Admin only
 		`inbox`:       {Inbox, true, false},
+		`iedit`:       {Iedit, true, true}, // Admin only
 		`inspect`:     {Inspect, false, false},
 		`inventory`:   {Inventory, true, false},
 		`jobs`:        {Jobs, true, false},
@@ -84,12 +95,14 @@ var (
 		`lock`:        {Lock, false, false},
 		`look`:        {Look, true, false},
 		`map`:         {Map, false, false},
+		`medit`:       {Medit, true, true},   // Admin only
 		`mudmail`:     {Mudmail, true, true}, // Admin only
 		`macros`:      {Macros, true, false},
 		`modify`:      {Modify, true, true}, // Admin only
 		`motd`:        {Motd, true, false},
 		`mute`:        {Mute, true, true},
 		`offer`:       {Offer, false, false},
+		`open`:        {Open, false, false},
 		`online`:      {Online, true, false},
 		`party`:       {Party, true, false},
 		`password`:    {Password, true, false},
@@ -108,6 +121,7 @@ var (
 		`quests`:      {Quests, true, false},
 		`quit`:        {Quit, true, false},
 		`questtoken`:  {QuestToken, false, true}, // Admin only
+		`qedit`:       {Qedit, true, true},       // Admin only
 		`rank`:        {Rank, false, false},
 		`read`:        {Read, false, false},
 		`recover`:     {Recover, false, false},
@@ -118,6 +132,7 @@ var (
 		`room`:        {Room, false, true},       // Admin only
 		`save`:        {Save, true, false},
 		`say`:         {Say, true, false},
+		`schedule`:    {Schedule, true, false},
 		`scribe`:      {Scribe, false, false},
 		`search`:      {Search, false, false},
 		`sell`:        {Sell, false, false},
@@ -137,6 +152,7 @@ var (
 		`storage`:     {Storage, false, false},
 		`suicide`:     {Suicide, true, false},
 		`tame`:        {Tame, false, false},
+		`taunt`:       {Taunt, false, false},
 		`time`:        {Time, true, false},
 		`throw`:       {Throw, false, false},
 		`track`:       {Track, false, false},
@@ -146,6 +162,7 @@ var (
 		`uncurse`:     {Uncurse, false, false},
 		`unlock`:      {Unlock, false, false},
 		`undeafen`:    {UnDeafen, true, true}, // Admin only
+		`undo`:        {Undo, true, true},     // Admin only
 		`unmute`:      {UnMute, true, true},   // Admin only
 		`use`:         {Use, false, false},
 		`dual-wield`:  {DualWield, true, false},
@@ -317,6 +334,12 @@ func TryCommand(cmd string, rest string, userId int) (bool, error) {
 				util.TrackTime(`usr-cmd[`+cmd+`]`, time.Since(start).Seconds())
 			}()
 
+			// Anything saved by an admin command is journaled under that builder
+			if cmdInfo.AdminOnly {
+				audit.Begin(user.UserId, user.Username, strings.TrimSpace(cmd+` `+rest))
+				defer audit.End()
+			}
+
 			// Run the command here
 			handled, err := cmdInfo.Func(rest, user, room)
 			return handled, err
@@ -6,10 +6,10 @@ func GetMemoryUsage() map[string]util.MemoryResult {
 
 	ret := map[string]util.MemoryResult{}
 
-	ret["Users"] = util.MemoryResult{util.MemoryUsage(userManager.Users), len(userManager.Users)}
-	ret["Usernames"] = util.MemoryResult{util.MemoryUsage(userManager.Usernames), len(userManager.Usernames)}
-	ret["Connections"] = util.MemoryResult{util.MemoryUsage(userManager.Connections), len(userManager.Connections)}
-	ret["UserConnections"] = util.MemoryResult{util.MemoryUsage(userManager.UserConnections), len(userManager.UserConnections)}
+	ret["Users"] = util.MemoryResult{Memory: util.MemoryUsage(userManager.Users), Count: len(userManager.Users)}
+	ret["Usernames"] = util.MemoryResult{Memory: util.MemoryUsage(userManager.Usernames), Count: len(userManager.Usernames)}
+	ret["Connections"] = util.MemoryResult{Memory: util.MemoryUsage(userManager.Connections), Count: len(userManager.Connections)}
+	ret["UserConnections"] = util.MemoryResult{Memory: util.MemoryUsage(userManager.UserConnections), Count: len(userManager.UserConnections)}
 
 	return ret
 }
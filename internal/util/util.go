@@ -50,6 +50,8 @@ var (
 	colorShortTagRegex = regexp.MustCompile(`\{(\d*)(?::)?(\d*)?\}`)
 
 	mudLock = sync.RWMutex{}
+
+	randIntn = rand.Intn // Where Rand() gets its numbers. Simulations swap in a seeded source so results can be repeated.
 )
 
 // Mutex lock intended for synchronizing at a high level between
@@ -155,7 +157,17 @@ func Rand(maxInt int) int {
 	if maxInt < 1 {
 		return 0
 	}
-	return rand.Intn(maxInt)
+
+	return randIntn(maxInt)
+}
+
+// Makes Rand() (and everything built on it, such as RollDice()) draw from a source of its own.
+// Returns a func that puts the normal random numbers back.
+// This is meant for simulations and tests, and must not be used while the game is running.
+func SetRandSource(r *rand.Rand) (restore func()) {
+	oldIntn := randIntn
+	randIntn = r.Intn
+	return func() { randIntn = oldIntn }
 }
 
 func LogRoll(name string, rollResult int, targetNumber int) {
@@ -0,0 +1,324 @@
+package weather
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/volte6/gomud/internal/gametime"
+	"github.com/volte6/gomud/internal/util"
+)
+
+type Condition string
+
+const (
+	None     Condition = ``
+	Clear    Condition = `clear`
+	Rain     Condition = `rain`
+	Storm    Condition = `storm`
+	Snow     Condition = `snow`
+	Fog      Condition = `fog`
+	Heatwave Condition = `heatwave`
+)
+
+type Season string
+
+const (
+	Spring Season = `spring`
+	Summer Season = `summer`
+	Autumn Season = `autumn`
+	Winter Season = `winter`
+)
+
+// Climates describe how likely each weather condition is for a given season.
+// Biomes declare a climate. Biomes without one are sheltered from the weather.
+type Climate string
+
+const (
+	Temperate Climate = `temperate`
+	Coastal   Climate = `coastal`
+	Alpine    Climate = `alpine`
+	Marsh     Climate = `marsh`
+	Arid      Climate = `arid`
+)
+
+type ConditionInfo struct {
+	Condition     Condition
+	Name          string
+	Symbol        string
+	Description   string // Appended to room descriptions while active
+	ColorPattern  string // Color pattern applied to the description text
+	LightMod      int    // -2 to 2 (change) applied to room visibility
+	PlayerBuffIds []int  // buffId's applied to players exposed to the weather
+	ProtectedFlag string // Players with a buff carrying this flag are spared the PlayerBuffIds
+	StartText     string // Sent to exposed rooms when this weather rolls in
+	EndText       string // Sent to exposed rooms when this weather moves on
+}
+
+type ZoneWeather struct {
+	Condition       Condition
+	StartRound      uint64 // When the current weather began
+	NextChangeRound uint64 // When the weather will next roll for a change
+	announced       Condition
+}
+
+var (
+	// How long a weather pattern lasts, in game hours (min + random up to range)
+	minDurationHours   = 3
+	durationHoursRange = 8
+
+	conditions = map[Condition]ConditionInfo{
+		Clear: {
+			Condition: Clear,
+			Name:      `Clear`,
+			Symbol:    `☀`,
+		},
+		Rain: {
+			Condition:     Rain,
+			Name:          `Rain`,
+			Symbol:        `☂`,
+			Description:   `A steady rain falls, soaking everything in sight.`,
+			ColorPattern:  `mute-lblue`,
+			PlayerBuffIds: []int{40}, // Soaked
+			StartText:     `<ansi fg="117">It begins to rain.</ansi>`,
+			EndText:       `<ansi fg="117">The rain lets up.</ansi>`,
+		},
+		Storm: {
+			Condition:     Storm,
+			Name:          `Storm`,
+			Symbol:        `☈`,
+			Description:   `Thunder rolls overhead as a storm lashes the area with wind and rain.`,
+			ColorPattern:  `mute-dblue`,
+			LightMod:      -1,
+			PlayerBuffIds: []int{40}, // Soaked
+			StartText:     `<ansi fg="69">Dark clouds roll in and a storm breaks overhead!</ansi>`,
+			EndText:       `<ansi fg="69">The storm passes.</ansi>`,
+		},
+		Snow: {
+			Condition:     Snow,
+			Name:          `Snow`,
+			Symbol:        `❄`,
+			Description:   `Snow drifts down from a pale sky, blanketing the ground.`,
+			ColorPattern:  `cyan`,
+			PlayerBuffIds: []int{41}, // Chilled
+			ProtectedFlag: `warmed`,
+			StartText:     `<ansi fg="51">Snowflakes begin to fall.</ansi>`,
+			EndText:       `<ansi fg="51">The snow stops falling.</ansi>`,
+		},
+		Fog: {
+			Condition:    Fog,
+			Name:         `Fog`,
+			Symbol:       `≡`,
+			Description:  `A thick fog hangs in the air, making it hard to see very far.`,
+			ColorPattern: `gray`,
+			LightMod:     -1,
+			StartText:    `<ansi fg="248">A thick fog rolls in.</ansi>`,
+			EndText:      `<ansi fg="248">The fog lifts.</ansi>`,
+		},
+		Heatwave: {
+			Condition:     Heatwave,
+			Name:          `Heatwave`,
+			Symbol:        `♨`,
+			Description:   `The air shimmers with a stifling, oppressive heat.`,
+			ColorPattern:  `flame`,
+			PlayerBuffIds: []int{42}, // Sweltering
+			ProtectedFlag: `hydrated`,
+			StartText:     `<ansi fg="208">The air grows stiflingly hot.</ansi>`,
+			EndText:       `<ansi fg="208">The heat finally breaks.</ansi>`,
+		},
+	}
+
+	// Relative chance of each condition occurring per climate and season
+	climateWeights = map[Climate]map[Season]map[Condition]int{
+		Temperate: {
+			Spring: {Clear: 5, Rain: 4, Storm: 1, Fog: 2},
+			Summer: {Clear: 7, Rain: 2, Storm: 2, Heatwave: 2},
+			Autumn: {Clear: 4, Rain: 4, Storm: 1, Fog: 3},
+			Winter: {Clear: 4, Rain: 1, Snow: 5, Fog: 2},
+		},
+		Coastal: {
+			Spring: {Clear: 4, Rain: 4, Storm: 2, Fog: 4},
+			Summer: {Clear: 6, Rain: 2, Storm: 3, Fog: 2},
+			Autumn: {Clear: 3, Rain: 4, Storm: 3, Fog: 4},
+			Winter: {Clear: 3, Rain: 3, Storm: 3, Snow: 2, Fog: 3},
+		},
+		Alpine: {
+			Spring: {Clear: 4, Rain: 2, Snow: 4, Fog: 2},
+			Summer: {Clear: 6, Rain: 3, Storm: 2, Fog: 1},
+			Autumn: {Clear: 4, Rain: 2, Snow: 4, Fog: 2},
+			Winter: {Clear: 2, Storm: 2, Snow: 8, Fog: 2},
+		},
+		Marsh: {
+			Spring: {Clear: 2, Rain: 5, Storm: 1, Fog: 5},
+			Summer: {Clear: 4, Rain: 3, Storm: 2, Fog: 3, Heatwave: 1},
+			Autumn: {Clear: 2, Rain: 4, Storm: 1, Fog: 6},
+			Winter: {Clear: 3, Rain: 3, Snow: 1, Fog: 6},
+		},
+		Arid: {
+			Spring: {Clear: 8, Storm: 1, Heatwave: 3},
+			Summer: {Clear: 5, Storm: 1, Heatwave: 7},
+			Autumn: {Clear: 8, Storm: 1, Heatwave: 2},
+			Winter: {Clear: 9, Rain: 1, Fog: 1},
+		},
+	}
+
+	zones = map[string]*ZoneWeather{}
+)
+
+// Returns the season for a given month (1-12)
+func GetSeason(month int) Season {
+	switch (month - 1) % 12 {
+	case 2, 3, 4:
+		return Spring
+	case 5, 6, 7:
+		return Summer
+	case 8, 9, 10:
+		return Autumn
+	}
+	return Winter
+}
+
+func GetConditionInfo(c Condition) (ConditionInfo, bool) {
+	info, ok := conditions[c]
+	return info, ok
+}
+
+// Returns the names of all valid weather conditions
+func GetConditionNames() []string {
+	ret := []string{}
+	for c := range conditions {
+		ret = append(ret, string(c))
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func IsValidCondition(c Condition) bool {
+	_, ok := conditions[c]
+	return ok
+}
+
+// Gets the current weather for a zone.
+// Zones that haven't been updated yet have no weather.
+func Get(zone string) Condition {
+	if zw, ok := zones[zone]; ok {
+		return zw.Condition
+	}
+	return None
+}
+
+// Forces the weather of a zone to a specific condition.
+// If a duration is provided (such as `2 hours`), the weather
+// won't change again until it has passed.
+func Set(zone string, c Condition, duration ...string) bool {
+
+	c = Condition(strings.ToLower(string(c)))
+	if !IsValidCondition(c) {
+		return false
+	}
+
+	gd := gametime.GetDate()
+
+	// Nothing announced yet, so Update() tells everyone about it
+	zw, ok := zones[zone]
+	if !ok {
+		zw = &ZoneWeather{}
+		zones[zone] = zw
+	}
+
+	zw.Condition = c
+	zw.StartRound = gd.RoundNumber
+	if len(duration) > 0 && duration[0] != `` {
+		zw.NextChangeRound = gd.AddPeriod(duration[0])
+	} else {
+		zw.NextChangeRound = nextChangeRound(gd)
+	}
+
+	return true
+}
+
+// Rolls for a weather change in a zone if enough time has passed.
+// Returns the previous and current conditions, and whether the change
+// still needs to be announced to players.
+func Update(zone string, climate Climate, roundNumber uint64) (Condition, Condition, bool) {
+
+	gd := gametime.GetDate(roundNumber)
+
+	zw, ok := zones[zone]
+	if !ok {
+		// First look at this zone, so just quietly pick something.
+		c := roll(climate, GetSeason(gd.Month), None)
+		zw = &ZoneWeather{
+			Condition:       c,
+			StartRound:      roundNumber,
+			NextChangeRound: nextChangeRound(gd),
+			announced:       c,
+		}
+		zones[zone] = zw
+		return c, c, false
+	}
+
+	if roundNumber >= zw.NextChangeRound {
+		zw.Condition = roll(climate, GetSeason(gd.Month), zw.Condition)
+		zw.NextChangeRound = nextChangeRound(gd)
+		if zw.Condition != zw.announced {
+			zw.StartRound = roundNumber
+		}
+	}
+
+	if zw.Condition == zw.announced {
+		return zw.Condition, zw.Condition, false
+	}
+
+	before := zw.announced
+	zw.announced = zw.Condition
+
+	return before, zw.Condition, true
+}
+
+func nextChangeRound(gd gametime.GameDate) uint64 {
+	return gd.Add(minDurationHours+util.Rand(durationHoursRange), 0, 0).RoundNumber
+}
+
+// Picks a weighted random condition for the climate/season.
+// The current condition gets extra weight so weather tends to linger.
+func roll(climate Climate, season Season, current Condition) Condition {
+
+	seasons, ok := climateWeights[climate]
+	if !ok {
+		seasons = climateWeights[Temperate]
+	}
+	weights := seasons[season]
+
+	// Sort for a stable roll order
+	names := []string{}
+	for c := range weights {
+		names = append(names, string(c))
+	}
+	sort.Strings(names)
+
+	total := 0
+	for _, name := range names {
+		total += weights[Condition(name)]
+		if Condition(name) == current {
+			total += weights[Condition(name)]
+		}
+	}
+
+	if total < 1 {
+		return Clear
+	}
+
+	pick := util.Rand(total)
+	for _, name := range names {
+		w := weights[Condition(name)]
+		if Condition(name) == current {
+			w *= 2
+		}
+		if pick < w {
+			return Condition(name)
+		}
+		pick -= w
+	}
+
+	return Clear
+}
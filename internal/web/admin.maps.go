@@ -0,0 +1,65 @@
+package web
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"text/template"
+
+	"github.com/volte6/gomud/internal/configs"
+	"github.com/volte6/gomud/internal/rooms"
+)
+
+func mapsIndex(w http.ResponseWriter, r *http.Request) {
+
+	tmpl, err := template.New("index.html").Funcs(funcMap).ParseFiles("_datafiles/html/admin/_header.html", "_datafiles/html/admin/maps/index.html", "_datafiles/html/admin/_footer.html")
+	if err != nil {
+		slog.Error("HTML Template", "error", err)
+	}
+
+	allZones := []ZoneDetails{}
+	for _, zoneName := range rooms.GetAllZoneNames() {
+		allZones = append(allZones, ZoneDetails{
+			ZoneName:  zoneName,
+			RoomCount: len(rooms.GetZoneRoomIds(zoneName)),
+		})
+	}
+
+	sort.SliceStable(allZones, func(i, j int) bool {
+		return allZones[i].ZoneName < allZones[j].ZoneName
+	})
+
+	mapIndexData := struct {
+		Zones      []ZoneDetails
+		PublicMaps bool
+	}{
+		allZones,
+		bool(configs.GetConfig().PublicMaps),
+	}
+
+	if err := tmpl.Execute(w, mapIndexData); err != nil {
+		slog.Error("HTML Execute", "error", err)
+	}
+
+}
+
+// Full zone map, including secret exits and RoomIds
+func mapSVG(w http.ResponseWriter, r *http.Request) {
+	writeZoneSVG(w, r, true)
+}
+
+// The level drawn can be picked with ?z=
+func writeZoneSVG(w http.ResponseWriter, r *http.Request, isAdmin bool) {
+
+	zLevel, _ := strconv.Atoi(r.URL.Query().Get(`z`))
+
+	svg, err := rooms.GetZoneSVG(r.PathValue(`zone`), zLevel, isAdmin, isAdmin, !isAdmin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set(`Content-Type`, `image/svg+xml`)
+	w.Write([]byte(svg))
+}
@@ -58,7 +58,7 @@ func roomsIndex(w http.ResponseWriter, r *http.Request) {
 
 				if rootRoomId, err := rooms.GetZoneRoot(room.Zone); err == nil {
 					if rootRoom := rooms.LoadRoom(rootRoomId); rootRoom != nil {
-						if rootRoom.ZoneConfig.MobAutoScale.Minimum > 0 || rootRoom.ZoneConfig.MobAutoScale.Minimum > 0 {
+						if rootRoom.ZoneConfig.MobAutoScale.Minimum > 0 || rootRoom.ZoneConfig.MobAutoScale.Maximum > 0 {
 							autoScale = fmt.Sprintf(`%d to %d`, rootRoom.ZoneConfig.MobAutoScale.Minimum, rootRoom.ZoneConfig.MobAutoScale.Maximum)
 						}
 					}
@@ -0,0 +1,49 @@
+package web
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"text/template"
+
+	"github.com/volte6/gomud/internal/configs"
+	"github.com/volte6/gomud/internal/rooms"
+)
+
+// Lists the zone maps players can view, only served if PublicMaps is enabled
+func servePublicMaps(w http.ResponseWriter, r *http.Request) {
+
+	if !configs.GetConfig().PublicMaps {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Zones nobody has been to yet stay a secret
+	zoneNames := rooms.GetExploredZoneNames()
+	sort.Strings(zoneNames)
+
+	tmpl, err := template.New("maps.html").Funcs(funcMap).ParseFiles("_datafiles/html/public/maps.html")
+	if err != nil {
+		slog.Error("HTML ERROR", "error", err)
+	}
+
+	tmpl.Execute(w, zoneNames)
+}
+
+// Zone map for players, without secret exits or RoomIds.
+// Only rooms players have explored are shown.
+func servePublicMapSVG(w http.ResponseWriter, r *http.Request) {
+
+	if !configs.GetConfig().PublicMaps {
+		http.NotFound(w, r)
+		return
+	}
+
+	if !slices.Contains(rooms.GetExploredZoneNames(), r.PathValue(`zone`)) {
+		http.NotFound(w, r)
+		return
+	}
+
+	writeZoneSVG(w, r, false)
+}
@@ -3,6 +3,7 @@ package web
 import (
 	"fmt"
 	"html"
+	"net/url"
 	"strings"
 	"text/template"
 )
@@ -97,5 +98,8 @@ var (
 		"lowercase": func(str string) string {
 			return strings.ToLower(str)
 		},
+		"escapepath": func(str string) string {
+			return url.PathEscape(str)
+		},
 	}
 )
@@ -49,6 +49,10 @@ func Listen(webPort int, wg *sync.WaitGroup, webSocketHandler func(*websocket.Co
 		webSocketHandler(conn)
 	})
 
+	// Zone maps, if enabled
+	http.HandleFunc("GET /maps/", RunWithMUDLocked(servePublicMaps))
+	http.HandleFunc("GET /maps/{zone}", RunWithMUDLocked(servePublicMapSVG))
+
 	// Static resources
 	http.Handle("GET /static/public/", handlerToHandlerFunc(
 		http.StripPrefix("/static/public/", http.FileServer(http.Dir("_datafiles/html/static/public"))),
@@ -107,6 +111,14 @@ func Listen(webPort int, wg *sync.WaitGroup, webSocketHandler func(*websocket.Co
 		doBasicAuth(roomData),
 	))
 
+	// Zone Maps
+	http.HandleFunc("GET /admin/maps/", RunWithMUDLocked(
+		doBasicAuth(mapsIndex),
+	))
+	http.HandleFunc("GET /admin/maps/{zone}", RunWithMUDLocked(
+		doBasicAuth(mapSVG),
+	))
+
 	go func() {
 		defer wg.Done()
 		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
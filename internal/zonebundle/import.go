@@ -0,0 +1,847 @@
+package zonebundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/volte6/gomud/internal/buffs"
+	"github.com/volte6/gomud/internal/configs"
+	"github.com/volte6/gomud/internal/events"
+	"github.com/volte6/gomud/internal/items"
+	"github.com/volte6/gomud/internal/mobs"
+	"github.com/volte6/gomud/internal/quests"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/util"
+	"gopkg.in/yaml.v2"
+)
+
+// What an import did (or would do, on a dry run)
+type ImportReport struct {
+	Zone       string
+	DryRun     bool
+	Changes    []string
+	Warnings   []string
+	NextRoomId int
+}
+
+func (r *ImportReport) change(format string, args ...any) {
+	r.Changes = append(r.Changes, fmt.Sprintf(format, args...))
+}
+
+func (r *ImportReport) warn(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+type importer struct {
+	manifest Manifest
+	files    map[string][]byte
+	report   *ImportReport
+
+	roomIds  map[int]int // bundle id => id on this server
+	mobIds   map[int]int
+	itemIds  map[int]int
+	buffIds  map[int]int
+	questIds map[int]int
+
+	skip   map[string]struct{} // archive files that are identical to what's already here
+	writes []pendingWrite      // files to write, once everything has been worked out
+	rooms  []rooms.Room        // rooms being written, so the server can pick them up afterwards
+}
+
+type pendingWrite struct {
+	path string
+	data []byte
+}
+
+// Lists the bundles that can be imported
+func GetBundleFiles() []string {
+	bundleFiles, _ := filepath.Glob(util.FilePath(BundleFolder, `/*.zip`))
+	for i, bundlePath := range bundleFiles {
+		bundleFiles[i] = filepath.Base(bundlePath)
+	}
+	return bundleFiles
+}
+
+// Imports a bundle from BundleFolder.
+// Room and mob ids already used by another zone, and item, buff and quest ids already used by
+// something different are given new ids, and every reference to them is rewritten.
+// If dryRun is true nothing is written, but the report describes what would happen.
+func Import(bundleFile string, dryRun bool) (*ImportReport, error) {
+
+	bundleFile = filepath.Base(bundleFile)
+	if !strings.HasSuffix(bundleFile, `.zip`) {
+		bundleFile += `.zip`
+	}
+
+	zr, err := zip.OpenReader(util.FilePath(BundleFolder, `/`, bundleFile))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	imp := &importer{
+		files:    map[string][]byte{},
+		roomIds:  map[int]int{},
+		mobIds:   map[int]int{},
+		itemIds:  map[int]int{},
+		buffIds:  map[int]int{},
+		questIds: map[int]int{},
+		skip:     map[string]struct{}{},
+	}
+
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		imp.files[f.Name] = data
+	}
+
+	manifestBytes, ok := imp.files[manifestFile]
+	if !ok {
+		return nil, ErrEmptyBundle
+	}
+
+	if err := yaml.Unmarshal(manifestBytes, &imp.manifest); err != nil {
+		return nil, err
+	}
+
+	if imp.manifest.Zone == `` {
+		return nil, fmt.Errorf(`%s has no zone name`, manifestFile)
+	}
+
+	imp.report = &ImportReport{
+		Zone:   imp.manifest.Zone,
+		DryRun: dryRun,
+	}
+
+	imp.mapRooms()
+	imp.mapMobs()
+	imp.mapSpecs(`item`, imp.manifest.Items, imp.itemIds, itemSpecExists, nextItemId)
+	imp.mapSpecs(`buff`, imp.manifest.Buffs, imp.buffIds, buffSpecExists, nextBuffId)
+	imp.mapSpecs(`quest`, imp.manifest.Quests, imp.questIds, questSpecExists, nextQuestId)
+
+	if err := imp.writeAll(); err != nil {
+		return imp.report, err
+	}
+
+	// Rooms someone is standing in can't be swapped out from under them
+	for _, r := range imp.rooms {
+		if loadedRoom := rooms.LoadRoom(r.RoomId); loadedRoom != nil && len(loadedRoom.GetPlayers()) > 0 {
+			return imp.report, fmt.Errorf(`%w: %d`, ErrImportRoomOccupied, r.RoomId)
+		}
+	}
+
+	if !dryRun {
+		if err := imp.commitWrites(); err != nil {
+			return imp.report, err
+		}
+
+		if imp.report.NextRoomId > rooms.GetNextRoomId() {
+			rooms.SetNextRoomId(imp.report.NextRoomId)
+		}
+
+		// Rooms aren't hot reloaded, so they are swapped in directly
+		rooms.RegisterRooms(imp.rooms)
+
+		// Pick up everything else that was written
+		events.AddToQueue(events.System{
+			Command: `reload`,
+		})
+	}
+
+	return imp.report, nil
+}
+
+// Rooms that already exist in the same zone are overwritten, otherwise they get new ids.
+func (imp *importer) mapRooms() {
+
+	existing := map[int]struct{}{}
+	for _, roomId := range rooms.GetAllRoomIds() {
+		existing[roomId] = struct{}{}
+	}
+
+	// Don't hand out ids that the bundle is about to use
+	claimed := map[int]struct{}{}
+	for _, entry := range imp.manifest.Rooms {
+		if _, ok := existing[entry.Id]; !ok {
+			claimed[entry.Id] = struct{}{}
+		}
+	}
+
+	nextRoomId := rooms.GetNextRoomId()
+
+	for _, entry := range imp.manifest.Rooms {
+
+		r := rooms.LoadRoom(entry.Id)
+
+		if _, ok := existing[entry.Id]; !ok {
+			imp.roomIds[entry.Id] = entry.Id
+			imp.report.change(`room %d: added`, entry.Id)
+		} else if r != nil && r.Zone == imp.manifest.Zone {
+			imp.roomIds[entry.Id] = entry.Id
+			if imp.sameAsDisk(entry.File, roomsFolder+`/`+r.Filepath()) {
+				imp.skip[entry.File] = struct{}{}
+			} else {
+				imp.report.change(`room %d: overwritten`, entry.Id)
+			}
+		} else {
+			for {
+				_, inUse := existing[nextRoomId]
+				_, isClaimed := claimed[nextRoomId]
+				if !inUse && !isClaimed {
+					break
+				}
+				nextRoomId++
+			}
+			imp.roomIds[entry.Id] = nextRoomId
+			claimed[nextRoomId] = struct{}{}
+			imp.report.change(`room %d: already used outside of %s, added as room %d`, entry.Id, imp.manifest.Zone, nextRoomId)
+		}
+
+		if imp.roomIds[entry.Id] >= nextRoomId {
+			nextRoomId = imp.roomIds[entry.Id] + 1
+		}
+	}
+
+	imp.report.NextRoomId = nextRoomId
+}
+
+// Mobs that are identical or belong to the same zone keep their ids, otherwise they get new ids.
+func (imp *importer) mapMobs() {
+
+	maxMobId := 0
+	for _, mobInfo := range mobs.GetAllMobInfo() {
+		if int(mobInfo.MobId) > maxMobId {
+			maxMobId = int(mobInfo.MobId)
+		}
+	}
+	for _, entry := range imp.manifest.Mobs {
+		if entry.Id > maxMobId && mobs.GetMobSpec(mobs.MobId(entry.Id)) == nil {
+			maxMobId = entry.Id
+		}
+	}
+
+	for _, entry := range imp.manifest.Mobs {
+
+		mobInfo := mobs.GetMobSpec(mobs.MobId(entry.Id))
+		if mobInfo == nil {
+			imp.mobIds[entry.Id] = entry.Id
+			imp.report.change(`mob %d: added`, entry.Id)
+			continue
+		}
+
+		if imp.sameAsDisk(entry.File, mobsFolder+`/`+mobInfo.Filepath()) {
+			imp.mobIds[entry.Id] = entry.Id
+			imp.skip[entry.File] = struct{}{}
+			imp.report.change(`mob %d: already here, skipped`, entry.Id)
+			continue
+		}
+
+		if mobInfo.Zone == imp.manifest.Zone {
+			imp.mobIds[entry.Id] = entry.Id
+			imp.report.change(`mob %d: overwritten`, entry.Id)
+			continue
+		}
+
+		maxMobId++
+		imp.mobIds[entry.Id] = maxMobId
+		imp.report.change(`mob %d: already used by %s, added as mob %d`, entry.Id, mobInfo.Character.Name, maxMobId)
+	}
+}
+
+// Items, buffs and quests that are identical keep their ids (and aren't written), otherwise they get new ids.
+func (imp *importer) mapSpecs(kind string, entries []Entry, idMap map[int]int, existingPath func(int) (string, bool), nextId func(int, map[int]struct{}) int) {
+
+	claimed := map[int]struct{}{}
+	for _, entry := range entries {
+		if _, exists := existingPath(entry.Id); !exists {
+			claimed[entry.Id] = struct{}{}
+		}
+	}
+
+	for _, entry := range entries {
+
+		diskPath, exists := existingPath(entry.Id)
+		if !exists {
+			idMap[entry.Id] = entry.Id
+			imp.report.change(`%s %d: added`, kind, entry.Id)
+			continue
+		}
+
+		if imp.sameAsDisk(entry.File, diskPath) {
+			idMap[entry.Id] = entry.Id
+			imp.skip[entry.File] = struct{}{}
+			imp.report.change(`%s %d: already here, skipped`, kind, entry.Id)
+			continue
+		}
+
+		newId := nextId(entry.Id, claimed)
+		claimed[newId] = struct{}{}
+		idMap[entry.Id] = newId
+		imp.report.change(`%s %d: differs from the one already here, added as %s %d`, kind, entry.Id, kind, newId)
+	}
+}
+
+func (imp *importer) sameAsDisk(archivePath string, diskPath string) bool {
+	diskBytes, err := os.ReadFile(util.FilePath(diskPath))
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(bytes.TrimSpace(diskBytes), bytes.TrimSpace(imp.files[archivePath]))
+}
+
+// Works out every file to write. Nothing is written until commitWrites()
+func (imp *importer) writeAll() error {
+
+	for _, entry := range imp.manifest.Rooms {
+		if err := imp.importRoom(entry); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range imp.manifest.Mobs {
+		if err := imp.importMob(entry); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range imp.manifest.Items {
+		if err := imp.importItem(entry); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range imp.manifest.Buffs {
+		if err := imp.importBuff(entry); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range imp.manifest.Quests {
+		if err := imp.importQuest(entry); err != nil {
+			return err
+		}
+	}
+
+	for _, archivePath := range imp.manifest.Conversations {
+		if err := imp.importConversation(archivePath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (imp *importer) importRoom(entry Entry) error {
+
+	if _, ok := imp.skip[entry.File]; ok {
+		return nil
+	}
+
+	r := rooms.Room{}
+	if err := yaml.Unmarshal(imp.files[entry.File], &r); err != nil {
+		return fmt.Errorf("%s: %w", entry.File, err)
+	}
+
+	r.RoomId = imp.roomIds[entry.Id]
+
+	if r.ZoneConfig.RoomId > 0 {
+		r.ZoneConfig.RoomId = imp.mapId(imp.roomIds, r.ZoneConfig.RoomId)
+	}
+
+	for exitName, exitInfo := range r.Exits {
+		if newRoomId, ok := imp.roomIds[exitInfo.RoomId]; ok {
+			exitInfo.RoomId = newRoomId
+			r.Exits[exitName] = exitInfo
+		} else if rooms.LoadRoom(exitInfo.RoomId) == nil {
+			imp.report.warn(`room %d: exit %s leads to room %d, which doesn't exist here`, r.RoomId, exitName, exitInfo.RoomId)
+		}
+	}
+
+	for idx, spawnInfo := range r.SpawnInfo {
+		spawnInfo.MobId = imp.mapId(imp.mobIds, spawnInfo.MobId)
+		spawnInfo.ItemId = imp.mapId(imp.itemIds, spawnInfo.ItemId)
+		for i, buffId := range spawnInfo.BuffIds {
+			spawnInfo.BuffIds[i] = imp.mapId(imp.buffIds, buffId)
+		}
+		for i, questToken := range spawnInfo.QuestFlags {
+			spawnInfo.QuestFlags[i] = imp.mapQuestToken(questToken)
+		}
+		r.SpawnInfo[idx] = spawnInfo
+	}
+
+	imp.mapItems(r.Items)
+	imp.mapItems(r.Stash)
+	for containerName, container := range r.Containers {
+		imp.mapItems(container.Items)
+		r.Containers[containerName] = container
+	}
+
+	if err := imp.queueFlatFile(roomsFolder, &r); err != nil {
+		return err
+	}
+	imp.rooms = append(imp.rooms, r)
+
+	for _, scriptFile := range entry.Scripts {
+		if err := imp.writeScript(scriptFile, strings.Replace(roomsFolder+`/`+r.Filepath(), `.yaml`, `.js`, 1)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (imp *importer) importMob(entry Entry) error {
+
+	if _, ok := imp.skip[entry.File]; ok {
+		return nil
+	}
+
+	m := mobs.Mob{}
+	if err := yaml.Unmarshal(imp.files[entry.File], &m); err != nil {
+		return fmt.Errorf("%s: %w", entry.File, err)
+	}
+
+	oldScriptPrefix := strings.TrimSuffix(path.Base(entry.File), `.yaml`)
+
+	m.MobId = mobs.MobId(imp.mobIds[entry.Id])
+
+	imp.mapItems(m.Character.Items)
+
+	m.Character.Equipment.Weapon.ItemId = imp.mapId(imp.itemIds, m.Character.Equipment.Weapon.ItemId)
+	m.Character.Equipment.Offhand.ItemId = imp.mapId(imp.itemIds, m.Character.Equipment.Offhand.ItemId)
+	m.Character.Equipment.Head.ItemId = imp.mapId(imp.itemIds, m.Character.Equipment.Head.ItemId)
+	m.Character.Equipment.Neck.ItemId = imp.mapId(imp.itemIds, m.Character.Equipment.Neck.ItemId)
+	m.Character.Equipment.Body.ItemId = imp.mapId(imp.itemIds, m.Character.Equipment.Body.ItemId)
+	m.Character.Equipment.Belt.ItemId = imp.mapId(imp.itemIds, m.Character.Equipment.Belt.ItemId)
+	m.Character.Equipment.Gloves.ItemId = imp.mapId(imp.itemIds, m.Character.Equipment.Gloves.ItemId)
+	m.Character.Equipment.Ring.ItemId = imp.mapId(imp.itemIds, m.Character.Equipment.Ring.ItemId)
+	m.Character.Equipment.Legs.ItemId = imp.mapId(imp.itemIds, m.Character.Equipment.Legs.ItemId)
+	m.Character.Equipment.Feet.ItemId = imp.mapId(imp.itemIds, m.Character.Equipment.Feet.ItemId)
+
+	for idx, shopItem := range m.Character.Shop {
+		shopItem.MobId = imp.mapId(imp.mobIds, shopItem.MobId)
+		shopItem.ItemId = imp.mapId(imp.itemIds, shopItem.ItemId)
+		shopItem.TradeItemId = imp.mapId(imp.itemIds, shopItem.TradeItemId)
+		shopItem.BuffId = imp.mapId(imp.buffIds, shopItem.BuffId)
+		m.Character.Shop[idx] = shopItem
+	}
+
+	for i, buffId := range m.BuffIds {
+		m.BuffIds[i] = imp.mapId(imp.buffIds, buffId)
+	}
+
+	for i, questToken := range m.QuestFlags {
+		m.QuestFlags[i] = imp.mapQuestToken(questToken)
+	}
+
+	if err := imp.queueFlatFile(mobsFolder, &m); err != nil {
+		return err
+	}
+
+	// Scripts are named after the mob, with an optional script tag on the end
+	newScriptPrefix := strings.TrimSuffix(m.Filename(), `.yaml`)
+	scriptFolder := util.FilePath(mobsFolder, `/`, mobs.ZoneNameSanitize(m.Zone), `/scripts/`)
+
+	for _, scriptFile := range entry.Scripts {
+		scriptName := newScriptPrefix + strings.TrimPrefix(path.Base(scriptFile), oldScriptPrefix)
+		if err := imp.writeScript(scriptFile, scriptFolder+scriptName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (imp *importer) importItem(entry Entry) error {
+
+	if _, ok := imp.skip[entry.File]; ok {
+		return nil
+	}
+
+	iSpec := items.ItemSpec{}
+	if err := yaml.Unmarshal(imp.files[entry.File], &iSpec); err != nil {
+		return fmt.Errorf("%s: %w", entry.File, err)
+	}
+
+	iSpec.ItemId = imp.itemIds[entry.Id]
+
+	for i, buffId := range iSpec.BuffIds {
+		iSpec.BuffIds[i] = imp.mapId(imp.buffIds, buffId)
+	}
+
+	for i, buffId := range iSpec.WornBuffIds {
+		iSpec.WornBuffIds[i] = imp.mapId(imp.buffIds, buffId)
+	}
+
+	for i, buffId := range iSpec.Damage.CritBuffIds {
+		iSpec.Damage.CritBuffIds[i] = imp.mapId(imp.buffIds, buffId)
+	}
+
+	iSpec.QuestToken = imp.mapQuestToken(iSpec.QuestToken)
+
+	// Keys are tied to a room: `778-north`
+	if lockRoom, lockExit, found := strings.Cut(iSpec.KeyLockId, `-`); found {
+		if lockRoomId, err := strconv.Atoi(lockRoom); err == nil {
+			iSpec.KeyLockId = fmt.Sprintf(`%d-%s`, imp.mapId(imp.roomIds, lockRoomId), lockExit)
+		}
+	}
+
+	itemFolder := string(configs.GetConfig().FolderItemData)
+
+	if err := imp.queueFlatFile(itemFolder, &iSpec); err != nil {
+		return err
+	}
+
+	for _, scriptFile := range entry.Scripts {
+		if err := imp.writeScript(scriptFile, strings.Replace(itemFolder+`/`+iSpec.Filepath(), `.yaml`, `.js`, 1)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (imp *importer) importBuff(entry Entry) error {
+
+	if _, ok := imp.skip[entry.File]; ok {
+		return nil
+	}
+
+	buffSpec := buffs.BuffSpec{}
+	if err := yaml.Unmarshal(imp.files[entry.File], &buffSpec); err != nil {
+		return fmt.Errorf("%s: %w", entry.File, err)
+	}
+
+	buffSpec.BuffId = imp.buffIds[entry.Id]
+
+	if err := imp.queueFlatFile(buffsFolder, &buffSpec); err != nil {
+		return err
+	}
+
+	for _, scriptFile := range entry.Scripts {
+		if err := imp.writeScript(scriptFile, strings.Replace(buffsFolder+`/`+buffSpec.Filepath(), `.yaml`, `.js`, 1)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (imp *importer) importQuest(entry Entry) error {
+
+	if _, ok := imp.skip[entry.File]; ok {
+		return nil
+	}
+
+	questInfo := quests.Quest{}
+	if err := yaml.Unmarshal(imp.files[entry.File], &questInfo); err != nil {
+		return fmt.Errorf("%s: %w", entry.File, err)
+	}
+
+	questInfo.QuestId = imp.questIds[entry.Id]
+	questInfo.Rewards.QuestId = imp.mapQuestToken(questInfo.Rewards.QuestId)
+	questInfo.Rewards.ItemId = imp.mapId(imp.itemIds, questInfo.Rewards.ItemId)
+	questInfo.Rewards.BuffId = imp.mapId(imp.buffIds, questInfo.Rewards.BuffId)
+	questInfo.Rewards.RoomId = imp.mapId(imp.roomIds, questInfo.Rewards.RoomId)
+
+	return imp.queueFlatFile(questsFolder, &questInfo)
+}
+
+// Conversation files are named after the mobs taking part: {mobId}-{mobId}.yaml
+func (imp *importer) importConversation(archivePath string) error {
+
+	fileName := strings.TrimSuffix(path.Base(archivePath), `.yaml`)
+
+	newFileName := imp.mapMobIdList(fileName, `-`)
+	if newFileName != fileName {
+		imp.report.change(`conversation %s: renamed to %s`, fileName, newFileName)
+	}
+
+	data, err := imp.mapConversationMobs(imp.files[archivePath])
+	if err != nil {
+		return fmt.Errorf("%s: %w", archivePath, err)
+	}
+
+	imp.queueFile(conversationsFolder+`/`+path.Base(path.Dir(archivePath))+`/`+newFileName+`.yaml`, data)
+
+	return nil
+}
+
+// Inside, each conversation is keyed by who takes part, by name or by mob id: "guard:guard" or "2:2"
+// The file is only rewritten if a mob id in it changed.
+func (imp *importer) mapConversationMobs(data []byte) ([]byte, error) {
+
+	contents := yaml.MapSlice{}
+	if err := yaml.Unmarshal(data, &contents); err != nil {
+		return nil, err
+	}
+
+	changed := false
+	for i, section := range contents {
+
+		conversationList, ok := section.Value.(yaml.MapSlice)
+		if section.Key != `Conversations` || !ok {
+			continue
+		}
+
+		for j, conversation := range conversationList {
+			key := fmt.Sprint(conversation.Key)
+			if newKey := imp.mapMobIdList(key, `:`); newKey != key {
+				conversationList[j].Key = newKey
+				changed = true
+			}
+		}
+
+		contents[i].Value = conversationList
+	}
+
+	if !changed {
+		return data, nil
+	}
+
+	return yaml.Marshal(contents)
+}
+
+// Maps any mob ids in a list such as "12-40", leaving anything that isn't a number alone
+func (imp *importer) mapMobIdList(list string, separator string) string {
+
+	parts := strings.Split(list, separator)
+	for i, mobIdStr := range parts {
+		if mobId, err := strconv.Atoi(mobIdStr); err == nil {
+			parts[i] = strconv.Itoa(imp.mapId(imp.mobIds, mobId))
+		}
+	}
+
+	return strings.Join(parts, separator)
+}
+
+// Writes a script to its new location, updating any quest tokens it hands out.
+// Scripts can refer to other ids in any number of ways, so anything else is left for a human to check.
+func (imp *importer) writeScript(archivePath string, diskPath string) error {
+
+	script := scriptQuestTokenRegex.ReplaceAllStringFunc(string(imp.files[archivePath]), func(match string) string {
+		parts := scriptQuestTokenRegex.FindStringSubmatch(match)
+		questId, _ := strconv.Atoi(parts[2])
+		return parts[1] + strconv.Itoa(imp.mapId(imp.questIds, questId)) + `-`
+	})
+
+	if imp.anyRemapped() {
+		imp.report.warn(`%s: may refer to ids that changed, check it by hand`, archivePath)
+	}
+
+	imp.queueFile(diskPath, []byte(script))
+
+	return nil
+}
+
+func (imp *importer) queueFile(diskPath string, data []byte) {
+	imp.writes = append(imp.writes, pendingWrite{path: util.FilePath(diskPath), data: data})
+}
+
+// Queues a spec the same way fileloader.SaveFlatFile() would write it
+func (imp *importer) queueFlatFile(basePath string, dataUnit interface{ Filepath() string }) error {
+	data, err := yaml.Marshal(dataUnit)
+	if err != nil {
+		return err
+	}
+	imp.queueFile(basePath+`/`+dataUnit.Filepath(), data)
+	return nil
+}
+
+// Writes everything that was queued.
+// Files are staged in a temporary folder first, then moved into place. If moving any of them fails,
+// the ones already moved are put back the way they were, so a zone is never left half imported.
+func (imp *importer) commitWrites() (retErr error) {
+
+	stageFolder, err := os.MkdirTemp(util.FilePath(BundleFolder), `.import-`)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stageFolder)
+
+	// Nothing has been touched if this part fails
+	stagedPaths := make([]string, len(imp.writes))
+	for i, w := range imp.writes {
+		stagedPaths[i] = filepath.Join(stageFolder, strconv.Itoa(i))
+		if err := os.WriteFile(stagedPaths[i], w.data, 0644); err != nil {
+			return err
+		}
+	}
+
+	type movedFile struct {
+		path       string
+		backupPath string // Empty if there was nothing there before
+	}
+	moved := []movedFile{}
+
+	defer func() {
+		if retErr == nil {
+			return
+		}
+		for i := len(moved) - 1; i >= 0; i-- {
+			if moved[i].backupPath == `` {
+				os.Remove(moved[i].path)
+			} else {
+				os.Rename(moved[i].backupPath, moved[i].path)
+			}
+		}
+	}()
+
+	for i, w := range imp.writes {
+
+		if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+			return err
+		}
+
+		m := movedFile{path: w.path}
+
+		if _, err := os.Stat(w.path); err == nil {
+			m.backupPath = stagedPaths[i] + `.old`
+			if err := os.Rename(w.path, m.backupPath); err != nil {
+				return err
+			}
+		}
+
+		if err := os.Rename(stagedPaths[i], w.path); err != nil {
+			if m.backupPath != `` {
+				os.Rename(m.backupPath, w.path)
+			}
+			return err
+		}
+
+		moved = append(moved, m)
+	}
+
+	return nil
+}
+
+func (imp *importer) mapItems(itemList []items.Item) {
+	for i := range itemList {
+		itemList[i].ItemId = imp.mapId(imp.itemIds, itemList[i].ItemId)
+	}
+}
+
+// Returns the new id for a bundled id, or the same id if it wasn't part of the bundle
+func (imp *importer) mapId(idMap map[int]int, id int) int {
+	if newId, ok := idMap[id]; ok {
+		return newId
+	}
+	return id
+}
+
+func (imp *importer) mapQuestToken(questToken string) string {
+	if questToken == `` {
+		return questToken
+	}
+	questId, questStep := quests.TokenToParts(questToken)
+	if newQuestId, ok := imp.questIds[questId]; ok && newQuestId != questId {
+		return quests.PartsToToken(newQuestId, questStep)
+	}
+	return questToken
+}
+
+// Whether anything in the bundle ended up with a different id
+func (imp *importer) anyRemapped() bool {
+	for _, idMap := range []map[int]int{imp.roomIds, imp.mobIds, imp.itemIds, imp.buffIds, imp.questIds} {
+		for oldId, newId := range idMap {
+			if oldId != newId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func itemSpecExists(itemId int) (string, bool) {
+	if iSpec := items.GetItemSpec(itemId); iSpec != nil {
+		return string(configs.GetConfig().FolderItemData) + `/` + iSpec.Filepath(), true
+	}
+	return ``, false
+}
+
+func buffSpecExists(buffId int) (string, bool) {
+	if buffSpec := buffs.GetBuffSpec(buffId); buffSpec != nil {
+		return buffsFolder + `/` + buffSpec.Filepath(), true
+	}
+	return ``, false
+}
+
+func questSpecExists(questId int) (string, bool) {
+	if questInfo := quests.GetQuest(strconv.Itoa(questId) + `-all+`); questInfo != nil {
+		return questsFolder + `/` + questInfo.Filepath(), true
+	}
+	return ``, false
+}
+
+// Item ids are grouped into ranges that decide which folder they live in, so stay in the same range.
+func nextItemId(itemId int, claimed map[int]struct{}) int {
+
+	rangeStart, rangeEnd := 1, 10000
+	for _, boundary := range []int{10000, 20000, 30000} {
+		if itemId >= boundary {
+			rangeStart, rangeEnd = boundary, boundary+10000
+		}
+	}
+
+	newId := rangeStart
+	for _, iSpec := range items.GetAllItemSpecs() {
+		if iSpec.ItemId >= newId && iSpec.ItemId < rangeEnd {
+			newId = iSpec.ItemId + 1
+		}
+	}
+
+	for {
+		if _, ok := claimed[newId]; !ok && items.GetItemSpec(newId) == nil {
+			return newId
+		}
+		newId++
+	}
+}
+
+func nextBuffId(buffId int, claimed map[int]struct{}) int {
+
+	newId := 1
+	for _, existingId := range buffs.GetAllBuffIds() {
+		if existingId >= newId {
+			newId = existingId + 1
+		}
+	}
+
+	for {
+		if _, ok := claimed[newId]; !ok {
+			return newId
+		}
+		newId++
+	}
+}
+
+func nextQuestId(questId int, claimed map[int]struct{}) int {
+
+	newId := 1
+	for _, questInfo := range quests.GetAllQuests() {
+		if questInfo.QuestId >= newId {
+			newId = questInfo.QuestId + 1
+		}
+	}
+
+	for {
+		if _, ok := claimed[newId]; !ok {
+			return newId
+		}
+		newId++
+	}
+}
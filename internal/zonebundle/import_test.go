@@ -0,0 +1,146 @@
+package zonebundle
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Runs a test from an empty folder, since imports write relative to the working directory
+func useTempFolder(t *testing.T) {
+	t.Helper()
+
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd() failed: %v", err)
+	}
+
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Chdir() failed: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(oldWd) })
+
+	if err := os.MkdirAll(BundleFolder, 0755); err != nil {
+		t.Fatalf("MkdirAll() failed: %v", err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%s) failed: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestCommitWritesRollsBack(t *testing.T) {
+	useTempFolder(t)
+
+	existingPath := filepath.Join(`rooms`, `1.yaml`)
+	newPath := filepath.Join(`rooms`, `2.yaml`)
+
+	if err := os.MkdirAll(`rooms`, 0755); err != nil {
+		t.Fatalf("MkdirAll() failed: %v", err)
+	}
+	if err := os.WriteFile(existingPath, []byte(`old`), 0644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	// A file where a folder needs to be, so the last write fails after the others have gone in
+	if err := os.WriteFile(`blocked`, []byte(`not a folder`), 0644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	imp := &importer{}
+	imp.queueFile(existingPath, []byte(`new`))
+	imp.queueFile(newPath, []byte(`added`))
+	imp.queueFile(filepath.Join(`blocked`, `3.yaml`), []byte(`never written`))
+
+	if err := imp.commitWrites(); err == nil {
+		t.Fatalf("commitWrites() succeeded; want an error")
+	}
+
+	if got := readFile(t, existingPath); got != `old` {
+		t.Errorf("Overwritten file is %q after rollback; want %q", got, `old`)
+	}
+
+	if _, err := os.Stat(newPath); !os.IsNotExist(err) {
+		t.Errorf("Added file is still there after rollback")
+	}
+
+	// Nothing is left behind in the staging folder either
+	if leftovers, _ := filepath.Glob(filepath.Join(BundleFolder, `.import-*`)); len(leftovers) > 0 {
+		t.Errorf("Staging folders left behind: %v", leftovers)
+	}
+}
+
+func TestCommitWrites(t *testing.T) {
+	useTempFolder(t)
+
+	existingPath := filepath.Join(`rooms`, `1.yaml`)
+	newPath := filepath.Join(`rooms`, `zone`, `2.yaml`)
+
+	if err := os.MkdirAll(`rooms`, 0755); err != nil {
+		t.Fatalf("MkdirAll() failed: %v", err)
+	}
+	if err := os.WriteFile(existingPath, []byte(`old`), 0644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	imp := &importer{}
+	imp.queueFile(existingPath, []byte(`new`))
+	imp.queueFile(newPath, []byte(`added`))
+
+	if err := imp.commitWrites(); err != nil {
+		t.Fatalf("commitWrites() failed: %v", err)
+	}
+
+	if got := readFile(t, existingPath); got != `new` {
+		t.Errorf("Overwritten file is %q; want %q", got, `new`)
+	}
+
+	if got := readFile(t, newPath); got != `added` {
+		t.Errorf("Added file is %q; want %q", got, `added`)
+	}
+}
+
+func TestMapConversationMobs(t *testing.T) {
+
+	imp := &importer{
+		mobIds: map[int]int{2: 2, 7: 41},
+	}
+
+	if got := imp.mapMobIdList(`7-2`, `-`); got != `41-2` {
+		t.Errorf("mapMobIdList(7-2) = %s; want 41-2", got)
+	}
+
+	// Named conversations are left exactly as they were
+	named := []byte("Conversations:\n  'guard:guard':\n  -\n    - mob1:\n      - say Cold night.\n")
+	if got, err := imp.mapConversationMobs(named); err != nil || string(got) != string(named) {
+		t.Errorf("mapConversationMobs() changed a named conversation: %q, %v", got, err)
+	}
+
+	byId := []byte("Conversations:\n  '7:2':\n  -\n    - mob1:\n      - say Cold night.\n")
+	got, err := imp.mapConversationMobs(byId)
+	if err != nil {
+		t.Fatalf("mapConversationMobs() failed: %v", err)
+	}
+
+	contents := map[string]map[string]any{}
+	if err := yaml.Unmarshal(got, &contents); err != nil {
+		t.Fatalf("Unmarshal() failed: %v\n%s", err, got)
+	}
+
+	if _, ok := contents[`Conversations`][`41:2`]; !ok {
+		t.Errorf("mapConversationMobs() didn't remap the mob ids:\n%s", got)
+	}
+
+	if !strings.Contains(string(got), `say Cold night.`) {
+		t.Errorf("mapConversationMobs() lost the conversation:\n%s", got)
+	}
+}
@@ -0,0 +1,522 @@
+package zonebundle
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/volte6/gomud/internal/buffs"
+	"github.com/volte6/gomud/internal/configs"
+	"github.com/volte6/gomud/internal/items"
+	"github.com/volte6/gomud/internal/mobs"
+	"github.com/volte6/gomud/internal/quests"
+	"github.com/volte6/gomud/internal/rooms"
+	"github.com/volte6/gomud/internal/util"
+	"gopkg.in/yaml.v2"
+)
+
+// A zone bundle is a zip archive holding everything needed to move a zone to another server:
+//
+//	manifest.yaml
+//	rooms/{zone}/{roomId}.yaml (and .js)
+//	mobs/{zone}/{mobId}-{name}.yaml (and scripts/{mobId}-{name}*.js)
+//	items/{folder}/{itemId}-{name}.yaml (and .js)
+//	buffs/{buffId}-{name}.yaml (and .js)
+//	quests/{questId}-{name}.yaml
+//	conversations/{zone}/{mobId}-{mobId}.yaml
+const (
+	BundleFolder = `_datafiles/bundles`
+
+	manifestFile = `manifest.yaml`
+
+	roomsFolder         = `_datafiles/rooms`
+	mobsFolder          = `_datafiles/mobs`
+	buffsFolder         = `_datafiles/buffs`
+	questsFolder        = `_datafiles/quests`
+	conversationsFolder = `_datafiles/conversations`
+)
+
+var (
+	// Matches quest tokens handed to GiveQuest()/HasQuest() in scripts, such as user.GiveQuest("4-start")
+	scriptQuestTokenRegex = regexp.MustCompile("((?:Give|Has)Quest\\(\\s*[\"'`])(\\d+)-")
+
+	ErrEmptyBundle        = errors.New(`bundle has no manifest`)
+	ErrImportRoomOccupied = errors.New(`someone is in a room the import would replace`)
+)
+
+// Describes the contents of a bundle
+type Manifest struct {
+	Zone          string
+	RootRoomId    int
+	ExportedAt    string
+	ExportedBy    string   `yaml:"exportedby,omitempty"`
+	Rooms         []Entry  `yaml:"rooms,omitempty"`
+	Mobs          []Entry  `yaml:"mobs,omitempty"`
+	Items         []Entry  `yaml:"items,omitempty"`
+	Buffs         []Entry  `yaml:"buffs,omitempty"`
+	Quests        []Entry  `yaml:"quests,omitempty"`
+	Conversations []string `yaml:"conversations,omitempty"`
+}
+
+// A single spec in the bundle, and where to find it in the archive
+type Entry struct {
+	Id      int
+	File    string
+	Scripts []string `yaml:"scripts,omitempty"`
+}
+
+type exporter struct {
+	manifest Manifest
+	files    map[string][]byte
+
+	mobIds   map[int]bool // value is whether it has been exported yet
+	itemIds  map[int]bool
+	buffIds  map[int]bool
+	questIds map[int]bool
+}
+
+// Writes every room of a zone, along with the mobs, items, buffs, quests and conversations
+// it refers to, into a single archive in BundleFolder.
+// Returns the path to the archive.
+func Export(zone string, exportedBy string) (string, *Manifest, error) {
+
+	rootRoomId, err := rooms.GetZoneRoot(zone)
+	if err != nil {
+		return ``, nil, err
+	}
+
+	e := &exporter{
+		manifest: Manifest{
+			Zone:       zone,
+			RootRoomId: rootRoomId,
+			ExportedAt: time.Now().Format(time.RFC3339),
+			ExportedBy: exportedBy,
+		},
+		files:    map[string][]byte{},
+		mobIds:   map[int]bool{},
+		itemIds:  map[int]bool{},
+		buffIds:  map[int]bool{},
+		questIds: map[int]bool{},
+	}
+
+	zoneRoomIds := rooms.GetZoneRoomIds(zone)
+
+	for _, roomId := range zoneRoomIds {
+		if err := e.exportRoom(roomId); err != nil {
+			return ``, nil, err
+		}
+	}
+
+	// Every mob that calls this zone home comes along, even if nothing spawns it
+	for _, mobInfo := range mobs.GetAllMobInfo() {
+		if mobInfo.Zone == zone {
+			e.want(e.mobIds, int(mobInfo.MobId))
+		}
+	}
+
+	// So do any keys that open locks in this zone
+	keyPrefixes := map[string]struct{}{}
+	for _, roomId := range zoneRoomIds {
+		keyPrefixes[strconv.Itoa(roomId)] = struct{}{}
+	}
+	for _, iSpec := range items.GetAllItemSpecs() {
+		if iSpec.KeyLockId == `` {
+			continue
+		}
+		if lockRoom, _, found := strings.Cut(iSpec.KeyLockId, `-`); found {
+			if _, ok := keyPrefixes[lockRoom]; ok {
+				e.want(e.itemIds, iSpec.ItemId)
+			}
+		}
+	}
+
+	// Exporting one thing can reference others, so keep going until nothing new turns up.
+	for {
+		found := false
+
+		for mobId, done := range e.mobIds {
+			if !done {
+				e.mobIds[mobId] = true
+				found = true
+				if err := e.exportMob(mobId); err != nil {
+					return ``, nil, err
+				}
+			}
+		}
+
+		for questId, done := range e.questIds {
+			if !done {
+				e.questIds[questId] = true
+				found = true
+				if err := e.exportQuest(questId); err != nil {
+					return ``, nil, err
+				}
+			}
+		}
+
+		for itemId, done := range e.itemIds {
+			if !done {
+				e.itemIds[itemId] = true
+				found = true
+				if err := e.exportItem(itemId); err != nil {
+					return ``, nil, err
+				}
+			}
+		}
+
+		for buffId, done := range e.buffIds {
+			if !done {
+				e.buffIds[buffId] = true
+				found = true
+				if err := e.exportBuff(buffId); err != nil {
+					return ``, nil, err
+				}
+			}
+		}
+
+		if !found {
+			break
+		}
+	}
+
+	if err := e.exportConversations(zone); err != nil {
+		return ``, nil, err
+	}
+
+	for _, entries := range [][]Entry{e.manifest.Rooms, e.manifest.Mobs, e.manifest.Items, e.manifest.Buffs, e.manifest.Quests} {
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Id < entries[j].Id
+		})
+	}
+
+	bundlePath := util.FilePath(BundleFolder, `/`, rooms.ZoneNameSanitize(zone)+`.zip`)
+	if err := e.write(bundlePath); err != nil {
+		return ``, nil, err
+	}
+
+	return bundlePath, &e.manifest, nil
+}
+
+// Flags an id as needing export, if it hasn't been already
+func (e *exporter) want(ids map[int]bool, id int) {
+	if id <= 0 {
+		return
+	}
+	if _, ok := ids[id]; !ok {
+		ids[id] = false
+	}
+}
+
+func (e *exporter) wantItem(itm items.Item) {
+	e.want(e.itemIds, itm.ItemId)
+}
+
+func (e *exporter) wantQuestToken(questToken string) {
+	if questToken == `` {
+		return
+	}
+	questId, _ := quests.TokenToParts(questToken)
+	e.want(e.questIds, questId)
+}
+
+// Adds a file from disk to the archive
+func (e *exporter) addFile(archivePath string, diskPath string) error {
+	data, err := os.ReadFile(util.FilePath(diskPath))
+	if err != nil {
+		return err
+	}
+	e.files[archivePath] = data
+	return nil
+}
+
+// Adds a script to the archive if it exists, and tracks any quests it hands out
+func (e *exporter) addScript(archivePath string, diskPath string) bool {
+	data, err := os.ReadFile(util.FilePath(diskPath))
+	if err != nil {
+		return false
+	}
+	e.files[archivePath] = data
+
+	for _, match := range scriptQuestTokenRegex.FindAllStringSubmatch(string(data), -1) {
+		questId, _ := strconv.Atoi(match[2])
+		e.want(e.questIds, questId)
+	}
+
+	return true
+}
+
+func (e *exporter) exportRoom(roomId int) error {
+
+	room := rooms.LoadRoom(roomId)
+	if room == nil {
+		return fmt.Errorf("room %d could not be loaded", roomId)
+	}
+
+	// Make sure what's on disk is current
+	if err := rooms.SaveRoom(*room); err != nil {
+		return err
+	}
+
+	entry := Entry{
+		Id:   roomId,
+		File: `rooms/` + filepath.ToSlash(room.Filepath()),
+	}
+
+	if err := e.addFile(entry.File, roomsFolder+`/`+room.Filepath()); err != nil {
+		return err
+	}
+
+	scriptFile := strings.Replace(entry.File, `.yaml`, `.js`, 1)
+	if e.addScript(scriptFile, room.GetScriptPath()) {
+		entry.Scripts = append(entry.Scripts, scriptFile)
+	}
+
+	for _, spawnInfo := range room.SpawnInfo {
+		e.want(e.mobIds, spawnInfo.MobId)
+		e.want(e.itemIds, spawnInfo.ItemId)
+		for _, buffId := range spawnInfo.BuffIds {
+			e.want(e.buffIds, buffId)
+		}
+		for _, questToken := range spawnInfo.QuestFlags {
+			e.wantQuestToken(questToken)
+		}
+	}
+
+	for _, itm := range room.Items {
+		e.wantItem(itm)
+	}
+
+	for _, itm := range room.Stash {
+		e.wantItem(itm)
+	}
+
+	for _, container := range room.Containers {
+		for _, itm := range container.Items {
+			e.wantItem(itm)
+		}
+	}
+
+	e.manifest.Rooms = append(e.manifest.Rooms, entry)
+
+	return nil
+}
+
+func (e *exporter) exportMob(mobId int) error {
+
+	mobInfo := mobs.GetMobSpec(mobs.MobId(mobId))
+	if mobInfo == nil {
+		return nil
+	}
+
+	entry := Entry{
+		Id:   mobId,
+		File: `mobs/` + filepath.ToSlash(mobInfo.Filepath()),
+	}
+
+	if err := e.addFile(entry.File, mobsFolder+`/`+mobInfo.Filepath()); err != nil {
+		return err
+	}
+
+	// Mobs can have several scripts, one per script tag
+	scriptPrefix := strings.TrimSuffix(mobInfo.Filename(), `.yaml`)
+	zoneFolder := mobs.ZoneNameSanitize(mobInfo.Zone)
+
+	scriptFiles, _ := filepath.Glob(util.FilePath(mobsFolder, `/`, zoneFolder, `/scripts/`, scriptPrefix+`*.js`))
+	sort.Strings(scriptFiles)
+
+	for _, scriptPath := range scriptFiles {
+		scriptFile := `mobs/` + zoneFolder + `/scripts/` + filepath.Base(scriptPath)
+		if e.addScript(scriptFile, scriptPath) {
+			entry.Scripts = append(entry.Scripts, scriptFile)
+		}
+	}
+
+	for _, itm := range mobInfo.Character.Items {
+		e.wantItem(itm)
+	}
+
+	for _, itm := range mobInfo.Character.Equipment.GetAllItems() {
+		e.wantItem(itm)
+	}
+
+	for _, shopItem := range mobInfo.Character.Shop {
+		e.want(e.mobIds, shopItem.MobId)
+		e.want(e.itemIds, shopItem.ItemId)
+		e.want(e.itemIds, shopItem.TradeItemId)
+		e.want(e.buffIds, shopItem.BuffId)
+	}
+
+	for _, buffId := range mobInfo.BuffIds {
+		e.want(e.buffIds, buffId)
+	}
+
+	for _, questToken := range mobInfo.QuestFlags {
+		e.wantQuestToken(questToken)
+	}
+
+	e.manifest.Mobs = append(e.manifest.Mobs, entry)
+
+	return nil
+}
+
+func (e *exporter) exportItem(itemId int) error {
+
+	iSpec := items.GetItemSpec(itemId)
+	if iSpec == nil {
+		return nil
+	}
+
+	entry := Entry{
+		Id:   itemId,
+		File: `items/` + filepath.ToSlash(iSpec.Filepath()),
+	}
+
+	if err := e.addFile(entry.File, string(configs.GetConfig().FolderItemData)+`/`+iSpec.Filepath()); err != nil {
+		return err
+	}
+
+	scriptFile := strings.Replace(entry.File, `.yaml`, `.js`, 1)
+	if e.addScript(scriptFile, iSpec.GetScriptPath()) {
+		entry.Scripts = append(entry.Scripts, scriptFile)
+	}
+
+	for _, buffId := range iSpec.BuffIds {
+		e.want(e.buffIds, buffId)
+	}
+
+	for _, buffId := range iSpec.WornBuffIds {
+		e.want(e.buffIds, buffId)
+	}
+
+	for _, buffId := range iSpec.Damage.CritBuffIds {
+		e.want(e.buffIds, buffId)
+	}
+
+	e.wantQuestToken(iSpec.QuestToken)
+
+	e.manifest.Items = append(e.manifest.Items, entry)
+
+	return nil
+}
+
+func (e *exporter) exportBuff(buffId int) error {
+
+	buffSpec := buffs.GetBuffSpec(buffId)
+	if buffSpec == nil {
+		return nil
+	}
+
+	entry := Entry{
+		Id:   buffId,
+		File: `buffs/` + filepath.ToSlash(buffSpec.Filepath()),
+	}
+
+	if err := e.addFile(entry.File, buffsFolder+`/`+buffSpec.Filepath()); err != nil {
+		return err
+	}
+
+	scriptFile := strings.Replace(entry.File, `.yaml`, `.js`, 1)
+	if e.addScript(scriptFile, buffSpec.GetScriptPath()) {
+		entry.Scripts = append(entry.Scripts, scriptFile)
+	}
+
+	e.manifest.Buffs = append(e.manifest.Buffs, entry)
+
+	return nil
+}
+
+func (e *exporter) exportQuest(questId int) error {
+
+	questInfo := quests.GetQuest(strconv.Itoa(questId) + `-all+`)
+	if questInfo == nil {
+		return nil
+	}
+
+	entry := Entry{
+		Id:   questId,
+		File: `quests/` + filepath.ToSlash(questInfo.Filepath()),
+	}
+
+	if err := e.addFile(entry.File, questsFolder+`/`+questInfo.Filepath()); err != nil {
+		return err
+	}
+
+	e.wantQuestToken(questInfo.Rewards.QuestId)
+	e.want(e.itemIds, questInfo.Rewards.ItemId)
+	e.want(e.buffIds, questInfo.Rewards.BuffId)
+
+	e.manifest.Quests = append(e.manifest.Quests, entry)
+
+	return nil
+}
+
+func (e *exporter) exportConversations(zone string) error {
+
+	zoneFolder := rooms.ZoneNameSanitize(zone)
+
+	conversationFiles, _ := filepath.Glob(util.FilePath(conversationsFolder, `/`, zoneFolder, `/*.yaml`))
+	sort.Strings(conversationFiles)
+
+	for _, conversationPath := range conversationFiles {
+		archivePath := `conversations/` + zoneFolder + `/` + filepath.Base(conversationPath)
+		if err := e.addFile(archivePath, conversationPath); err != nil {
+			return err
+		}
+		e.manifest.Conversations = append(e.manifest.Conversations, archivePath)
+	}
+
+	return nil
+}
+
+func (e *exporter) write(bundlePath string) error {
+
+	manifestBytes, err := yaml.Marshal(&e.manifest)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(bundlePath), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+
+	archivePaths := []string{}
+	for archivePath := range e.files {
+		archivePaths = append(archivePaths, archivePath)
+	}
+	sort.Strings(archivePaths)
+
+	w, err := zw.Create(manifestFile)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(manifestBytes); err != nil {
+		return err
+	}
+
+	for _, archivePath := range archivePaths {
+		w, err := zw.Create(archivePath)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(e.files[archivePath]); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
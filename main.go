@@ -20,7 +20,9 @@ import (
 
 	"github.com/gorilla/websocket"
 	"github.com/natefinch/lumberjack"
+	"github.com/volte6/gomud/internal/audit"
 	"github.com/volte6/gomud/internal/buffs"
+	"github.com/volte6/gomud/internal/calendar"
 	"github.com/volte6/gomud/internal/characters"
 	"github.com/volte6/gomud/internal/colorpatterns"
 	"github.com/volte6/gomud/internal/configs"
@@ -43,6 +45,7 @@ import (
 	"github.com/volte6/gomud/internal/suggestions"
 	"github.com/volte6/gomud/internal/templates"
 	"github.com/volte6/gomud/internal/term"
+	"github.com/volte6/gomud/internal/transports"
 	"github.com/volte6/gomud/internal/users"
 	"github.com/volte6/gomud/internal/util"
 	"github.com/volte6/gomud/internal/version"
@@ -74,6 +77,11 @@ func main() {
 		}
 	}()
 
+	// Balance testing, rather than running the server
+	if len(os.Args) > 1 && os.Args[1] == `simulate` {
+		os.Exit(runSimulateCommand(os.Args[2:]))
+	}
+
 	setupLogger()
 
 	flags.HandleFlags()
@@ -771,6 +779,9 @@ func loadAllDataFiles(isReload bool) {
 	templates.LoadAliases()
 	keywords.LoadAliases()
 	mutators.LoadDataFiles()
+	transports.LoadDataFiles()
+	calendar.LoadDataFiles()
+	audit.LoadDataFiles()
 	colorpatterns.LoadColorPatterns()
 	characters.CompileAdjectiveSwaps() // This should come after loading color patterns.
 }
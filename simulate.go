@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/volte6/gomud/internal/combat"
+	"github.com/volte6/gomud/internal/configs"
+)
+
+// Handles the "simulate" subcommand, which runs fights through the combat simulator and prints a report.
+// Returns the exit code.
+//
+// Example:
+//
+//	go-mud-server simulate -a "race=1 level=5 items=10001,20003" -b "mob=2" -fights 1000 -seed 7
+//
+// -min-win and -max-win fail the run (exit code 1) if side a's win rate falls outside them, for use in CI.
+func runSimulateCommand(args []string) int {
+
+	fs := flag.NewFlagSet(`simulate`, flag.ContinueOnError)
+
+	aSpec := fs.String(`a`, ``, `Side a: "mob=<id>" or "race=<id>", optionally with "level=<n>" and "items=<id>,<id>"`)
+	bSpec := fs.String(`b`, ``, `Side b, in the same format as -a`)
+	fights := fs.Int(`fights`, combat.SimDefaultFights, `How many fights to run`)
+	maxRounds := fs.Int(`rounds`, combat.SimDefaultMaxRounds, `How many rounds before a fight is a draw`)
+	seed := fs.Int64(`seed`, combat.SimDefaultSeed, `Random seed. The same seed always gives the same results.`)
+	minWin := fs.Float64(`min-win`, 0, `Fail if side a wins less than this % of fights`)
+	maxWin := fs.Float64(`max-win`, 100, `Fail if side a wins more than this % of fights`)
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	// The combat code logs every roll, which is only noise here
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})))
+
+	sideA, err := parseSimCombatant(*aSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "-a: %s\n", err)
+		return 2
+	}
+
+	sideB, err := parseSimCombatant(*bSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "-b: %s\n", err)
+		return 2
+	}
+
+	if err := configs.ReloadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "config: %s\n", err)
+		return 2
+	}
+
+	loadAllDataFiles(false)
+
+	report, err := combat.Simulate(sideA, sideB, combat.SimOptions{
+		Fights:    *fights,
+		MaxRounds: *maxRounds,
+		Seed:      *seed,
+	})
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "simulate: %s\n", err)
+		return 2
+	}
+
+	fmt.Print(report.String())
+
+	if winRate := report.WinRate(report.A); winRate < *minWin || winRate > *maxWin {
+		fmt.Fprintf(os.Stderr, "%s win rate %.1f%% is outside of %.1f%%-%.1f%%\n", report.A.Name, winRate, *minWin, *maxWin)
+		return 1
+	}
+
+	return 0
+}
+
+// Parses a combatant such as "race=1 level=5 items=10001,20003"
+func parseSimCombatant(spec string) (combat.SimCombatant, error) {
+
+	sc := combat.SimCombatant{}
+
+	for _, part := range strings.Fields(spec) {
+
+		key, value, found := strings.Cut(part, `=`)
+		if !found {
+			return sc, fmt.Errorf(`expected key=value, got "%s"`, part)
+		}
+
+		var err error
+
+		switch strings.ToLower(key) {
+		case `mob`:
+			sc.MobId, err = strconv.Atoi(value)
+		case `race`:
+			sc.RaceId, err = strconv.Atoi(value)
+		case `level`:
+			sc.Level, err = strconv.Atoi(value)
+		case `items`:
+			for _, itemIdStr := range strings.Split(value, `,`) {
+				itemId, itemErr := strconv.Atoi(itemIdStr)
+				if itemErr != nil {
+					err = itemErr
+					break
+				}
+				sc.ItemIds = append(sc.ItemIds, itemId)
+			}
+		default:
+			return sc, fmt.Errorf(`unknown key "%s"`, key)
+		}
+
+		if err != nil {
+			return sc, fmt.Errorf(`%s: %w`, key, err)
+		}
+	}
+
+	if sc.MobId == 0 && sc.RaceId == 0 {
+		return sc, combat.ErrSimNoCombatant
+	}
+
+	return sc, nil
+}
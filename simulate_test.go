@@ -0,0 +1,77 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/volte6/gomud/internal/combat"
+	"github.com/volte6/gomud/internal/configs"
+)
+
+var simDataOnce sync.Once
+
+// Loads the real datafiles, so the simulations below break when item or mob yaml changes balance
+func loadSimulationData(t *testing.T) {
+	t.Helper()
+
+	simDataOnce.Do(func() {
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})))
+
+		if err := configs.ReloadConfig(); err != nil {
+			t.Fatalf("ReloadConfig() failed: %v", err)
+		}
+		loadAllDataFiles(false)
+	})
+}
+
+func TestSimulateIsDeterministic(t *testing.T) {
+	loadSimulationData(t)
+
+	a := combat.SimCombatant{RaceId: 1, Level: 1, ItemIds: []int{10001}}
+	b := combat.SimCombatant{MobId: 1}
+	opts := combat.SimOptions{Fights: 50, Seed: 42}
+
+	first, err := combat.Simulate(a, b, opts)
+	if err != nil {
+		t.Fatalf("Simulate() failed: %v", err)
+	}
+
+	second, err := combat.Simulate(a, b, opts)
+	if err != nil {
+		t.Fatalf("Simulate() failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Same seed gave different results:\n%s\n%s", first.String(), second.String())
+	}
+}
+
+func TestSimulateBalance(t *testing.T) {
+	loadSimulationData(t)
+
+	tests := []struct {
+		name       string
+		a          combat.SimCombatant
+		b          combat.SimCombatant
+		minWinRate float64
+		maxWinRate float64
+	}{
+		{`Level 1 human with a stick vs. a rat`, combat.SimCombatant{RaceId: 1, Level: 1, ItemIds: []int{10001}}, combat.SimCombatant{MobId: 1}, 75, 100},
+		{`Level 1 human with a stick vs. a skeleton`, combat.SimCombatant{RaceId: 1, Level: 1, ItemIds: []int{10001}}, combat.SimCombatant{MobId: 15}, 0, 25},
+		{`Level 15 human with a broadsword vs. a level 15 skeleton`, combat.SimCombatant{RaceId: 1, Level: 15, ItemIds: []int{10002, 20001}}, combat.SimCombatant{MobId: 15, Level: 15}, 20, 70},
+	}
+
+	for _, test := range tests {
+		report, err := combat.Simulate(test.a, test.b, combat.SimOptions{Fights: 500, Seed: 1})
+		if err != nil {
+			t.Fatalf("%s: Simulate() failed: %v", test.name, err)
+		}
+
+		if winRate := report.WinRate(report.A); winRate < test.minWinRate || winRate > test.maxWinRate {
+			t.Errorf("%s: win rate %.1f%% outside of %.0f%%-%.0f%%\n%s", test.name, winRate, test.minWinRate, test.maxWinRate, report.String())
+		}
+	}
+}
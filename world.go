@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -116,12 +117,31 @@ func (w *World) enterWorld(userId int, roomId int) {
 	users.RemoveZombieUser(userId)
 
 	room := rooms.LoadRoom(user.Character.RoomId)
+
+	// If they were in a zone instance that has since closed, put them back at the entrance
+	if room == nil && rooms.IsInstanceRoomId(user.Character.RoomId) {
+		if entranceRoomId, ok := user.Character.GetMiscData(`InstanceEntrance`).(int); ok {
+			user.Character.SetMiscData(`InstanceEntrance`, nil)
+			if room = rooms.LoadRoom(entranceRoomId); room != nil {
+				user.Character.RoomId = room.RoomId
+				user.Character.Zone = room.Zone
+				roomId = room.RoomId
+			}
+		}
+	}
+
+	// An arena match that ended while they were away
+	if rooms.GetArenaMatchForUser(userId) == nil {
+		rooms.RestoreArenaVitals(user)
+	}
+
 	if room == nil {
 
 		slog.Error("EnterWorld", "error", fmt.Sprintf(`room %d not found`, user.Character.RoomId))
 
 		user.Character.RoomId = 1
 		user.Character.Zone = "Frostfang"
+		roomId = user.Character.RoomId
 		room = rooms.LoadRoom(user.Character.RoomId)
 		if room == nil {
 			slog.Error("EnterWorld", "error", fmt.Sprintf(`room %d not found`, user.Character.RoomId))
@@ -618,6 +638,8 @@ const (
 	roomMaintenancePeriod = time.Second * 3  // Every 3 seconds run room maintenance.
 	serverStatsLogPeriod  = time.Second * 60 // Every 60 seconds log server stats.
 	ansiAliasReloadPeriod = time.Second * 4  // Every 4 seconds reload ansi aliases.
+
+	combatNoiseRounds = 3 // Fighting is heard by nearby rooms every this many rounds
 )
 
 func (w *World) MainWorker(shutdown chan bool, wg *sync.WaitGroup) {
@@ -1093,6 +1115,26 @@ func (w *World) MessageTick() {
 				}
 			}
 
+			// Anyone watching an arena match from elsewhere sees what happens in it
+			if !message.IsQuiet {
+				for _, userId := range rooms.GetArenaSpectators(message.RoomId) {
+
+					user := users.GetByUserId(userId)
+					if user == nil || user.Character.RoomId == message.RoomId {
+						continue
+					}
+
+					if message.IsCommunication && user.Deafened {
+						continue
+					}
+
+					connections.SendTo([]byte(term.AnsiMoveCursorColumn.String()+term.AnsiEraseLine.String()+templates.AnsiParse(`<ansi fg="red-bold">[Arena]</ansi> `+message.Text)), user.ConnectionId())
+					if _, ok := redrawPrompts[user.ConnectionId()]; !ok {
+						redrawPrompts[user.ConnectionId()] = templates.AnsiParse(user.GetCommandPrompt(true))
+					}
+				}
+			}
+
 		}
 
 	}
@@ -1505,7 +1547,7 @@ func (w *World) TurnTick() {
 			continue
 		}
 
-		slog.Debug(`Event`, `type`, buff.Type(), `UserId`, buff.UserId, `MobInstanceId`, buff.MobInstanceId, `BuffId`, buff.BuffId)
+		slog.Debug(`Event`, `type`, buff.Type(), `UserId`, buff.UserId, `MobInstanceId`, buff.MobInstanceId, `BuffId`, buff.BuffId, `SourceUserId`, buff.SourceUserId, `SourceMobInstanceId`, buff.SourceMobInstanceId)
 
 		buffInfo := buffs.GetBuffSpec(buff.BuffId)
 		if buffInfo == nil {
@@ -1534,7 +1576,23 @@ func (w *World) TurnTick() {
 		}
 
 		// Apply the buff
-		targetChar.AddBuff(buff.BuffId, false)
+		// It may not take, due to its stacking rules or diminishing returns
+		if err := targetChar.AddBuff(buff.BuffId, false, buffs.Source{UserId: buff.SourceUserId, MobInstanceId: buff.SourceMobInstanceId}); err != nil {
+			slog.Debug(`Buff`, `error`, err)
+
+			if errors.Is(err, buffs.ErrBuffImmune) && !buffInfo.Secret {
+				nameColor := `username`
+				if buff.MobInstanceId > 0 {
+					nameColor = `mobname`
+				}
+				if room := rooms.LoadRoom(targetChar.RoomId); room != nil {
+					room.SendText(fmt.Sprintf(`<ansi fg="%s">%s</ansi> shrugs off the effects of <ansi fg="buffname">%s</ansi>.`, nameColor, targetChar.Name, buffInfo.Name))
+				}
+			}
+			continue
+		}
+
+		healthBefore := targetChar.Health
 
 		//
 		// Fire onStart for buff script
@@ -1558,6 +1616,12 @@ func (w *World) TurnTick() {
 			}
 		}
 
+		if buff.MobInstanceId == 0 {
+			if buffUser := users.GetByUserId(buff.UserId); buffUser != nil {
+				addBuffHealingThreat(buffUser, buff.SourceUserId, healthBefore)
+			}
+		}
+
 	}
 
 	//
@@ -1721,6 +1785,28 @@ func (w *World) TurnTick() {
 
 	}
 
+	//
+	// Handle ZoneReset Queue
+	//
+	eq = events.GetQueue(events.ZoneReset{})
+	for eq.Len() > 0 {
+
+		e := eq.Poll().(events.Event)
+
+		zoneReset, typeOk := e.(events.ZoneReset)
+		if !typeOk {
+			slog.Error("Event", "Expected Type", "ZoneReset", "Actual Type", e.Type())
+			continue
+		}
+
+		slog.Debug(`Event`, `type`, zoneReset.Type(), `Zone`, zoneReset.Zone, `RoomCount`, len(zoneReset.RoomIds))
+
+		for _, roomId := range zoneReset.RoomIds {
+			scripting.TryRoomZoneResetEvent(zoneReset.Zone, roomId)
+		}
+
+	}
+
 	//
 	// Prune all buffs that have expired.
 	//
@@ -9,6 +9,7 @@ import (
 
 	"github.com/volte6/gomud/internal/auctions"
 	"github.com/volte6/gomud/internal/buffs"
+	"github.com/volte6/gomud/internal/calendar"
 	"github.com/volte6/gomud/internal/characters"
 	"github.com/volte6/gomud/internal/colorpatterns"
 	"github.com/volte6/gomud/internal/combat"
@@ -24,8 +25,10 @@ import (
 	"github.com/volte6/gomud/internal/spells"
 	"github.com/volte6/gomud/internal/templates"
 	"github.com/volte6/gomud/internal/term"
+	"github.com/volte6/gomud/internal/transports"
 	"github.com/volte6/gomud/internal/users"
 	"github.com/volte6/gomud/internal/util"
+	"github.com/volte6/gomud/internal/weather"
 )
 
 func (w *World) roundTick() {
@@ -64,6 +67,32 @@ func (w *World) roundTick() {
 		}
 	}
 
+	//
+	// Update the weather wherever players are
+	//
+	w.handleWeather(roundNumber)
+
+	//
+	// Move any ferries, carriages etc. along their schedules
+	//
+	transports.RoundTick(roundNumber)
+
+	//
+	// Start and end festivals and other calendar events
+	//
+	for _, change := range calendar.RoundTick(roundNumber) {
+		if change.Started {
+			scripting.TryCalendarScriptEvent(`onStart`, change.EventId)
+		} else {
+			scripting.TryCalendarScriptEvent(`onEnd`, change.EventId)
+		}
+	}
+
+	//
+	// Collect rent on player houses
+	//
+	rooms.ChargeHouseRent(roundNumber)
+
 	//
 	// Disconnect players that have been inactive too long
 	//
@@ -118,9 +147,15 @@ func (w *World) roundTick() {
 
 	affectedPlayers2, affectedMobs2 := w.handleMobCombat()
 
+	// Anyone losing a duel yields before they can fall
+	rooms.DuelRoundTick(roundNumber)
+
 	// Do any resolution or extra checks based on everyone that has been involved in combat this round.
 	w.handleAffected(append(affectedPlayers1, affectedPlayers2...), append(affectedMobs1, affectedMobs2...))
 
+	// Let the surrounding area hear the fighting
+	w.handleCombatNoise(roundNumber, append(affectedPlayers1, affectedPlayers2...), append(affectedMobs1, affectedMobs2...))
+
 	//
 	// Healing
 	//
@@ -181,6 +216,49 @@ func (w *World) handleInactivePlayers(maxIdleRounds int) {
 
 }
 
+// Evolves the weather of any zones players are in, and lets them know when it changes
+func (w *World) handleWeather(roundNumber uint64) {
+
+	zoneRooms := map[string][]*rooms.Room{}
+	for _, roomId := range rooms.GetRoomsWithPlayers() {
+		if room := rooms.LoadRoom(roomId); room != nil {
+			zoneRooms[room.Zone] = append(zoneRooms[room.Zone], room)
+		}
+	}
+
+	for zoneName, roomList := range zoneRooms {
+
+		climate := weather.Temperate
+		if b, ok := rooms.GetBiome(rooms.GetZoneBiome(zoneName)); ok && !b.IsSheltered() {
+			climate = b.Climate()
+		}
+
+		before, after, changed := weather.Update(zoneName, climate, roundNumber)
+		if !changed {
+			continue
+		}
+
+		msg := ``
+		if wInfo, ok := weather.GetConditionInfo(after); ok && wInfo.StartText != `` {
+			msg = wInfo.StartText
+		} else if wInfo, ok := weather.GetConditionInfo(before); ok {
+			msg = wInfo.EndText
+		}
+
+		if msg == `` {
+			continue
+		}
+
+		for _, room := range roomList {
+			if room.GetBiome().IsSheltered() {
+				continue
+			}
+			room.SendText(msg)
+		}
+	}
+
+}
+
 // Round ticks for players
 func (w *World) handlePlayerRoundTicks() {
 
@@ -257,7 +335,9 @@ func (w *World) handlePlayerRoundTicks() {
 					//
 					for _, buff := range triggeredBuffs {
 						if !buff.Expired() {
+							healthBefore := user.Character.Health
 							scripting.TryBuffScriptEvent(`onTrigger`, uId, 0, buff.BuffId)
+							addBuffHealingThreat(user, buff.SourceUserId, healthBefore)
 						}
 					}
 
@@ -566,7 +646,7 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 				// fail
 				user.SendText(fmt.Sprintf(`<ansi fg="spell-text"><ansi fg="magenta">***</ansi> Your spell fizzles! <ansi fg="magenta">***</ansi> (Rolled %d on %d%% chance of success)</ansi>`, roll, successChance))
 				uRoom.SendText(fmt.Sprintf(`<ansi fg="spell-text"><ansi fg="username">%s</ansi> tries to cast a spell but it <ansi fg="magenta">fizzles</ansi>!</ansi>`, user.Character.Name), userId)
-				user.Character.Aggro = nil
+				user.Character.EndAggro()
 
 				continue
 
@@ -586,6 +666,14 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 				}
 			}
 
+			// Healing players draws threat, so track their health too
+			userHealthBefore := map[int]int{}
+			for _, uId := range user.Character.Aggro.SpellInfo.TargetUserIds {
+				if defUser := users.GetByUserId(uId); defUser != nil {
+					userHealthBefore[uId] = defUser.Character.Health
+				}
+			}
+
 			allowRetaliation := true
 			if handled, err := scripting.TrySpellScriptEvent(`onMagic`, user.UserId, 0, user.Character.Aggro.SpellInfo); err == nil {
 				if handled {
@@ -595,6 +683,14 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 
 			user.Character.TrackSpellCast(user.Character.Aggro.SpellInfo.SpellId)
 
+			for uId, hBefore := range userHealthBefore {
+				if defUser := users.GetByUserId(uId); defUser != nil {
+					if hDelta := defUser.Character.Health - hBefore; hDelta > 0 {
+						mobs.AddHealingThreat(defUser.Character.RoomId, user.UserId, uId, hDelta)
+					}
+				}
+			}
+
 			if allowRetaliation {
 				if spellData := spells.GetSpell(user.Character.Aggro.SpellInfo.SpellId); spellData != nil {
 
@@ -630,7 +726,7 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 				}
 			}
 
-			user.Character.Aggro = nil
+			user.Character.EndAggro()
 
 			continue
 
@@ -656,7 +752,7 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 			uRoom := rooms.LoadRoom(roomId)
 
 			if uRoom == nil {
-				user.Character.Aggro = nil
+				user.Character.EndAggro()
 				continue
 			}
 
@@ -678,13 +774,13 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 
 			if !targetFound {
 				user.SendText(`Your target can't be found.`)
-				user.Character.Aggro = nil
+				user.Character.EndAggro()
 				continue
 			}
 
 			defRoom := rooms.LoadRoom(defUser.Character.RoomId)
 			if defRoom == nil {
-				user.Character.Aggro = nil
+				user.Character.EndAggro()
 				continue
 			}
 
@@ -692,7 +788,7 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 
 			if defUser.Character.Health < 1 {
 				user.SendText(`Your rage subsides.`)
-				user.Character.Aggro = nil
+				user.Character.EndAggro()
 				continue
 			}
 
@@ -734,9 +830,15 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 
 			affectedPlayerIds = append(affectedPlayerIds, user.Character.Aggro.UserId)
 
+			// Scripts can take over the round, such as for a special attack
+			user.Character.CombatRounds++
+			if scripting.TryCombatRoundEvent(user.UserId, 0, defUser.UserId, 0) {
+				continue
+			}
+
 			var roundResult combat.AttackResult
 
-			roundResult = combat.AttackPlayerVsPlayer(user, defUser)
+			roundResult = combat.AttackPlayerVsPlayer(user, defUser, scripting.GetAttackModifier(user.UserId, 0, defUser.UserId, 0))
 
 			// If a mob attacks a player, check whether player has a charmed mob helping them, and if so, they will move to attack back
 			room := rooms.LoadRoom(roomId)
@@ -761,6 +863,7 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 					UserId:        user.UserId,
 					MobInstanceId: 0,
 					BuffId:        buffId,
+					SourceUserId:  user.UserId,
 				})
 
 			}
@@ -771,6 +874,7 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 					UserId:        defUser.UserId,
 					MobInstanceId: 0,
 					BuffId:        buffId,
+					SourceUserId:  user.UserId,
 				})
 
 			}
@@ -822,6 +926,8 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 				}
 			}
 
+			scripting.TryAttackResultEvents(user.UserId, 0, defUser.UserId, 0, roundResult)
+
 			if user.Character.Health <= 0 || defUser.Character.Health <= 0 {
 				defUser.Character.EndAggro()
 				user.Character.EndAggro()
@@ -850,7 +956,7 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 
 					uRoom := rooms.LoadRoom(roomId)
 					if uRoom == nil {
-						user.Character.Aggro = nil
+						user.Character.EndAggro()
 						continue
 					}
 
@@ -865,7 +971,7 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 
 			if !targetFound {
 				user.SendText("Your target can't be found.")
-				user.Character.Aggro = nil
+				user.Character.EndAggro()
 				continue
 			}
 
@@ -875,7 +981,7 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 
 			if defMob.Character.Health < 1 {
 				user.SendText("Your rage subsides.")
-				user.Character.Aggro = nil
+				user.Character.EndAggro()
 				continue
 			}
 
@@ -909,9 +1015,15 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 
 			affectedPlayerIds = append(affectedPlayerIds, user.Character.Aggro.UserId)
 
+			// Scripts can take over the round, such as for a special attack
+			user.Character.CombatRounds++
+			if scripting.TryCombatRoundEvent(user.UserId, 0, 0, defMob.InstanceId) {
+				continue
+			}
+
 			var roundResult combat.AttackResult
 
-			roundResult = combat.AttackPlayerVsMob(user, defMob)
+			roundResult = combat.AttackPlayerVsMob(user, defMob, scripting.GetAttackModifier(user.UserId, 0, 0, defMob.InstanceId))
 
 			for _, buffId := range roundResult.BuffSource {
 
@@ -919,6 +1031,7 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 					UserId:        user.UserId,
 					MobInstanceId: 0,
 					BuffId:        buffId,
+					SourceUserId:  user.UserId,
 				})
 
 			}
@@ -929,6 +1042,7 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 					UserId:        0,
 					MobInstanceId: defMob.InstanceId,
 					BuffId:        buffId,
+					SourceUserId:  user.UserId,
 				})
 
 			}
@@ -980,6 +1094,8 @@ func (w *World) handlePlayerCombat() (affectedPlayerIds []int, affectedMobInstan
 				defMob.Command(fmt.Sprintf("attack @%d", user.UserId)) // @ means player
 			}
 
+			scripting.TryAttackResultEvents(user.UserId, 0, 0, defMob.InstanceId, roundResult)
+
 			if user.Character.Health <= 0 || defMob.Character.Health <= 0 {
 				defMob.Character.EndAggro()
 				user.Character.EndAggro()
@@ -1027,7 +1143,7 @@ func (w *World) handleMobCombat() (affectedPlayerIds []int, affectedMobInstanceI
 		mobRoom := rooms.LoadRoom(mob.Character.RoomId)
 
 		if mobRoom == nil {
-			mob.Character.Aggro = nil
+			mob.Character.EndAggro()
 			continue
 		}
 
@@ -1055,7 +1171,7 @@ func (w *World) handleMobCombat() (affectedPlayerIds []int, affectedMobInstanceI
 
 				// fail
 				mobRoom.SendText(fmt.Sprintf(`<ansi fg="mobnamme">%s</ansi> tries to cast a spell but it <ansi fg="magenta">fizzles</ansi>!`, mob.Character.Name))
-				mob.Character.Aggro = nil
+				mob.Character.EndAggro()
 
 				continue
 
@@ -1095,7 +1211,7 @@ func (w *World) handleMobCombat() (affectedPlayerIds []int, affectedMobInstanceI
 				}
 			}
 
-			mob.Character.Aggro = nil
+			mob.Character.EndAggro()
 
 			continue
 
@@ -1142,6 +1258,26 @@ func (w *World) handleMobCombat() (affectedPlayerIds []int, affectedMobInstanceI
 			}
 
 		}
+
+		// Turn on whoever is hated the most
+		if mob.Character.Aggro != nil && mob.Character.Aggro.UserId > 0 && mob.Character.Aggro.Type == characters.DefaultAttack {
+
+			currentUserId := mob.Character.Aggro.UserId
+
+			newUserId := mob.Character.ThreatTarget(currentUserId, int(c.ThreatStickiness), func(userId int) bool {
+				u := users.GetByUserId(userId)
+				return u != nil && u.Character.RoomId == mob.Character.RoomId && u.Character.Health > 0 && !u.Character.HasBuffFlag(buffs.Hidden)
+			})
+
+			if newUserId > 0 && newUserId != currentUserId {
+				if newUser := users.GetByUserId(newUserId); newUser != nil {
+					mob.Character.SetAggro(newUserId, 0, characters.DefaultAttack)
+					newUser.SendText(fmt.Sprintf(`<ansi fg="mobname">%s</ansi> turns to attack <ansi fg="red">you</ansi>!`, mob.Character.Name))
+					mobRoom.SendText(fmt.Sprintf(`<ansi fg="mobname">%s</ansi> turns to attack <ansi fg="username">%s</ansi>!`, mob.Character.Name, newUser.Character.Name), newUserId)
+				}
+			}
+		}
+
 		roomId := mob.Character.RoomId
 
 		affectedMobInstanceIds = append(affectedMobInstanceIds, mob.InstanceId)
@@ -1151,20 +1287,20 @@ func (w *World) handleMobCombat() (affectedPlayerIds []int, affectedMobInstanceI
 
 			defUser := users.GetByUserId(mob.Character.Aggro.UserId)
 			if defUser == nil || mob.Character.RoomId != defUser.Character.RoomId {
-				mob.Character.Aggro = nil
+				mob.Character.EndAggro()
 				continue
 			}
 
 			defRoom := rooms.LoadRoom(defUser.Character.RoomId)
 			if defRoom == nil {
-				mob.Character.Aggro = nil
+				mob.Character.EndAggro()
 				continue
 			}
 
 			defUser.Character.CancelBuffsWithFlag(buffs.CancelIfCombat)
 
 			if defUser.Character.Health < 1 {
-				mob.Character.Aggro = nil
+				mob.Character.EndAggro()
 				continue
 			}
 
@@ -1221,9 +1357,15 @@ func (w *World) handleMobCombat() (affectedPlayerIds []int, affectedMobInstanceI
 				continue
 			}
 
+			// Scripts can take over the round, such as for a special attack
+			mob.Character.CombatRounds++
+			if scripting.TryCombatRoundEvent(0, mob.InstanceId, defUser.UserId, 0) {
+				continue
+			}
+
 			var roundResult combat.AttackResult
 
-			roundResult = combat.AttackMobVsPlayer(mob, defUser)
+			roundResult = combat.AttackMobVsPlayer(mob, defUser, scripting.GetAttackModifier(0, mob.InstanceId, defUser.UserId, 0))
 
 			// If a mob attacks a player, check whether player has a charmed mob helping them, and if so, they will move to attack back
 			room := rooms.LoadRoom(roomId)
@@ -1244,9 +1386,10 @@ func (w *World) handleMobCombat() (affectedPlayerIds []int, affectedMobInstanceI
 			for _, buffId := range roundResult.BuffSource {
 
 				events.AddToQueue(events.Buff{
-					UserId:        0,
-					MobInstanceId: mob.InstanceId,
-					BuffId:        buffId,
+					UserId:              0,
+					MobInstanceId:       mob.InstanceId,
+					BuffId:              buffId,
+					SourceMobInstanceId: mob.InstanceId,
 				})
 
 			}
@@ -1254,9 +1397,10 @@ func (w *World) handleMobCombat() (affectedPlayerIds []int, affectedMobInstanceI
 			for _, buffId := range roundResult.BuffTarget {
 
 				events.AddToQueue(events.Buff{
-					UserId:        defUser.UserId,
-					MobInstanceId: 0,
-					BuffId:        buffId,
+					UserId:              defUser.UserId,
+					MobInstanceId:       0,
+					BuffId:              buffId,
+					SourceMobInstanceId: mob.InstanceId,
 				})
 
 			}
@@ -1302,6 +1446,8 @@ func (w *World) handleMobCombat() (affectedPlayerIds []int, affectedMobInstanceI
 				}
 			}
 
+			scripting.TryAttackResultEvents(0, mob.InstanceId, defUser.UserId, 0, roundResult)
+
 			if mob.Character.Health <= 0 || defUser.Character.Health <= 0 {
 				mob.Character.EndAggro()
 				defUser.Character.EndAggro()
@@ -1318,7 +1464,7 @@ func (w *World) handleMobCombat() (affectedPlayerIds []int, affectedMobInstanceI
 			defMob := mobs.GetInstance(mob.Character.Aggro.MobInstanceId)
 
 			if defMob == nil || mob.Character.RoomId != defMob.Character.RoomId {
-				mob.Character.Aggro = nil
+				mob.Character.EndAggro()
 				continue
 			}
 
@@ -1327,7 +1473,7 @@ func (w *World) handleMobCombat() (affectedPlayerIds []int, affectedMobInstanceI
 			defMob.Character.CancelBuffsWithFlag(buffs.CancelIfCombat)
 
 			if defMob.Character.Health < 1 {
-				mob.Character.Aggro = nil
+				mob.Character.EndAggro()
 				continue
 			}
 
@@ -1354,16 +1500,23 @@ func (w *World) handleMobCombat() (affectedPlayerIds []int, affectedMobInstanceI
 				continue
 			}
 
+			// Scripts can take over the round, such as for a special attack
+			mob.Character.CombatRounds++
+			if scripting.TryCombatRoundEvent(0, mob.InstanceId, 0, defMob.InstanceId) {
+				continue
+			}
+
 			var roundResult combat.AttackResult
 
-			roundResult = combat.AttackMobVsMob(mob, defMob)
+			roundResult = combat.AttackMobVsMob(mob, defMob, scripting.GetAttackModifier(0, mob.InstanceId, 0, defMob.InstanceId))
 
 			for _, buffId := range roundResult.BuffSource {
 
 				events.AddToQueue(events.Buff{
-					UserId:        0,
-					MobInstanceId: mob.InstanceId,
-					BuffId:        buffId,
+					UserId:              0,
+					MobInstanceId:       mob.InstanceId,
+					BuffId:              buffId,
+					SourceMobInstanceId: mob.InstanceId,
 				})
 
 			}
@@ -1371,9 +1524,10 @@ func (w *World) handleMobCombat() (affectedPlayerIds []int, affectedMobInstanceI
 			for _, buffId := range roundResult.BuffTarget {
 
 				events.AddToQueue(events.Buff{
-					UserId:        0,
-					MobInstanceId: defMob.InstanceId,
-					BuffId:        buffId,
+					UserId:              0,
+					MobInstanceId:       defMob.InstanceId,
+					BuffId:              buffId,
+					SourceMobInstanceId: mob.InstanceId,
 				})
 			}
 
@@ -1426,6 +1580,8 @@ func (w *World) handleMobCombat() (affectedPlayerIds []int, affectedMobInstanceI
 				}
 			}
 
+			scripting.TryAttackResultEvents(0, mob.InstanceId, 0, defMob.InstanceId, roundResult)
+
 			if mob.Character.Health <= 0 || defMob.Character.Health <= 0 {
 				mob.Character.EndAggro()
 				defMob.Character.EndAggro()
@@ -1532,7 +1688,7 @@ func (w *World) handleIdleMobs() {
 				user := users.GetByUserId(mob.Character.Aggro.UserId)
 				if user == nil || user.Character.RoomId != mob.Character.RoomId {
 					mob.Command(`emote mumbles about losing their quarry.`)
-					mob.Character.Aggro = nil
+					mob.Character.EndAggro()
 				}
 			}
 			continue
@@ -1912,3 +2068,66 @@ func (w *World) processAuction(tNow time.Time) {
 	}
 
 }
+
+// Fighting can be heard a few rooms away. It only goes out every few rounds so nobody gets flooded.
+// If everyone fighting in a room is sneaking, it doesn't carry as far.
+func (w *World) handleCombatNoise(roundNumber uint64, affectedPlayerIds []int, affectedMobInstanceIds []int) {
+
+	if roundNumber%combatNoiseRounds != 0 {
+		return
+	}
+
+	// roomId => whether everyone fighting there is sneaking
+	fightingRooms := map[int]bool{}
+
+	addFighter := func(roomId int, isSneaking bool) {
+		if allSneaking, ok := fightingRooms[roomId]; ok {
+			fightingRooms[roomId] = allSneaking && isSneaking
+			return
+		}
+		fightingRooms[roomId] = isSneaking
+	}
+
+	for _, userId := range affectedPlayerIds {
+		if user := users.GetByUserId(userId); user != nil && user.Character.Aggro != nil {
+			addFighter(user.Character.RoomId, user.Character.HasBuffFlag(buffs.Hidden))
+		}
+	}
+
+	for _, mobInstanceId := range affectedMobInstanceIds {
+		if mob := mobs.GetInstance(mobInstanceId); mob != nil && mob.Character.Aggro != nil {
+			addFighter(mob.Character.RoomId, mob.Character.HasBuffFlag(buffs.Hidden))
+		}
+	}
+
+	for roomId, allSneaking := range fightingRooms {
+
+		room := rooms.LoadRoom(roomId)
+		if room == nil {
+			continue
+		}
+
+		fightSound := rooms.Sound{Description: `fighting`, Volume: 2}
+		if allSneaking {
+			fightSound = fightSound.Sneaking()
+		}
+
+		room.PropagateSound(fightSound)
+	}
+}
+
+// Healing from a buff draws threat to whoever applied it.
+// If nobody did (potions, food and so on) the healed user draws it themselves.
+func addBuffHealingThreat(user *users.UserRecord, sourceUserId int, healthBefore int) {
+
+	healed := user.Character.Health - healthBefore
+	if healed < 1 {
+		return
+	}
+
+	if sourceUserId < 1 {
+		sourceUserId = user.UserId
+	}
+
+	mobs.AddHealingThreat(user.Character.RoomId, sourceUserId, user.UserId, healed)
+}